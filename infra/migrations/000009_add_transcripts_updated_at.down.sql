@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transcripts_processing_updated_at;
+
+ALTER TABLE transcripts DROP COLUMN IF EXISTS updated_at;
@@ -0,0 +1,5 @@
+-- Track the last status change so stuck transcripts can be detected
+ALTER TABLE transcripts ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW();
+
+-- Partial index for the stale transcript reaper
+CREATE INDEX IF NOT EXISTS idx_transcripts_processing_updated_at ON transcripts(updated_at) WHERE status = 'processing';
@@ -0,0 +1,6 @@
+DROP INDEX IF EXISTS idx_chunks_transcript_speaker;
+
+ALTER TABLE transcript_speakers
+    DROP COLUMN IF EXISTS renamed_at,
+    DROP COLUMN IF EXISTS renamed_by,
+    DROP COLUMN IF EXISTS is_human_set;
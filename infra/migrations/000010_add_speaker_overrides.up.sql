@@ -0,0 +1,8 @@
+-- Speakers renamed by a person are never overwritten by LLM inference
+ALTER TABLE transcript_speakers
+    ADD COLUMN IF NOT EXISTS is_human_set BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS renamed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN IF NOT EXISTS renamed_at TIMESTAMP WITH TIME ZONE;
+
+-- Speeds up remapping chunks when speakers are merged
+CREATE INDEX IF NOT EXISTS idx_chunks_transcript_speaker ON transcript_chunks(transcript_id, speaker_index);
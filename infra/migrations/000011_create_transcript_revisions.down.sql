@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_transcript_revisions_transcript_id;
+
+DROP TABLE IF EXISTS transcript_revisions;
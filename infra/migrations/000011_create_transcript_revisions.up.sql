@@ -0,0 +1,19 @@
+-- Revisions of transcript text over a range of chunk positions.
+-- transcript_chunks always holds the latest text; revisions keep the history.
+CREATE TABLE IF NOT EXISTS transcript_revisions (
+    id SERIAL PRIMARY KEY,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    start_position INTEGER NOT NULL,
+    end_position INTEGER NOT NULL,
+    old_text TEXT NOT NULL,
+    new_text TEXT NOT NULL,
+    old_chunk_texts TEXT[] NOT NULL,
+    new_chunk_texts TEXT[] NOT NULL,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    reverts_revision_id INTEGER REFERENCES transcript_revisions(id) ON DELETE SET NULL,
+    reverted_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (start_position <= end_position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_transcript_revisions_transcript_id ON transcript_revisions(transcript_id);
@@ -0,0 +1,3 @@
+ALTER TABLE transcripts DROP COLUMN IF EXISTS options;
+
+ALTER TABLE podcasts DROP COLUMN IF EXISTS transcription_options;
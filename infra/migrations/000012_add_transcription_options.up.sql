@@ -0,0 +1,5 @@
+-- Per-podcast transcription preferences (language, model, keyterms, smart_format)
+ALTER TABLE podcasts ADD COLUMN IF NOT EXISTS transcription_options JSONB NOT NULL DEFAULT '{}';
+
+-- Options actually used to produce a transcript, kept for reproducibility
+ALTER TABLE transcripts ADD COLUMN IF NOT EXISTS options JSONB;
@@ -0,0 +1 @@
+ALTER TABLE transcript_chunks DROP COLUMN IF EXISTS confidence;
@@ -0,0 +1,2 @@
+-- Word confidence reported by the provider; NULL when the provider does not report it
+ALTER TABLE transcript_chunks ADD COLUMN IF NOT EXISTS confidence REAL;
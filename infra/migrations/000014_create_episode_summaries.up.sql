@@ -0,0 +1,15 @@
+-- LLM summaries of episode transcripts. A row stays readable while it is regenerated:
+-- status goes back to 'processing' and the previous content is kept until replaced.
+CREATE TABLE IF NOT EXISTS episode_summaries (
+    id SERIAL PRIMARY KEY,
+    episode_id INTEGER NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    status transcript_status NOT NULL DEFAULT 'processing',
+    tldr TEXT NOT NULL DEFAULT '',
+    key_points JSONB NOT NULL DEFAULT '[]',
+    sections JSONB NOT NULL DEFAULT '[]',
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(episode_id)
+);
@@ -0,0 +1,13 @@
+-- LLM chapters of episode transcripts, aligned to chunk start times. Like summaries,
+-- the previous chapters stay readable while they are regenerated.
+CREATE TABLE IF NOT EXISTS episode_chapters (
+    id SERIAL PRIMARY KEY,
+    episode_id INTEGER NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    status transcript_status NOT NULL DEFAULT 'processing',
+    chapters JSONB NOT NULL DEFAULT '[]',
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(episode_id)
+);
@@ -0,0 +1,16 @@
+-- Embeddings of overlapping transcript passages for semantic search. Vectors are stored
+-- as plain arrays (no pgvector) and searched by an in-memory index in the server.
+CREATE TABLE IF NOT EXISTS transcript_embeddings (
+    id SERIAL PRIMARY KEY,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    episode_id INTEGER NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    start_position INTEGER NOT NULL,
+    end_position INTEGER NOT NULL,
+    start_time FLOAT NOT NULL,
+    end_time FLOAT NOT NULL,
+    text TEXT NOT NULL,
+    model VARCHAR(100) NOT NULL,
+    embedding REAL[] NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE (transcript_id, start_position)
+);
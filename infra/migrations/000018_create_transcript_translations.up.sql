@@ -0,0 +1,13 @@
+-- LLM translations of transcripts, one per language. Each segment is a translated
+-- speaker turn keeping the positions and times of its chunks.
+CREATE TABLE IF NOT EXISTS transcript_translations (
+    id SERIAL PRIMARY KEY,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    language VARCHAR(16) NOT NULL,
+    status transcript_status NOT NULL DEFAULT 'processing',
+    segments JSONB NOT NULL DEFAULT '[]',
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(transcript_id, language)
+);
@@ -0,0 +1,14 @@
+-- Speaker analytics computed from chunk timings when a transcript completes and after
+-- edits. Speaker names are read from transcript_speakers so renames apply immediately.
+CREATE TABLE IF NOT EXISTS transcript_analytics (
+    id SERIAL PRIMARY KEY,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    duration FLOAT NOT NULL,
+    words INTEGER NOT NULL,
+    words_per_minute FLOAT NOT NULL,
+    turns INTEGER NOT NULL,
+    interruptions INTEGER NOT NULL,
+    speakers JSONB NOT NULL DEFAULT '[]',
+    computed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(transcript_id)
+);
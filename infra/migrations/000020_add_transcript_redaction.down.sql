@@ -0,0 +1,3 @@
+ALTER TABLE transcript_chunks DROP COLUMN IF EXISTS original_text;
+ALTER TABLE podcasts DROP COLUMN IF EXISTS redaction_policy;
+ALTER TABLE users DROP COLUMN IF EXISTS is_admin;
@@ -0,0 +1,9 @@
+-- Admins choose the redaction policy of each podcast
+ALTER TABLE users ADD COLUMN IF NOT EXISTS is_admin BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Redaction policy (pii, profanity, llm, terms) applied to new transcripts of the podcast
+ALTER TABLE podcasts ADD COLUMN IF NOT EXISTS redaction_policy JSONB NOT NULL DEFAULT '{}';
+
+-- Text of redacted chunks before masking. Only selected for admins; NULL when the chunk
+-- was not redacted.
+ALTER TABLE transcript_chunks ADD COLUMN IF NOT EXISTS original_text TEXT;
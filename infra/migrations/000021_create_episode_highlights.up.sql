@@ -0,0 +1,14 @@
+-- LLM highlights of episode transcripts: the most quotable passages, each mapped to an
+-- exact range of chunk positions. Like chapters, the previous highlights stay readable
+-- while they are regenerated.
+CREATE TABLE IF NOT EXISTS episode_highlights (
+    id SERIAL PRIMARY KEY,
+    episode_id INTEGER NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    transcript_id INTEGER NOT NULL REFERENCES transcripts(id) ON DELETE CASCADE,
+    status transcript_status NOT NULL DEFAULT 'processing',
+    highlights JSONB NOT NULL DEFAULT '[]',
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(episode_id)
+);
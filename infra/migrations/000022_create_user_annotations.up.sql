@@ -0,0 +1,19 @@
+-- Highlights and notes of users on ranges of an episode transcript. The range is anchored to
+-- the start and end times of its chunks, so it is resolved again against the current chunks
+-- when the transcript is corrected or transcribed again; the positions are the last known ones.
+CREATE TABLE IF NOT EXISTS user_annotations (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    episode_id INTEGER NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    start_position INTEGER NOT NULL,
+    end_position INTEGER NOT NULL,
+    start_time FLOAT NOT NULL,
+    end_time FLOAT NOT NULL,
+    color VARCHAR(16) NOT NULL DEFAULT 'yellow',
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (start_position <= end_position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_annotations_user_episode ON user_annotations(user_id, episode_id);
@@ -0,0 +1,15 @@
+DROP TABLE IF EXISTS user_quiz_preferences;
+
+DROP INDEX IF EXISTS idx_user_quiz_sessions_user_bank;
+ALTER TABLE user_quiz_sessions DROP COLUMN IF EXISTS bank_id;
+
+-- Only the questions of the former fixed configuration fit the former per episode positions
+DELETE FROM questions q USING question_banks b
+WHERE b.id = q.bank_id
+  AND NOT (b.multiple_choice = 1 AND b.true_false = 1 AND b.open_ended = 1 AND b.difficulty = 'medium');
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_bank_id_position_key;
+ALTER TABLE questions DROP COLUMN IF EXISTS bank_id;
+ALTER TABLE questions ADD CONSTRAINT questions_episode_id_position_key UNIQUE(episode_id, position);
+
+DROP TABLE IF EXISTS question_banks;
+DROP TYPE IF EXISTS quiz_difficulty;
@@ -0,0 +1,41 @@
+CREATE TYPE quiz_difficulty AS ENUM ('easy', 'medium', 'hard');
+
+-- Question banks group the questions of an episode generated for one quiz configuration:
+-- the number of questions of each type and their difficulty
+CREATE TABLE IF NOT EXISTS question_banks (
+    id SERIAL PRIMARY KEY,
+    episode_id INTEGER NOT NULL REFERENCES episodes(id) ON DELETE CASCADE,
+    multiple_choice INTEGER NOT NULL,
+    true_false INTEGER NOT NULL,
+    open_ended INTEGER NOT NULL,
+    difficulty quiz_difficulty NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(episode_id, multiple_choice, true_false, open_ended, difficulty),
+    CHECK (multiple_choice >= 0 AND true_false >= 0 AND open_ended >= 0)
+);
+
+-- Existing questions were generated with the former fixed configuration
+INSERT INTO question_banks (episode_id, multiple_choice, true_false, open_ended, difficulty)
+SELECT DISTINCT episode_id, 1, 1, 1, 'medium'::quiz_difficulty FROM questions;
+
+ALTER TABLE questions ADD COLUMN bank_id INTEGER REFERENCES question_banks(id) ON DELETE CASCADE;
+UPDATE questions q SET bank_id = b.id FROM question_banks b WHERE b.episode_id = q.episode_id;
+ALTER TABLE questions ALTER COLUMN bank_id SET NOT NULL;
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_episode_id_position_key;
+ALTER TABLE questions ADD CONSTRAINT questions_bank_id_position_key UNIQUE(bank_id, position);
+
+-- Sessions of episodes without questions stay without a bank
+ALTER TABLE user_quiz_sessions ADD COLUMN bank_id INTEGER REFERENCES question_banks(id) ON DELETE CASCADE;
+UPDATE user_quiz_sessions s SET bank_id = b.id FROM question_banks b WHERE b.episode_id = s.episode_id;
+
+-- Quiz configuration of users, used when a quiz request doesn't set one
+CREATE TABLE IF NOT EXISTS user_quiz_preferences (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    multiple_choice INTEGER NOT NULL,
+    true_false INTEGER NOT NULL,
+    open_ended INTEGER NOT NULL,
+    difficulty quiz_difficulty NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_quiz_sessions_user_bank ON user_quiz_sessions(user_id, bank_id);
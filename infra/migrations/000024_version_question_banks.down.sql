@@ -0,0 +1,12 @@
+ALTER TABLE user_answers DROP CONSTRAINT IF EXISTS user_answers_question_id_fkey;
+ALTER TABLE user_answers ADD CONSTRAINT user_answers_question_id_fkey
+    FOREIGN KEY (question_id) REFERENCES questions(id) ON DELETE CASCADE;
+
+-- Archived banks don't fit the former unique configuration per episode
+DELETE FROM question_banks WHERE archived_at IS NOT NULL;
+
+DROP INDEX IF EXISTS idx_question_banks_active_config;
+ALTER TABLE question_banks ADD UNIQUE(episode_id, multiple_choice, true_false, open_ended, difficulty);
+
+ALTER TABLE question_banks DROP COLUMN IF EXISTS archived_at;
+ALTER TABLE question_banks DROP COLUMN IF EXISTS version;
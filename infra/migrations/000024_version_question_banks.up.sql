@@ -0,0 +1,25 @@
+-- Regenerating the questions of a bank used by sessions archives it and creates the next
+-- version, so sessions keep the questions they were started on. Only one bank per episode
+-- and configuration is active.
+ALTER TABLE question_banks ADD COLUMN version INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE question_banks ADD COLUMN archived_at TIMESTAMP WITH TIME ZONE;
+
+DO $$
+DECLARE
+    constraint_name TEXT;
+BEGIN
+    SELECT conname INTO constraint_name
+    FROM pg_constraint
+    WHERE conrelid = 'question_banks'::regclass AND contype = 'u';
+
+    EXECUTE format('ALTER TABLE question_banks DROP CONSTRAINT %I', constraint_name);
+END $$;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_question_banks_active_config
+    ON question_banks(episode_id, multiple_choice, true_false, open_ended, difficulty)
+    WHERE archived_at IS NULL;
+
+-- Answered questions can't be deleted; they are archived with their bank instead
+ALTER TABLE user_answers DROP CONSTRAINT IF EXISTS user_answers_question_id_fkey;
+ALTER TABLE user_answers ADD CONSTRAINT user_answers_question_id_fkey
+    FOREIGN KEY (question_id) REFERENCES questions(id);
@@ -0,0 +1,6 @@
+ALTER TABLE questions DROP CONSTRAINT IF EXISTS questions_source_range_check;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS source_end_time;
+ALTER TABLE questions DROP COLUMN IF EXISTS source_start_time;
+ALTER TABLE questions DROP COLUMN IF EXISTS source_end_position;
+ALTER TABLE questions DROP COLUMN IF EXISTS source_start_position;
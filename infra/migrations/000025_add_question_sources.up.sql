@@ -0,0 +1,9 @@
+-- The transcript span supporting the answer of a question: the chunk positions and times of
+-- the quote found word for word in the transcript. Questions generated before have none.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS source_start_position INTEGER;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS source_end_position INTEGER;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS source_start_time FLOAT;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS source_end_time FLOAT;
+
+ALTER TABLE questions ADD CONSTRAINT questions_source_range_check
+    CHECK (source_start_position <= source_end_position AND source_start_time <= source_end_time);
@@ -0,0 +1,4 @@
+ALTER TABLE user_quiz_sessions DROP CONSTRAINT IF EXISTS user_quiz_sessions_attempt_key;
+
+ALTER TABLE user_quiz_sessions DROP COLUMN IF EXISTS shuffle_seed;
+ALTER TABLE user_quiz_sessions DROP COLUMN IF EXISTS attempt;
@@ -0,0 +1,16 @@
+-- Sessions are the attempts of a user at the quiz of an episode, numbered from 1 in start
+-- order. Retakes shuffle the questions and options with a seed kept on the session, so the
+-- order stays the same for the whole attempt; sessions without a seed use the bank order.
+ALTER TABLE user_quiz_sessions ADD COLUMN IF NOT EXISTS attempt INTEGER;
+ALTER TABLE user_quiz_sessions ADD COLUMN IF NOT EXISTS shuffle_seed BIGINT;
+
+UPDATE user_quiz_sessions s
+SET attempt = numbered.attempt
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY user_id, episode_id ORDER BY started_at, id) AS attempt
+    FROM user_quiz_sessions
+) numbered
+WHERE s.id = numbered.id;
+
+ALTER TABLE user_quiz_sessions ALTER COLUMN attempt SET NOT NULL;
+ALTER TABLE user_quiz_sessions ADD CONSTRAINT user_quiz_sessions_attempt_key UNIQUE (user_id, episode_id, attempt);
@@ -0,0 +1,9 @@
+ALTER TABLE user_quiz_sessions DROP COLUMN IF EXISTS score;
+
+ALTER TABLE user_answers DROP CONSTRAINT IF EXISTS user_answers_score_check;
+ALTER TABLE user_answers DROP COLUMN IF EXISTS key_points_missed;
+ALTER TABLE user_answers DROP COLUMN IF EXISTS key_points_hit;
+ALTER TABLE user_answers DROP COLUMN IF EXISTS score;
+
+ALTER TABLE questions DROP COLUMN IF EXISTS key_points;
+ALTER TABLE questions DROP COLUMN IF EXISTS reference_answer;
@@ -0,0 +1,20 @@
+-- Open-ended questions are generated with a reference answer and a rubric of key points, and
+-- their answers get partial credit: a score from 0 to 1 with the key points hit and missed.
+-- Choice answers score 1 when correct and 0 otherwise. The score of a session adds up the
+-- scores of its answers.
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS reference_answer TEXT;
+ALTER TABLE questions ADD COLUMN IF NOT EXISTS key_points TEXT[];
+
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS score FLOAT;
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS key_points_hit TEXT[];
+ALTER TABLE user_answers ADD COLUMN IF NOT EXISTS key_points_missed TEXT[];
+
+UPDATE user_answers SET score = CASE WHEN is_correct THEN 1 ELSE 0 END;
+
+ALTER TABLE user_answers ALTER COLUMN score SET DEFAULT 0;
+ALTER TABLE user_answers ALTER COLUMN score SET NOT NULL;
+ALTER TABLE user_answers ADD CONSTRAINT user_answers_score_check CHECK (score >= 0 AND score <= 1);
+
+ALTER TABLE user_quiz_sessions ADD COLUMN IF NOT EXISTS score FLOAT NOT NULL DEFAULT 0;
+
+UPDATE user_quiz_sessions SET score = correct_answers;
@@ -1,6 +1,7 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -8,6 +9,7 @@ import (
 	"io"
 	"net/http"
 	"os"
+	"sort"
 	"strings"
 
 	"cribeapp.com/cribe-server/internal/core/logger"
@@ -19,6 +21,19 @@ type LLMClient interface {
 	Chat(ctx context.Context, req ChatRequest) (ChatCompletionResponse, error)
 }
 
+// StreamingLLMClient is implemented by clients that can stream the reply while it is
+// generated. Services check for it and fall back to Chat otherwise.
+type StreamingLLMClient interface {
+	LLMClient
+	ChatStream(ctx context.Context, req ChatRequest, onDelta func(content string) error) (ChatCompletionResponse, error)
+}
+
+// EmbeddingClient is implemented by clients that can embed text into vectors. Services
+// check for it and disable semantic features otherwise.
+type EmbeddingClient interface {
+	Embed(ctx context.Context, req EmbeddingRequest) (EmbeddingResponse, error)
+}
+
 // NewClient creates a new LLM client
 func NewClient() *Client {
 	log := logger.NewServiceLogger("LLMClient")
@@ -64,20 +79,153 @@ func (c *Client) Chat(ctx context.Context, req ChatRequest) (ChatCompletionRespo
 		MaxTokens:   req.MaxTokens,
 	}
 
-	jsonBody, err := utils.EncodeToJSON(internalReq)
+	resp, err := c.sendChatRequest(ctx, internalReq)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer c.closeBody(resp)
+
+	// Parse response
+	var chatResp ChatCompletionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
+		c.log.Error("Failed to decode LLM response", map[string]any{
+			"error": err.Error(),
+		})
+		return ChatCompletionResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	return chatResp, nil
+}
+
+// ChatStream requests a streamed completion and calls onDelta with each piece of the
+// reply as it arrives. The returned response holds the complete reply.
+func (c *Client) ChatStream(ctx context.Context, req ChatRequest, onDelta func(content string) error) (ChatCompletionResponse, error) {
+	internalReq := chatCompletionRequest{
+		Model:       DefaultChatModel,
+		Messages:    req.Messages,
+		Temperature: DefaultTemperature,
+		MaxTokens:   req.MaxTokens,
+		Stream:      true,
+	}
+
+	resp, err := c.sendChatRequest(ctx, internalReq)
+	if err != nil {
+		return ChatCompletionResponse{}, err
+	}
+	defer c.closeBody(resp)
+
+	var (
+		chatResp     ChatCompletionResponse
+		content      strings.Builder
+		finishReason string
+	)
+
+	// Server-sent events: each "data:" line holds one chunk, "[DONE]" ends the stream
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data:")
+		if !ok {
+			continue
+		}
+		data = strings.TrimSpace(data)
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk chatCompletionChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			c.log.Error("Failed to decode LLM stream chunk", map[string]any{
+				"error": err.Error(),
+			})
+			return ChatCompletionResponse{}, fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+
+		chatResp.ID, chatResp.Created, chatResp.Model = chunk.ID, chunk.Created, chunk.Model
+		for _, choice := range chunk.Choices {
+			if choice.FinishReason != nil {
+				finishReason = *choice.FinishReason
+			}
+			if choice.Delta.Content == "" {
+				continue
+			}
+			content.WriteString(choice.Delta.Content)
+			if err := onDelta(choice.Delta.Content); err != nil {
+				return ChatCompletionResponse{}, err
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		c.log.Error("Failed to read LLM stream", map[string]any{
+			"error": err.Error(),
+		})
+		return ChatCompletionResponse{}, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	chatResp.Object = "chat.completion"
+	chatResp.Choices = []Choice{{
+		Message:      Message{Role: "assistant", Content: content.String()},
+		FinishReason: finishReason,
+	}}
+
+	return chatResp, nil
+}
+
+// Embed returns one vector per input, in the order of the inputs
+func (c *Client) Embed(ctx context.Context, req EmbeddingRequest) (EmbeddingResponse, error) {
+	internalReq := embeddingRequest{
+		Model:      DefaultEmbeddingModel,
+		Input:      req.Input,
+		Dimensions: DefaultEmbeddingDimensions,
+	}
+
+	resp, err := c.post(ctx, "/embeddings", internalReq)
+	if err != nil {
+		return EmbeddingResponse{}, err
+	}
+	defer c.closeBody(resp)
+
+	var embedResp EmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&embedResp); err != nil {
+		c.log.Error("Failed to decode embedding response", map[string]any{
+			"error": err.Error(),
+		})
+		return EmbeddingResponse{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	if len(embedResp.Data) != len(req.Input) {
+		return EmbeddingResponse{}, fmt.Errorf("expected %d embeddings, got %d", len(req.Input), len(embedResp.Data))
+	}
+
+	// The API may return the vectors out of order
+	sort.Slice(embedResp.Data, func(i, j int) bool { return embedResp.Data[i].Index < embedResp.Data[j].Index })
+
+	return embedResp, nil
+}
+
+// sendChatRequest posts a completion request and returns the response when the API
+// accepted it. The caller must close the body.
+func (c *Client) sendChatRequest(ctx context.Context, internalReq chatCompletionRequest) (*http.Response, error) {
+	return c.post(ctx, "/chat/completions", internalReq)
+}
+
+// post sends a JSON request to an API path and returns the response when the API
+// accepted it. The caller must close the body.
+func (c *Client) post(ctx context.Context, path string, body any) (*http.Response, error) {
+	jsonBody, err := utils.EncodeToJSON(body)
 	if err != nil {
 		c.log.Error("Failed to marshal LLM request", map[string]any{
 			"error": err.Error(),
 		})
-		return ChatCompletionResponse{}, fmt.Errorf("failed to marshal request: %w", err)
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/chat/completions", bytes.NewReader(jsonBody))
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+path, bytes.NewReader(jsonBody))
 	if err != nil {
 		c.log.Error("Failed to create LLM request", map[string]any{
 			"error": err.Error(),
 		})
-		return ChatCompletionResponse{}, fmt.Errorf("failed to create request: %w", err)
+		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
 	c.setRequestHeaders(httpReq)
@@ -88,33 +236,26 @@ func (c *Client) Chat(ctx context.Context, req ChatRequest) (ChatCompletionRespo
 		c.log.Error("Failed to send LLM request", map[string]any{
 			"error": err.Error(),
 		})
-		return ChatCompletionResponse{}, fmt.Errorf("failed to send request: %w", err)
+		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
-	defer func() {
-		if closeErr := resp.Body.Close(); closeErr != nil {
-			c.log.Error("Failed to close response body", map[string]any{
-				"error": closeErr.Error(),
-			})
-		}
-	}()
 
 	if resp.StatusCode != http.StatusOK {
+		defer c.closeBody(resp)
 		body, _ := io.ReadAll(resp.Body)
 		c.log.Error("LLM API returned error", map[string]any{
 			"statusCode": resp.StatusCode,
 			"response":   string(body),
 		})
-		return ChatCompletionResponse{}, fmt.Errorf("LLM API error: status=%d, body=%s", resp.StatusCode, string(body))
+		return nil, fmt.Errorf("LLM API error: status=%d, body=%s", resp.StatusCode, string(body))
 	}
 
-	// Parse response
-	var chatResp ChatCompletionResponse
-	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
-		c.log.Error("Failed to decode LLM response", map[string]any{
-			"error": err.Error(),
+	return resp, nil
+}
+
+func (c *Client) closeBody(resp *http.Response) {
+	if closeErr := resp.Body.Close(); closeErr != nil {
+		c.log.Error("Failed to close response body", map[string]any{
+			"error": closeErr.Error(),
 		})
-		return ChatCompletionResponse{}, fmt.Errorf("failed to decode response: %w", err)
 	}
-
-	return chatResp, nil
 }
@@ -330,3 +330,158 @@ func TestChat(t *testing.T) {
 		}
 	})
 }
+
+func TestChatStream(t *testing.T) {
+	t.Run("should stream deltas and return the complete reply", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			var reqBody chatCompletionRequest
+			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+				t.Errorf("Failed to decode request body: %v", err)
+			}
+			if !reqBody.Stream {
+				t.Error("Expected stream to be requested")
+			}
+
+			w.Header().Set("Content-Type", "text/event-stream")
+			_, _ = w.Write([]byte(`data: {"id":"1","model":"m","choices":[{"index":0,"delta":{"role":"assistant","content":""}}]}
+
+data: {"id":"1","model":"m","choices":[{"index":0,"delta":{"content":"Hello"}}]}
+
+data: {"id":"1","model":"m","choices":[{"index":0,"delta":{"content":" there"},"finish_reason":"stop"}]}
+
+data: [DONE]
+
+`))
+		}))
+		defer server.Close()
+
+		client := &Client{
+			apiKey:     "test-key",
+			baseURL:    server.URL,
+			httpClient: &http.Client{},
+			log:        logger.NewServiceLogger("TestLLMClient"),
+		}
+
+		var deltas []string
+		response, err := client.ChatStream(context.Background(), ChatRequest{
+			Messages: []Message{{Role: "user", Content: "Hello"}},
+		}, func(content string) error {
+			deltas = append(deltas, content)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(deltas) != 2 || deltas[0] != "Hello" || deltas[1] != " there" {
+			t.Errorf("Expected 2 deltas, got %q", deltas)
+		}
+		if response.Choices[0].Message.Content != "Hello there" || response.Choices[0].FinishReason != "stop" {
+			t.Errorf("Unexpected response: %+v", response.Choices[0])
+		}
+	})
+
+	t.Run("should stop when the delta callback fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("data: {\"choices\":[{\"delta\":{\"content\":\"Hi\"}}]}\n\ndata: [DONE]\n\n"))
+		}))
+		defer server.Close()
+
+		client := &Client{
+			apiKey:     "test-key",
+			baseURL:    server.URL,
+			httpClient: &http.Client{},
+			log:        logger.NewServiceLogger("TestLLMClient"),
+		}
+
+		_, err := client.ChatStream(context.Background(), ChatRequest{}, func(content string) error {
+			return context.Canceled
+		})
+
+		if err != context.Canceled {
+			t.Errorf("Expected the callback error, got %v", err)
+		}
+	})
+
+	t.Run("should return error when API returns non-200 status", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusTooManyRequests)
+		}))
+		defer server.Close()
+
+		client := &Client{
+			apiKey:     "test-key",
+			baseURL:    server.URL,
+			httpClient: &http.Client{},
+			log:        logger.NewServiceLogger("TestLLMClient"),
+		}
+
+		if _, err := client.ChatStream(context.Background(), ChatRequest{}, func(string) error { return nil }); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestEmbed(t *testing.T) {
+	t.Run("should return the embeddings in input order", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path != "/embeddings" {
+				t.Errorf("Expected path /embeddings, got %s", r.URL.Path)
+			}
+
+			var reqBody embeddingRequest
+			if err := json.NewDecoder(r.Body).Decode(&reqBody); err != nil {
+				t.Errorf("Failed to decode request body: %v", err)
+			}
+			if reqBody.Model != DefaultEmbeddingModel || reqBody.Dimensions != DefaultEmbeddingDimensions {
+				t.Errorf("Unexpected model settings: %+v", reqBody)
+			}
+			if len(reqBody.Input) != 2 {
+				t.Errorf("Expected 2 inputs, got %d", len(reqBody.Input))
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(EmbeddingResponse{
+				Data: []Embedding{
+					{Index: 1, Embedding: []float32{0, 1}},
+					{Index: 0, Embedding: []float32{1, 0}},
+				},
+			})
+		}))
+		defer server.Close()
+
+		client := &Client{
+			apiKey:     "test-key",
+			baseURL:    server.URL,
+			httpClient: &http.Client{},
+			log:        logger.NewServiceLogger("TestLLMClient"),
+		}
+
+		response, err := client.Embed(context.Background(), EmbeddingRequest{Input: []string{"first", "second"}})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if response.Data[0].Embedding[0] != 1 || response.Data[1].Embedding[1] != 1 {
+			t.Errorf("Expected embeddings in input order, got %+v", response.Data)
+		}
+	})
+
+	t.Run("should return error when an embedding is missing", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_ = json.NewEncoder(w).Encode(EmbeddingResponse{Data: []Embedding{{Index: 0}}})
+		}))
+		defer server.Close()
+
+		client := &Client{
+			apiKey:     "test-key",
+			baseURL:    server.URL,
+			httpClient: &http.Client{},
+			log:        logger.NewServiceLogger("TestLLMClient"),
+		}
+
+		if _, err := client.Embed(context.Background(), EmbeddingRequest{Input: []string{"a", "b"}}); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
@@ -10,6 +10,11 @@ const (
 	// Default models for different use cases
 	DefaultChatModel   = "gpt-4o-mini" // For general chat/inference
 	DefaultTemperature = 0.7           // Default creativity level
+
+	// DefaultEmbeddingModel embeds transcript passages and search queries. Vectors are
+	// shortened to DefaultEmbeddingDimensions to keep the in-memory search index small.
+	DefaultEmbeddingModel      = "text-embedding-3-small"
+	DefaultEmbeddingDimensions = 256
 )
 
 // Client handles LLM API interactions
@@ -38,6 +43,7 @@ type chatCompletionRequest struct {
 	Messages    []Message `json:"messages"`
 	Temperature float64   `json:"temperature,omitempty"`
 	MaxTokens   int       `json:"max_tokens,omitempty"`
+	Stream      bool      `json:"stream,omitempty"`
 }
 
 // Choice represents a completion choice
@@ -47,6 +53,18 @@ type Choice struct {
 	FinishReason string  `json:"finish_reason"`
 }
 
+// chatCompletionChunk is one server-sent event of a streamed completion
+type chatCompletionChunk struct {
+	ID      string `json:"id"`
+	Created int64  `json:"created"`
+	Model   string `json:"model"`
+	Choices []struct {
+		Index        int     `json:"index"`
+		Delta        Message `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
 // Usage represents token usage
 type Usage struct {
 	PromptTokens     int `json:"prompt_tokens"`
@@ -63,3 +81,29 @@ type ChatCompletionResponse struct {
 	Choices []Choice `json:"choices"`
 	Usage   Usage    `json:"usage"`
 }
+
+// EmbeddingRequest is the service-level embedding request (infrastructure-agnostic)
+type EmbeddingRequest struct {
+	Input []string `json:"input"`
+}
+
+// embeddingRequest is the internal OpenAI-specific request
+type embeddingRequest struct {
+	Model      string   `json:"model"`
+	Input      []string `json:"input"`
+	Dimensions int      `json:"dimensions,omitempty"`
+}
+
+// Embedding is the vector of one input
+type Embedding struct {
+	Index     int       `json:"index"`
+	Embedding []float32 `json:"embedding"`
+}
+
+// EmbeddingResponse represents the embeddings of a request, ordered by input
+type EmbeddingResponse struct {
+	Object string      `json:"object"`
+	Model  string      `json:"model"`
+	Data   []Embedding `json:"data"`
+	Usage  Usage       `json:"usage"`
+}
@@ -2,6 +2,7 @@ package transcription
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
@@ -11,6 +12,7 @@ import (
 	"net/url"
 	"os"
 	"strings"
+	"time"
 
 	"github.com/gorilla/websocket"
 
@@ -47,21 +49,41 @@ func NewClient() *Client {
 	}
 }
 
-// StreamAudioURL is the service-level method - streams audio from URL with default settings
-func (c *Client) StreamAudioURL(ctx context.Context, audioURL string, callback StreamCallback) error {
+// DefaultDeepgramModel is used when the options do not name a model
+const DefaultDeepgramModel = "nova-3"
 
-	// Default options configured in client
-	opts := StreamOptions{
-		Model:      "nova-3",
-		Language:   "en",
-		Diarize:    true,
-		Punctuate:  true,
-		Utterances: false,
+// StreamAudioURL is the service-level method - transcribes audio from URL with the given
+// options, over the real-time WebSocket or, in batch mode, the pre-recorded endpoint
+func (c *Client) StreamAudioURL(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error {
+	if opts.Model == "" {
+		opts.Model = DefaultDeepgramModel
+	}
+
+	if opts.Mode == ModeBatch {
+		return c.TranscribeAudioURL(ctx, audioURL, opts, callback)
 	}
 
 	return c.StreamAudioURLWebSocket(ctx, audioURL, opts, callback)
 }
 
+// buildListenQuery sets the query parameters shared by the streaming and pre-recorded endpoints
+func buildListenQuery(q url.Values, opts StreamOptions) {
+	q.Set("model", opts.Model)
+	q.Set("diarize", fmt.Sprintf("%t", opts.Diarize))
+	q.Set("punctuate", fmt.Sprintf("%t", opts.Punctuate))
+	q.Set("smart_format", fmt.Sprintf("%t", opts.SmartFormat))
+	q.Set("utterances", fmt.Sprintf("%t", opts.Utterances))
+
+	// Nova-3 boosts vocabulary with keyterm prompting; older models use keywords
+	keytermParam := "keywords"
+	if strings.HasPrefix(opts.Model, "nova-3") {
+		keytermParam = "keyterm"
+	}
+	for _, term := range opts.Keyterms {
+		q.Add(keytermParam, term)
+	}
+}
+
 // buildWebSocketURL constructs a WebSocket URL with query parameters for Deepgram API
 func (c *Client) buildWebSocketURL(opts StreamOptions) (string, error) {
 	wsURL := strings.Replace(c.baseURL, "https://", "wss://", 1)
@@ -72,33 +94,71 @@ func (c *Client) buildWebSocketURL(opts StreamOptions) (string, error) {
 	}
 
 	q := u.Query()
-	q.Set("model", opts.Model)
-	q.Set("language", opts.Language)
-	q.Set("diarize", fmt.Sprintf("%t", opts.Diarize))
-	q.Set("punctuate", fmt.Sprintf("%t", opts.Punctuate))
-	q.Set("utterances", fmt.Sprintf("%t", opts.Utterances))
+	buildListenQuery(q, opts)
+	if opts.IsLanguageDetection() {
+		// Streaming has no detect_language; "multi" transcribes whatever language is spoken
+		q.Set("language", "multi")
+	} else {
+		q.Set("language", opts.Language)
+	}
 	q.Set("interim_results", "false")
 	u.RawQuery = q.Encode()
 
 	return u.String(), nil
 }
 
-// streamAudioToWebSocket downloads audio from URL and streams it to the WebSocket connection
-func (c *Client) streamAudioToWebSocket(ctx context.Context, conn *websocket.Conn, audioURL string, errCh chan<- error, doneCh chan<- struct{}) {
-	req, err := http.NewRequestWithContext(ctx, "GET", audioURL, nil)
+// buildPreRecordedURL constructs the pre-recorded /listen URL with query parameters for Deepgram API
+func (c *Client) buildPreRecordedURL(opts StreamOptions) (string, error) {
+	u, err := url.Parse(c.baseURL + "/listen")
 	if err != nil {
-		errCh <- fmt.Errorf("failed to create audio download request: %w", err)
-		return
+		return "", fmt.Errorf("failed to parse pre-recorded URL: %w", err)
+	}
+
+	q := u.Query()
+	buildListenQuery(q, opts)
+	if opts.IsLanguageDetection() {
+		q.Set("detect_language", "true")
+	} else {
+		q.Set("language", opts.Language)
+	}
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// TranscribeAudioURL submits the audio URL to Deepgram's pre-recorded endpoint, which
+// fetches and transcribes the whole file server-side instead of at playback speed.
+// The full result arrives in one response and is reported through the callback.
+func (c *Client) TranscribeAudioURL(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error {
+	listenURL, err := c.buildPreRecordedURL(opts)
+	if err != nil {
+		return err
 	}
 
+	jsonBody, err := utils.EncodeToJSON(map[string]string{"url": audioURL})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", listenURL, bytes.NewReader(jsonBody))
+	if err != nil {
+		return fmt.Errorf("failed to create pre-recorded request: %w", err)
+	}
+	req.Header.Set("Authorization", "Token "+c.apiKey)
+	req.Header.Set("Content-Type", "application/json")
+
+	c.log.Info("Submitting audio for pre-recorded transcription", map[string]any{
+		"url":      listenURL,
+		"audioURL": audioURL,
+	})
+
 	resp, err := c.httpClient.Do(req)
 	if err != nil {
-		errCh <- fmt.Errorf("failed to download audio: %w", err)
-		return
+		return fmt.Errorf("failed to send pre-recorded request: %w", err)
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
-			c.log.Error("Failed to close audio response body", map[string]any{
+			c.log.Error("Failed to close pre-recorded response body", map[string]any{
 				"error": err.Error(),
 			})
 		}
@@ -106,17 +166,75 @@ func (c *Client) streamAudioToWebSocket(ctx context.Context, conn *websocket.Con
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		errCh <- fmt.Errorf("audio download failed: status=%d, body=%s", resp.StatusCode, string(body))
+		return fmt.Errorf("pre-recorded transcription failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	return c.processStreamingResponse(resp.Body, callback)
+}
+
+// openAudio downloads the audio starting at offset. Servers that ignore the Range
+// header send the whole file, in which case the leading bytes are skipped.
+func (c *Client) openAudio(ctx context.Context, audioURL string, offset int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", audioURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audio download request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download audio: %w", err)
+	}
+
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		return resp, nil
+	case resp.StatusCode == http.StatusOK:
+		if offset > 0 {
+			if _, err := io.CopyN(io.Discard, resp.Body, offset); err != nil {
+				_ = resp.Body.Close()
+				return nil, fmt.Errorf("failed to skip to audio offset %d: %w", offset, err)
+			}
+		}
+		return resp, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		return nil, fmt.Errorf("audio download failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+}
+
+// streamAudioToWebSocket downloads audio from URL and streams it to the WebSocket connection,
+// starting at the session checkpoint. Finalize is sent every checkpointBytes so the session
+// learns which offsets Deepgram has processed and can resume from there after a disconnect.
+func (c *Client) streamAudioToWebSocket(ctx context.Context, conn *websocket.Conn, audioURL string, session *streamSession, errCh chan<- error, doneCh chan<- struct{}) {
+	offset := session.begin()
+
+	resp, err := c.openAudio(ctx, audioURL, offset)
+	if err != nil {
+		errCh <- err
 		return
 	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Error("Failed to close audio response body", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}()
+	session.setContentType(resp.Header.Get("Content-Type"))
 
 	c.log.Info("Started streaming audio to WebSocket", map[string]any{
 		"audioURL": audioURL,
+		"offset":   offset,
 	})
 
 	reader := bufio.NewReader(resp.Body)
 	buffer := make([]byte, 8192)
 	totalBytesSent := 0
+	sinceCheckpoint := 0
 
 	for {
 		select {
@@ -128,11 +246,20 @@ func (c *Client) streamAudioToWebSocket(ctx context.Context, conn *websocket.Con
 
 		n, err := reader.Read(buffer)
 		if n > 0 {
-			if err := conn.WriteMessage(websocket.BinaryMessage, buffer[:n]); err != nil {
-				errCh <- fmt.Errorf("failed to send audio chunk: %w", err)
+			if err := session.writeMessage(conn, websocket.BinaryMessage, buffer[:n]); err != nil {
+				errCh <- fmt.Errorf("%w: failed to send audio chunk: %v", errConnectionLost, err)
 				return
 			}
 			totalBytesSent += n
+			sinceCheckpoint += n
+		}
+
+		if sinceCheckpoint >= checkpointBytes && session.requestCheckpoint(offset+int64(totalBytesSent)) {
+			sinceCheckpoint = 0
+			if err := session.writeMessage(conn, websocket.TextMessage, []byte("{\"type\":\"Finalize\"}")); err != nil {
+				errCh <- fmt.Errorf("%w: failed to send finalize message: %v", errConnectionLost, err)
+				return
+			}
 		}
 
 		if err != nil {
@@ -141,7 +268,7 @@ func (c *Client) streamAudioToWebSocket(ctx context.Context, conn *websocket.Con
 					"totalBytesSent": totalBytesSent,
 				})
 				closeMsg := []byte("{\"type\":\"CloseStream\"}")
-				if err := conn.WriteMessage(websocket.TextMessage, closeMsg); err != nil {
+				if err := session.writeMessage(conn, websocket.TextMessage, closeMsg); err != nil {
 					c.log.Error("Failed to send close stream message", map[string]any{
 						"error": err.Error(),
 					})
@@ -155,16 +282,34 @@ func (c *Client) streamAudioToWebSocket(ctx context.Context, conn *websocket.Con
 	}
 }
 
-// readTranscriptionResults reads and processes messages from the WebSocket connection
-func (c *Client) readTranscriptionResults(ctx context.Context, conn *websocket.Conn, callback StreamCallback, errCh chan<- error, doneCh chan<- struct{}) {
-	defer func() {
-		if err := conn.Close(); err != nil {
-			c.log.Error("Failed to close WebSocket in reader goroutine", map[string]any{
-				"error": err.Error(),
-			})
+// keepAlive sends KeepAlive messages while no audio is written, so slow downloads
+// and long pauses don't trigger the provider's idle timeout
+func (c *Client) keepAlive(ctx context.Context, conn *websocket.Conn, session *streamSession) {
+	ticker := time.NewTicker(keepAliveInterval / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if session.idleFor() < keepAliveInterval {
+				continue
+			}
+			if err := session.writeMessage(conn, websocket.TextMessage, []byte("{\"type\":\"KeepAlive\"}")); err != nil {
+				// The reader notices the broken connection and triggers the reconnect
+				c.log.Debug("Failed to send keepalive message", map[string]any{
+					"error": err.Error(),
+				})
+				return
+			}
 		}
-	}()
+	}
+}
 
+// readTranscriptionResults reads and processes messages from the WebSocket connection.
+// Abnormal closes and read failures are reported as errConnectionLost.
+func (c *Client) readTranscriptionResults(ctx context.Context, conn *websocket.Conn, session *streamSession, callback StreamCallback, errCh chan<- error, doneCh chan<- struct{}) {
 	messageCount := 0
 	for {
 		select {
@@ -186,6 +331,14 @@ func (c *Client) readTranscriptionResults(ctx context.Context, conn *websocket.C
 				doneCh <- struct{}{}
 				return
 			}
+			if ctx.Err() != nil {
+				errCh <- ctx.Err()
+				return
+			}
+			if isConnectionLost(err) {
+				errCh <- fmt.Errorf("%w: failed to read WebSocket message: %v", errConnectionLost, err)
+				return
+			}
 			errCh <- fmt.Errorf("failed to read WebSocket message: %w", err)
 			return
 		}
@@ -210,6 +363,10 @@ func (c *Client) readTranscriptionResults(ctx context.Context, conn *websocket.C
 			continue
 		}
 
+		if resp.FromFinalize {
+			session.acknowledge(resp.Start + resp.Duration)
+		}
+
 		if len(resp.Channel.Alternatives) == 0 {
 			c.log.Debug("Received message with no alternatives", map[string]any{
 				"type": resp.Type,
@@ -221,6 +378,13 @@ func (c *Client) readTranscriptionResults(ctx context.Context, conn *websocket.C
 			continue
 		}
 
+		if words := resp.Channel.Alternatives[0].Words; len(words) > 0 {
+			resp.Channel.Alternatives[0].Words = session.rebase(words)
+			if len(resp.Channel.Alternatives[0].Words) == 0 {
+				continue
+			}
+		}
+
 		if err := callback(&resp); err != nil {
 			// Context cancellation is expected when client disconnects
 			if errors.Is(err, context.Canceled) {
@@ -239,13 +403,37 @@ func (c *Client) readTranscriptionResults(ctx context.Context, conn *websocket.C
 // StreamAudioURLWebSocket streams audio from a URL to Deepgram's WebSocket endpoint
 // for real-time transcription. This enables progressive transcription of long-form
 // audio (e.g., 2+ hour podcasts) by downloading and streaming audio chunks concurrently
-// with receiving transcription results.
+// with receiving transcription results. When the connection drops it reconnects and
+// resumes from the last acknowledged audio offset.
 func (c *Client) StreamAudioURLWebSocket(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error {
 	wsURLString, err := c.buildWebSocketURL(opts)
 	if err != nil {
 		return err
 	}
 
+	session := newStreamSession()
+	for attempt := 0; ; attempt++ {
+		err := c.streamWebSocketConnection(ctx, wsURLString, audioURL, session, callback)
+		if err == nil || !errors.Is(err, errConnectionLost) || attempt >= maxReconnectAttempts {
+			return err
+		}
+
+		c.log.Warn("WebSocket connection lost, reconnecting", map[string]any{
+			"error":   err.Error(),
+			"attempt": attempt + 1,
+		})
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay * time.Duration(attempt+1)):
+		}
+	}
+}
+
+// streamWebSocketConnection runs one WebSocket connection until the audio is fully
+// transcribed or the connection fails
+func (c *Client) streamWebSocketConnection(ctx context.Context, wsURLString, audioURL string, session *streamSession, callback StreamCallback) error {
 	headers := http.Header{}
 	headers.Set("Authorization", "Token "+c.apiKey)
 
@@ -254,28 +442,31 @@ func (c *Client) StreamAudioURLWebSocket(ctx context.Context, audioURL string, o
 		"audioURL": audioURL,
 	})
 
-	conn, _, err := websocket.DefaultDialer.Dial(wsURLString, headers)
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, wsURLString, headers)
 	if err != nil {
 		c.log.Error("Failed to connect to WebSocket", map[string]any{
 			"error": err.Error(),
 		})
+		if session.resuming() && ctx.Err() == nil {
+			return fmt.Errorf("%w: failed to connect to WebSocket: %v", errConnectionLost, err)
+		}
 		return fmt.Errorf("failed to connect to WebSocket: %w", err)
 	}
+	defer func() { _ = conn.Close() }()
 
 	streamCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
 	errCh := make(chan error, 2)
 	doneCh := make(chan struct{}, 2)
 
-	go c.streamAudioToWebSocket(streamCtx, conn, audioURL, errCh, doneCh)
-	go func() {
-		defer cancel()
-		c.readTranscriptionResults(streamCtx, conn, callback, errCh, doneCh)
-	}()
+	go c.streamAudioToWebSocket(streamCtx, conn, audioURL, session, errCh, doneCh)
+	go c.readTranscriptionResults(streamCtx, conn, session, callback, errCh, doneCh)
+	go c.keepAlive(streamCtx, conn, session)
 
 	completedGoroutines := 0
 	for completedGoroutines < 2 {
 		select {
-		case <-streamCtx.Done():
+		case <-ctx.Done():
 			c.log.Info("WebSocket stream context cancelled", map[string]any{
 				"error": ctx.Err(),
 			})
@@ -284,7 +475,6 @@ func (c *Client) StreamAudioURLWebSocket(ctx context.Context, audioURL string, o
 			c.log.Error("WebSocket stream error", map[string]any{
 				"error": err.Error(),
 			})
-			cancel()
 			return err
 		case <-doneCh:
 			completedGoroutines++
@@ -326,6 +516,11 @@ func (c *Client) processStreamingResponse(body io.Reader, callback StreamCallbac
 			continue
 		}
 
+		// Expose the result in the same shape as WebSocket results so callers read
+		// words from Channel regardless of the endpoint
+		resp.Channel = resp.Results.Channels[0]
+		resp.IsFinal = true
+
 		alt := resp.Results.Channels[0].Alternatives[0]
 		words := alt.Words
 
@@ -73,6 +73,77 @@ func TestBuildWebSocketURL(t *testing.T) {
 	if !strings.HasPrefix(wsURL, "wss://") || !strings.Contains(wsURL, "model=nova-2") {
 		t.Errorf("Invalid WebSocket URL: %s", wsURL)
 	}
+
+	t.Run("keyterms and language detection", func(t *testing.T) {
+		tests := []struct {
+			name  string
+			opts  StreamOptions
+			wants []string
+		}{
+			{"nova-3 uses keyterm", StreamOptions{Model: "nova-3", Language: "pt", SmartFormat: true, Keyterms: []string{"Cribe App"}},
+				[]string{"keyterm=Cribe+App", "language=pt", "smart_format=true"}},
+			{"older models use keywords", StreamOptions{Model: "nova-2", Language: "en", Keyterms: []string{"Cribe"}},
+				[]string{"keywords=Cribe"}},
+			{"auto language streams multilingual", StreamOptions{Model: "nova-3", Language: LanguageAuto},
+				[]string{"language=multi"}},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				wsURL, err := client.buildWebSocketURL(tt.opts)
+				if err != nil {
+					t.Fatalf("Expected no error, got %v", err)
+				}
+				for _, want := range tt.wants {
+					if !strings.Contains(wsURL, want) {
+						t.Errorf("Expected %q in %s", want, wsURL)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestPreferencesValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		prefs   Preferences
+		wantErr bool
+	}{
+		{"empty", Preferences{}, false},
+		{"full", Preferences{Language: "pt-BR", Model: "nova-3", Keyterms: []string{"Cribe"}}, false},
+		{"auto language", Preferences{Language: LanguageAuto}, false},
+		{"invalid language", Preferences{Language: "Portuguese"}, true},
+		{"invalid model", Preferences{Model: "nova 3&x=1"}, true},
+		{"blank keyterm", Preferences{Keyterms: []string{" "}}, true},
+		{"too many keyterms", Preferences{Keyterms: make([]string, MaxKeyterms+1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.prefs.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestPreferencesApply(t *testing.T) {
+	smartFormat := false
+	base := DefaultStreamOptions()
+	base.Keyterms = []string{"Cribe"}
+
+	opts := Preferences{Language: "es", Keyterms: []string{"Fabio"}, SmartFormat: &smartFormat}.Apply(base)
+
+	if opts.Language != "es" || opts.Model != "" || opts.SmartFormat {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+	if len(opts.Keyterms) != 2 || len(base.Keyterms) != 1 {
+		t.Errorf("Expected keyterms appended without mutating base, got %v and %v", opts.Keyterms, base.Keyterms)
+	}
+	if unchanged := (Preferences{}).Apply(base); unchanged.Language != "en" || !unchanged.SmartFormat {
+		t.Errorf("Expected empty preferences to keep options, got %+v", unchanged)
+	}
 }
 
 func TestStreamAudioToWebSocket(t *testing.T) {
@@ -106,7 +177,7 @@ func TestStreamAudioToWebSocket(t *testing.T) {
 		defer cancel()
 
 		errCh, doneCh := make(chan error, 1), make(chan struct{}, 1)
-		go client.streamAudioToWebSocket(ctx, conn, audioServer.URL, errCh, doneCh)
+		go client.streamAudioToWebSocket(ctx, conn, audioServer.URL, newStreamSession(), errCh, doneCh)
 
 		select {
 		case <-doneCh:
@@ -142,7 +213,7 @@ func TestStreamAudioToWebSocket(t *testing.T) {
 		defer cancel()
 
 		errCh, doneCh := make(chan error, 1), make(chan struct{}, 1)
-		go client.streamAudioToWebSocket(ctx, conn, audioServer.URL, errCh, doneCh)
+		go client.streamAudioToWebSocket(ctx, conn, audioServer.URL, newStreamSession(), errCh, doneCh)
 
 		select {
 		case err := <-errCh:
@@ -234,7 +305,7 @@ func TestReadTranscriptionResults(t *testing.T) {
 				return nil
 			}
 
-			go client.readTranscriptionResults(context.Background(), conn, callback, errCh, doneCh)
+			go client.readTranscriptionResults(context.Background(), conn, newStreamSession(), callback, errCh, doneCh)
 
 			select {
 			case <-doneCh:
@@ -588,3 +659,70 @@ func TestProcessStreamingResponse(t *testing.T) {
 		}
 	})
 }
+
+func TestTranscribeAudioURL(t *testing.T) {
+	preRecorded := `{"metadata":{"request_id":"req-1","duration":2.5},"results":{"channels":[{"alternatives":[{"transcript":"Hello there","words":[` +
+		`{"word":"hello","punctuated_word":"Hello","start":0,"end":0.4,"speaker":0},` +
+		`{"word":"there","punctuated_word":"there.","start":0.5,"end":0.9,"speaker":1}]}]}]}}`
+
+	var gotQuery, gotBody, gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/v1/listen" {
+			http.NotFound(w, r)
+			return
+		}
+		gotQuery = r.URL.RawQuery
+		gotAuth = r.Header.Get("Authorization")
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		if strings.Contains(gotBody, "fail") {
+			http.Error(w, "bad audio", http.StatusBadRequest)
+			return
+		}
+		_, _ = w.Write([]byte(preRecorded))
+	}))
+	defer server.Close()
+
+	client := &Client{
+		apiKey:     "test-key",
+		baseURL:    server.URL + "/v1",
+		httpClient: &http.Client{},
+		log:        logger.NewServiceLogger("TestClient"),
+	}
+
+	t.Run("batch mode submits the URL and reports the full result", func(t *testing.T) {
+		opts := StreamOptions{Language: LanguageAuto, Diarize: true, Mode: ModeBatch}
+
+		var words []Word
+		err := client.StreamAudioURL(context.Background(), "https://cdn.example.com/ep.mp3", opts, func(resp *StreamResponse) error {
+			if !resp.IsFinal {
+				t.Error("Expected batch result to be final")
+			}
+			words = append(words, resp.Channel.Alternatives[0].Words...)
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(words) != 2 || words[1].Speaker != 1 || words[1].PunctuatedWord != "there." {
+			t.Errorf("Unexpected words: %+v", words)
+		}
+		if !strings.Contains(gotQuery, "detect_language=true") || !strings.Contains(gotQuery, "model="+DefaultDeepgramModel) {
+			t.Errorf("Unexpected query: %s", gotQuery)
+		}
+		if gotBody != `{"url":"https://cdn.example.com/ep.mp3"}` || gotAuth != "Token test-key" {
+			t.Errorf("Unexpected request: body=%s auth=%s", gotBody, gotAuth)
+		}
+	})
+
+	t.Run("returns error on failed request", func(t *testing.T) {
+		err := client.TranscribeAudioURL(context.Background(), "https://cdn.example.com/fail.mp3", StreamOptions{Model: "nova-3", Language: "en"}, func(resp *StreamResponse) error {
+			return nil
+		})
+
+		if err == nil || !strings.Contains(err.Error(), "status=400") {
+			t.Errorf("Expected status error, got %v", err)
+		}
+	})
+}
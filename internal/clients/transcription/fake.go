@@ -0,0 +1,83 @@
+package transcription
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+//go:embed fixtures/default.json
+var defaultFixture []byte
+
+// FakeClient replays a fixture of streaming responses for every audio URL,
+// giving deterministic transcripts in development and tests without API calls
+type FakeClient struct {
+	responses []StreamResponse
+	delay     time.Duration
+	log       *logger.ContextualLogger
+}
+
+// NewFakeClient loads the fixture from TRANSCRIPTION_FIXTURE_PATH, falling back to
+// the embedded default. TRANSCRIPTION_FAKE_DELAY_MS paces the replay.
+func NewFakeClient() (*FakeClient, error) {
+	log := logger.NewServiceLogger("FakeTranscriptionClient")
+
+	fixture := defaultFixture
+	fixturePath := os.Getenv("TRANSCRIPTION_FIXTURE_PATH")
+	if fixturePath != "" {
+		data, err := os.ReadFile(fixturePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read transcription fixture: %w", err)
+		}
+		fixture = data
+	}
+
+	var responses []StreamResponse
+	if err := json.Unmarshal(fixture, &responses); err != nil {
+		return nil, fmt.Errorf("failed to parse transcription fixture: %w", err)
+	}
+
+	log.Info("Fake transcription client initialized", map[string]any{
+		"fixturePath": fixturePath,
+		"responses":   len(responses),
+	})
+
+	return &FakeClient{
+		responses: responses,
+		delay:     time.Duration(utils.GetEnvInt("TRANSCRIPTION_FAKE_DELAY_MS", 0)) * time.Millisecond,
+		log:       log,
+	}, nil
+}
+
+// StreamAudioURL replays the fixture responses, ignoring the audio URL and options
+func (c *FakeClient) StreamAudioURL(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error {
+	c.log.Debug("Replaying transcription fixture", map[string]any{
+		"audioURL":  audioURL,
+		"responses": len(c.responses),
+	})
+
+	for i := range c.responses {
+		if c.delay > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(c.delay):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		response := c.responses[i]
+		if err := callback(&response); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
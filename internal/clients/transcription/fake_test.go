@@ -0,0 +1,82 @@
+package transcription
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFakeClient(t *testing.T) {
+	t.Run("replays the embedded fixture", func(t *testing.T) {
+		t.Setenv("TRANSCRIPTION_FIXTURE_PATH", "")
+		client, err := NewFakeClient()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		speakers := map[int]bool{}
+		responses := 0
+		err = client.StreamAudioURL(context.Background(), "https://example.com/a.mp3", DefaultStreamOptions(), func(resp *StreamResponse) error {
+			responses++
+			for _, word := range resp.Channel.Alternatives[0].Words {
+				speakers[word.Speaker] = true
+			}
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if responses == 0 || len(speakers) != 2 {
+			t.Errorf("Expected responses from two speakers, got %d responses and speakers %v", responses, speakers)
+		}
+	})
+
+	t.Run("loads fixture from path", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fixture.json")
+		fixture := `[{"type":"Results","is_final":true,"channel":{"alternatives":[{"words":[{"punctuated_word":"Hi.","speaker":3}]}]}}]`
+		if err := os.WriteFile(path, []byte(fixture), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("TRANSCRIPTION_FIXTURE_PATH", path)
+
+		client, err := NewFakeClient()
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		var got string
+		_ = client.StreamAudioURL(context.Background(), "", DefaultStreamOptions(), func(resp *StreamResponse) error {
+			got = resp.Channel.Alternatives[0].Words[0].PunctuatedWord
+			return nil
+		})
+		if got != "Hi." {
+			t.Errorf("Expected fixture word, got %q", got)
+		}
+	})
+
+	t.Run("fails on invalid fixture", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "fixture.json")
+		if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		t.Setenv("TRANSCRIPTION_FIXTURE_PATH", path)
+
+		if _, err := NewFakeClient(); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+
+	t.Run("stops when context is cancelled", func(t *testing.T) {
+		t.Setenv("TRANSCRIPTION_FIXTURE_PATH", "")
+		client, _ := NewFakeClient()
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := client.StreamAudioURL(ctx, "", DefaultStreamOptions(), func(resp *StreamResponse) error { return nil })
+		if err == nil {
+			t.Error("Expected context error, got nil")
+		}
+	})
+}
@@ -1,7 +1,11 @@
 package transcription
 
 import (
+	"fmt"
 	"net/http"
+	"regexp"
+	"slices"
+	"strings"
 
 	"cribeapp.com/cribe-server/internal/core/logger"
 )
@@ -14,13 +18,105 @@ type Client struct {
 	log        *logger.ContextualLogger
 }
 
-// StreamOptions configures the transcription request
+// LanguageAuto asks the provider to detect the spoken language
+const LanguageAuto = "auto"
+
+const (
+	// ModeStreaming sends audio in real time and receives results progressively
+	ModeStreaming = "streaming"
+	// ModeBatch submits the whole file and receives the full result at once,
+	// which is much faster for long episodes and suited to backfills
+	ModeBatch = "batch"
+)
+
+// StreamOptions configures the transcription request. An empty Model selects the
+// provider default and an empty or "auto" Language enables language detection.
 type StreamOptions struct {
-	Model      string // Model to use (e.g., "nova-3")
-	Language   string // Language code (e.g., "en")
-	Diarize    bool   // Enable speaker detection
-	Punctuate  bool   // Enable punctuation
-	Utterances bool   // Enable utterance grouping
+	Model       string   `json:"model,omitempty"`    // Model to use (e.g., "nova-3")
+	Language    string   `json:"language,omitempty"` // Language code (e.g., "en") or "auto"
+	Keyterms    []string `json:"keyterms,omitempty"` // Vocabulary to boost (names, jargon)
+	Diarize     bool     `json:"diarize"`            // Enable speaker detection
+	Punctuate   bool     `json:"punctuate"`          // Enable punctuation
+	SmartFormat bool     `json:"smart_format"`       // Format numbers, dates, etc.
+	Utterances  bool     `json:"utterances"`         // Enable utterance grouping
+	Mode        string   `json:"mode,omitempty"`     // "streaming" (default) or "batch"
+}
+
+// DefaultStreamOptions returns the options used when nothing overrides them
+func DefaultStreamOptions() StreamOptions {
+	return StreamOptions{
+		Language:    "en",
+		Diarize:     true,
+		Punctuate:   true,
+		SmartFormat: true,
+		Utterances:  false,
+		Mode:        ModeStreaming,
+	}
+}
+
+// IsLanguageDetection reports whether the provider should detect the language
+func (o StreamOptions) IsLanguageDetection() bool {
+	return o.Language == "" || o.Language == LanguageAuto
+}
+
+// Preferences are optional overrides layered onto StreamOptions, stored per podcast
+// and accepted per request. Zero values keep the underlying option.
+type Preferences struct {
+	Language    string   `json:"language,omitempty"`
+	Model       string   `json:"model,omitempty"`
+	Keyterms    []string `json:"keyterms,omitempty"`
+	SmartFormat *bool    `json:"smart_format,omitempty"`
+	Mode        string   `json:"mode,omitempty"`
+}
+
+const (
+	MaxKeyterms      = 50
+	MaxKeytermLength = 100
+)
+
+var (
+	languagePattern = regexp.MustCompile(`^(auto|[a-z]{2,3}(-[A-Za-z0-9]{2,8})?)$`)
+	modelPattern    = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+)
+
+// Validate checks the preferences before they are stored or sent to a provider
+func (p Preferences) Validate() error {
+	if p.Language != "" && !languagePattern.MatchString(p.Language) {
+		return fmt.Errorf("language must be a language code (e.g. en, pt-BR) or %q", LanguageAuto)
+	}
+	if p.Model != "" && !modelPattern.MatchString(p.Model) {
+		return fmt.Errorf("model must be 1-64 letters, digits, dots, dashes or underscores")
+	}
+	if p.Mode != "" && p.Mode != ModeStreaming && p.Mode != ModeBatch {
+		return fmt.Errorf("mode must be %q or %q", ModeStreaming, ModeBatch)
+	}
+	if len(p.Keyterms) > MaxKeyterms {
+		return fmt.Errorf("at most %d keyterms are allowed", MaxKeyterms)
+	}
+	for _, term := range p.Keyterms {
+		if strings.TrimSpace(term) == "" || len(term) > MaxKeytermLength {
+			return fmt.Errorf("keyterms must be non-blank and at most %d characters", MaxKeytermLength)
+		}
+	}
+	return nil
+}
+
+// Apply returns opts with the preferences applied. Keyterms are appended.
+func (p Preferences) Apply(opts StreamOptions) StreamOptions {
+	if p.Language != "" {
+		opts.Language = p.Language
+	}
+	if p.Model != "" {
+		opts.Model = p.Model
+	}
+	if p.SmartFormat != nil {
+		opts.SmartFormat = *p.SmartFormat
+	}
+	if p.Mode != "" {
+		opts.Mode = p.Mode
+	}
+	opts.Keyterms = append(slices.Clone(opts.Keyterms), p.Keyterms...)
+	return opts
 }
 
 // Word represents a single word in the transcript
@@ -63,7 +159,10 @@ type StreamResponse struct {
 	Channel      Channel  `json:"channel"`
 	ChannelIndex []int    `json:"channel_index"`
 	IsFinal      bool     `json:"is_final"`
-	Type         string   `json:"type"` // "Results", "UtteranceEnd", "Metadata"
+	Type         string   `json:"type"`          // "Results", "UtteranceEnd", "Metadata"
+	Start        float64  `json:"start"`         // Audio time the result starts at
+	Duration     float64  `json:"duration"`      // Audio duration covered by the result
+	FromFinalize bool     `json:"from_finalize"` // Result flushed by a Finalize message
 }
 
 // StreamCallback is called for each response chunk
@@ -0,0 +1,109 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+)
+
+const (
+	ProviderDeepgram = "deepgram"
+	ProviderWhisper  = "whisper"
+	ProviderFake     = "fake"
+
+	DefaultProvider = ProviderDeepgram
+)
+
+// Provider streams transcription results for an audio URL. Every provider reports
+// words through the same StreamResponse shape used by the live streaming API and
+// ignores options it does not support.
+type Provider interface {
+	StreamAudioURL(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error
+}
+
+// ProviderFactory builds a provider from the environment
+type ProviderFactory func() (Provider, error)
+
+// providersMu guards providers, which may be registered while others are looked up
+var providersMu sync.RWMutex
+
+var providers = map[string]ProviderFactory{
+	ProviderDeepgram: func() (Provider, error) {
+		client := NewClient()
+		if client == nil {
+			return nil, fmt.Errorf("missing TRANSCRIPTION_API_KEY or TRANSCRIPTION_API_BASE_URL")
+		}
+		return client, nil
+	},
+	ProviderWhisper: func() (Provider, error) {
+		client := NewWhisperClient()
+		if client == nil {
+			return nil, fmt.Errorf("missing WHISPER_API_BASE_URL")
+		}
+		return client, nil
+	},
+	ProviderFake: func() (Provider, error) {
+		return NewFakeClient()
+	},
+}
+
+// RegisterProvider adds or replaces a provider that can be selected with TRANSCRIPTION_PROVIDER
+func RegisterProvider(name string, factory ProviderFactory) {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	providers[strings.ToLower(name)] = factory
+}
+
+// NewProvider creates the provider selected by TRANSCRIPTION_PROVIDER (deepgram by default).
+// It returns nil when the provider is unknown or misconfigured.
+func NewProvider() Provider {
+	log := logger.NewServiceLogger("TranscriptionProvider")
+
+	name := strings.ToLower(strings.TrimSpace(os.Getenv("TRANSCRIPTION_PROVIDER")))
+	if name == "" {
+		name = DefaultProvider
+	}
+
+	providersMu.RLock()
+	factory, ok := providers[name]
+	providersMu.RUnlock()
+	if !ok {
+		log.Error("Unknown transcription provider", map[string]any{
+			"provider":  name,
+			"available": ProviderNames(),
+		})
+		return nil
+	}
+
+	provider, err := factory()
+	if err != nil {
+		log.Error("Failed to initialize transcription provider", map[string]any{
+			"provider": name,
+			"error":    err.Error(),
+		})
+		return nil
+	}
+
+	log.Info("Transcription provider selected", map[string]any{
+		"provider": name,
+	})
+
+	return provider
+}
+
+// ProviderNames returns the registered provider names in a stable order
+func ProviderNames() []string {
+	providersMu.RLock()
+	names := make([]string, 0, len(providers))
+	for name := range providers {
+		names = append(names, name)
+	}
+	providersMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
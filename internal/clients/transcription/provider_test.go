@@ -0,0 +1,97 @@
+package transcription
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type stubProvider struct{}
+
+func (stubProvider) StreamAudioURL(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error {
+	return nil
+}
+
+func TestNewProvider(t *testing.T) {
+	RegisterProvider("Stub", func() (Provider, error) { return stubProvider{}, nil })
+	defer delete(providers, "stub")
+
+	tests := []struct {
+		name     string
+		env      map[string]string
+		wantType string
+		wantNil  bool
+	}{
+		{"defaults to deepgram", map[string]string{"TRANSCRIPTION_API_KEY": "key", "TRANSCRIPTION_API_BASE_URL": "https://api.deepgram.com"}, "*transcription.Client", false},
+		{"misconfigured deepgram", map[string]string{"TRANSCRIPTION_PROVIDER": "deepgram"}, "", true},
+		{"whisper provider", map[string]string{"TRANSCRIPTION_PROVIDER": "whisper", "WHISPER_API_BASE_URL": "http://localhost:8000/v1"}, "*transcription.WhisperClient", false},
+		{"fake provider", map[string]string{"TRANSCRIPTION_PROVIDER": "FAKE"}, "*transcription.FakeClient", false},
+		{"registered provider", map[string]string{"TRANSCRIPTION_PROVIDER": "stub"}, "transcription.stubProvider", false},
+		{"unknown provider", map[string]string{"TRANSCRIPTION_PROVIDER": "nope"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, key := range []string{"TRANSCRIPTION_PROVIDER", "TRANSCRIPTION_API_KEY", "TRANSCRIPTION_API_BASE_URL", "WHISPER_API_BASE_URL", "TRANSCRIPTION_FIXTURE_PATH"} {
+				t.Setenv(key, tt.env[key])
+			}
+
+			provider := NewProvider()
+
+			if tt.wantNil {
+				if provider != nil {
+					t.Errorf("Expected nil provider, got %T", provider)
+				}
+				return
+			}
+			if got := fmt.Sprintf("%T", provider); got != tt.wantType {
+				t.Errorf("Expected provider %s, got %s", tt.wantType, got)
+			}
+		})
+	}
+}
+
+func TestProviderNames(t *testing.T) {
+	names := ProviderNames()
+	want := []string{ProviderDeepgram, ProviderFake, ProviderWhisper}
+
+	if len(names) != len(want) {
+		t.Fatalf("Expected %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Errorf("Expected %v, got %v", want, names)
+		}
+	}
+}
+
+func TestRegisterProvider_Concurrent(t *testing.T) {
+	t.Setenv("TRANSCRIPTION_PROVIDER", ProviderFake)
+	defer func() {
+		providersMu.Lock()
+		defer providersMu.Unlock()
+		for i := range 10 {
+			delete(providers, fmt.Sprintf("stub%d", i))
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			RegisterProvider(fmt.Sprintf("Stub%d", i), func() (Provider, error) { return stubProvider{}, nil })
+		}()
+		go func() {
+			defer wg.Done()
+			_ = NewProvider()
+			_ = ProviderNames()
+		}()
+	}
+	wg.Wait()
+
+	if names := ProviderNames(); len(names) != 13 {
+		t.Errorf("Expected 13 providers, got %v", names)
+	}
+}
@@ -0,0 +1,173 @@
+package transcription
+
+import (
+	"errors"
+	"mime"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// checkpointBytes is how much audio is sent between Finalize messages. Each
+	// finalized result tells us which byte offset Deepgram has fully processed.
+	checkpointBytes = 1 << 20
+	// maxReconnectAttempts limits reconnects after the connection is lost
+	maxReconnectAttempts = 3
+)
+
+var (
+	// keepAliveInterval keeps the socket open while audio is not flowing (slow
+	// downloads, long pauses); Deepgram closes idle streams after about 10 seconds
+	keepAliveInterval = 5 * time.Second
+	// reconnectDelay is multiplied by the attempt number before reconnecting
+	reconnectDelay = time.Second
+)
+
+// errConnectionLost marks WebSocket failures that are worth reconnecting for
+var errConnectionLost = errors.New("transcription connection lost")
+
+// resumePoint maps a byte offset of the audio to the audio time it starts at
+type resumePoint struct {
+	Offset int64
+	Time   float64
+}
+
+// streamSession keeps the progress of a WebSocket transcription across reconnects.
+// Each connection streams audio from the last checkpoint; its timestamps restart at
+// zero, so words are re-based onto the checkpoint time and already delivered words
+// are dropped to keep chunk positions unique and in order.
+type streamSession struct {
+	mu sync.Mutex
+	// base is the checkpoint the current connection started from
+	base resumePoint
+	// checkpoint is the latest position Deepgram acknowledged with a finalized result
+	checkpoint resumePoint
+	// pending holds the byte offsets at which Finalize was sent, oldest first
+	pending []int64
+	// resumable is false for formats that can't be decoded from the middle of the file
+	resumable bool
+	// lastWordEnd is the end time of the last delivered word
+	lastWordEnd float64
+	// replaying is set after a reconnect until the words pass lastWordEnd
+	replaying bool
+	started   bool
+
+	writeMu   sync.Mutex
+	lastWrite time.Time
+}
+
+func newStreamSession() *streamSession {
+	return &streamSession{}
+}
+
+// begin starts a new connection from the latest checkpoint and returns its byte offset
+func (s *streamSession) begin() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Finalize requests sent on the lost connection will never be answered
+	s.pending = nil
+	s.base = s.checkpoint
+	s.replaying = s.lastWordEnd > s.base.Time
+	s.started = true
+	return s.base.Offset
+}
+
+// resuming reports whether audio was already streamed on an earlier connection
+func (s *streamSession) resuming() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.started
+}
+
+// setContentType enables checkpoints for frame-based formats that decode from any
+// offset (MP3, ADTS AAC). Other formats resume from the start of the file.
+func (s *streamSession) setContentType(contentType string) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch mediaType {
+	case "audio/mpeg", "audio/mp3", "audio/aac":
+		s.resumable = true
+	default:
+		s.resumable = false
+	}
+}
+
+// requestCheckpoint records that Finalize is being sent after offset bytes of audio
+func (s *streamSession) requestCheckpoint(offset int64) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.resumable {
+		return false
+	}
+	s.pending = append(s.pending, offset)
+	return true
+}
+
+// acknowledge moves the checkpoint when a finalized result arrives. end is the audio
+// time, relative to the connection, up to which Deepgram processed the stream.
+func (s *streamSession) acknowledge(end float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.pending) == 0 {
+		return
+	}
+	s.checkpoint = resumePoint{Offset: s.pending[0], Time: s.base.Time + end}
+	s.pending = s.pending[1:]
+}
+
+// rebase shifts the words of a result onto the audio timeline. After a reconnect,
+// words whose midpoint falls before the end of the last delivered word are replays
+// of audio sent before the disconnect and are dropped.
+func (s *streamSession) rebase(words []Word) []Word {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := words[:0]
+	for _, word := range words {
+		word.Start += s.base.Time
+		word.End += s.base.Time
+		if s.replaying && (word.Start+word.End)/2 < s.lastWordEnd {
+			continue
+		}
+		s.replaying = false
+		s.lastWordEnd = word.End
+		kept = append(kept, word)
+	}
+	return kept
+}
+
+// writeMessage serializes writes from the audio streamer and the keepalive loop
+func (s *streamSession) writeMessage(conn *websocket.Conn, messageType int, data []byte) error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	s.lastWrite = time.Now()
+	return conn.WriteMessage(messageType, data)
+}
+
+// idleFor reports how long ago the last message was written
+func (s *streamSession) idleFor() time.Duration {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return time.Since(s.lastWrite)
+}
+
+// isConnectionLost reports whether a read error means the connection dropped, as
+// opposed to a normal close or the provider rejecting the stream
+func isConnectionLost(err error) bool {
+	var closeErr *websocket.CloseError
+	if errors.As(err, &closeErr) {
+		switch closeErr.Code {
+		case websocket.ClosePolicyViolation, websocket.CloseUnsupportedData, websocket.CloseInvalidFramePayloadData:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,336 @@
+package transcription
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+)
+
+func TestStreamSession(t *testing.T) {
+	t.Run("checkpoints only resumable formats", func(t *testing.T) {
+		session := newStreamSession()
+		session.setContentType("audio/mp4")
+		if session.requestCheckpoint(100) {
+			t.Error("Expected no checkpoint for audio/mp4")
+		}
+
+		session.setContentType("audio/mpeg; charset=binary")
+		if !session.requestCheckpoint(100) {
+			t.Error("Expected checkpoint for audio/mpeg")
+		}
+	})
+
+	t.Run("acknowledges checkpoints in order and resumes from the latest", func(t *testing.T) {
+		session := newStreamSession()
+		session.begin()
+		session.setContentType("audio/mpeg")
+		session.requestCheckpoint(1000)
+		session.requestCheckpoint(2000)
+
+		session.acknowledge(10)
+		session.acknowledge(20)
+		session.acknowledge(30) // no pending Finalize
+
+		if offset := session.begin(); offset != 2000 {
+			t.Errorf("Expected to resume from 2000, got %d", offset)
+		}
+		if session.base.Time != 20 {
+			t.Errorf("Expected base time 20, got %v", session.base.Time)
+		}
+
+		// Times on the new connection are relative to the checkpoint
+		session.requestCheckpoint(3000)
+		session.acknowledge(5)
+		if session.checkpoint != (resumePoint{Offset: 3000, Time: 25}) {
+			t.Errorf("Unexpected checkpoint: %+v", session.checkpoint)
+		}
+	})
+
+	t.Run("rebases words and drops replayed ones after a reconnect", func(t *testing.T) {
+		session := newStreamSession()
+		session.begin()
+		session.setContentType("audio/mpeg")
+		session.requestCheckpoint(1000)
+		session.acknowledge(10)
+
+		delivered := session.rebase([]Word{{Word: "a", Start: 9, End: 9.5}, {Word: "b", Start: 11, End: 11.25}, {Word: "c", Start: 11.25, End: 11.5}})
+		if len(delivered) != 3 {
+			t.Fatalf("Expected all words without a reconnect, got %d", len(delivered))
+		}
+
+		session.begin()
+		got := session.rebase([]Word{{Word: "b", Start: 1, End: 1.25}, {Word: "c", Start: 1.25, End: 1.5}, {Word: "d", Start: 1.5, End: 1.75}})
+		if len(got) != 1 || got[0].Word != "d" || got[0].Start != 11.5 {
+			t.Errorf("Expected only the rebased new word, got %+v", got)
+		}
+
+		got = session.rebase([]Word{{Word: "e", Start: 1.75, End: 2}})
+		if len(got) != 1 {
+			t.Errorf("Expected words after the replay to be kept, got %+v", got)
+		}
+	})
+}
+
+func TestIsConnectionLost(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"abnormal close", &websocket.CloseError{Code: websocket.CloseAbnormalClosure}, true},
+		{"provider timeout", &websocket.CloseError{Code: websocket.CloseInternalServerErr}, true},
+		{"network error", io.ErrUnexpectedEOF, true},
+		{"rejected audio", &websocket.CloseError{Code: websocket.CloseUnsupportedData}, false},
+		{"policy violation", fmt.Errorf("read: %w", &websocket.CloseError{Code: websocket.ClosePolicyViolation}), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isConnectionLost(tt.err); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestOpenAudio(t *testing.T) {
+	audio := []byte("0123456789")
+	client := &Client{httpClient: http.DefaultClient, log: logger.NewServiceLogger("TestClient")}
+
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"server honours range", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeContent(w, r, "audio.mp3", time.Time{}, bytes.NewReader(audio))
+		}},
+		{"server ignores range", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write(audio)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			resp, err := client.openAudio(context.Background(), server.URL, 4)
+			if err != nil {
+				t.Fatalf("Expected no error, got %v", err)
+			}
+			defer func() { _ = resp.Body.Close() }()
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != "456789" {
+				t.Errorf("Expected audio from offset 4, got %q", body)
+			}
+		})
+	}
+}
+
+func TestStreamAudioURLWebSocketResilience(t *testing.T) {
+	originalDelay, originalKeepAlive := reconnectDelay, keepAliveInterval
+	reconnectDelay, keepAliveInterval = 0, 20*time.Millisecond
+	t.Cleanup(func() { reconnectDelay, keepAliveInterval = originalDelay, originalKeepAlive })
+
+	writeResult := func(conn *websocket.Conn, resp StreamResponse) {
+		resp.Type, resp.IsFinal = "Results", true
+		data, _ := json.Marshal(resp)
+		_ = conn.WriteMessage(websocket.TextMessage, data)
+	}
+	result := func(words ...Word) StreamResponse {
+		return StreamResponse{Channel: Channel{Alternatives: []Alternative{{Words: words}}}}
+	}
+	newClient := func(wsURL string) *Client {
+		return &Client{apiKey: "test-key", baseURL: strings.Replace(wsURL, "http://", "ws://", 1), httpClient: http.DefaultClient, log: logger.NewServiceLogger("TestClient")}
+	}
+	var mu sync.Mutex
+	collect := func(words *[]Word) StreamCallback {
+		return func(resp *StreamResponse) error {
+			mu.Lock()
+			defer mu.Unlock()
+			*words = append(*words, resp.Channel.Alternatives[0].Words...)
+			return nil
+		}
+	}
+
+	t.Run("reconnects after an abnormal close without duplicating words", func(t *testing.T) {
+		audioServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "audio data")
+		}))
+		defer audioServer.Close()
+
+		var connections atomic.Int32
+		wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, _ := upgrader.Upgrade(w, r, nil)
+			defer func() { _ = conn.Close() }()
+			first := connections.Add(1) == 1
+
+			for {
+				messageType, message, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if first && messageType == websocket.BinaryMessage {
+					writeResult(conn, result(Word{Word: "hello", Start: 0, End: 0.5}))
+					_ = conn.UnderlyingConn().Close()
+					return
+				}
+				if strings.Contains(string(message), "CloseStream") {
+					writeResult(conn, result(Word{Word: "hello", Start: 0, End: 0.5}, Word{Word: "world", Start: 0.6, End: 1}))
+					_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}))
+		defer wsServer.Close()
+
+		var words []Word
+		err := newClient(wsServer.URL).StreamAudioURLWebSocket(context.Background(), audioServer.URL, StreamOptions{Model: "nova-2"}, collect(&words))
+
+		if err != nil {
+			t.Fatalf("Expected reconnect to succeed, got %v", err)
+		}
+		if connections.Load() != 2 || len(words) != 2 || words[0].Word != "hello" || words[1].Word != "world" {
+			t.Errorf("Expected hello world over 2 connections, got %+v over %d", words, connections.Load())
+		}
+	})
+
+	t.Run("resumes from the acknowledged offset and re-bases timestamps", func(t *testing.T) {
+		audio := bytes.Repeat([]byte{0xff}, checkpointBytes+50000)
+		var ranges []string
+		audioServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			ranges = append(ranges, r.Header.Get("Range"))
+			mu.Unlock()
+			w.Header().Set("Content-Type", "audio/mpeg")
+			http.ServeContent(w, r, "episode.mp3", time.Time{}, bytes.NewReader(audio))
+		}))
+		defer audioServer.Close()
+
+		var connections atomic.Int32
+		wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, _ := upgrader.Upgrade(w, r, nil)
+			defer func() { _ = conn.Close() }()
+			first := connections.Add(1) == 1
+
+			for {
+				_, message, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if first && strings.Contains(string(message), "Finalize") {
+					ack := result(Word{Word: "one", Start: 59, End: 59.5})
+					ack.Start, ack.Duration, ack.FromFinalize = 0, 60, true
+					writeResult(conn, ack)
+					_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseInternalServerErr, "timeout"))
+				}
+				if strings.Contains(string(message), "CloseStream") {
+					writeResult(conn, result(Word{Word: "two", Start: 1, End: 1.5}))
+					_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}))
+		defer wsServer.Close()
+
+		var words []Word
+		err := newClient(wsServer.URL).StreamAudioURLWebSocket(context.Background(), audioServer.URL, StreamOptions{Model: "nova-2"}, collect(&words))
+
+		if err != nil {
+			t.Fatalf("Expected resume to succeed, got %v", err)
+		}
+		if len(words) != 2 || words[0].Start != 59 || words[1].Word != "two" || words[1].Start != 61 {
+			t.Errorf("Expected rebased words, got %+v", words)
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		var offset int
+		if len(ranges) != 2 || ranges[0] != "" {
+			t.Fatalf("Expected a full download then a ranged one, got %q", ranges)
+		}
+		if _, err := fmt.Sscanf(ranges[1], "bytes=%d-", &offset); err != nil || offset < checkpointBytes {
+			t.Errorf("Expected resume from the checkpoint offset, got %q", ranges[1])
+		}
+	})
+
+	t.Run("gives up when the provider rejects the stream", func(t *testing.T) {
+		audioServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = io.WriteString(w, "audio data")
+		}))
+		defer audioServer.Close()
+
+		var connections atomic.Int32
+		wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, _ := upgrader.Upgrade(w, r, nil)
+			defer func() { _ = conn.Close() }()
+			connections.Add(1)
+			_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseUnsupportedData, "corrupt audio"))
+			for {
+				if _, _, err := conn.ReadMessage(); err != nil {
+					return
+				}
+			}
+		}))
+		defer wsServer.Close()
+
+		err := newClient(wsServer.URL).StreamAudioURLWebSocket(context.Background(), audioServer.URL, StreamOptions{Model: "nova-2"}, func(resp *StreamResponse) error { return nil })
+
+		if err == nil || connections.Load() != 1 {
+			t.Errorf("Expected an error without reconnecting, got %v after %d connections", err, connections.Load())
+		}
+	})
+
+	t.Run("sends keepalive while audio is not flowing", func(t *testing.T) {
+		audioServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.(http.Flusher).Flush()
+			time.Sleep(150 * time.Millisecond)
+			_, _ = io.WriteString(w, "audio data")
+		}))
+		defer audioServer.Close()
+
+		var keepAlives atomic.Int32
+		wsServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			upgrader := websocket.Upgrader{}
+			conn, _ := upgrader.Upgrade(w, r, nil)
+			defer func() { _ = conn.Close() }()
+			for {
+				_, message, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+				if strings.Contains(string(message), "KeepAlive") {
+					keepAlives.Add(1)
+				}
+				if strings.Contains(string(message), "CloseStream") {
+					_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+					return
+				}
+			}
+		}))
+		defer wsServer.Close()
+
+		err := newClient(wsServer.URL).StreamAudioURLWebSocket(context.Background(), audioServer.URL, StreamOptions{Model: "nova-2"}, func(resp *StreamResponse) error { return nil })
+
+		if err != nil || keepAlives.Load() == 0 {
+			t.Errorf("Expected keepalive messages, got err=%v, keepAlives=%d", err, keepAlives.Load())
+		}
+	})
+}
@@ -0,0 +1,252 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+)
+
+const (
+	DefaultWhisperModel = "whisper-1"
+
+	// whisperWordsPerResponse groups words into responses so callers receive
+	// progressive updates instead of one response with the whole episode
+	whisperWordsPerResponse = 100
+)
+
+// WhisperClient transcribes audio through an OpenAI/Whisper-compatible
+// POST /audio/transcriptions endpoint (e.g. a locally hosted whisper server)
+type WhisperClient struct {
+	apiKey     string
+	baseURL    string
+	model      string
+	httpClient *http.Client
+	log        *logger.ContextualLogger
+}
+
+type whisperWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+type whisperResponse struct {
+	Text     string        `json:"text"`
+	Language string        `json:"language"`
+	Duration float64       `json:"duration"`
+	Words    []whisperWord `json:"words"`
+}
+
+// NewWhisperClient creates a Whisper-compatible client. The API key is optional
+// because local servers usually do not require one.
+func NewWhisperClient() *WhisperClient {
+	log := logger.NewServiceLogger("WhisperClient")
+
+	apiKey := os.Getenv("WHISPER_API_KEY")
+	baseURL := strings.TrimSuffix(os.Getenv("WHISPER_API_BASE_URL"), "/")
+	model := os.Getenv("WHISPER_MODEL")
+	if model == "" {
+		model = DefaultWhisperModel
+	}
+
+	if baseURL == "" {
+		log.Error("Missing required environment variables for Whisper client", map[string]any{
+			"has_base_url": false,
+		})
+		return nil
+	}
+
+	log.Info("Whisper client initialized", map[string]any{
+		"baseURL": baseURL,
+		"model":   model,
+		"hasKey":  apiKey != "",
+	})
+
+	return &WhisperClient{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		model:      model,
+		httpClient: &http.Client{},
+		log:        log,
+	}
+}
+
+// StreamAudioURL downloads the audio, uploads it for transcription and reports the
+// words in batches. Whisper has no diarization, so every word belongs to speaker 0;
+// keyterms are passed as a prompt and smart formatting is not supported.
+func (c *WhisperClient) StreamAudioURL(ctx context.Context, audioURL string, opts StreamOptions, callback StreamCallback) error {
+	audioReq, err := http.NewRequestWithContext(ctx, "GET", audioURL, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create audio download request: %w", err)
+	}
+
+	audioResp, err := c.httpClient.Do(audioReq)
+	if err != nil {
+		return fmt.Errorf("failed to download audio: %w", err)
+	}
+	defer func() {
+		if err := audioResp.Body.Close(); err != nil {
+			c.log.Error("Failed to close audio response body", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	if audioResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(audioResp.Body)
+		return fmt.Errorf("audio download failed: status=%d, body=%s", audioResp.StatusCode, string(body))
+	}
+
+	// Pipe the download into the multipart upload so the audio is never held in memory
+	bodyReader, bodyWriter := io.Pipe()
+	form := multipart.NewWriter(bodyWriter)
+	go func() {
+		bodyWriter.CloseWithError(c.writeTranscriptionForm(form, audioURL, opts, audioResp.Body))
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.baseURL+"/audio/transcriptions", bodyReader)
+	if err != nil {
+		_ = bodyReader.Close()
+		return fmt.Errorf("failed to create transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if c.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	c.log.Info("Uploading audio for Whisper transcription", map[string]any{
+		"audioURL": audioURL,
+		"model":    c.modelFor(opts),
+		"language": opts.Language,
+	})
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to send transcription request: %w", err)
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			c.log.Error("Failed to close transcription response body", map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("transcription request failed: status=%d, body=%s", resp.StatusCode, string(body))
+	}
+
+	var result whisperResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("failed to decode transcription response: %w", err)
+	}
+
+	c.log.Info("Whisper transcription received", map[string]any{
+		"words":    len(result.Words),
+		"duration": result.Duration,
+		"language": result.Language,
+	})
+
+	for start := 0; start < len(result.Words); start += whisperWordsPerResponse {
+		end := min(start+whisperWordsPerResponse, len(result.Words))
+		if err := callback(toStreamResponse(result.Words[start:end], result.Duration)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// modelFor returns the requested model, falling back to the configured one
+func (c *WhisperClient) modelFor(opts StreamOptions) string {
+	if opts.Model != "" {
+		return opts.Model
+	}
+	return c.model
+}
+
+// writeTranscriptionForm writes the multipart fields and the audio file
+func (c *WhisperClient) writeTranscriptionForm(form *multipart.Writer, audioURL string, opts StreamOptions, audio io.Reader) error {
+	fields := [][2]string{
+		{"model", c.modelFor(opts)},
+		{"response_format", "verbose_json"},
+		{"timestamp_granularities[]", "word"},
+	}
+	if !opts.IsLanguageDetection() {
+		fields = append(fields, [2]string{"language", opts.Language})
+	}
+	if len(opts.Keyterms) > 0 {
+		// Whisper has no vocabulary boosting; a prompt with the terms biases spelling
+		fields = append(fields, [2]string{"prompt", "Glossary: " + strings.Join(opts.Keyterms, ", ") + "."})
+	}
+	for _, field := range fields {
+		if err := form.WriteField(field[0], field[1]); err != nil {
+			return err
+		}
+	}
+
+	part, err := form.CreateFormFile("file", audioFileName(audioURL))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(part, audio); err != nil {
+		return fmt.Errorf("failed to upload audio: %w", err)
+	}
+
+	return form.Close()
+}
+
+// toStreamResponse converts Whisper words into a final streaming result
+func toStreamResponse(words []whisperWord, duration float64) *StreamResponse {
+	converted := make([]Word, 0, len(words))
+	texts := make([]string, 0, len(words))
+	for _, word := range words {
+		text := strings.TrimSpace(word.Word)
+		if text == "" {
+			continue
+		}
+		converted = append(converted, Word{
+			Word:           text,
+			PunctuatedWord: text,
+			Start:          word.Start,
+			End:            word.End,
+		})
+		texts = append(texts, text)
+	}
+
+	return &StreamResponse{
+		Type:     "Results",
+		IsFinal:  true,
+		Metadata: Metadata{Duration: duration},
+		Channel: Channel{
+			Alternatives: []Alternative{{
+				Transcript: strings.Join(texts, " "),
+				Words:      converted,
+			}},
+		},
+	}
+}
+
+// audioFileName keeps the extension of the audio URL so servers can detect the format
+func audioFileName(audioURL string) string {
+	u, err := url.Parse(audioURL)
+	if err != nil {
+		return "audio.mp3"
+	}
+
+	name := path.Base(u.Path)
+	if !strings.Contains(name, ".") {
+		return "audio.mp3"
+	}
+	return name
+}
@@ -0,0 +1,136 @@
+package transcription
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+)
+
+func TestWhisperClientStreamAudioURL(t *testing.T) {
+	audioServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("fake-audio-bytes"))
+	}))
+	defer audioServer.Close()
+
+	words := make([]whisperWord, 0, whisperWordsPerResponse+1)
+	for i := 0; i <= whisperWordsPerResponse; i++ {
+		words = append(words, whisperWord{Word: " word", Start: float64(i), End: float64(i) + 0.5})
+	}
+
+	var gotForm map[string]string
+	var gotAudio string
+	var gotAuth string
+	whisperServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/audio/transcriptions" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		if err := r.ParseMultipartForm(1 << 20); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		gotForm = map[string]string{
+			"model":           r.FormValue("model"),
+			"response_format": r.FormValue("response_format"),
+			"language":        r.FormValue("language"),
+			"prompt":          r.FormValue("prompt"),
+		}
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		gotAudio = header.Filename + ":" + string(data)
+
+		_ = json.NewEncoder(w).Encode(whisperResponse{Text: "word", Duration: 101, Words: words})
+	}))
+	defer whisperServer.Close()
+
+	client := &WhisperClient{
+		apiKey:     "local-key",
+		baseURL:    whisperServer.URL,
+		model:      "large-v3",
+		httpClient: &http.Client{},
+		log:        logger.NewServiceLogger("WhisperClient"),
+	}
+
+	t.Run("uploads audio and reports words in batches", func(t *testing.T) {
+		var batches []int
+		opts := StreamOptions{Language: "pt", Keyterms: []string{"Cribe", "Fabio"}}
+		err := client.StreamAudioURL(context.Background(), audioServer.URL+"/episode.m4a?token=x", opts, func(resp *StreamResponse) error {
+			if !resp.IsFinal || resp.Channel.Alternatives[0].Words[0].PunctuatedWord != "word" {
+				t.Errorf("Unexpected response: %+v", resp)
+			}
+			batches = append(batches, len(resp.Channel.Alternatives[0].Words))
+			return nil
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(batches) != 2 || batches[0] != whisperWordsPerResponse || batches[1] != 1 {
+			t.Errorf("Unexpected batches: %v", batches)
+		}
+		if gotForm["model"] != "large-v3" || gotForm["response_format"] != "verbose_json" ||
+			gotForm["language"] != "pt" || gotForm["prompt"] != "Glossary: Cribe, Fabio." {
+			t.Errorf("Unexpected form fields: %v", gotForm)
+		}
+		if gotAudio != "episode.m4a:fake-audio-bytes" {
+			t.Errorf("Unexpected uploaded audio: %s", gotAudio)
+		}
+		if gotAuth != "Bearer local-key" {
+			t.Errorf("Unexpected authorization header: %s", gotAuth)
+		}
+	})
+
+	t.Run("stops on callback error", func(t *testing.T) {
+		callbackErr := errors.New("client gone")
+		calls := 0
+		err := client.StreamAudioURL(context.Background(), audioServer.URL, DefaultStreamOptions(), func(resp *StreamResponse) error {
+			calls++
+			return callbackErr
+		})
+
+		if !errors.Is(err, callbackErr) || calls != 1 {
+			t.Errorf("Expected callback error after one call, got %v after %d calls", err, calls)
+		}
+	})
+
+	t.Run("returns error on server failure", func(t *testing.T) {
+		failingServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "boom", http.StatusInternalServerError)
+		}))
+		defer failingServer.Close()
+
+		failing := *client
+		failing.baseURL = failingServer.URL
+
+		err := failing.StreamAudioURL(context.Background(), audioServer.URL, DefaultStreamOptions(), func(resp *StreamResponse) error { return nil })
+
+		if err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestAudioFileName(t *testing.T) {
+	tests := map[string]string{
+		"https://cdn.example.com/shows/ep1.mp3?sig=abc": "ep1.mp3",
+		"https://cdn.example.com/stream":                "audio.mp3",
+		"https://cdn.example.com/":                      "audio.mp3",
+	}
+
+	for audioURL, want := range tests {
+		if got := audioFileName(audioURL); got != want {
+			t.Errorf("audioFileName(%q) = %q, want %q", audioURL, got, want)
+		}
+	}
+}
@@ -3,6 +3,7 @@
 package core
 
 import (
+	"context"
 	"net/http"
 	"time"
 
@@ -37,30 +38,38 @@ func Handler(port string) error {
 
 	mux := http.NewServeMux()
 
+	// The transcripts, quizzes and reaper share one transcript repository
+	transcriptRepo := transcripts.NewTranscriptRepository()
+
 	// Initialize handlers
 	authHandler := auth.HandleHTTPRequests()
 	podcastsHandler := podcasts.HandleHTTPRequests()
-	quizzesHandler := quizzes.HandleHTTPRequests()
+	quizzesHandler := quizzes.HandleHTTPRequests(transcriptRepo)
 	statusHandler := status.HandleHTTPRequests()
-	transcriptsHandler := transcripts.HandleHTTPRequests()
+	transcriptsHandler := transcripts.HandleHTTPRequests(transcriptRepo)
 	usersHandler := users.HandleHTTPRequests()
 
 	// Register routes
 	registerRoute(mux, "/auth", authHandler)
+	registerRoute(mux, "/episodes", transcriptsHandler)
 	registerRoute(mux, "/migrations", migrations.HandleHTTPRequests)
 	registerRoute(mux, "/podcasts", podcastsHandler)
 	registerRoute(mux, "/quizzes", quizzesHandler)
+	registerRoute(mux, "/search", transcriptsHandler)
 	registerRoute(mux, "/status", statusHandler)
 	registerRoute(mux, "/transcripts", transcriptsHandler)
 	registerRoute(mux, "/users", usersHandler)
 	mux.HandleFunc("/", utils.NotFound)
 
 	log.Debug("Registered routes", map[string]any{
-		"routes": []string{"/auth/", "/migrations", "/podcasts/", "/quizzes/", "/status/", "/transcripts/", "/users/", "/"},
+		"routes": []string{"/auth/", "/episodes/", "/migrations", "/podcasts/", "/quizzes/", "/search/", "/status/", "/transcripts/", "/users/", "/"},
 	})
 
 	muxWithMiddleware := middlewares.MainMiddleware(mux)
 
+	// Start background workers
+	go transcripts.NewStaleTranscriptReaper(transcriptRepo).Start(context.Background())
+
 	server := &http.Server{
 		Addr:           ":" + port,
 		Handler:        muxWithMiddleware,
@@ -20,6 +20,7 @@ const (
 const (
 	DatabaseError    = "Database error"
 	DatabaseNotFound = "Database record not found"
+	DatabaseConflict = "Database record changed concurrently"
 )
 
 // External API Errors
@@ -11,7 +11,9 @@ var privateRoutes = map[string]bool{
 	"/users":       true,
 	"/podcasts":    true,
 	"/transcripts": true,
+	"/episodes":    true,
 	"/quizzes":     true,
+	"/search":      true,
 }
 
 func isPrivateRoute(path string) bool {
@@ -0,0 +1,45 @@
+package redaction
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	MaxTerms      = 100
+	MaxTermLength = 100
+)
+
+// Policy selects what is redacted from a podcast's transcripts. The zero value redacts nothing.
+type Policy struct {
+	// PII masks email addresses and phone numbers
+	PII bool `json:"pii,omitempty"`
+	// Profanity masks common English and Portuguese profanity
+	Profanity bool `json:"profanity,omitempty"`
+	// LLM adds a pass over the finished transcript for what the patterns miss, such as
+	// spelled-out contact details or street addresses
+	LLM bool `json:"llm,omitempty"`
+	// Terms are extra words or phrases masked like profanity, e.g. a guest's private name
+	Terms []string `json:"terms,omitempty"`
+}
+
+// Enabled reports whether the policy redacts anything
+func (p Policy) Enabled() bool {
+	return p.PII || p.Profanity || len(p.Terms) > 0
+}
+
+// Validate checks the policy before it is stored
+func (p Policy) Validate() error {
+	if p.LLM && !p.PII && !p.Profanity {
+		return fmt.Errorf("llm requires pii or profanity to be enabled")
+	}
+	if len(p.Terms) > MaxTerms {
+		return fmt.Errorf("at most %d terms are allowed", MaxTerms)
+	}
+	for _, term := range p.Terms {
+		if strings.TrimSpace(term) == "" || len(term) > MaxTermLength {
+			return fmt.Errorf("terms must be non-blank and at most %d characters", MaxTermLength)
+		}
+	}
+	return nil
+}
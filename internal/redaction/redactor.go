@@ -0,0 +1,245 @@
+package redaction
+
+import (
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	EmailMask = "[email]"
+	PhoneMask = "[phone]"
+	// PIIMask replaces personal information found by the LLM pass
+	PIIMask = "[redacted]"
+)
+
+// Categories of the phrases reported by the LLM pass
+const (
+	CategoryPII       = "pii"
+	CategoryProfanity = "profanity"
+)
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// phonePattern matches 10 to 15 digits, optionally separated by spaces, dots, dashes
+	// or parentheses, so numbers split across several words are caught too
+	phonePattern = regexp.MustCompile(`\+?\(?\d(?:[\s().-]{0,2}\d){9,14}`)
+)
+
+// profanity lists whole words only; stems would also mask words like "class" or "assist"
+var profanity = []string{
+	"asshole", "assholes", "bastard", "bastards", "bitch", "bitches", "bullshit", "cunt", "damn",
+	"dick", "dickhead", "fuck", "fucked", "fucker", "fuckers", "fucking", "goddamn", "motherfucker",
+	"motherfucking", "piss", "pissed", "shit", "shits", "shitty",
+	"buceta", "cacete", "caralho", "foda", "foda-se", "fodase", "foder", "merda", "porra", "puta",
+	"puto",
+}
+
+var profanityPattern = phrasePattern(profanity)
+
+// Phrase is a span of transcript text to redact, as reported by the LLM pass
+type Phrase struct {
+	Text     string `json:"text"`
+	Category string `json:"category"`
+}
+
+// Redactor masks the words of a transcript according to a policy
+type Redactor struct {
+	rules []rule
+}
+
+type rule struct {
+	pattern *regexp.Regexp
+	mask    func(match string) string
+}
+
+type span struct {
+	start, end int
+	mask       string
+}
+
+// New returns a redactor for the policy; it leaves text unchanged when the policy is disabled
+func New(policy Policy) *Redactor {
+	r := &Redactor{}
+	if policy.PII {
+		r.rules = append(r.rules,
+			rule{emailPattern, fixedMask(EmailMask)},
+			rule{phonePattern, fixedMask(PhoneMask)},
+		)
+	}
+	if policy.Profanity {
+		r.rules = append(r.rules, rule{profanityPattern, maskWord})
+	}
+	if len(policy.Terms) > 0 {
+		r.rules = append(r.rules, rule{phrasePattern(policy.Terms), maskWord})
+	}
+	return r
+}
+
+// Enabled reports whether the redactor has any rules
+func (r *Redactor) Enabled() bool {
+	return len(r.rules) > 0
+}
+
+// Redact returns the words with every match masked. Matches may span several words: the
+// first word holds the mask and the matched parts of the following words are removed,
+// so the result has one entry per word and keeps positions and timings aligned.
+func (r *Redactor) Redact(words []string) []string {
+	if !r.Enabled() {
+		return words
+	}
+
+	return redactSpans(words, func(text string) []span {
+		var spans []span
+		for _, rule := range r.rules {
+			for _, loc := range rule.pattern.FindAllStringIndex(text, -1) {
+				spans = append(spans, span{loc[0], loc[1], rule.mask(text[loc[0]:loc[1]])})
+			}
+		}
+		return spans
+	})
+}
+
+// RedactPhrases masks the phrases reported by the LLM pass wherever they occur in the words
+func RedactPhrases(words []string, phrases []Phrase) []string {
+	var rules []rule
+	for _, phrase := range phrases {
+		if strings.TrimSpace(phrase.Text) == "" {
+			continue
+		}
+		mask := fixedMask(PIIMask)
+		if phrase.Category == CategoryProfanity {
+			mask = maskWord
+		}
+		rules = append(rules, rule{phrasePattern([]string{phrase.Text}), mask})
+	}
+
+	return (&Redactor{rules: rules}).Redact(words)
+}
+
+func redactSpans(words []string, find func(text string) []span) []string {
+	offsets := make([]int, len(words))
+	var text strings.Builder
+	for i, word := range words {
+		if i > 0 {
+			text.WriteByte(' ')
+		}
+		offsets[i] = text.Len()
+		text.WriteString(word)
+	}
+
+	spans := mergeSpans(find(text.String()))
+	if len(spans) == 0 {
+		return words
+	}
+
+	redacted := slices.Clone(words)
+	masked := make([]bool, len(spans))
+	for i, word := range words {
+		start, end := offsets[i], offsets[i]+len(word)
+
+		var out strings.Builder
+		cursor := 0
+		changed := false
+		for j, s := range spans {
+			if s.end <= start || s.start >= end {
+				continue
+			}
+			from, to := max(s.start, start)-start, min(s.end, end)-start
+			out.WriteString(word[cursor:from])
+			if !masked[j] {
+				out.WriteString(s.mask)
+				masked[j] = true
+			}
+			cursor = to
+			changed = true
+		}
+		if changed {
+			out.WriteString(word[cursor:])
+			redacted[i] = out.String()
+		}
+	}
+
+	return redacted
+}
+
+// mergeSpans sorts the spans and joins overlapping ones, keeping the mask of the earliest
+func mergeSpans(spans []span) []span {
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	var merged []span
+	for _, s := range spans {
+		if n := len(merged); n > 0 && s.start < merged[n-1].end {
+			merged[n-1].end = max(merged[n-1].end, s.end)
+			continue
+		}
+		merged = append(merged, s)
+	}
+	return merged
+}
+
+// phrasePattern matches any of the phrases case-insensitively as whole words, allowing any
+// whitespace between their words
+func phrasePattern(phrases []string) *regexp.Regexp {
+	alternatives := make([]string, 0, len(phrases))
+	for _, phrase := range phrases {
+		fields := strings.Fields(phrase)
+		if len(fields) == 0 {
+			continue
+		}
+		quoted := make([]string, len(fields))
+		for i, field := range fields {
+			quoted[i] = regexp.QuoteMeta(field)
+		}
+		pattern := strings.Join(quoted, `\s+`)
+
+		// \b only knows ASCII word characters, so phrases starting or ending with an
+		// accented letter match without a boundary on that side
+		first, _ := utf8.DecodeRuneInString(fields[0])
+		last, _ := utf8.DecodeLastRuneInString(fields[len(fields)-1])
+		if isASCIIWordRune(first) {
+			pattern = `\b` + pattern
+		}
+		if isASCIIWordRune(last) {
+			pattern += `\b`
+		}
+		alternatives = append(alternatives, pattern)
+	}
+
+	// Longer phrases first so "foda-se" wins over "foda"
+	sort.SliceStable(alternatives, func(i, j int) bool { return len(alternatives[i]) > len(alternatives[j]) })
+	return regexp.MustCompile(`(?i)(?:` + strings.Join(alternatives, "|") + `)`)
+}
+
+func isASCIIWordRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+func fixedMask(mask string) func(string) string {
+	return func(string) string { return mask }
+}
+
+// maskWord keeps the first letter of each word and replaces the other letters with
+// asterisks, e.g. "shit" becomes "s***"
+func maskWord(match string) string {
+	var out strings.Builder
+	first := true
+	for _, r := range match {
+		switch {
+		case unicode.IsSpace(r):
+			out.WriteRune(r)
+			first = true
+		case first:
+			out.WriteRune(r)
+			first = false
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			out.WriteByte('*')
+		default:
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
@@ -0,0 +1,117 @@
+package redaction
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestRedactor_Redact(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy Policy
+		text   string
+		want   []string
+	}{
+		{
+			name:   "email in one word",
+			policy: Policy{PII: true},
+			text:   "write to jane.doe@example.com, thanks",
+			want:   []string{"write", "to", "[email],", "thanks"},
+		},
+		{
+			name:   "phone number across words",
+			policy: Policy{PII: true},
+			text:   "call (555) 123 4567 today",
+			want:   []string{"call", "[phone]", "", "", "today"},
+		},
+		{
+			name:   "short numbers are kept",
+			policy: Policy{PII: true},
+			text:   "in 2024 we had 300 episodes",
+			want:   []string{"in", "2024", "we", "had", "300", "episodes"},
+		},
+		{
+			name:   "profanity keeps punctuation",
+			policy: Policy{Profanity: true},
+			text:   "Oh shit! That class was a porra",
+			want:   []string{"Oh", "s***!", "That", "class", "was", "a", "p****"},
+		},
+		{
+			name:   "longest profanity wins",
+			policy: Policy{Profanity: true},
+			text:   "foda-se",
+			want:   []string{"f***-**"},
+		},
+		{
+			name:   "custom terms",
+			policy: Policy{Terms: []string{"Jane Roe", "Érica"}},
+			text:   "with jane roe and Érica",
+			want:   []string{"with", "j*** r**", "", "and", "É****"},
+		},
+		{
+			name:   "disabled policy",
+			policy: Policy{},
+			text:   "shit jane@example.com",
+			want:   []string{"shit", "jane@example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(tt.policy).Redact(strings.Fields(tt.text))
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestRedactor_RedactKeepsInput(t *testing.T) {
+	words := []string{"damn", "it"}
+
+	New(Policy{Profanity: true}).Redact(words)
+
+	if words[0] != "damn" {
+		t.Errorf("Expected the input to be left untouched, got %q", words)
+	}
+}
+
+func TestRedactPhrases(t *testing.T) {
+	words := strings.Fields("I live at 12 Oak Street, it sucks")
+
+	got := RedactPhrases(words, []Phrase{
+		{Text: "12  Oak Street", Category: CategoryPII},
+		{Text: "sucks", Category: CategoryProfanity},
+		{Text: " ", Category: CategoryPII},
+	})
+
+	want := []string{"I", "live", "at", "[redacted]", "", ",", "it", "s****"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+}
+
+func TestPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		policy  Policy
+		wantErr bool
+	}{
+		{"empty", Policy{}, false},
+		{"llm with pii", Policy{PII: true, LLM: true}, false},
+		{"llm alone", Policy{LLM: true}, true},
+		{"blank term", Policy{Terms: []string{" "}}, true},
+		{"long term", Policy{Terms: []string{strings.Repeat("a", MaxTermLength+1)}}, true},
+		{"too many terms", Policy{Terms: make([]string, MaxTerms+1)}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.policy.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
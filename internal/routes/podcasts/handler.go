@@ -4,6 +4,8 @@ import (
 	"net/http"
 	"strings"
 
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
@@ -21,6 +23,8 @@ func (h *PodcastHandler) HandleRequest(w http.ResponseWriter, r *http.Request) {
 		h.handleGet(w, r)
 	case http.MethodPost:
 		h.handlePost(w, r)
+	case http.MethodPut:
+		h.handlePut(w, r)
 	default:
 		utils.NotAllowed(w)
 	}
@@ -88,3 +92,91 @@ func (h *PodcastHandler) handleSyncEpisodes(w http.ResponseWriter, r *http.Reque
 	}
 	utils.EncodeResponse(w, http.StatusOK, response)
 }
+
+func (h *PodcastHandler) handlePut(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/podcasts")
+	path = strings.TrimPrefix(path, "/")
+
+	if path == "" {
+		utils.NotAllowed(w)
+		return
+	}
+
+	// PUT /podcasts/:id/transcription-options
+	if strings.HasSuffix(path, "/transcription-options") {
+		podcastID := strings.TrimSuffix(path, "/transcription-options")
+		h.handleUpdateTranscriptionOptions(w, r, podcastID)
+		return
+	}
+
+	// PUT /podcasts/:id/redaction-policy
+	if strings.HasSuffix(path, "/redaction-policy") {
+		podcastID := strings.TrimSuffix(path, "/redaction-policy")
+		h.handleUpdateRedactionPolicy(w, r, podcastID)
+		return
+	}
+
+	utils.NotFound(w, r)
+}
+
+func (h *PodcastHandler) handleUpdateTranscriptionOptions(w http.ResponseWriter, r *http.Request, podcastID string) {
+	req, errResp := utils.DecodeBody[UpdateTranscriptionOptionsRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	response, errResp := h.service.UpdateTranscriptionOptions(podcastID, userID, req.Preferences)
+	if errResp != nil {
+		switch errResp.Message {
+		case errors.ValidationError:
+			utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		case errors.Unauthorized:
+			utils.EncodeResponse(w, http.StatusForbidden, errResp)
+		case errors.DatabaseNotFound:
+			utils.EncodeResponse(w, http.StatusNotFound, errResp)
+		default:
+			utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+		}
+		return
+	}
+	utils.EncodeResponse(w, http.StatusOK, response)
+}
+
+func (h *PodcastHandler) handleUpdateRedactionPolicy(w http.ResponseWriter, r *http.Request, podcastID string) {
+	req, errResp := utils.DecodeBody[UpdateRedactionPolicyRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	response, errResp := h.service.UpdateRedactionPolicy(podcastID, userID, req.Policy)
+	if errResp != nil {
+		switch errResp.Message {
+		case errors.ValidationError:
+			utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		case errors.Unauthorized:
+			utils.EncodeResponse(w, http.StatusForbidden, errResp)
+		case errors.DatabaseNotFound:
+			utils.EncodeResponse(w, http.StatusNotFound, errResp)
+		default:
+			utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+		}
+		return
+	}
+	utils.EncodeResponse(w, http.StatusOK, response)
+}
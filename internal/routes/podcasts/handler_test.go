@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 )
 
@@ -189,3 +190,69 @@ func TestHandleSync_Error(t *testing.T) {
 		t.Errorf("Expected status 500, got %d", w.Code)
 	}
 }
+
+func TestHandleUpdateTranscriptionOptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		isAdmin    bool
+		wantStatus int
+	}{
+		{"valid options", "/podcasts/1/transcription-options", `{"language":"pt-BR","keyterms":["Cribe"],"smart_format":false}`, true, http.StatusOK},
+		{"auto language", "/podcasts/1/transcription-options", `{"language":"auto"}`, true, http.StatusOK},
+		{"not an admin", "/podcasts/1/transcription-options", `{"language":"pt-BR"}`, false, http.StatusForbidden},
+		{"invalid language", "/podcasts/1/transcription-options", `{"language":"portuguese"}`, true, http.StatusBadRequest},
+		{"invalid body", "/podcasts/1/transcription-options", `{`, true, http.StatusBadRequest},
+		{"invalid podcast ID", "/podcasts/abc/transcription-options", `{}`, true, http.StatusBadRequest},
+		{"unknown path", "/podcasts/1/other", `{}`, true, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := MockPodcastRepo{isAdminFunc: func(userID int) (bool, error) { return tt.isAdmin, nil }}
+			handler := NewPodcastHandler(NewPodcastService(mockRepo, &MockAPIClient{}))
+
+			req := httptest.NewRequest(http.MethodPut, tt.url, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.HandleRequest(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestHandleUpdateRedactionPolicy(t *testing.T) {
+	tests := []struct {
+		name       string
+		url        string
+		body       string
+		isAdmin    bool
+		wantStatus int
+	}{
+		{"valid policy", "/podcasts/1/redaction-policy", `{"pii":true,"profanity":true,"llm":true,"terms":["Jane Roe"]}`, true, http.StatusOK},
+		{"not an admin", "/podcasts/1/redaction-policy", `{"pii":true}`, false, http.StatusForbidden},
+		{"llm without categories", "/podcasts/1/redaction-policy", `{"llm":true}`, true, http.StatusBadRequest},
+		{"invalid body", "/podcasts/1/redaction-policy", `{`, true, http.StatusBadRequest},
+		{"invalid podcast ID", "/podcasts/abc/redaction-policy", `{}`, true, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := MockPodcastRepo{isAdminFunc: func(userID int) (bool, error) { return tt.isAdmin, nil }}
+			handler := NewPodcastHandler(NewPodcastService(mockRepo, &MockAPIClient{}))
+
+			req := httptest.NewRequest(http.MethodPut, tt.url, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+
+			handler.HandleRequest(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
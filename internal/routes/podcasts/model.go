@@ -4,18 +4,23 @@ import (
 	"time"
 
 	"cribeapp.com/cribe-server/internal/clients/podcast"
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/redaction"
 )
 
 type Podcast struct {
-	ID          int       `json:"id"`
-	AuthorName  string    `json:"author_name"`
-	Name        string    `json:"name"`
-	ImageURL    string    `json:"image_url"`
-	Description string    `json:"description"`
-	ExternalID  string    `json:"external_id"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
-	Episodes    []Episode `json:"episodes,omitempty"`
+	ID                   int                       `json:"id"`
+	AuthorName           string                    `json:"author_name"`
+	Name                 string                    `json:"name"`
+	ImageURL             string                    `json:"image_url"`
+	Description          string                    `json:"description"`
+	ExternalID           string                    `json:"external_id"`
+	TranscriptionOptions transcription.Preferences `json:"transcription_options"`
+	RedactionPolicy      redaction.Policy          `json:"redaction_policy"`
+	CreatedAt            time.Time                 `json:"created_at"`
+	UpdatedAt            time.Time                 `json:"updated_at"`
+	Episodes             []Episode                 `json:"episodes,omitempty"`
 }
 
 type Episode struct {
@@ -47,3 +52,33 @@ type SyncResult struct {
 	New         int    `json:"new"`
 	Message     string `json:"message"`
 }
+
+// UpdateTranscriptionOptionsRequest sets the transcription preferences used for a podcast's episodes
+type UpdateTranscriptionOptionsRequest struct {
+	transcription.Preferences
+}
+
+func (dto UpdateTranscriptionOptionsRequest) Validate() *errors.ErrorResponse {
+	if err := dto.Preferences.Validate(); err != nil {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// UpdateRedactionPolicyRequest sets what is redacted from a podcast's transcripts
+type UpdateRedactionPolicyRequest struct {
+	redaction.Policy
+}
+
+func (dto UpdateRedactionPolicyRequest) Validate() *errors.ErrorResponse {
+	if err := dto.Policy.Validate(); err != nil {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
@@ -2,13 +2,17 @@ package podcasts
 
 import (
 	"cribeapp.com/cribe-server/internal/clients/podcast"
+	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/redaction"
+	"cribeapp.com/cribe-server/internal/routes/users"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
 type PodcastRepository struct {
 	*utils.Repository[Podcast]
 	episodeExecutor utils.QueryExecutor[Episode]
+	roles           *users.RoleRepository
 	logger          *logger.ContextualLogger
 }
 
@@ -21,6 +25,13 @@ func WithEpisodeExecutor(executor utils.QueryExecutor[Episode]) PodcastRepositor
 	}
 }
 
+// WithRoleRepository creates an option to set a custom repository for user role lookups
+func WithRoleRepository(roles *users.RoleRepository) PodcastRepositoryOption {
+	return func(r *PodcastRepository) {
+		r.roles = roles
+	}
+}
+
 func defaultEpisodeExecutor() utils.QueryExecutor[Episode] {
 	episodeRepo := utils.NewRepository[Episode]()
 	return episodeRepo.Executor
@@ -32,6 +43,7 @@ func NewPodcastRepository(options ...utils.Option[Podcast]) *PodcastRepository {
 	pr := &PodcastRepository{
 		Repository:      podcastRepo,
 		episodeExecutor: defaultEpisodeExecutor(),
+		roles:           users.NewRoleRepository(),
 		logger:          logger.NewRepositoryLogger("PodcastRepository"),
 	}
 
@@ -157,6 +169,67 @@ func (r *PodcastRepository) UpsertPodcast(podcast ExternalPodcast) (Podcast, err
 	return result, nil
 }
 
+func (r *PodcastRepository) UpdateTranscriptionOptions(id int, options transcription.Preferences) (Podcast, error) {
+	r.logger.Debug("Updating podcast transcription options", map[string]any{
+		"id": id,
+	})
+
+	query := `
+		UPDATE podcasts
+		SET transcription_options = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`
+
+	result, err := r.Executor.QueryItem(query, id, options)
+	if err != nil {
+		r.logger.Error("Failed to update podcast transcription options", map[string]any{
+			"id":    id,
+			"error": err.Error(),
+		})
+		return result, err
+	}
+
+	r.logger.Info("Podcast transcription options updated", map[string]any{
+		"id": id,
+	})
+
+	return result, nil
+}
+
+func (r *PodcastRepository) UpdateRedactionPolicy(id int, policy redaction.Policy) (Podcast, error) {
+	r.logger.Debug("Updating podcast redaction policy", map[string]any{
+		"id": id,
+	})
+
+	query := `
+		UPDATE podcasts
+		SET redaction_policy = $2, updated_at = NOW()
+		WHERE id = $1
+		RETURNING *
+	`
+
+	result, err := r.Executor.QueryItem(query, id, policy)
+	if err != nil {
+		r.logger.Error("Failed to update podcast redaction policy", map[string]any{
+			"id":    id,
+			"error": err.Error(),
+		})
+		return result, err
+	}
+
+	r.logger.Info("Podcast redaction policy updated", map[string]any{
+		"id": id,
+	})
+
+	return result, nil
+}
+
+// IsAdmin reports whether the user may change podcast settings reserved to admins
+func (r *PodcastRepository) IsAdmin(userID int) (bool, error) {
+	return r.roles.IsAdmin(userID)
+}
+
 func (r *PodcastRepository) GetEpisodesByPodcastID(podcastID int) ([]Episode, error) {
 	r.logger.Debug("Fetching episodes by podcast ID", map[string]any{
 		"podcastID": podcastID,
@@ -4,7 +4,9 @@ import (
 	"strconv"
 
 	"cribeapp.com/cribe-server/internal/clients/podcast"
+	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/redaction"
 )
 
 type PodcastRepo interface {
@@ -14,6 +16,9 @@ type PodcastRepo interface {
 	UpsertPodcast(podcast ExternalPodcast) (Podcast, error)
 	GetEpisodesByPodcastID(podcastID int) ([]Episode, error)
 	UpsertEpisode(episode podcast.PodcastEpisode, podcastID int) (Episode, error)
+	UpdateTranscriptionOptions(id int, options transcription.Preferences) (Podcast, error)
+	UpdateRedactionPolicy(id int, policy redaction.Policy) (Podcast, error)
+	IsAdmin(userID int) (bool, error)
 }
 
 type PodcastAPIClientInterface interface {
@@ -233,3 +238,87 @@ func (s *PodcastService) SyncPodcastEpisodes(podcastID string) (SyncResult, *err
 		Message:     "Episodes synced successfully",
 	}, nil
 }
+
+// UpdateTranscriptionOptions stores the transcription preferences applied to the podcast's episodes.
+// Only admins may change them.
+func (s *PodcastService) UpdateTranscriptionOptions(podcastID string, userID int, options transcription.Preferences) (*Podcast, *errors.ErrorResponse) {
+	id, err := strconv.Atoi(podcastID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Invalid podcast ID",
+		}
+	}
+
+	isAdmin, err := s.repo.IsAdmin(userID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to check user role",
+		}
+	}
+	if !isAdmin {
+		return nil, &errors.ErrorResponse{
+			Message: errors.Unauthorized,
+			Details: "Only admins can change the transcription options",
+		}
+	}
+
+	podcast, err := s.repo.UpdateTranscriptionOptions(id, options)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, &errors.ErrorResponse{
+				Message: errors.DatabaseNotFound,
+				Details: "Podcast not found",
+			}
+		}
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to update transcription options",
+		}
+	}
+
+	return &podcast, nil
+}
+
+// UpdateRedactionPolicy stores the redaction policy applied to the podcast's new transcripts.
+// Only admins may change it.
+func (s *PodcastService) UpdateRedactionPolicy(podcastID string, userID int, policy redaction.Policy) (*Podcast, *errors.ErrorResponse) {
+	id, err := strconv.Atoi(podcastID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Invalid podcast ID",
+		}
+	}
+
+	isAdmin, err := s.repo.IsAdmin(userID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to check user role",
+		}
+	}
+	if !isAdmin {
+		return nil, &errors.ErrorResponse{
+			Message: errors.Unauthorized,
+			Details: "Only admins can change the redaction policy",
+		}
+	}
+
+	podcast, err := s.repo.UpdateRedactionPolicy(id, policy)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return nil, &errors.ErrorResponse{
+				Message: errors.DatabaseNotFound,
+				Details: "Podcast not found",
+			}
+		}
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to update redaction policy",
+		}
+	}
+
+	return &podcast, nil
+}
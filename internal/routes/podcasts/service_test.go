@@ -5,17 +5,22 @@ import (
 	"testing"
 
 	"cribeapp.com/cribe-server/internal/clients/podcast"
+	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/redaction"
 )
 
 // Mock Repository that satisfies the methods needed by PodcastService
 type MockPodcastRepo struct {
-	getPodcastsFunc            func() ([]Podcast, error)
-	getPodcastByIDFunc         func(id int) (Podcast, error)
-	getPodcastByExternalIDFunc func(externalID string) (Podcast, error)
-	upsertPodcastFunc          func(podcast ExternalPodcast) (Podcast, error)
-	getEpisodesByPodcastIDFunc func(podcastID int) ([]Episode, error)
-	upsertEpisodeFunc          func(episode PodcastEpisode, podcastID int) (Episode, error)
+	getPodcastsFunc                func() ([]Podcast, error)
+	getPodcastByIDFunc             func(id int) (Podcast, error)
+	getPodcastByExternalIDFunc     func(externalID string) (Podcast, error)
+	upsertPodcastFunc              func(podcast ExternalPodcast) (Podcast, error)
+	getEpisodesByPodcastIDFunc     func(podcastID int) ([]Episode, error)
+	upsertEpisodeFunc              func(episode PodcastEpisode, podcastID int) (Episode, error)
+	updateTranscriptionOptionsFunc func(id int, options transcription.Preferences) (Podcast, error)
+	updateRedactionPolicyFunc      func(id int, policy redaction.Policy) (Podcast, error)
+	isAdminFunc                    func(userID int) (bool, error)
 }
 
 func (m MockPodcastRepo) GetPodcasts() ([]Podcast, error) {
@@ -60,6 +65,27 @@ func (m MockPodcastRepo) UpsertEpisode(episode PodcastEpisode, podcastID int) (E
 	return Episode{}, nil
 }
 
+func (m MockPodcastRepo) UpdateTranscriptionOptions(id int, options transcription.Preferences) (Podcast, error) {
+	if m.updateTranscriptionOptionsFunc != nil {
+		return m.updateTranscriptionOptionsFunc(id, options)
+	}
+	return Podcast{ID: id, TranscriptionOptions: options}, nil
+}
+
+func (m MockPodcastRepo) UpdateRedactionPolicy(id int, policy redaction.Policy) (Podcast, error) {
+	if m.updateRedactionPolicyFunc != nil {
+		return m.updateRedactionPolicyFunc(id, policy)
+	}
+	return Podcast{ID: id, RedactionPolicy: policy}, nil
+}
+
+func (m MockPodcastRepo) IsAdmin(userID int) (bool, error) {
+	if m.isAdminFunc != nil {
+		return m.isAdminFunc(userID)
+	}
+	return true, nil
+}
+
 type MockAPIClient struct {
 	getTopPodcastsFunc func() ([]podcast.ExternalPodcastSeries, error)
 	getPodcastByIDFunc func(podcastID string) (*podcast.PodcastWithEpisodes, error)
@@ -380,3 +406,101 @@ func TestServiceGetPodcasts_AutoSyncOnEmpty(t *testing.T) {
 		t.Errorf("GetPodcasts auto-sync failed: err=%v, count=%d", err, len(result))
 	}
 }
+
+func TestServiceUpdateTranscriptionOptions(t *testing.T) {
+	t.Run("stores options", func(t *testing.T) {
+		service := NewPodcastService(MockPodcastRepo{}, &MockAPIClient{})
+
+		result, err := service.UpdateTranscriptionOptions("3", 1, transcription.Preferences{Language: "pt"})
+
+		if err != nil || result.ID != 3 || result.TranscriptionOptions.Language != "pt" {
+			t.Errorf("UpdateTranscriptionOptions failed: err=%v, result=%+v", err, result)
+		}
+	})
+
+	t.Run("rejects users who are not admins", func(t *testing.T) {
+		mockRepo := MockPodcastRepo{
+			isAdminFunc: func(userID int) (bool, error) { return false, nil },
+			updateTranscriptionOptionsFunc: func(id int, options transcription.Preferences) (Podcast, error) {
+				t.Error("Expected the options not to be stored")
+				return Podcast{}, nil
+			},
+		}
+		service := NewPodcastService(mockRepo, &MockAPIClient{})
+
+		_, err := service.UpdateTranscriptionOptions("3", 2, transcription.Preferences{Language: "pt"})
+
+		if err == nil || err.Message != errors.Unauthorized {
+			t.Errorf("Expected Unauthorized, got %v", err)
+		}
+	})
+
+	t.Run("maps missing podcast to not found", func(t *testing.T) {
+		mockRepo := MockPodcastRepo{
+			updateTranscriptionOptionsFunc: func(id int, options transcription.Preferences) (Podcast, error) {
+				return Podcast{}, fmt.Errorf("no rows in result set")
+			},
+		}
+		service := NewPodcastService(mockRepo, &MockAPIClient{})
+
+		_, err := service.UpdateTranscriptionOptions("3", 1, transcription.Preferences{})
+
+		if err == nil || err.Message != errors.DatabaseNotFound {
+			t.Errorf("Expected DatabaseNotFound, got %v", err)
+		}
+	})
+
+	t.Run("rejects invalid ID", func(t *testing.T) {
+		service := NewPodcastService(MockPodcastRepo{}, &MockAPIClient{})
+
+		_, err := service.UpdateTranscriptionOptions("abc", 1, transcription.Preferences{})
+
+		if err == nil || err.Message != errors.ValidationError {
+			t.Errorf("Expected ValidationError, got %v", err)
+		}
+	})
+}
+
+func TestServiceUpdateRedactionPolicy(t *testing.T) {
+	t.Run("stores the policy for admins", func(t *testing.T) {
+		service := NewPodcastService(MockPodcastRepo{}, &MockAPIClient{})
+
+		result, err := service.UpdateRedactionPolicy("3", 1, redaction.Policy{PII: true, Terms: []string{"Jane"}})
+
+		if err != nil || result.ID != 3 || !result.RedactionPolicy.PII || result.RedactionPolicy.Terms[0] != "Jane" {
+			t.Errorf("UpdateRedactionPolicy failed: err=%v, result=%+v", err, result)
+		}
+	})
+
+	t.Run("rejects users who are not admins", func(t *testing.T) {
+		mockRepo := MockPodcastRepo{
+			isAdminFunc: func(userID int) (bool, error) { return false, nil },
+			updateRedactionPolicyFunc: func(id int, policy redaction.Policy) (Podcast, error) {
+				t.Error("Expected the policy not to be stored")
+				return Podcast{}, nil
+			},
+		}
+		service := NewPodcastService(mockRepo, &MockAPIClient{})
+
+		_, err := service.UpdateRedactionPolicy("3", 2, redaction.Policy{PII: true})
+
+		if err == nil || err.Message != errors.Unauthorized {
+			t.Errorf("Expected Unauthorized, got %v", err)
+		}
+	})
+
+	t.Run("maps missing podcast to not found", func(t *testing.T) {
+		mockRepo := MockPodcastRepo{
+			updateRedactionPolicyFunc: func(id int, policy redaction.Policy) (Podcast, error) {
+				return Podcast{}, fmt.Errorf("no rows in result set")
+			},
+		}
+		service := NewPodcastService(mockRepo, &MockAPIClient{})
+
+		_, err := service.UpdateRedactionPolicy("3", 1, redaction.Policy{})
+
+		if err == nil || err.Message != errors.DatabaseNotFound {
+			t.Errorf("Expected DatabaseNotFound, got %v", err)
+		}
+	})
+}
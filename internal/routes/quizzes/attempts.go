@@ -0,0 +1,128 @@
+package quizzes
+
+import (
+	"math"
+	"math/rand/v2"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// RetakeQuiz starts a new attempt of a user on the question bank matching the quiz
+// configuration, with the questions and options in a fresh order. An attempt still in
+// progress on the bank is abandoned.
+func (s *QuizService) RetakeQuiz(userID int, episodeID int, override *QuizConfigRequest) (QuizSessionDetail, *errors.ErrorResponse) {
+	s.logger.Info("Retaking quiz", map[string]any{
+		"user_id":    userID,
+		"episode_id": episodeID,
+	})
+
+	config, errResp := s.resolveQuizConfig(userID, override)
+	if errResp != nil {
+		return QuizSessionDetail{}, errResp
+	}
+
+	bank, err := s.repo.GetOrCreateQuestionBank(episodeID, config)
+	if err != nil {
+		return QuizSessionDetail{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch question bank",
+		}
+	}
+
+	previous, err := s.repo.GetLatestSessionByUserAndBank(userID, bank.ID)
+	if err == nil && previous.Status == InProgress {
+		now := time.Now()
+		previous.Status = Abandoned
+		previous.CompletedAt = &now
+		if err := s.repo.UpdateSession(previous); err != nil {
+			return QuizSessionDetail{}, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to abandon the attempt in progress",
+			}
+		}
+	}
+
+	seed := rand.Int64()
+	return s.startSession(userID, bank, &seed)
+}
+
+// GetEpisodeAttempts lists the attempts of a user at the quiz of an episode with the best
+// and latest completed scores
+func (s *QuizService) GetEpisodeAttempts(userID, episodeID int) (EpisodeAttempts, *errors.ErrorResponse) {
+	sessions, err := s.repo.GetSessionsByUserAndEpisode(userID, episodeID)
+	if err != nil {
+		return EpisodeAttempts{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch attempts",
+		}
+	}
+
+	result := EpisodeAttempts{EpisodeID: episodeID, Attempts: make([]QuizAttempt, 0, len(sessions))}
+	for _, session := range sessions {
+		result.Attempts = append(result.Attempts, QuizAttempt{
+			SessionID:         session.ID,
+			Attempt:           session.Attempt,
+			BankID:            session.BankID,
+			Status:            session.Status,
+			TotalQuestions:    session.TotalQuestions,
+			AnsweredQuestions: session.AnsweredQuestions,
+			CorrectAnswers:    session.CorrectAnswers,
+			Score:             attemptScore(session),
+			StartedAt:         session.StartedAt,
+			CompletedAt:       session.CompletedAt,
+		})
+	}
+
+	// Attempts are newest first, so the first completed one is the latest and ties for the
+	// best score go to the most recent attempt
+	for i := range result.Attempts {
+		attempt := &result.Attempts[i]
+		if attempt.Status != Completed {
+			continue
+		}
+		if result.Latest == nil {
+			result.Latest = attempt
+		}
+		if result.Best == nil || attempt.Score > result.Best.Score {
+			result.Best = attempt
+		}
+	}
+
+	return result, nil
+}
+
+// attemptScore is the score of a session as a percentage of its questions, rounded to one
+// decimal
+func attemptScore(session UserQuizSession) float64 {
+	if session.TotalQuestions == 0 {
+		return 0
+	}
+	return math.Round(session.Score*1000/float64(session.TotalQuestions)) / 10
+}
+
+// orderQuestions returns the questions of a session in its order: the bank order, or the
+// shuffle given by the seed of a retake. Multiple choice options are shuffled as well, while
+// true/false options keep their order.
+func orderQuestions(questions []Question, seed *int64) []Question {
+	if seed == nil {
+		return questions
+	}
+
+	rng := rand.New(rand.NewPCG(uint64(*seed), 0))
+	ordered := make([]Question, len(questions))
+	copy(ordered, questions)
+	rng.Shuffle(len(ordered), func(i, j int) { ordered[i], ordered[j] = ordered[j], ordered[i] })
+
+	for i := range ordered {
+		if ordered[i].Type != MultipleChoice {
+			continue
+		}
+		options := make([]QuestionOption, len(ordered[i].Options))
+		copy(options, ordered[i].Options)
+		rng.Shuffle(len(options), func(a, b int) { options[a], options[b] = options[b], options[a] })
+		ordered[i].Options = options
+	}
+
+	return ordered
+}
@@ -0,0 +1,186 @@
+package quizzes
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cribeapp.com/cribe-server/internal/middlewares"
+)
+
+func TestOrderQuestions(t *testing.T) {
+	questions := make([]Question, 8)
+	for i := range questions {
+		questions[i] = Question{ID: i + 1, Type: MultipleChoice}
+		for j := range 4 {
+			questions[i].Options = append(questions[i].Options, QuestionOption{ID: (i+1)*10 + j, OptionText: "option"})
+		}
+	}
+	questions[0].Type = TrueFalse
+
+	t.Run("keeps the bank order without a seed", func(t *testing.T) {
+		ordered := orderQuestions(questions, nil)
+
+		for i := range ordered {
+			if ordered[i].ID != questions[i].ID {
+				t.Fatalf("expected the bank order, got %+v", ordered)
+			}
+		}
+	})
+
+	t.Run("shuffles the same way for the same seed", func(t *testing.T) {
+		seed := int64(42)
+
+		first := orderQuestions(questions, &seed)
+		second := orderQuestions(questions, &seed)
+
+		for i := range first {
+			if first[i].ID != second[i].ID || first[i].Options[0].ID != second[i].Options[0].ID {
+				t.Fatalf("expected the same order for the same seed, got %+v and %+v", first, second)
+			}
+		}
+	})
+
+	t.Run("permutes questions and multiple choice options without touching the bank", func(t *testing.T) {
+		seed := int64(7)
+
+		ordered := orderQuestions(questions, &seed)
+
+		seen := map[int]bool{}
+		moved := false
+		for i, question := range ordered {
+			seen[question.ID] = true
+			if question.ID != questions[i].ID {
+				moved = true
+			}
+			if len(question.Options) != 4 {
+				t.Errorf("expected 4 options for question %d, got %d", question.ID, len(question.Options))
+			}
+			if question.Type == TrueFalse && question.Options[0].ID != question.ID*10 {
+				t.Errorf("expected true/false options to keep their order, got %+v", question.Options)
+			}
+		}
+		if len(seen) != len(questions) || !moved {
+			t.Errorf("expected a permutation of the questions, got %+v", ordered)
+		}
+		for i, question := range questions {
+			if question.ID != i+1 || question.Options[0].ID != (i+1)*10 {
+				t.Fatalf("expected the bank questions to be left untouched, got %+v", questions)
+			}
+		}
+	})
+}
+
+func TestQuizService_RetakeQuiz(t *testing.T) {
+	t.Run("starts a new shuffled attempt and abandons the one in progress", func(t *testing.T) {
+		svc := setupRegenerationService()
+		first, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+
+		retake, errResp := svc.RetakeQuiz(2, 1, nil)
+
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		if retake.Session.ID == first.Session.ID || retake.Session.Attempt != first.Session.Attempt+1 {
+			t.Errorf("expected attempt %d in a new session, got %+v", first.Session.Attempt+1, retake.Session)
+		}
+		if retake.Session.ShuffleSeed == nil || *retake.Session.BankID != *first.Session.BankID || len(retake.Questions) != len(first.Questions) {
+			t.Errorf("expected a shuffled attempt on the same bank, got %+v", retake)
+		}
+
+		previous, _ := svc.repo.GetSessionByID(first.Session.ID)
+		if previous.Status != Abandoned || previous.CompletedAt == nil {
+			t.Errorf("expected the previous attempt to be abandoned, got %+v", previous)
+		}
+
+		resumed, _ := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+		if resumed.Session.ID != retake.Session.ID {
+			t.Fatalf("expected the retake to be resumed, got %+v", resumed.Session)
+		}
+		for i := range resumed.Questions {
+			if resumed.Questions[i].ID != retake.Questions[i].ID {
+				t.Fatalf("expected the retake to keep its order, got %+v", resumed.Questions)
+			}
+		}
+	})
+}
+
+func TestQuizService_GetEpisodeAttempts(t *testing.T) {
+	svc := setupRegenerationService()
+	var sessionIDs []int
+	for _, score := range []float64{2, 2.5, 1} {
+		detail, errResp := svc.RetakeQuiz(2, 1, nil)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		session := detail.Session
+		session.Score = score
+		session.AnsweredQuestions = session.TotalQuestions
+		_ = svc.repo.UpdateSession(session)
+		if _, errResp := svc.UpdateSessionStatus(session.ID, 2, Completed); errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		sessionIDs = append(sessionIDs, session.ID)
+	}
+	inProgress, _ := svc.RetakeQuiz(2, 1, nil)
+
+	attempts, errResp := svc.GetEpisodeAttempts(2, 1)
+
+	if errResp != nil {
+		t.Fatalf("unexpected error: %v", errResp.Details)
+	}
+	if len(attempts.Attempts) != 4 || attempts.Attempts[0].SessionID != inProgress.Session.ID || attempts.Attempts[0].Attempt != 4 {
+		t.Fatalf("expected 4 attempts newest first, got %+v", attempts.Attempts)
+	}
+	if attempts.Latest == nil || attempts.Latest.SessionID != sessionIDs[2] || attempts.Latest.Score != 33.3 {
+		t.Errorf("expected the third attempt as latest with 33.3, got %+v", attempts.Latest)
+	}
+	if attempts.Best == nil || attempts.Best.SessionID != sessionIDs[1] || attempts.Best.Score != 83.3 {
+		t.Errorf("expected the second attempt as best with 83.3, got %+v", attempts.Best)
+	}
+}
+
+func TestQuizHandler_Attempts(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"retake", http.MethodPost, "/quizzes", `{"episode_id": 1, "retake": true}`, http.StatusOK},
+		{"list attempts", http.MethodGet, "/quizzes/episodes/1/attempts", "", http.StatusOK},
+		{"wrong method", http.MethodPost, "/quizzes/episodes/1/attempts", "", http.StatusMethodNotAllowed},
+		{"invalid episode ID", http.MethodGet, "/quizzes/episodes/abc/attempts", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := setupRegenerationService()
+			if _, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil); errResp != nil {
+				t.Fatalf("unexpected error: %v", errResp.Details)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			r = r.WithContext(context.WithValue(r.Context(), middlewares.UserIDContextKey, 2))
+			NewQuizHandler(svc).HandleRequest(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if tt.name == "retake" {
+				var detail QuizSessionDetail
+				if err := json.Unmarshal(w.Body.Bytes(), &detail); err != nil || detail.Session.Attempt != 2 {
+					t.Errorf("expected attempt 2, got %s", w.Body.String())
+				}
+			}
+		})
+	}
+}
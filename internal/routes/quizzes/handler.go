@@ -40,12 +40,24 @@ func (h *QuizHandler) HandleRequest(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Handle /quizzes/preferences
+	if path == "preferences" {
+		h.handlePreferences(w, r, userID)
+		return
+	}
+
 	parts := strings.Split(path, "/")
 
 	h.logger.Info("Handling quiz session path", map[string]any{
 		"parts": parts,
 	})
 
+	// Handle /quizzes/episodes/:episode_id/...
+	if parts[0] == "episodes" {
+		h.handleEpisodeRoutes(w, r, parts[1:], userID)
+		return
+	}
+
 	sessionID, err := strconv.Atoi(parts[0])
 	if err != nil {
 		// Invalid session ID format
@@ -94,7 +106,7 @@ func (h *QuizHandler) handleGetSessionsWithDetails(w http.ResponseWriter, r *htt
 	utils.EncodeResponse(w, http.StatusOK, sessions)
 }
 
-// POST /quizzes - Get existing session or create a new for user
+// POST /quizzes - Get existing session or create a new for user, or start a new attempt when retaking
 func (h *QuizHandler) handleGetOrCreateSessionWithDetails(w http.ResponseWriter, r *http.Request, userID int) {
 	req, errResp := utils.DecodeBody[GetOrCreateSessionRequest](r)
 	if errResp != nil {
@@ -107,15 +119,126 @@ func (h *QuizHandler) handleGetOrCreateSessionWithDetails(w http.ResponseWriter,
 		return
 	}
 
-	session, errResp := h.service.GetOrCreateSessionWithDetails(userID, req.EpisodeID)
+	var session QuizSessionDetail
+	if req.Retake {
+		session, errResp = h.service.RetakeQuiz(userID, req.EpisodeID, req.Config)
+	} else {
+		session, errResp = h.service.GetOrCreateSessionWithDetails(userID, req.EpisodeID, req.Config)
+	}
 	if errResp != nil {
-		utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+		switch errResp.Message {
+		case errors.ValidationError:
+			utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		case errors.DatabaseNotFound:
+			utils.EncodeResponse(w, http.StatusNotFound, errResp)
+		default:
+			utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+		}
 		return
 	}
 
 	utils.EncodeResponse(w, http.StatusOK, session)
 }
 
+// handleEpisodeRoutes routes the paths under /quizzes/episodes/:episode_id
+func (h *QuizHandler) handleEpisodeRoutes(w http.ResponseWriter, r *http.Request, parts []string, userID int) {
+	if len(parts) < 2 {
+		utils.NotFound(w, r)
+		return
+	}
+
+	episodeID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		utils.NotFound(w, r)
+		return
+	}
+
+	switch {
+	case len(parts) == 2 && parts[1] == "attempts":
+		h.handleEpisodeAttempts(w, r, episodeID, userID)
+	case len(parts) == 3 && parts[1] == "questions" && parts[2] == "regenerate":
+		h.handleRegenerateQuestions(w, r, episodeID, userID)
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+// GET /quizzes/episodes/:episode_id/attempts - List the user's attempts at the quiz of an episode
+func (h *QuizHandler) handleEpisodeAttempts(w http.ResponseWriter, r *http.Request, episodeID, userID int) {
+	if r.Method != http.MethodGet {
+		utils.NotAllowed(w)
+		return
+	}
+
+	attempts, errResp := h.service.GetEpisodeAttempts(userID, episodeID)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, attempts)
+}
+
+// POST /quizzes/episodes/:episode_id/questions/regenerate - Regenerate the questions of an episode (admins)
+func (h *QuizHandler) handleRegenerateQuestions(w http.ResponseWriter, r *http.Request, episodeID, userID int) {
+	if r.Method != http.MethodPost {
+		utils.NotAllowed(w)
+		return
+	}
+
+	banks, errResp := h.service.RegenerateQuestions(episodeID, userID)
+	if errResp != nil {
+		switch errResp.Message {
+		case errors.Unauthorized:
+			utils.EncodeResponse(w, http.StatusForbidden, errResp)
+		case errors.DatabaseNotFound:
+			utils.EncodeResponse(w, http.StatusNotFound, errResp)
+		case errors.DatabaseConflict:
+			utils.EncodeResponse(w, http.StatusConflict, errResp)
+		case errors.ValidationError:
+			utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		default:
+			utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+		}
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, banks)
+}
+
+// GET /quizzes/preferences - Get the user's quiz configuration
+// PUT /quizzes/preferences - Replace the user's quiz configuration
+func (h *QuizHandler) handlePreferences(w http.ResponseWriter, r *http.Request, userID int) {
+	switch r.Method {
+	case http.MethodGet:
+		preferences, errResp := h.service.GetQuizPreferences(userID)
+		if errResp != nil {
+			utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+			return
+		}
+		utils.EncodeResponse(w, http.StatusOK, preferences)
+	case http.MethodPut:
+		req, errResp := utils.DecodeBody[QuizConfig](r)
+		if errResp != nil {
+			utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+			return
+		}
+
+		preferences, errResp := h.service.UpdateQuizPreferences(userID, req)
+		if errResp != nil {
+			if errResp.Message == errors.ValidationError {
+				utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+				return
+			}
+			utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
+			return
+		}
+		utils.EncodeResponse(w, http.StatusOK, preferences)
+	default:
+		utils.NotAllowed(w)
+	}
+}
+
 // GET /quizzes/:session_id - Get session by ID
 func (h *QuizHandler) handleSessionWithDetailsByID(w http.ResponseWriter, r *http.Request, sessionID int) {
 	if r.Method != http.MethodGet {
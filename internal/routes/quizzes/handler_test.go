@@ -6,6 +6,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"cribeapp.com/cribe-server/internal/middlewares"
@@ -424,3 +425,50 @@ func TestQuizHandler_Routing(t *testing.T) {
 		}
 	})
 }
+
+func TestQuizHandler_Preferences(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"defaults", http.MethodGet, "", http.StatusOK, `"difficulty":"medium"`},
+		{"update", http.MethodPut, `{"multiple_choice": 3, "true_false": 2, "open_ended": 0, "difficulty": "hard"}`, http.StatusOK, `"multiple_choice":3`},
+		{"update without questions", http.MethodPut, `{"difficulty": "easy"}`, http.StatusBadRequest, "between 1 and"},
+		{"update with unknown difficulty", http.MethodPut, `{"multiple_choice": 1, "difficulty": "extreme"}`, http.StatusBadRequest, ""},
+		{"wrong method", http.MethodDelete, "", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			testHandler := NewMockQuizHandlerReady()
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, "/quizzes/preferences", bytes.NewBufferString(tt.body))
+			r = r.WithContext(context.WithValue(r.Context(), middlewares.UserIDContextKey, 1))
+
+			testHandler.HandleRequest(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("rejects an invalid quiz configuration", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest(http.MethodPost, "/quizzes", bytes.NewBufferString(`{"episode_id": 1, "config": {"difficulty": "extreme"}}`))
+		r = r.WithContext(context.WithValue(r.Context(), middlewares.UserIDContextKey, 1))
+
+		NewMockQuizHandlerReady().HandleRequest(w, r)
+
+		if w.Code != http.StatusBadRequest {
+			t.Errorf("Expected status %d, got %d: %s", http.StatusBadRequest, w.Code, w.Body.String())
+		}
+	})
+}
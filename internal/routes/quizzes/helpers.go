@@ -3,13 +3,15 @@ package quizzes
 import (
 	"database/sql"
 	"fmt"
+	"strconv"
 	"strings"
 
 	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/routes/transcripts"
 )
 
-// getTranscriptText retrieves and compiles the transcript text for an episode
-func (s *QuizService) getTranscriptText(episodeID int) (string, *errors.ErrorResponse) {
+// getTranscriptChunks retrieves the chunks of the complete transcript of an episode
+func (s *QuizService) getTranscriptChunks(episodeID int) ([]transcripts.TranscriptChunk, *errors.ErrorResponse) {
 	// Get transcript
 	transcript, err := s.transcriptRepo.GetTranscriptByEpisodeID(episodeID)
 	if err != nil {
@@ -17,7 +19,7 @@ func (s *QuizService) getTranscriptText(episodeID int) (string, *errors.ErrorRes
 			"episode_id": episodeID,
 			"error":      err.Error(),
 		})
-		return "", &errors.ErrorResponse{
+		return nil, &errors.ErrorResponse{
 			Message: errors.DatabaseNotFound,
 			Details: "Failed to fetch transcript for this episode",
 		}
@@ -25,7 +27,7 @@ func (s *QuizService) getTranscriptText(episodeID int) (string, *errors.ErrorRes
 
 	// Check transcript status
 	if transcript.Status != "complete" {
-		return "", &errors.ErrorResponse{
+		return nil, &errors.ErrorResponse{
 			Message: errors.ValidationError,
 			Details: "Transcript must be complete before generating questions",
 		}
@@ -38,112 +40,242 @@ func (s *QuizService) getTranscriptText(episodeID int) (string, *errors.ErrorRes
 			"transcript_id": transcript.ID,
 			"error":         err.Error(),
 		})
-		return "", &errors.ErrorResponse{
+		return nil, &errors.ErrorResponse{
 			Message: errors.DatabaseError,
 			Details: "Failed to fetch transcript chunks",
 		}
 	}
 
-	// Compile transcript text
-	var transcriptText strings.Builder
-	for _, chunk := range chunks {
-		transcriptText.WriteString(chunk.Text)
-		transcriptText.WriteString(" ")
+	return chunks, nil
+}
+
+// getTranscriptText renders the transcript of an episode as timestamped lines, so the LLM
+// can tell where each passage is
+func (s *QuizService) getTranscriptText(episodeID int) (string, *errors.ErrorResponse) {
+	chunks, errResp := s.getTranscriptChunks(episodeID)
+	if errResp != nil {
+		return "", errResp
 	}
 
-	return transcriptText.String(), nil
+	return transcripts.RenderTranscript(chunks), nil
 }
 
-// generateQuestions generates quiz questions for an episode using LLM
-func (s *QuizService) generateQuestions(episodeID int) ([]Question, *errors.ErrorResponse) {
-	s.logger.Info("Generating questions for episode", map[string]any{
-		"episode_id": episodeID,
-	})
-
-	transcriptText, errResp := s.getTranscriptText(episodeID)
+// generateQuestions (re)generates the questions of a question bank using LLM and saves them
+func (s *QuizService) generateQuestions(bank QuestionBank) ([]Question, *errors.ErrorResponse) {
+	questions, errResp := s.buildQuestions(bank)
 	if errResp != nil {
 		return nil, errResp
 	}
 
-	// Delete existing questions for this episode (regenerate)
-	if err := s.repo.DeleteQuestionsByEpisodeID(episodeID); err != nil {
-		s.logger.Error("Failed to delete existing questions", map[string]any{
-			"episode_id": episodeID,
-			"error":      err.Error(),
-		})
+	// Replace the existing questions of this bank with the new ones in one transaction
+	savedQuestions, err := s.repo.ReplaceBankQuestions(bank.ID, questions)
+	if err != nil {
 		return nil, &errors.ErrorResponse{
 			Message: errors.DatabaseError,
-			Details: "Failed to delete existing questions before regeneration",
+			Details: "Failed to save generated questions",
 		}
 	}
 
+	s.logger.Info("Questions generated successfully", map[string]any{
+		"bank_id": bank.ID,
+		"count":   len(savedQuestions),
+	})
+
+	return savedQuestions, nil
+}
+
+// buildQuestions generates questions for the configuration of a question bank using LLM,
+// without saving them
+func (s *QuizService) buildQuestions(bank QuestionBank) ([]Question, *errors.ErrorResponse) {
+	s.logger.Info("Generating questions for question bank", map[string]any{
+		"episode_id": bank.EpisodeID,
+		"bank_id":    bank.ID,
+		"config":     bank.QuizConfig,
+	})
+
+	chunks, errResp := s.getTranscriptChunks(bank.EpisodeID)
+	if errResp != nil {
+		return nil, errResp
+	}
+	transcriptText := transcripts.RenderTranscript(chunks)
+
 	// Generate questions using LLM
-	llmQuestions, err := s.generateQuestionsWithLLM(transcriptText)
+	generated, err := s.generateQuestionsWithLLM(transcriptText, bank.QuizConfig)
 	if err != nil {
+		s.logger.Error("Failed to generate questions with LLM", map[string]any{
+			"bank_id": bank.ID,
+			"error":   err.Error(),
+		})
 		return nil, &errors.ErrorResponse{
 			Message: errors.InternalServerError,
-			Details: fmt.Sprintf("Failed to generate questions: %v", err),
+			Details: "Failed to generate questions",
 		}
 	}
 
-	// Save questions to database
-	var savedQuestions []Question
-	for i, llmQ := range llmQuestions {
-		// Validate question type before creating
-		questionType := QuestionType(llmQ.Type)
-		if questionType != MultipleChoice && questionType != TrueFalse && questionType != OpenEnded {
-			s.logger.Error("Invalid question type from LLM, skipping question", map[string]any{
-				"type":          llmQ.Type,
-				"question_text": llmQ.QuestionText,
-			})
-			continue
+	validQuestions := s.repairQuestions(transcriptText, chunks, bank, generated)
+	if len(validQuestions) == 0 {
+		return nil, &errors.ErrorResponse{
+			Message: errors.InternalServerError,
+			Details: "Failed to generate questions",
 		}
+	}
 
+	questions := []Question{}
+	for _, llmQ := range validQuestions {
+		questionType := QuestionType(llmQ.Type)
+		span := llmQ.Span
 		question := Question{
-			EpisodeID:    episodeID,
-			QuestionText: llmQ.QuestionText,
-			Type:         questionType,
-			Position:     i,
+			EpisodeID:           bank.EpisodeID,
+			BankID:              bank.ID,
+			QuestionText:        strings.TrimSpace(llmQ.QuestionText),
+			Type:                questionType,
+			Position:            len(questions),
+			SourceStartPosition: &span.StartPosition,
+			SourceEndPosition:   &span.EndPosition,
+			SourceStartTime:     &span.StartTime,
+			SourceEndTime:       &span.EndTime,
 		}
 
-		savedQuestion, err := s.repo.CreateQuestion(question)
-		if err != nil {
-			s.logger.Error("Failed to save question", map[string]any{
-				"error": err.Error(),
-			})
-			continue
+		if questionType == OpenEnded {
+			referenceAnswer := strings.TrimSpace(llmQ.ReferenceAnswer)
+			question.ReferenceAnswer = &referenceAnswer
+			for _, keyPoint := range llmQ.KeyPoints {
+				question.KeyPoints = append(question.KeyPoints, strings.TrimSpace(keyPoint))
+			}
 		}
 
-		// Save options if applicable
-		var savedOptions []QuestionOption
 		if questionType == MultipleChoice || questionType == TrueFalse {
 			for j, opt := range llmQ.Options {
-				option := QuestionOption{
-					QuestionID: savedQuestion.ID,
-					OptionText: opt.Text,
+				question.Options = append(question.Options, QuestionOption{
+					OptionText: strings.TrimSpace(opt.Text),
 					Position:   j,
 					IsCorrect:  opt.IsCorrect,
-				}
-
-				savedOption, err := s.repo.CreateQuestionOption(option)
-				if err != nil {
-					s.logger.Error("Failed to save option", map[string]any{
-						"error": err.Error(),
-					})
-					continue
-				}
-				savedOptions = append(savedOptions, savedOption)
+				})
 			}
 		}
 
-		savedQuestion.Options = savedOptions
-		savedQuestions = append(savedQuestions, savedQuestion)
+		questions = append(questions, question)
 	}
 
-	s.logger.Info("Questions generated successfully", map[string]any{
-		"episode_id": episodeID,
-		"count":      len(savedQuestions),
+	return questions, nil
+}
+
+// generationResult is the outcome of a question generation shared by concurrent callers
+type generationResult struct {
+	questions []Question
+	errResp   *errors.ErrorResponse
+}
+
+// generateQuestionsOnce generates the questions of a bank without questions, making concurrent
+// callers share one generation. Callers in this process wait on the same call, and the bank's
+// generation lock makes any other caller wait and reuse the questions saved while it waited.
+func (s *QuizService) generateQuestionsOnce(bank QuestionBank) ([]Question, *errors.ErrorResponse) {
+	value, _, shared := s.generations.Do(strconv.Itoa(bank.ID), func() (any, error) {
+		var questions []Question
+		errResp := s.withGenerationLock(bank.ID, func() *errors.ErrorResponse {
+			// Another caller may have generated the questions while we waited for the lock
+			existing, err := s.repo.GetQuestionsByBankID(bank.ID)
+			if err == nil && len(existing) > 0 {
+				s.logger.Info("Reusing questions generated concurrently", map[string]any{
+					"bank_id": bank.ID,
+				})
+				questions = existing
+				return nil
+			}
+
+			var errResp *errors.ErrorResponse
+			questions, errResp = s.generateQuestions(bank)
+			return errResp
+		})
+		return generationResult{questions: questions, errResp: errResp}, nil
 	})
 
-	return savedQuestions, nil
+	if shared {
+		s.logger.Debug("Shared question generation between concurrent callers", map[string]any{
+			"bank_id": bank.ID,
+		})
+	}
+
+	result := value.(generationResult)
+	return result.questions, result.errResp
+}
+
+// withGenerationLock runs fn while holding the generation lock of a question bank, waiting up
+// to GenerationLockTimeout for the caller currently holding it
+func (s *QuizService) withGenerationLock(bankID int, fn func() *errors.ErrorResponse) *errors.ErrorResponse {
+	var errResp *errors.ErrorResponse
+	err := s.repo.LockQuestionBankGeneration(bankID, GenerationLockTimeout, func() error {
+		errResp = fn()
+		return nil
+	})
+	if errResp != nil {
+		return errResp
+	}
+	if err == ErrGenerationLockTimeout {
+		return &errors.ErrorResponse{
+			Message: errors.InternalServerError,
+			Details: "Timed out waiting for question generation",
+		}
+	}
+	if err != nil {
+		return &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to lock question generation",
+		}
+	}
+
+	return nil
+}
+
+// repairQuestions validates the generated questions and asks the LLM to fix the problems
+// found, including a reply that couldn't be parsed, up to MaxQuestionRepairAttempts times. It
+// returns the valid questions of the best attempt, which may be fewer than configured when the
+// problems couldn't be fixed.
+func (s *QuizService) repairQuestions(transcriptText string, chunks []transcripts.TranscriptChunk, bank QuestionBank, generated generatedQuestions) []groundedQuestion {
+	valid, problems := validateGeneratedQuestions(generated, bank.QuizConfig, chunks)
+
+	for attempt := 1; len(problems) > 0 && attempt <= MaxQuestionRepairAttempts; attempt++ {
+		s.logger.Warn("Generated questions are invalid, asking the LLM to repair them", map[string]any{
+			"bank_id":  bank.ID,
+			"attempt":  attempt,
+			"problems": problems,
+		})
+
+		repaired, err := s.repairQuestionsWithLLM(transcriptText, bank.QuizConfig, generated, problems)
+		if err != nil {
+			s.logger.Error("Failed to repair questions with LLM", map[string]any{
+				"bank_id": bank.ID,
+				"error":   err.Error(),
+			})
+			break
+		}
+
+		repairedValid, repairedProblems := validateGeneratedQuestions(repaired, bank.QuizConfig, chunks)
+		if len(repairedValid) >= len(valid) {
+			generated, valid, problems = repaired, repairedValid, repairedProblems
+		}
+	}
+
+	if len(problems) > 0 {
+		s.logger.Warn("Keeping only the valid generated questions", map[string]any{
+			"bank_id":  bank.ID,
+			"valid":    len(valid),
+			"problems": problems,
+		})
+	}
+
+	return valid
+}
+
+// withSourceHint points the feedback to the moment of the episode that answers the question
+func withSourceHint(feedback string, question Question) string {
+	if question.SourceStartTime == nil {
+		return feedback
+	}
+
+	hint := fmt.Sprintf("Hear it at %s.", transcripts.FormatTimestamp(*question.SourceStartTime))
+	if feedback == "" {
+		return hint
+	}
+	return feedback + " " + hint
 }
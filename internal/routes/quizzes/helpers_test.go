@@ -1,11 +1,18 @@
 package quizzes
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"cribeapp.com/cribe-server/internal/clients/llm"
 	"cribeapp.com/cribe-server/internal/routes/transcripts"
+	"cribeapp.com/cribe-server/internal/utils"
+	"github.com/jackc/pgx/v5/pgconn"
 )
 
 func TestQuizService_generateQuestions(t *testing.T) {
@@ -14,17 +21,14 @@ func TestQuizService_generateQuestions(t *testing.T) {
 			return transcripts.Transcript{ID: 1, EpisodeID: id, Status: "complete"}, nil
 		},
 		GetChunksByTranscriptIDFunc: func(id int) ([]transcripts.TranscriptChunk, error) {
-			return []transcripts.TranscriptChunk{
-				{TranscriptID: id, Text: "Speaker 1: Hello world"},
-				{TranscriptID: id, Text: "Speaker 2: Hi there"},
-			}, nil
+			return quizChunks(quizTranscriptText), nil
 		},
 	}
 
 	t.Run("success", func(t *testing.T) {
 		svc := setupQuizService(&MockLLMClient{ChatResponse: makeLLMResponse(makeQuizJSON(3))}, mockTranscript)
 
-		questions, errResp := svc.generateQuestions(1)
+		questions, errResp := svc.generateQuestions(QuestionBank{ID: 1, EpisodeID: 1, QuizConfig: DefaultQuizConfig()})
 		if errResp != nil {
 			t.Fatalf("unexpected error: %v", errResp.Details)
 		}
@@ -42,7 +46,7 @@ func TestQuizService_generateQuestions(t *testing.T) {
 		}
 		svc := setupQuizService(&MockLLMClient{}, failingTranscript)
 
-		_, errResp := svc.generateQuestions(999)
+		_, errResp := svc.generateQuestions(QuestionBank{ID: 1, EpisodeID: 999, QuizConfig: DefaultQuizConfig()})
 		if errResp == nil || !strings.Contains(errResp.Details, "Failed to fetch transcript") {
 			t.Errorf("got error %v, want error containing 'Failed to fetch transcript'", errResp)
 		}
@@ -56,7 +60,7 @@ func TestQuizService_generateQuestions(t *testing.T) {
 		}
 		svc := setupQuizService(&MockLLMClient{}, incompleteTranscript)
 
-		_, errResp := svc.generateQuestions(1)
+		_, errResp := svc.generateQuestions(QuestionBank{ID: 1, EpisodeID: 1, QuizConfig: DefaultQuizConfig()})
 		if errResp == nil || !strings.Contains(errResp.Details, "must be complete") {
 			t.Errorf("got error %v, want error containing 'must be complete'", errResp)
 		}
@@ -65,13 +69,192 @@ func TestQuizService_generateQuestions(t *testing.T) {
 	t.Run("LLM error", func(t *testing.T) {
 		svc := setupQuizService(&MockLLMClient{ChatError: fmt.Errorf("LLM failed")}, mockTranscript)
 
-		_, errResp := svc.generateQuestions(1)
+		_, errResp := svc.generateQuestions(QuestionBank{ID: 1, EpisodeID: 1, QuizConfig: DefaultQuizConfig()})
 		if errResp == nil || !strings.Contains(errResp.Details, "Failed to generate questions") {
 			t.Errorf("got error %v, want error containing 'Failed to generate questions'", errResp)
 		}
 	})
 }
 
+// blockingLLMClient counts chat calls and blocks them until release is closed
+type blockingLLMClient struct {
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (c *blockingLLMClient) Chat(ctx context.Context, req llm.ChatRequest) (llm.ChatCompletionResponse, error) {
+	c.calls.Add(1)
+	<-c.release
+	return makeLLMResponse(makeQuizJSON(3)), nil
+}
+
+func TestQuizService_generateQuestionsOnce(t *testing.T) {
+	mockTranscript := &MockTranscriptRepository{
+		GetTranscriptByEpisodeIDFunc: func(id int) (transcripts.Transcript, error) {
+			return transcripts.Transcript{ID: 1, EpisodeID: id, Status: "complete"}, nil
+		},
+		GetChunksByTranscriptIDFunc: func(id int) ([]transcripts.TranscriptChunk, error) {
+			return quizChunks(quizTranscriptText), nil
+		},
+	}
+	bank := QuestionBank{ID: 1, EpisodeID: 1, QuizConfig: DefaultQuizConfig()}
+
+	t.Run("concurrent callers share one generation", func(t *testing.T) {
+		client := &blockingLLMClient{release: make(chan struct{})}
+		svc := NewQuizService(*NewMockQuizRepository(), mockTranscript, client)
+
+		const callers = 5
+		var wg sync.WaitGroup
+		counts := make([]int, callers)
+		for i := range callers {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				questions, errResp := svc.generateQuestionsOnce(bank)
+				if errResp != nil {
+					t.Errorf("unexpected error: %v", errResp.Details)
+				}
+				counts[i] = len(questions)
+			}()
+		}
+
+		// Let every caller join the generation before the LLM answers
+		time.Sleep(50 * time.Millisecond)
+		close(client.release)
+		wg.Wait()
+
+		if calls := client.calls.Load(); calls != 1 {
+			t.Errorf("got %d LLM calls, want 1", calls)
+		}
+		for i, count := range counts {
+			if count != 3 {
+				t.Errorf("caller %d got %d questions, want 3", i, count)
+			}
+		}
+	})
+
+	t.Run("waits for another caller and reuses its questions", func(t *testing.T) {
+		mockLLM := &MockLLMClient{ChatError: fmt.Errorf("LLM should not be called")}
+		repo := NewMockQuizRepository()
+		var queries []string
+		repo.lockConn = func(fn func(repo *utils.Repository[generationLock]) error) error {
+			return fn(utils.NewRepository(utils.WithQueryExecutor(utils.QueryExecutor[generationLock]{
+				Exec: func(query string, args ...any) error {
+					queries = append(queries, query)
+					if strings.Contains(query, "pg_advisory_xact_lock") {
+						// Another caller holds the lock and saves the questions meanwhile
+						_, _ = repo.CreateQuestion(Question{EpisodeID: 1, BankID: bank.ID, QuestionText: "What is Go?", Type: OpenEnded})
+					}
+					return nil
+				},
+			})))
+		}
+		svc := NewQuizService(*repo, mockTranscript, mockLLM)
+
+		questions, errResp := svc.generateQuestionsOnce(bank)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+
+		if len(questions) != 1 || questions[0].QuestionText != "What is Go?" {
+			t.Errorf("got %+v, want the questions saved by the other caller", questions)
+		}
+		if len(queries) != 2 || !strings.Contains(queries[0], "lock_timeout = 120000") {
+			t.Errorf("got queries %v, want the lock timeout set before taking the lock", queries)
+		}
+	})
+
+	lockErrors := []struct {
+		name    string
+		err     error
+		details string
+	}{
+		{"lock failure", fmt.Errorf("connection lost"), "Failed to lock question generation"},
+		{"lock timeout", &pgconn.PgError{Code: "55P03"}, "Timed out waiting for question generation"},
+	}
+	for _, tt := range lockErrors {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewMockQuizRepository()
+			repo.lockConn = func(fn func(repo *utils.Repository[generationLock]) error) error {
+				return fn(utils.NewRepository(utils.WithQueryExecutor(utils.QueryExecutor[generationLock]{
+					Exec: func(query string, args ...any) error {
+						if strings.Contains(query, "pg_advisory_xact_lock") {
+							return tt.err
+						}
+						return nil
+					},
+				})))
+			}
+			svc := NewQuizService(*repo, mockTranscript, &MockLLMClient{ChatError: fmt.Errorf("LLM should not be called")})
+
+			_, errResp := svc.generateQuestionsOnce(bank)
+			if errResp == nil || errResp.Details != tt.details {
+				t.Errorf("got error %v, want %q", errResp, tt.details)
+			}
+		})
+	}
+}
+
+func TestQuizService_repairQuestions(t *testing.T) {
+	invalid := `{"questions": [{"question_text": "What is Go?", "type": "multiple_choice", "options": [{"text": "A language", "is_correct": true}, {"text": "A tool", "is_correct": true}]}, {"question_text": "Go is fast", "type": "true_false", "options": [{"text": "Yes", "is_correct": true}, {"text": "No", "is_correct": false}]}, {"question_text": "Explain goroutines", "type": "open_ended", "reference_answer": "Goroutines make concurrency cheap in Go.", "key_points": ["Goroutines are lightweight", "Concurrency is cheap"], "source": {"quote": "Goroutines make concurrency cheap", "start": "0:08"}}]}`
+	bank := QuestionBank{ID: 1, EpisodeID: 1, QuizConfig: DefaultQuizConfig()}
+
+	t.Run("re-prompts with the problems until the questions are valid", func(t *testing.T) {
+		mockLLM := &MockLLMClient{
+			Responses:    []llm.ChatCompletionResponse{makeLLMResponse(invalid)},
+			ChatResponse: makeLLMResponse(makeQuizJSON(3)),
+		}
+		svc := setupQuizService(mockLLM, nil)
+		generated, _ := svc.generateQuestionsWithLLM("transcript", bank.QuizConfig)
+
+		valid := svc.repairQuestions("transcript", quizChunks(quizTranscriptText), bank, generated)
+
+		if len(valid) != 3 {
+			t.Errorf("expected 3 repaired questions, got %d", len(valid))
+		}
+		messages := mockLLM.LastRequest.Messages
+		repairPrompt := messages[len(messages)-1].Content
+		if len(messages) != 4 || messages[2].Role != "assistant" || !strings.Contains(repairPrompt, "Question 1: multiple_choice questions need exactly 4 options, got 2") || !strings.Contains(repairPrompt, "Question 2: true_false") {
+			t.Errorf("expected the repair prompt to list the problems, got %+v", messages)
+		}
+	})
+
+	t.Run("keeps the valid questions after the last attempt", func(t *testing.T) {
+		mockLLM := &MockLLMClient{ChatResponse: makeLLMResponse(invalid)}
+		svc := setupQuizService(mockLLM, nil)
+		generated, _ := svc.generateQuestionsWithLLM("transcript", bank.QuizConfig)
+
+		valid := svc.repairQuestions("transcript", quizChunks(quizTranscriptText), bank, generated)
+
+		if len(valid) != 1 || valid[0].Type != "open_ended" {
+			t.Errorf("expected only the open-ended question, got %+v", valid)
+		}
+	})
+
+	t.Run("repairs a response cut off before the JSON ends", func(t *testing.T) {
+		full := makeQuizJSON(3)
+		mockLLM := &MockLLMClient{
+			Responses:    []llm.ChatCompletionResponse{makeLLMResponse(full[:len(full)/2])},
+			ChatResponse: makeLLMResponse(full),
+		}
+		svc := setupQuizService(mockLLM, nil)
+		generated, err := svc.generateQuestionsWithLLM("transcript", bank.QuizConfig)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		valid := svc.repairQuestions("transcript", quizChunks(quizTranscriptText), bank, generated)
+
+		if len(valid) != 3 {
+			t.Errorf("expected 3 repaired questions, got %d", len(valid))
+		}
+		messages := mockLLM.LastRequest.Messages
+		if len(messages) != 4 || messages[2].Content != full[:len(full)/2] || !strings.Contains(messages[3].Content, "not a valid JSON object") {
+			t.Errorf("expected the repair prompt to report the unparsable response, got %+v", messages)
+		}
+	})
+}
+
 func TestQuizService_getTranscriptText(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		mockTranscript := &MockTranscriptRepository{
@@ -92,7 +275,7 @@ func TestQuizService_getTranscriptText(t *testing.T) {
 			t.Fatalf("unexpected error: %v", errResp.Details)
 		}
 
-		expected := "Hello World "
+		expected := "[0:00] Speaker 0: Hello World"
 		if text != expected {
 			t.Errorf("got %q, want %q", text, expected)
 		}
@@ -164,3 +347,18 @@ func TestQuizService_getTranscriptText(t *testing.T) {
 		}
 	})
 }
+
+func TestWithSourceHint(t *testing.T) {
+	start := 754.6
+	grounded := Question{SourceStartTime: &start}
+
+	if got := withSourceHint("Correct!", grounded); got != "Correct! Hear it at 12:34." {
+		t.Errorf("got %q, want the feedback followed by the source timestamp", got)
+	}
+	if got := withSourceHint("", grounded); got != "Hear it at 12:34." {
+		t.Errorf("got %q, want only the source timestamp", got)
+	}
+	if got := withSourceHint("Correct!", Question{}); got != "Correct!" {
+		t.Errorf("got %q, want the feedback unchanged for questions without a source", got)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"slices"
 	"strings"
 	"time"
 
@@ -11,15 +12,33 @@ import (
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
-// generateQuestionsWithLLM calls the LLM to generate questions
-func (s *QuizService) generateQuestionsWithLLM(transcriptText string) ([]LLMQuestion, error) {
+// generateQuestionsWithLLM calls the LLM to generate questions with the given configuration
+func (s *QuizService) generateQuestionsWithLLM(transcriptText string, config QuizConfig) (generatedQuestions, error) {
+	return s.requestQuestions(config, []llm.Message{
+		{Role: "system", Content: GenerateQuestionsSystemPrompt(config)},
+		{Role: "user", Content: GenerateQuestionsUserPrompt(transcriptText)},
+	})
+}
+
+// repairQuestionsWithLLM sends the previous reply back to the LLM with the problems found in
+// it, asking for a corrected set
+func (s *QuizService) repairQuestionsWithLLM(transcriptText string, config QuizConfig, previous generatedQuestions, problems []string) (generatedQuestions, error) {
+	return s.requestQuestions(config, []llm.Message{
+		{Role: "system", Content: GenerateQuestionsSystemPrompt(config)},
+		{Role: "user", Content: GenerateQuestionsUserPrompt(transcriptText)},
+		{Role: "assistant", Content: previous.content},
+		{Role: "user", Content: RepairQuestionsUserPrompt(problems)},
+	})
+}
+
+// requestQuestions sends a question generation conversation to the LLM and parses the
+// questions of its response. A response that can't be parsed, e.g. because it was cut off,
+// is returned with its parse error so it can be repaired like invalid questions.
+func (s *QuizService) requestQuestions(config QuizConfig, messages []llm.Message) (generatedQuestions, error) {
 	// Create LLM request
 	reqBody := llm.ChatRequest{
-		Messages: []llm.Message{
-			{Role: "system", Content: GenerateQuestionsSystemPrompt},
-			{Role: "user", Content: GenerateQuestionsUserPrompt(transcriptText)},
-		},
-		MaxTokens: 2000,
+		Messages:  messages,
+		MaxTokens: GenerationBaseTokens + GenerationTokensPerQuestion*config.TotalQuestions(),
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
@@ -31,11 +50,11 @@ func (s *QuizService) generateQuestionsWithLLM(transcriptText string) ([]LLMQues
 		s.logger.Error("LLM request failed", map[string]any{
 			"error": err.Error(),
 		})
-		return nil, err
+		return generatedQuestions{}, err
 	}
 
 	if len(response.Choices) == 0 {
-		return nil, fmt.Errorf("no response from LLM")
+		return generatedQuestions{}, fmt.Errorf("no response from LLM")
 	}
 
 	// Parse LLM response
@@ -50,24 +69,33 @@ func (s *QuizService) generateQuestionsWithLLM(transcriptText string) ([]LLMQues
 
 	llmResponse, err := utils.DecodeResponse[LLMQuestionsResponse](content)
 	if err != nil {
-		s.logger.Error("Failed to parse LLM response", map[string]any{
-			"error":   err.Error(),
-			"content": content,
+		s.logger.Warn("Failed to parse LLM response", map[string]any{
+			"error":         err.Error(),
+			"finish_reason": response.Choices[0].FinishReason,
+			"content":       content,
 		})
-		return nil, fmt.Errorf("failed to parse LLM response: %w", err)
+		return generatedQuestions{content: content, parseErr: fmt.Errorf("failed to parse LLM response: %w", err)}, nil
 	}
 
-	return llmResponse.Questions, nil
+	return generatedQuestions{content: content, questions: llmResponse.Questions}, nil
 }
 
-// evaluateOpenEndedAnswer uses LLM to evaluate open-ended answers
-func (s *QuizService) evaluateOpenEndedAnswer(question Question, userAnswer string) (bool, string, error) {
+// evaluateOpenEndedAnswer uses LLM to grade open-ended answers against the reference answer
+// and key points of the question
+func (s *QuizService) evaluateOpenEndedAnswer(question Question, userAnswer string) (answerEvaluation, error) {
+	failed := answerEvaluation{Feedback: "Unable to evaluate answer at this time"}
+
+	referenceAnswer := ""
+	if question.ReferenceAnswer != nil {
+		referenceAnswer = *question.ReferenceAnswer
+	}
+
 	reqBody := llm.ChatRequest{
 		Messages: []llm.Message{
 			{Role: "system", Content: EvaluateOpenEndedSystemPrompt},
-			{Role: "user", Content: EvaluateOpenEndedUserPrompt(question.QuestionText, userAnswer, "")},
+			{Role: "user", Content: EvaluateOpenEndedUserPrompt(question.QuestionText, userAnswer, referenceAnswer, question.KeyPoints)},
 		},
-		MaxTokens: 300,
+		MaxTokens: 400,
 	}
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
@@ -76,11 +104,11 @@ func (s *QuizService) evaluateOpenEndedAnswer(question Question, userAnswer stri
 	// Call LLM
 	response, err := s.llmClient.Chat(ctx, reqBody)
 	if err != nil {
-		return false, "Unable to evaluate answer at this time", err
+		return failed, err
 	}
 
 	if len(response.Choices) == 0 {
-		return false, "Unable to evaluate answer at this time", fmt.Errorf("no response from LLM")
+		return failed, fmt.Errorf("no response from LLM")
 	}
 
 	content := strings.TrimSpace(response.Choices[0].Message.Content)
@@ -96,10 +124,34 @@ func (s *QuizService) evaluateOpenEndedAnswer(question Question, userAnswer stri
 			"error":   err.Error(),
 			"content": content,
 		})
-		return false, "Unable to evaluate answer at this time", err
+		return failed, err
 	}
 
-	return evaluation.IsCorrect, evaluation.Feedback, nil
+	score := min(max(evaluation.Score, 0), 1)
+	hit, missed := splitKeyPoints(question.KeyPoints, evaluation.KeyPointsHit)
+
+	return answerEvaluation{
+		IsCorrect:       score >= OpenEndedPassingScore,
+		Score:           score,
+		KeyPointsHit:    hit,
+		KeyPointsMissed: missed,
+		Feedback:        evaluation.Feedback,
+	}, nil
+}
+
+// splitKeyPoints splits the rubric of a question into the key points the LLM reported as hit
+// and the missed ones, so the lists only hold the question's own key points
+func splitKeyPoints(keyPoints, reportedHit []string) (hit, missed []string) {
+	for _, keyPoint := range keyPoints {
+		if slices.ContainsFunc(reportedHit, func(reported string) bool {
+			return strings.EqualFold(strings.TrimSpace(reported), strings.TrimSpace(keyPoint))
+		}) {
+			hit = append(hit, keyPoint)
+		} else {
+			missed = append(missed, keyPoint)
+		}
+	}
+	return hit, missed
 }
 
 // generateFeedbackWithLLM generates personalized feedback using LLM
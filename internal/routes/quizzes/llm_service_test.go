@@ -2,6 +2,7 @@ package quizzes
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -11,24 +12,25 @@ import (
 
 func TestQuizService_generateQuestionsWithLLM(t *testing.T) {
 	tests := []struct {
-		name        string
-		mockResp    llm.ChatCompletionResponse
-		mockError   error
-		wantCount   int
-		wantErr     bool
-		errContains string
+		name         string
+		mockResp     llm.ChatCompletionResponse
+		mockError    error
+		wantCount    int
+		wantErr      bool
+		errContains  string
+		wantParseErr bool
 	}{
-		{"clean JSON", makeLLMResponse(makeQuizJSON(3)), nil, 3, false, ""},
-		{"markdown wrapped", makeLLMResponse("```json\n" + makeQuizJSON(1) + "\n```"), nil, 1, false, ""},
-		{"no choices", llm.ChatCompletionResponse{Choices: []llm.Choice{}}, nil, 0, true, "no response from LLM"},
-		{"invalid JSON", makeLLMResponse("Not JSON"), nil, 0, true, "failed to parse LLM response"},
-		{"API error", llm.ChatCompletionResponse{}, fmt.Errorf("API failed"), 0, true, "API failed"},
+		{"clean JSON", makeLLMResponse(makeQuizJSON(3)), nil, 3, false, "", false},
+		{"markdown wrapped", makeLLMResponse("```json\n" + makeQuizJSON(1) + "\n```"), nil, 1, false, "", false},
+		{"no choices", llm.ChatCompletionResponse{Choices: []llm.Choice{}}, nil, 0, true, "no response from LLM", false},
+		{"invalid JSON is kept for repair", makeLLMResponse("Not JSON"), nil, 0, false, "", true},
+		{"API error", llm.ChatCompletionResponse{}, fmt.Errorf("API failed"), 0, true, "API failed", false},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			svc := setupQuizService(&MockLLMClient{ChatResponse: tt.mockResp, ChatError: tt.mockError}, nil)
-			questions, err := svc.generateQuestionsWithLLM("test")
+			generated, err := svc.generateQuestionsWithLLM("test", DefaultQuizConfig())
 
 			if tt.wantErr {
 				if err == nil || !strings.Contains(err.Error(), tt.errContains) {
@@ -38,35 +40,61 @@ func TestQuizService_generateQuestionsWithLLM(t *testing.T) {
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
-				if len(questions) != tt.wantCount {
-					t.Errorf("got %d questions, want %d", len(questions), tt.wantCount)
+				if len(generated.questions) != tt.wantCount {
+					t.Errorf("got %d questions, want %d", len(generated.questions), tt.wantCount)
+				}
+				if (generated.parseErr != nil) != tt.wantParseErr {
+					t.Errorf("got parse error %v, want parse error %v", generated.parseErr, tt.wantParseErr)
 				}
 			}
 		})
 	}
+
+	t.Run("scales the token budget with the number of questions", func(t *testing.T) {
+		mockLLM := &MockLLMClient{ChatResponse: makeLLMResponse(makeQuizJSON(3))}
+		svc := setupQuizService(mockLLM, nil)
+		config := QuizConfig{MultipleChoice: 5, TrueFalse: 5, OpenEnded: 5, Difficulty: Medium}
+
+		_, _ = svc.generateQuestionsWithLLM("test", config)
+
+		if want := GenerationBaseTokens + GenerationTokensPerQuestion*MaxQuestionsPerQuiz; mockLLM.LastRequest.MaxTokens != want {
+			t.Errorf("got %d max tokens, want %d", mockLLM.LastRequest.MaxTokens, want)
+		}
+	})
 }
 
 func TestQuizService_evaluateOpenEndedAnswer(t *testing.T) {
+	referenceAnswer := "Goroutines make concurrency cheap in Go."
+	question := Question{
+		QuestionText:    "Explain goroutines",
+		ReferenceAnswer: &referenceAnswer,
+		KeyPoints:       []string{"Goroutines are lightweight", "Concurrency is cheap"},
+	}
+
 	tests := []struct {
-		name         string
-		mockResp     llm.ChatCompletionResponse
-		mockError    error
-		wantCorrect  bool
-		wantFeedback string
-		wantErr      bool
+		name      string
+		mockResp  llm.ChatCompletionResponse
+		mockError error
+		want      answerEvaluation
+		wantErr   bool
 	}{
-		{"correct", makeLLMResponse(`{"is_correct": true, "feedback": "Great!"}`), nil, true, "Great!", false},
-		{"incorrect", makeLLMResponse(`{"is_correct": false, "feedback": "Try again"}`), nil, false, "Try again", false},
-		{"markdown wrapped", makeLLMResponse("```json\n" + `{"is_correct": true, "feedback": "Good!"}` + "\n```"), nil, true, "Good!", false},
-		{"no choices", llm.ChatCompletionResponse{Choices: []llm.Choice{}}, nil, false, "", true},
-		{"invalid JSON", makeLLMResponse("Not JSON"), nil, false, "", true},
-		{"network error", llm.ChatCompletionResponse{}, fmt.Errorf("network error"), false, "", true},
+		{"full credit", makeLLMResponse(`{"score": 1, "key_points_hit": ["Goroutines are lightweight", "Concurrency is cheap"], "key_points_missed": [], "feedback": "Great!"}`), nil,
+			answerEvaluation{IsCorrect: true, Score: 1, KeyPointsHit: []string{"Goroutines are lightweight", "Concurrency is cheap"}, Feedback: "Great!"}, false},
+		{"partial credit", makeLLMResponse(`{"score": 0.5, "key_points_hit": ["concurrency is cheap "], "key_points_missed": ["Goroutines are lightweight"], "feedback": "Almost"}`), nil,
+			answerEvaluation{IsCorrect: true, Score: 0.5, KeyPointsHit: []string{"Concurrency is cheap"}, KeyPointsMissed: []string{"Goroutines are lightweight"}, Feedback: "Almost"}, false},
+		{"unknown key points are missed", makeLLMResponse(`{"score": 0.2, "key_points_hit": ["Channels exist"], "feedback": "Try again"}`), nil,
+			answerEvaluation{Score: 0.2, KeyPointsMissed: []string{"Goroutines are lightweight", "Concurrency is cheap"}, Feedback: "Try again"}, false},
+		{"score out of range", makeLLMResponse("```json\n" + `{"score": 3, "key_points_hit": [], "feedback": "Good!"}` + "\n```"), nil,
+			answerEvaluation{IsCorrect: true, Score: 1, KeyPointsMissed: []string{"Goroutines are lightweight", "Concurrency is cheap"}, Feedback: "Good!"}, false},
+		{"no choices", llm.ChatCompletionResponse{Choices: []llm.Choice{}}, nil, answerEvaluation{}, true},
+		{"invalid JSON", makeLLMResponse("Not JSON"), nil, answerEvaluation{}, true},
+		{"network error", llm.ChatCompletionResponse{}, fmt.Errorf("network error"), answerEvaluation{}, true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			svc := setupQuizService(&MockLLMClient{ChatResponse: tt.mockResp, ChatError: tt.mockError}, nil)
-			isCorrect, feedback, err := svc.evaluateOpenEndedAnswer(Question{QuestionText: "Test"}, "Answer")
+			evaluation, err := svc.evaluateOpenEndedAnswer(question, "Answer")
 
 			if tt.wantErr {
 				if err == nil {
@@ -76,14 +104,28 @@ func TestQuizService_evaluateOpenEndedAnswer(t *testing.T) {
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
-				if isCorrect != tt.wantCorrect || feedback != tt.wantFeedback {
-					t.Errorf("got (%v, %q), want (%v, %q)", isCorrect, feedback, tt.wantCorrect, tt.wantFeedback)
+				if !reflect.DeepEqual(evaluation, tt.want) {
+					t.Errorf("got %+v, want %+v", evaluation, tt.want)
 				}
 			}
 		})
 	}
 }
 
+func TestEvaluateOpenEndedUserPrompt(t *testing.T) {
+	prompt := EvaluateOpenEndedUserPrompt("Explain goroutines", "They are cheap", "Goroutines make concurrency cheap.", []string{"Lightweight", "Cheap"})
+
+	for _, want := range []string{"Question: Explain goroutines", "Expected answer: Goroutines make concurrency cheap.", "Key points:\n- Lightweight\n- Cheap", "User's answer: They are cheap"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("expected the prompt to contain %q, got %q", want, prompt)
+		}
+	}
+
+	if prompt := EvaluateOpenEndedUserPrompt("Q", "A", "", nil); strings.Contains(prompt, "Expected answer") || strings.Contains(prompt, "Key points") {
+		t.Errorf("expected no rubric in the prompt, got %q", prompt)
+	}
+}
+
 func TestQuizService_generateFeedbackWithLLM(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -103,7 +145,7 @@ func TestQuizService_generateFeedbackWithLLM(t *testing.T) {
 			mockTranscript := &MockTranscriptRepository{
 				GetTranscriptByEpisodeIDFunc: func(int) (transcripts.Transcript, error) { return transcripts.Transcript{ID: 1}, nil },
 				GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
-					return []transcripts.TranscriptChunk{{Text: "Test"}}, nil
+					return quizChunks(quizTranscriptText), nil
 				},
 			}
 			svc := setupQuizService(&MockLLMClient{ChatResponse: tt.llmResp, ChatError: tt.llmErr}, mockTranscript)
@@ -121,7 +163,7 @@ func TestQuizService_generateFeedbackWithLLM_Success(t *testing.T) {
 	mockTranscript := &MockTranscriptRepository{
 		GetTranscriptByEpisodeIDFunc: func(int) (transcripts.Transcript, error) { return transcripts.Transcript{ID: 1}, nil },
 		GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
-			return []transcripts.TranscriptChunk{{Text: "Test transcript"}}, nil
+			return quizChunks(quizTranscriptText), nil
 		},
 	}
 	svc := setupQuizService(&MockLLMClient{ChatResponse: makeLLMResponse("Great explanation!")}, mockTranscript)
@@ -4,11 +4,13 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"cribeapp.com/cribe-server/internal/clients/llm"
 	"cribeapp.com/cribe-server/internal/core/logger"
 	"cribeapp.com/cribe-server/internal/routes/transcripts"
+	"cribeapp.com/cribe-server/internal/routes/users"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
@@ -16,9 +18,18 @@ import (
 type MockLLMClient struct {
 	ChatResponse llm.ChatCompletionResponse
 	ChatError    error
+	LastRequest  llm.ChatRequest
+	// Responses are returned in order before ChatResponse
+	Responses []llm.ChatCompletionResponse
 }
 
 func (m *MockLLMClient) Chat(ctx context.Context, req llm.ChatRequest) (llm.ChatCompletionResponse, error) {
+	m.LastRequest = req
+	if len(m.Responses) > 0 {
+		response := m.Responses[0]
+		m.Responses = m.Responses[1:]
+		return response, nil
+	}
 	if m.ChatError != nil {
 		return llm.ChatCompletionResponse{}, m.ChatError
 	}
@@ -55,9 +66,12 @@ func makeLLMResponse(content string) llm.ChatCompletionResponse {
 // Mock Quiz Repository
 func NewMockQuizRepository() *QuizRepository {
 	var questions []Question
+	var lastQuestionID int
 	var options []QuestionOption
+	var banks []QuestionBank
 	var sessions []UserQuizSession
 	var answers []UserAnswer
+	preferences := map[int]QuizPreferences{}
 
 	repo := &QuizRepository{
 		logger: logger.NewRepositoryLogger("QuizRepository"),
@@ -85,13 +99,20 @@ func NewMockQuizRepository() *QuizRepository {
 				return Question{}, fmt.Errorf("question not found")
 			},
 			QueryList: func(query string, args ...any) ([]Question, error) {
-				// GetQuestionsByEpisodeID
+				// GetQuestionsByBankID or GetQuestionsByEpisodeID
 				if len(args) > 0 {
-					episodeID, ok := args[0].(int)
+					id, ok := args[0].(int)
 					if ok {
+						byBank := strings.Contains(query, "bank_id = $1")
 						var result []Question
 						for _, q := range questions {
-							if q.EpisodeID == episodeID {
+							if (byBank && q.BankID == id) || (!byBank && q.EpisodeID == id) {
+								// Populate options for this question
+								for _, opt := range options {
+									if opt.QuestionID == q.ID {
+										q.Options = append(q.Options, opt)
+									}
+								}
 								result = append(result, q)
 							}
 						}
@@ -101,14 +122,15 @@ func NewMockQuizRepository() *QuizRepository {
 				return []Question{}, nil
 			},
 			Exec: func(query string, args ...any) error {
-				// DeleteQuestionsByEpisodeID
+				// DeleteQuestionsByBankID or DeleteQuestionsByEpisodeID
 				if len(args) > 0 {
-					episodeID, ok := args[0].(int)
+					id, ok := args[0].(int)
 					if ok {
-						// Remove questions with matching episodeID
+						byBank := strings.Contains(query, "bank_id = $1")
+						// Remove questions with matching bankID or episodeID
 						var filtered []Question
 						for _, q := range questions {
-							if q.EpisodeID != episodeID {
+							if (byBank && q.BankID != id) || (!byBank && q.EpisodeID != id) {
 								filtered = append(filtered, q)
 							}
 						}
@@ -134,8 +156,120 @@ func NewMockQuizRepository() *QuizRepository {
 				return QuestionOption{}, nil
 			},
 		})),
+		bankRepo: utils.NewRepository[QuestionBank](utils.WithQueryExecutor[QuestionBank](utils.QueryExecutor[QuestionBank]{
+			QueryItem: func(query string, args ...any) (QuestionBank, error) {
+				// GetOrCreateQuestionBank
+				if len(args) >= 5 {
+					config := QuizConfig{
+						MultipleChoice: args[1].(int),
+						TrueFalse:      args[2].(int),
+						OpenEnded:      args[3].(int),
+						Difficulty:     args[4].(Difficulty),
+					}
+					for _, b := range banks {
+						if b.EpisodeID == args[0].(int) && b.QuizConfig == config && b.ArchivedAt == nil {
+							return b, nil
+						}
+					}
+					b := QuestionBank{ID: len(banks) + 1, EpisodeID: args[0].(int), QuizConfig: config, Version: 1}
+					banks = append(banks, b)
+					return b, nil
+				}
+				// ReplaceQuestionBank, BumpQuestionBankVersion or GetQuestionBankByID
+				if len(args) == 1 {
+					for i, b := range banks {
+						if b.ID != args[0].(int) {
+							continue
+						}
+						switch {
+						case strings.Contains(query, "archived_at = NOW()"):
+							now := time.Now()
+							banks[i].ArchivedAt = &now
+							next := QuestionBank{ID: len(banks) + 1, EpisodeID: b.EpisodeID, QuizConfig: b.QuizConfig, Version: b.Version + 1}
+							banks = append(banks, next)
+							return next, nil
+						case strings.Contains(query, "version = version + 1"):
+							banks[i].Version++
+							return banks[i], nil
+						}
+						return b, nil
+					}
+				}
+				return QuestionBank{}, fmt.Errorf("no rows in result set")
+			},
+			QueryList: func(query string, args ...any) ([]QuestionBank, error) {
+				// GetActiveQuestionBanksByEpisodeID
+				var result []QuestionBank
+				for _, b := range banks {
+					if b.EpisodeID != args[0].(int) || b.ArchivedAt != nil {
+						continue
+					}
+					for _, s := range sessions {
+						if s.BankID != nil && *s.BankID == b.ID {
+							b.InUse = true
+						}
+					}
+					result = append(result, b)
+				}
+				return result, nil
+			},
+		})),
+		roles: users.NewRoleRepository(utils.WithQueryExecutor(utils.QueryExecutor[users.Role]{
+			QueryItem: func(query string, args ...any) (users.Role, error) {
+				return users.Role{}, fmt.Errorf("no rows in result set")
+			},
+		})),
+		lockConn: func(fn func(repo *utils.Repository[generationLock]) error) error {
+			return fn(utils.NewRepository[generationLock](utils.WithQueryExecutor[generationLock](utils.QueryExecutor[generationLock]{
+				Exec: func(query string, args ...any) error {
+					return nil
+				},
+			})))
+		},
+		preferenceRepo: utils.NewRepository[QuizPreferences](utils.WithQueryExecutor[QuizPreferences](utils.QueryExecutor[QuizPreferences]{
+			QueryItem: func(query string, args ...any) (QuizPreferences, error) {
+				// SaveQuizPreferences
+				if len(args) >= 5 {
+					p := QuizPreferences{
+						UserID: args[0].(int),
+						QuizConfig: QuizConfig{
+							MultipleChoice: args[1].(int),
+							TrueFalse:      args[2].(int),
+							OpenEnded:      args[3].(int),
+							Difficulty:     args[4].(Difficulty),
+						},
+						UpdatedAt: time.Now(),
+					}
+					preferences[p.UserID] = p
+					return p, nil
+				}
+				// GetQuizPreferences
+				if p, ok := preferences[args[0].(int)]; ok {
+					return p, nil
+				}
+				return QuizPreferences{}, fmt.Errorf("no rows in result set")
+			},
+		})),
 		sessionRepo: utils.NewRepository[UserQuizSession](utils.WithQueryExecutor[UserQuizSession](utils.QueryExecutor[UserQuizSession]{
 			QueryItem: func(query string, args ...any) (UserQuizSession, error) {
+				// RecordAnswer increments the counters of an in-progress session
+				if strings.Contains(query, "answered_questions + 1") {
+					for i := range sessions {
+						if sessions[i].ID != args[0].(int) || sessions[i].Status != InProgress {
+							continue
+						}
+						sessions[i].AnsweredQuestions++
+						sessions[i].CorrectAnswers += args[1].(int)
+						sessions[i].Score += args[2].(float64)
+						if sessions[i].AnsweredQuestions >= sessions[i].TotalQuestions {
+							now := time.Now()
+							sessions[i].Status = Completed
+							sessions[i].CompletedAt = &now
+						}
+						return sessions[i], nil
+					}
+					return UserQuizSession{}, fmt.Errorf("no rows in result set")
+				}
 				// GetSessionByID or GetActiveSessionByUserAndEpisode
 				if len(args) == 1 {
 					// GetSessionByID
@@ -145,6 +279,22 @@ func NewMockQuizRepository() *QuizRepository {
 							return s, nil
 						}
 					}
+				} else if len(args) == 2 && strings.Contains(query, "bank_id = $2") {
+					// GetLatestSessionByUserAndBank, including in-progress sessions on archived versions
+					userID := args[0].(int)
+					current := banks[args[1].(int)-1]
+					for i := len(sessions) - 1; i >= 0; i-- {
+						s := sessions[i]
+						if s.UserID != userID || s.BankID == nil {
+							continue
+						}
+						bank := banks[*s.BankID-1]
+						if bank.ID == current.ID || (s.Status == InProgress && bank.ArchivedAt != nil &&
+							bank.EpisodeID == current.EpisodeID && bank.QuizConfig == current.QuizConfig) {
+							return s, nil
+						}
+					}
+					return UserQuizSession{}, sql.ErrNoRows
 				} else if len(args) == 2 {
 					// GetActiveSessionByUserAndEpisode
 					userID := args[0].(int)
@@ -159,6 +309,17 @@ func NewMockQuizRepository() *QuizRepository {
 				return UserQuizSession{}, sql.ErrNoRows
 			},
 			QueryList: func(query string, args ...any) ([]UserQuizSession, error) {
+				// GetSessionsByUserAndEpisode, newest attempt first
+				if len(args) == 2 {
+					userID, episodeID := args[0].(int), args[1].(int)
+					result := []UserQuizSession{}
+					for i := len(sessions) - 1; i >= 0; i-- {
+						if sessions[i].UserID == userID && sessions[i].EpisodeID == episodeID {
+							result = append(result, sessions[i])
+						}
+					}
+					return result, nil
+				}
 				// GetSessionsByUserID
 				if len(args) > 0 {
 					userID := args[0].(int)
@@ -174,10 +335,10 @@ func NewMockQuizRepository() *QuizRepository {
 			},
 			Exec: func(query string, args ...any) error {
 				// UpdateSession or DeleteSession
-				if len(args) >= 5 {
+				if len(args) >= 6 {
 					// UpdateSession
-					// args: status, answered_questions, correct_answers, completed_at, session_id
-					sessionID, ok := args[4].(int)
+					// args: status, answered_questions, correct_answers, score, completed_at, session_id
+					sessionID, ok := args[5].(int)
 					if ok {
 						for i := range sessions {
 							if sessions[i].ID == sessionID {
@@ -190,8 +351,11 @@ func NewMockQuizRepository() *QuizRepository {
 								if correct, ok := args[2].(int); ok {
 									sessions[i].CorrectAnswers = correct
 								}
+								if score, ok := args[3].(float64); ok {
+									sessions[i].Score = score
+								}
 								// Handle CompletedAt - can be *time.Time or nil
-								if completedAt, ok := args[3].(*time.Time); ok {
+								if completedAt, ok := args[4].(*time.Time); ok {
 									sessions[i].CompletedAt = completedAt
 								}
 								break
@@ -233,6 +397,12 @@ func NewMockQuizRepository() *QuizRepository {
 						UserID:     args[2].(int),
 						IsCorrect:  args[5].(bool),
 					}
+					if len(args) >= 10 {
+						ans.Score, _ = args[6].(float64)
+						ans.KeyPointsHit, _ = args[7].([]string)
+						ans.KeyPointsMissed, _ = args[8].([]string)
+						ans.Feedback, _ = args[9].(string)
+					}
 					answers = append(answers, ans)
 					return ans, nil
 				}
@@ -289,13 +459,27 @@ func NewMockQuizRepository() *QuizRepository {
 			// Type assertion for QuestionType
 			questionType, _ := args[2].(QuestionType)
 
+			lastQuestionID++
 			q := Question{
-				ID:           len(questions) + 1,
+				ID:           lastQuestionID,
 				EpisodeID:    args[0].(int),
 				QuestionText: args[1].(string),
 				Type:         questionType,
 				Position:     args[3].(int),
 			}
+			if len(args) >= 5 {
+				q.BankID, _ = args[4].(int)
+			}
+			if len(args) >= 9 {
+				q.SourceStartPosition, _ = args[5].(*int)
+				q.SourceEndPosition, _ = args[6].(*int)
+				q.SourceStartTime, _ = args[7].(*float64)
+				q.SourceEndTime, _ = args[8].(*float64)
+			}
+			if len(args) >= 11 {
+				q.ReferenceAnswer, _ = args[9].(*string)
+				q.KeyPoints, _ = args[10].([]string)
+			}
 			questions = append(questions, q)
 			return q, nil
 		}
@@ -320,6 +504,20 @@ func NewMockQuizRepository() *QuizRepository {
 				AnsweredQuestions: args[4].(int),
 				CorrectAnswers:    args[5].(int),
 			}
+			if len(args) >= 7 {
+				s.BankID, _ = args[6].(*int)
+			}
+			if len(args) >= 8 {
+				s.ShuffleSeed, _ = args[7].(*int64)
+			}
+			// The attempt number follows the user's previous attempts at the episode
+			s.Attempt = 1
+			for _, previous := range sessions {
+				if previous.UserID == s.UserID && previous.EpisodeID == s.EpisodeID && previous.Attempt >= s.Attempt {
+					s.Attempt = previous.Attempt + 1
+				}
+			}
+			s.StartedAt = time.Now()
 			sessions = append(sessions, s)
 			return s, nil
 		}
@@ -331,16 +529,11 @@ func NewMockQuizRepository() *QuizRepository {
 
 func NewMockQuizServiceReady() *QuizService {
 	mockLLMClient := &MockLLMClient{
-		ChatResponse: makeLLMResponse(`{"is_correct": true, "feedback": "Great job! This demonstrates a solid understanding of the concept."}`),
+		ChatResponse: makeLLMResponse(`{"score": 1, "key_points_hit": [], "key_points_missed": [], "feedback": "Great job! This demonstrates a solid understanding of the concept."}`),
 	}
 	mockTranscriptRepo := &MockTranscriptRepository{}
 
-	return &QuizService{
-		repo:           *NewMockQuizRepository(),
-		llmClient:      mockLLMClient,
-		transcriptRepo: mockTranscriptRepo,
-		logger:         logger.NewServiceLogger("QuizService"),
-	}
+	return NewQuizService(*NewMockQuizRepository(), mockTranscriptRepo, mockLLMClient)
 }
 
 func NewMockQuizHandlerReady() *QuizHandler {
@@ -1,6 +1,7 @@
 package quizzes
 
 import (
+	"fmt"
 	"time"
 
 	"cribeapp.com/cribe-server/internal/errors"
@@ -25,16 +26,117 @@ const (
 	Abandoned  SessionStatus = "abandoned"
 )
 
+// Difficulty represents the level of the questions of a quiz
+type Difficulty string
+
+const (
+	Easy   Difficulty = "easy"
+	Medium Difficulty = "medium"
+	Hard   Difficulty = "hard"
+)
+
+// QuizConfig is the number of questions of each type and the difficulty of a quiz. Each
+// configuration of an episode has its own question bank.
+type QuizConfig struct {
+	MultipleChoice int        `json:"multiple_choice" validate:"min=0"`
+	TrueFalse      int        `json:"true_false" validate:"min=0"`
+	OpenEnded      int        `json:"open_ended" validate:"min=0"`
+	Difficulty     Difficulty `json:"difficulty" validate:"required,oneof=easy medium hard"`
+}
+
+// DefaultQuizConfig is the configuration of users who haven't chosen one
+func DefaultQuizConfig() QuizConfig {
+	return QuizConfig{
+		MultipleChoice: DefaultMultipleChoiceCount,
+		TrueFalse:      DefaultTrueFalseCount,
+		OpenEnded:      DefaultOpenEndedCount,
+		Difficulty:     DefaultDifficulty,
+	}
+}
+
+// TotalQuestions is the number of questions of a quiz with this configuration
+func (c QuizConfig) TotalQuestions() int {
+	return c.MultipleChoice + c.TrueFalse + c.OpenEnded
+}
+
+// Count is the number of questions of the given type
+func (c QuizConfig) Count(questionType QuestionType) int {
+	switch questionType {
+	case MultipleChoice:
+		return c.MultipleChoice
+	case TrueFalse:
+		return c.TrueFalse
+	case OpenEnded:
+		return c.OpenEnded
+	default:
+		return 0
+	}
+}
+
+func (c QuizConfig) Validate() *errors.ErrorResponse {
+	if err := utils.ValidateStruct(c); err != nil {
+		return err
+	}
+
+	if total := c.TotalQuestions(); total < 1 || total > MaxQuestionsPerQuiz {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: fmt.Sprintf("A quiz must have between 1 and %d questions", MaxQuestionsPerQuiz),
+		}
+	}
+
+	return nil
+}
+
+// QuestionBank groups the questions generated for an episode with one configuration. When
+// the questions of a bank used by sessions are regenerated, the bank is archived and its next
+// version becomes the active bank of the configuration.
+type QuestionBank struct {
+	ID        int `json:"id"`
+	EpisodeID int `json:"episode_id"`
+	QuizConfig
+	Version    int        `json:"version"`
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	// InUse reports whether sessions were started on the bank
+	InUse bool `json:"-"`
+}
+
+// RegeneratedQuestionBank is a question bank with its regenerated questions
+type RegeneratedQuestionBank struct {
+	QuestionBank
+	ReplacedBankID *int       `json:"replaced_bank_id,omitempty"`
+	Questions      []Question `json:"questions"`
+}
+
+// QuizPreferences is the quiz configuration of a user, used when a request doesn't set one
+type QuizPreferences struct {
+	UserID int `json:"user_id"`
+	QuizConfig
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // Question represents a quiz question for an episode
 type Question struct {
-	ID           int              `json:"id"`
-	EpisodeID    int              `json:"episode_id"`
-	QuestionText string           `json:"question_text"`
-	Type         QuestionType     `json:"type"`
-	Position     int              `json:"position"`
-	CreatedAt    time.Time        `json:"created_at"`
-	UpdatedAt    time.Time        `json:"updated_at"`
-	Options      []QuestionOption `json:"options,omitempty"`
+	ID           int          `json:"id"`
+	EpisodeID    int          `json:"episode_id"`
+	BankID       int          `json:"bank_id"`
+	QuestionText string       `json:"question_text"`
+	Type         QuestionType `json:"type"`
+	Position     int          `json:"position"`
+	// Transcript span supporting the answer, so clients can jump to it; nil for questions
+	// generated before questions were grounded in the transcript
+	SourceStartPosition *int     `json:"source_start_position,omitempty"`
+	SourceEndPosition   *int     `json:"source_end_position,omitempty"`
+	SourceStartTime     *float64 `json:"source_start_time,omitempty"`
+	SourceEndTime       *float64 `json:"source_end_time,omitempty"`
+	// Reference answer and rubric of an open-ended question, used to grade its answers; nil
+	// for choice questions and open-ended questions generated before rubrics
+	ReferenceAnswer *string          `json:"reference_answer,omitempty"`
+	KeyPoints       []string         `json:"key_points,omitempty"`
+	CreatedAt       time.Time        `json:"created_at"`
+	UpdatedAt       time.Time        `json:"updated_at"`
+	Options         []QuestionOption `json:"options,omitempty"`
 }
 
 // QuestionOption represents an option for a multiple choice or true/false question
@@ -49,33 +151,88 @@ type QuestionOption struct {
 
 // UserQuizSession represents a user's quiz session for an episode
 type UserQuizSession struct {
-	ID                int           `json:"id"`
-	UserID            int           `json:"user_id"`
-	EpisodeID         int           `json:"episode_id"`
+	ID        int  `json:"id"`
+	UserID    int  `json:"user_id"`
+	EpisodeID int  `json:"episode_id"`
+	BankID    *int `json:"bank_id,omitempty"`
+	Attempt   int  `json:"attempt"`
+	// ShuffleSeed orders the questions and options of a retake; nil keeps the bank order
+	ShuffleSeed       *int64        `json:"-"`
 	EpisodeName       string        `json:"episode_name"`
 	PodcastName       string        `json:"podcast_name"`
 	Status            SessionStatus `json:"status"`
 	TotalQuestions    int           `json:"total_questions"`
 	AnsweredQuestions int           `json:"answered_questions"`
 	CorrectAnswers    int           `json:"correct_answers"`
+	// Score adds up the scores of the answers, with partial credit for open-ended ones
+	Score       float64    `json:"score"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// QuizAttempt summarizes one attempt of a user at the quiz of an episode. Score is the
+// session score as a percentage of the questions.
+type QuizAttempt struct {
+	SessionID         int           `json:"session_id"`
+	Attempt           int           `json:"attempt"`
+	BankID            *int          `json:"bank_id,omitempty"`
+	Status            SessionStatus `json:"status"`
+	TotalQuestions    int           `json:"total_questions"`
+	AnsweredQuestions int           `json:"answered_questions"`
+	CorrectAnswers    int           `json:"correct_answers"`
+	Score             float64       `json:"score"`
 	StartedAt         time.Time     `json:"started_at"`
 	CompletedAt       *time.Time    `json:"completed_at,omitempty"`
-	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+// EpisodeAttempts lists the attempts of a user at the quiz of an episode, newest first, with
+// the best and latest completed ones
+type EpisodeAttempts struct {
+	EpisodeID int           `json:"episode_id"`
+	Attempts  []QuizAttempt `json:"attempts"`
+	Best      *QuizAttempt  `json:"best,omitempty"`
+	Latest    *QuizAttempt  `json:"latest,omitempty"`
 }
 
 // UserAnswer represents a user's answer to a question
 type UserAnswer struct {
-	ID               int       `json:"id"`
-	SessionID        int       `json:"session_id"`
-	QuestionID       int       `json:"question_id"`
-	UserID           int       `json:"user_id"`
-	SelectedOptionID *int      `json:"selected_option_id,omitempty"`
-	TextAnswer       *string   `json:"text_answer,omitempty"`
-	IsCorrect        bool      `json:"is_correct"`
-	Feedback         string    `json:"feedback"`
-	AnsweredAt       time.Time `json:"answered_at"`
+	ID               int     `json:"id"`
+	SessionID        int     `json:"session_id"`
+	QuestionID       int     `json:"question_id"`
+	UserID           int     `json:"user_id"`
+	SelectedOptionID *int    `json:"selected_option_id,omitempty"`
+	TextAnswer       *string `json:"text_answer,omitempty"`
+	IsCorrect        bool    `json:"is_correct"`
+	// Score is from 0 to 1: partial credit for open-ended answers, all or nothing otherwise
+	Score           float64   `json:"score"`
+	KeyPointsHit    []string  `json:"key_points_hit,omitempty"`
+	KeyPointsMissed []string  `json:"key_points_missed,omitempty"`
+	Feedback        string    `json:"feedback"`
+	AnsweredAt      time.Time `json:"answered_at"`
 }
 
+// answerEvaluation is the grading of an answer: all or nothing for choice questions, partial
+// credit against the rubric for open-ended ones
+type answerEvaluation struct {
+	IsCorrect       bool
+	Score           float64
+	KeyPointsHit    []string
+	KeyPointsMissed []string
+	Feedback        string
+}
+
+// generatedQuestions is an LLM reply to a question generation request. The raw content is kept
+// to be sent back when asking for a repair; parseErr is set when it isn't a valid questions object.
+type generatedQuestions struct {
+	content   string
+	questions []LLMQuestion
+	parseErr  error
+}
+
+// generationLock types the connection holding a question bank's generation lock
+type generationLock struct{}
+
 // DTOs for API requests/responses
 
 // GenerateQuestionsRequest is the request to generate questions for an episode
@@ -96,18 +253,48 @@ func (dto StartSessionRequest) Validate() *errors.ErrorResponse {
 	return utils.ValidateStruct(dto)
 }
 
-// GetOrCreateSessionRequest is the request to get or create a quiz session
+// GetOrCreateSessionRequest is the request to get or create a quiz session. The config
+// overrides parts of the user's quiz preferences for this quiz.
 type GetOrCreateSessionRequest struct {
-	EpisodeID int `json:"episode_id" validate:"required,min=1"`
+	EpisodeID int                `json:"episode_id" validate:"required,min=1"`
+	Config    *QuizConfigRequest `json:"config,omitempty"`
+	// Retake starts a new attempt instead of returning the latest session
+	Retake bool `json:"retake"`
 }
 
 func (dto GetOrCreateSessionRequest) Validate() *errors.ErrorResponse {
 	return utils.ValidateStruct(dto)
 }
 
+// QuizConfigRequest sets some of the fields of a quiz configuration
+type QuizConfigRequest struct {
+	MultipleChoice *int        `json:"multiple_choice,omitempty"`
+	TrueFalse      *int        `json:"true_false,omitempty"`
+	OpenEnded      *int        `json:"open_ended,omitempty"`
+	Difficulty     *Difficulty `json:"difficulty,omitempty"`
+}
+
+// applyTo returns the configuration with the fields set by the request replaced
+func (dto QuizConfigRequest) applyTo(config QuizConfig) QuizConfig {
+	if dto.MultipleChoice != nil {
+		config.MultipleChoice = *dto.MultipleChoice
+	}
+	if dto.TrueFalse != nil {
+		config.TrueFalse = *dto.TrueFalse
+	}
+	if dto.OpenEnded != nil {
+		config.OpenEnded = *dto.OpenEnded
+	}
+	if dto.Difficulty != nil {
+		config.Difficulty = *dto.Difficulty
+	}
+	return config
+}
+
 // QuizSessionDetail is the complete session response with questions and answers
 type QuizSessionDetail struct {
 	Session   UserQuizSession `json:"session"`
+	Config    *QuizConfig     `json:"config,omitempty"`
 	Questions []Question      `json:"questions"`
 	Answers   []UserAnswer    `json:"answers"`
 }
@@ -120,6 +307,7 @@ type QuizSessionSummary struct {
 	TotalQuestions    int           `json:"total_questions"`
 	AnsweredQuestions int           `json:"answered_questions"`
 	CorrectAnswers    int           `json:"correct_answers"`
+	Score             float64       `json:"score"`
 	StartedAt         time.Time     `json:"started_at"`
 	CompletedAt       *time.Time    `json:"completed_at,omitempty"`
 	UpdatedAt         time.Time     `json:"updated_at"`
@@ -162,11 +350,21 @@ type LLMQuestionOption struct {
 	IsCorrect bool   `json:"is_correct"`
 }
 
+// LLMQuestionSource is the transcript passage the LLM says supports a question's answer
+type LLMQuestionSource struct {
+	Quote string `json:"quote"`
+	Start string `json:"start"`
+}
+
 // LLMQuestion represents a question generated by LLM
 type LLMQuestion struct {
 	QuestionText string              `json:"question_text"`
 	Type         string              `json:"type"`
 	Options      []LLMQuestionOption `json:"options,omitempty"`
+	Source       *LLMQuestionSource  `json:"source,omitempty"`
+	// Open-ended questions only
+	ReferenceAnswer string   `json:"reference_answer,omitempty"`
+	KeyPoints       []string `json:"key_points,omitempty"`
 }
 
 // LLMQuestionsResponse represents the full response from LLM
@@ -174,7 +372,10 @@ type LLMQuestionsResponse struct {
 	Questions []LLMQuestion `json:"questions"`
 }
 
+// LLMEvaluationResponse is the grading of an open-ended answer against the rubric of its question
 type LLMEvaluationResponse struct {
-	IsCorrect bool   `json:"is_correct"`
-	Feedback  string `json:"feedback"`
+	Score           float64  `json:"score"`
+	KeyPointsHit    []string `json:"key_points_hit"`
+	KeyPointsMissed []string `json:"key_points_missed"`
+	Feedback        string   `json:"feedback"`
 }
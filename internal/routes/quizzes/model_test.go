@@ -82,3 +82,43 @@ func TestUpdateSessionStatusRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestQuizConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  QuizConfig
+		wantErr bool
+	}{
+		{"default", DefaultQuizConfig(), false},
+		{"single type", QuizConfig{OpenEnded: 2, Difficulty: Hard}, false},
+		{"no questions", QuizConfig{Difficulty: Easy}, true},
+		{"too many questions", QuizConfig{MultipleChoice: MaxQuestionsPerQuiz, TrueFalse: 1, Difficulty: Easy}, true},
+		{"negative count", QuizConfig{MultipleChoice: 3, TrueFalse: -1, Difficulty: Easy}, true},
+		{"unknown difficulty", QuizConfig{MultipleChoice: 1, Difficulty: "extreme"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.wantErr && err == nil {
+				t.Error("Expected validation error, got nil")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Expected no error, got: %v", err.Details)
+			}
+		})
+	}
+}
+
+func TestQuizConfigRequest_applyTo(t *testing.T) {
+	trueFalse, difficulty := 0, Hard
+	req := QuizConfigRequest{TrueFalse: &trueFalse, Difficulty: &difficulty}
+
+	got := req.applyTo(DefaultQuizConfig())
+
+	want := QuizConfig{MultipleChoice: 1, TrueFalse: 0, OpenEnded: 1, Difficulty: Hard}
+	if got != want {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
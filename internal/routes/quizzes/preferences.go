@@ -0,0 +1,64 @@
+package quizzes
+
+import (
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// GetQuizPreferences returns the quiz configuration of a user, or the default one when the
+// user hasn't chosen any
+func (s *QuizService) GetQuizPreferences(userID int) (QuizPreferences, *errors.ErrorResponse) {
+	preferences, err := s.repo.GetQuizPreferences(userID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return QuizPreferences{UserID: userID, QuizConfig: DefaultQuizConfig()}, nil
+		}
+		return QuizPreferences{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch quiz preferences",
+		}
+	}
+
+	return preferences, nil
+}
+
+// UpdateQuizPreferences replaces the quiz configuration of a user
+func (s *QuizService) UpdateQuizPreferences(userID int, config QuizConfig) (QuizPreferences, *errors.ErrorResponse) {
+	if errResp := config.Validate(); errResp != nil {
+		return QuizPreferences{}, errResp
+	}
+
+	preferences, err := s.repo.SaveQuizPreferences(userID, config)
+	if err != nil {
+		return QuizPreferences{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to save quiz preferences",
+		}
+	}
+
+	s.logger.Info("Quiz preferences updated", map[string]any{
+		"user_id": userID,
+		"config":  config,
+	})
+
+	return preferences, nil
+}
+
+// resolveQuizConfig returns the user's quiz configuration with the fields set by the request
+// replaced
+func (s *QuizService) resolveQuizConfig(userID int, override *QuizConfigRequest) (QuizConfig, *errors.ErrorResponse) {
+	preferences, errResp := s.GetQuizPreferences(userID)
+	if errResp != nil {
+		return QuizConfig{}, errResp
+	}
+
+	config := preferences.QuizConfig
+	if override != nil {
+		config = override.applyTo(config)
+	}
+
+	if errResp := config.Validate(); errResp != nil {
+		return QuizConfig{}, errResp
+	}
+
+	return config, nil
+}
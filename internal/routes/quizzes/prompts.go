@@ -1,17 +1,31 @@
 package quizzes
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
+
+// difficultyGuidelines describes the questions expected at each difficulty level
+var difficultyGuidelines = map[Difficulty]string{
+	Easy:   "Questions should check recall of the main points stated explicitly in the episode",
+	Medium: "Questions should test understanding of key concepts, not just recall",
+	Hard:   "Questions should require analysis: connecting ideas across the episode, inferring implications or comparing viewpoints, with plausible wrong options",
+}
 
-var GenerateQuestionsSystemPrompt = fmt.Sprintf(`You are an expert at creating educational quiz questions from podcast transcripts. Generate a mix of multiple choice, true/false, and open-ended questions.
+// GenerateQuestionsSystemPrompt asks for the questions of a bank with the given configuration
+func GenerateQuestionsSystemPrompt(config QuizConfig) string {
+	return fmt.Sprintf(`You are an expert at creating educational quiz questions from podcast transcripts. Generate a mix of multiple choice, true/false, and open-ended questions.
 
 Rules:
 - Generate exactly %d questions total
-- %d multiple choice (%d options each), %d true/false, and %d open-ended question
-- Questions should test understanding of key concepts, not just recall
+- %d multiple choice (%d options each), %d true/false, and %d open-ended questions
+- Do not generate any question of a type with a count of 0
+- %s
 - For multiple choice: exactly %d options, only one correct
 - For true/false: exactly 2 options ("True" and "False")
-- For open-ended: no options needed
+- For open-ended: no options, but a "reference_answer" (a complete answer in 1-3 sentences) and %d to %d "key_points", the short ideas a good answer must cover, used to give partial credit
 - Questions should be clear and unambiguous
+- Every question must have a source: a quote of %d to %d consecutive words copied exactly from the transcript that supports the correct answer, and the [timestamp] of the line the quote starts in
 
 Return ONLY a valid JSON object with this exact structure:
 {
@@ -24,7 +38,8 @@ Return ONLY a valid JSON object with this exact structure:
         {"text": "Option B", "is_correct": true},
         {"text": "Option C", "is_correct": false},
         {"text": "Option D", "is_correct": false}
-      ]
+      ],
+      "source": {"quote": "the words of the transcript that answer the question", "start": "12:34"}
     },
     {
       "question_text": "The speaker mentioned X",
@@ -32,51 +47,67 @@ Return ONLY a valid JSON object with this exact structure:
       "options": [
         {"text": "True", "is_correct": true},
         {"text": "False", "is_correct": false}
-      ]
+      ],
+      "source": {"quote": "the words of the transcript that answer the question", "start": "3:05"}
     },
     {
       "question_text": "Explain the main concept discussed.",
       "type": "open_ended",
-			"options": []
+      "options": [],
+      "reference_answer": "A complete answer to the question.",
+      "key_points": ["First idea a good answer covers", "Second idea a good answer covers"],
+      "source": {"quote": "the words of the transcript that answer the question", "start": "41:10"}
     }
   ]
 }
 
 Do not include any markdown formatting, code blocks, or explanatory text. Return only the raw JSON object.`,
-	TotalQuestionsPerEpisode, MultipleChoiceCount, MultipleChoiceOptions,
-	TrueFalseCount, OpenEndedCount, MultipleChoiceOptions)
+		config.TotalQuestions(), config.MultipleChoice, MultipleChoiceOptions,
+		config.TrueFalse, config.OpenEnded, difficultyGuidelines[config.Difficulty], MultipleChoiceOptions,
+		MinKeyPoints, MaxKeyPoints, MinSourceQuoteWords, MaxSourceQuoteWords)
+}
 
 func GenerateQuestionsUserPrompt(transcriptText string) string {
-	return fmt.Sprintf("Generate quiz questions from this podcast transcript:\n\n%s", transcriptText)
+	return fmt.Sprintf("Generate quiz questions from this podcast transcript. Every line starts with a [timestamp] and the speaker:\n\n%s", transcriptText)
+}
+
+// RepairQuestionsUserPrompt lists the problems found in the generated questions
+func RepairQuestionsUserPrompt(problems []string) string {
+	return fmt.Sprintf(`Your questions have these problems:
+- %s
+
+Fix them and return the complete corrected set of questions, following all the rules. Return only the JSON object.`,
+		strings.Join(problems, "\n- "))
 }
 
-var EvaluateOpenEndedSystemPrompt = `You are evaluating a user's answer to an open-ended question. Determine if the answer demonstrates understanding of the key concepts.
+var EvaluateOpenEndedSystemPrompt = `You are grading a user's answer to an open-ended question about a podcast episode. Give partial credit for the key points the answer covers.
 
 Return a JSON object with this exact structure:
 {
-  "is_correct": true,
+  "score": 0.5,
+  "key_points_hit": ["Key point the answer covers"],
+  "key_points_missed": ["Key point the answer misses"],
   "feedback": "Your explanation here..."
 }
 
-Be encouraging but honest. If the answer is partially correct, set is_correct to true if they grasp the main concepts.`
-
-func EvaluateOpenEndedUserPrompt(questionText, userAnswer, correctAnswer string) string {
-	if correctAnswer != "" {
-		return fmt.Sprintf(`Question: %s
-
-Expected answer: %s
-
-User's answer: %s
-
-Evaluate if the user's answer demonstrates understanding of the topic and aligns with the expected answer. Return only the JSON object.`,
-			questionText, correctAnswer, userAnswer)
+Rules:
+- "score" is from 0 to 1: the share of the key points the answer covers, lowered for statements that contradict the expected answer
+- Copy the key points exactly as given, each one in either "key_points_hit" or "key_points_missed"
+- A key point is hit when the answer expresses the idea, in any words
+- Without key points, judge the answer against the expected answer, or against your understanding of the topic when there is none, and leave both lists empty
+- The feedback names what the answer got right and what it missed (1-2 sentences). Be encouraging but honest.`
+
+func EvaluateOpenEndedUserPrompt(questionText, userAnswer, referenceAnswer string, keyPoints []string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question: %s\n\n", questionText)
+	if referenceAnswer != "" {
+		fmt.Fprintf(&b, "Expected answer: %s\n\n", referenceAnswer)
 	}
-	return fmt.Sprintf(`Question: %s
-
-User's answer: %s
-
-Evaluate if this answer demonstrates understanding of the topic. Return only the JSON object.`,
-		questionText, userAnswer)
+	if len(keyPoints) > 0 {
+		fmt.Fprintf(&b, "Key points:\n- %s\n\n", strings.Join(keyPoints, "\n- "))
+	}
+	fmt.Fprintf(&b, "User's answer: %s\n\nGrade the user's answer. Return only the JSON object.", userAnswer)
+	return b.String()
 }
 
 var GenerateFeedbackSystemPrompt = `You are a helpful tutor providing personalized feedback on quiz answers about podcast content.
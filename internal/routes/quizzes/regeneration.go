@@ -0,0 +1,113 @@
+package quizzes
+
+import (
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// RegenerateQuestions regenerates the questions of every active question bank of an episode.
+// A bank used by sessions is replaced by its next version, so its questions and their answers
+// are kept and in-progress sessions continue with them. It's archived with the new version's
+// questions saved, so a failed generation leaves it active. An unused bank is regenerated in
+// place. Only admins may regenerate questions.
+func (s *QuizService) RegenerateQuestions(episodeID, userID int) ([]RegeneratedQuestionBank, *errors.ErrorResponse) {
+	isAdmin, err := s.repo.roles.IsAdmin(userID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to check user role",
+		}
+	}
+	if !isAdmin {
+		return nil, &errors.ErrorResponse{
+			Message: errors.Unauthorized,
+			Details: "Only admins can regenerate questions",
+		}
+	}
+
+	banks, err := s.repo.GetActiveQuestionBanksByEpisodeID(episodeID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch question banks",
+		}
+	}
+	if len(banks) == 0 {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "No questions to regenerate for this episode",
+		}
+	}
+
+	// Fail before changing any bank when questions can't be generated
+	if _, errResp := s.getTranscriptChunks(episodeID); errResp != nil {
+		return nil, errResp
+	}
+
+	regenerated := make([]RegeneratedQuestionBank, 0, len(banks))
+	for _, bank := range banks {
+		if bank.InUse {
+			replacement, errResp := s.replaceQuestionBank(bank)
+			if errResp != nil {
+				return nil, errResp
+			}
+			regenerated = append(regenerated, replacement)
+			continue
+		}
+
+		next, err := s.repo.BumpQuestionBankVersion(bank.ID)
+		if err != nil {
+			return nil, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to create the new question bank version",
+			}
+		}
+
+		// The new version has no questions, so callers fetching it meanwhile share this generation
+		questions, errResp := s.generateQuestionsOnce(next)
+		if errResp != nil {
+			return nil, errResp
+		}
+
+		regenerated = append(regenerated, RegeneratedQuestionBank{
+			QuestionBank: next,
+			Questions:    questions,
+		})
+	}
+
+	s.logger.Info("Questions regenerated", map[string]any{
+		"episode_id": episodeID,
+		"banks":      len(regenerated),
+	})
+
+	return regenerated, nil
+}
+
+// replaceQuestionBank generates the questions of the next version of a question bank used by
+// sessions, then archives the bank and saves the new version with its questions
+func (s *QuizService) replaceQuestionBank(bank QuestionBank) (RegeneratedQuestionBank, *errors.ErrorResponse) {
+	questions, errResp := s.buildQuestions(bank)
+	if errResp != nil {
+		return RegeneratedQuestionBank{}, errResp
+	}
+
+	next, savedQuestions, err := s.repo.ReplaceQuestionBank(bank.ID, questions)
+	if err != nil {
+		// The bank is no longer active when another regeneration replaced it first
+		if err.Error() == "no rows in result set" {
+			return RegeneratedQuestionBank{}, &errors.ErrorResponse{
+				Message: errors.DatabaseConflict,
+				Details: "The question bank was regenerated concurrently",
+			}
+		}
+		return RegeneratedQuestionBank{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to create the new question bank version",
+		}
+	}
+
+	return RegeneratedQuestionBank{
+		QuestionBank:   next,
+		ReplacedBankID: &bank.ID,
+		Questions:      savedQuestions,
+	}, nil
+}
@@ -0,0 +1,189 @@
+package quizzes
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
+	"cribeapp.com/cribe-server/internal/routes/transcripts"
+	"cribeapp.com/cribe-server/internal/routes/users"
+)
+
+// setupRegenerationService mocks a complete transcript and an LLM generating three questions.
+// User 1 is an admin.
+func setupRegenerationService() *QuizService {
+	svc := setupQuizService(&MockLLMClient{ChatResponse: makeLLMResponse(makeQuizJSON(3))}, &MockTranscriptRepository{
+		GetTranscriptByEpisodeIDFunc: func(int) (transcripts.Transcript, error) {
+			return transcripts.Transcript{ID: 1, Status: "complete"}, nil
+		},
+		GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
+			return quizChunks(quizTranscriptText), nil
+		},
+	})
+	svc.repo.roles.Executor.QueryItem = func(query string, args ...any) (users.Role, error) {
+		return users.Role{IsAdmin: args[0].(int) == 1}, nil
+	}
+	return svc
+}
+
+func TestQuizService_RegenerateQuestions(t *testing.T) {
+	t.Run("archives used banks and keeps in-progress sessions on their questions", func(t *testing.T) {
+		svc := setupRegenerationService()
+		started, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		unused, _ := svc.repo.GetOrCreateQuestionBank(1, QuizConfig{OpenEnded: 1, Difficulty: Hard})
+
+		banks, errResp := svc.RegenerateQuestions(1, 1)
+
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		if len(banks) != 2 {
+			t.Fatalf("expected 2 regenerated banks, got %d", len(banks))
+		}
+		if banks[0].ReplacedBankID == nil || *banks[0].ReplacedBankID != *started.Session.BankID || banks[0].Version != 2 || len(banks[0].Questions) != 3 {
+			t.Errorf("expected the used bank to be replaced by version 2, got %+v", banks[0])
+		}
+		if banks[1].ID != unused.ID || banks[1].ReplacedBankID != nil || banks[1].Version != 2 || len(banks[1].Questions) != 1 {
+			t.Errorf("expected the unused bank to be regenerated in place, got %+v", banks[1])
+		}
+
+		resumed, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		if resumed.Session.ID != started.Session.ID || resumed.Questions[0].ID != started.Questions[0].ID {
+			t.Errorf("expected the in-progress session with its original questions, got %+v", resumed)
+		}
+
+		if _, errResp := svc.UpdateSessionStatus(started.Session.ID, 2, Completed); errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		next, _ := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+		if *next.Session.BankID != banks[0].ID || next.Questions[0].ID != banks[0].Questions[0].ID {
+			t.Errorf("expected a new session on the new version, got %+v", next)
+		}
+	})
+
+	t.Run("clears an unused bank and shares its generation with callers fetching it", func(t *testing.T) {
+		svc := setupRegenerationService()
+		bank, _ := svc.repo.GetOrCreateQuestionBank(1, DefaultQuizConfig())
+		if _, errResp := svc.generateQuestionsOnce(bank); errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		client := &blockingLLMClient{release: make(chan struct{})}
+		svc.llmClient = client
+
+		var wg sync.WaitGroup
+		var regenerated []RegeneratedQuestionBank
+		var fetched []Question
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			regenerated, _ = svc.RegenerateQuestions(1, 1)
+		}()
+		go func() {
+			defer wg.Done()
+			// Fetch the bank once the regeneration cleared it
+			time.Sleep(50 * time.Millisecond)
+			fetched, _ = svc.generateQuestionsOnce(bank)
+		}()
+		time.Sleep(100 * time.Millisecond)
+		close(client.release)
+		wg.Wait()
+
+		if calls := client.calls.Load(); calls != 1 {
+			t.Errorf("got %d LLM calls, want 1", calls)
+		}
+		if len(regenerated) != 1 || regenerated[0].Version != 2 {
+			t.Errorf("expected the bank regenerated in place, got %+v", regenerated)
+		}
+		if len(fetched) != 3 {
+			t.Errorf("expected the caller to get the regenerated questions, got %d", len(fetched))
+		}
+	})
+
+	t.Run("keeps a used bank active when its replacement fails to generate", func(t *testing.T) {
+		svc := setupRegenerationService()
+		started, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		svc.llmClient = &MockLLMClient{ChatError: fmt.Errorf("LLM unavailable")}
+
+		if _, errResp := svc.RegenerateQuestions(1, 1); errResp == nil {
+			t.Fatal("expected an error")
+		}
+
+		banks, _ := svc.repo.GetActiveQuestionBanksByEpisodeID(1)
+		if len(banks) != 1 || banks[0].ID != *started.Session.BankID || banks[0].Version != 1 {
+			t.Errorf("expected the used bank to stay active, got %+v", banks)
+		}
+		questions, _ := svc.repo.GetQuestionsByBankID(*started.Session.BankID)
+		if len(questions) != len(started.Questions) {
+			t.Errorf("expected the used bank to keep its questions, got %d", len(questions))
+		}
+	})
+
+	t.Run("only admins can regenerate questions", func(t *testing.T) {
+		svc := setupRegenerationService()
+
+		_, errResp := svc.RegenerateQuestions(1, 2)
+
+		if errResp == nil || errResp.Message != errors.Unauthorized {
+			t.Errorf("expected unauthorized, got %v", errResp)
+		}
+	})
+
+	t.Run("returns not found for an episode without questions", func(t *testing.T) {
+		svc := setupRegenerationService()
+
+		_, errResp := svc.RegenerateQuestions(1, 1)
+
+		if errResp == nil || errResp.Message != errors.DatabaseNotFound {
+			t.Errorf("expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestQuizHandler_RegenerateQuestions(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		userID     int
+		wantStatus int
+	}{
+		{"regenerate", http.MethodPost, "/quizzes/episodes/1/questions/regenerate", 1, http.StatusOK},
+		{"not an admin", http.MethodPost, "/quizzes/episodes/1/questions/regenerate", 2, http.StatusForbidden},
+		{"wrong method", http.MethodGet, "/quizzes/episodes/1/questions/regenerate", 1, http.StatusMethodNotAllowed},
+		{"invalid episode ID", http.MethodPost, "/quizzes/episodes/abc/questions/regenerate", 1, http.StatusNotFound},
+		{"unknown sub-path", http.MethodPost, "/quizzes/episodes/1/questions", 1, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := setupRegenerationService()
+			if _, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil); errResp != nil {
+				t.Fatalf("unexpected error: %v", errResp.Details)
+			}
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			r = r.WithContext(context.WithValue(r.Context(), middlewares.UserIDContextKey, tt.userID))
+			NewQuizHandler(svc).HandleRequest(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
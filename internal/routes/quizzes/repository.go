@@ -1,26 +1,234 @@
 package quizzes
 
 import (
+	"errors"
+	"fmt"
+	"time"
+
 	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/routes/users"
 	"cribeapp.com/cribe-server/internal/utils"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrGenerationLockTimeout is returned when a question bank's generation lock isn't acquired in time
+var ErrGenerationLockTimeout = errors.New("timed out waiting for question generation lock")
+
+const (
+	// sessionAttemptConstraint keeps the attempt numbers of a user at an episode unique
+	sessionAttemptConstraint = "user_quiz_sessions_attempt_key"
+	// sessionAttemptRetries caps the retries of a session whose attempt number was taken concurrently
+	sessionAttemptRetries = 5
 )
 
 type QuizRepository struct {
-	questionRepo *utils.Repository[Question]
-	optionRepo   *utils.Repository[QuestionOption]
-	sessionRepo  *utils.Repository[UserQuizSession]
-	answerRepo   *utils.Repository[UserAnswer]
-	logger       *logger.ContextualLogger
+	questionRepo   *utils.Repository[Question]
+	optionRepo     *utils.Repository[QuestionOption]
+	bankRepo       *utils.Repository[QuestionBank]
+	sessionRepo    *utils.Repository[UserQuizSession]
+	answerRepo     *utils.Repository[UserAnswer]
+	preferenceRepo *utils.Repository[QuizPreferences]
+	roles          *users.RoleRepository
+	logger         *logger.ContextualLogger
+	// lockConn runs fn on a connection of its own, which holds a generation lock
+	lockConn func(fn func(repo *utils.Repository[generationLock]) error) error
 }
 
 func NewQuizRepository(options ...utils.Option[Question]) *QuizRepository {
 	return &QuizRepository{
-		questionRepo: utils.NewRepository(options...),
-		optionRepo:   utils.NewRepository[QuestionOption](),
-		sessionRepo:  utils.NewRepository[UserQuizSession](),
-		answerRepo:   utils.NewRepository[UserAnswer](),
-		logger:       logger.NewRepositoryLogger("QuizRepository"),
+		questionRepo:   utils.NewRepository(options...),
+		optionRepo:     utils.NewRepository[QuestionOption](),
+		bankRepo:       utils.NewRepository[QuestionBank](),
+		sessionRepo:    utils.NewRepository[UserQuizSession](),
+		answerRepo:     utils.NewRepository[UserAnswer](),
+		preferenceRepo: utils.NewRepository[QuizPreferences](),
+		roles:          users.NewRoleRepository(),
+		logger:         logger.NewRepositoryLogger("QuizRepository"),
+		lockConn:       utils.WithConnection[generationLock],
+	}
+}
+
+// withTx returns a copy of the repository whose queries run inside the transaction
+func (r *QuizRepository) withTx(tx utils.Transaction) *QuizRepository {
+	return &QuizRepository{
+		questionRepo:   r.questionRepo.WithTx(tx),
+		optionRepo:     r.optionRepo.WithTx(tx),
+		bankRepo:       r.bankRepo.WithTx(tx),
+		sessionRepo:    r.sessionRepo.WithTx(tx),
+		answerRepo:     r.answerRepo.WithTx(tx),
+		preferenceRepo: r.preferenceRepo.WithTx(tx),
+		roles:          r.roles,
+		logger:         r.logger,
+		lockConn:       r.lockConn,
+	}
+}
+
+// Question bank operations
+
+// GetOrCreateQuestionBank returns the active question bank of an episode for a configuration,
+// creating an empty one the first time the configuration is requested
+func (r *QuizRepository) GetOrCreateQuestionBank(episodeID int, config QuizConfig) (QuestionBank, error) {
+	r.logger.Debug("Fetching question bank", map[string]any{
+		"episode_id": episodeID,
+		"config":     config,
+	})
+
+	// The no-op update makes RETURNING yield the existing row on conflict
+	query := `
+		INSERT INTO question_banks (episode_id, multiple_choice, true_false, open_ended, difficulty)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (episode_id, multiple_choice, true_false, open_ended, difficulty) WHERE archived_at IS NULL
+		DO UPDATE SET episode_id = EXCLUDED.episode_id
+		RETURNING id, episode_id, multiple_choice, true_false, open_ended, difficulty, version, archived_at, created_at
+	`
+
+	result, err := r.bankRepo.Executor.QueryItem(query,
+		episodeID,
+		config.MultipleChoice,
+		config.TrueFalse,
+		config.OpenEnded,
+		config.Difficulty,
+	)
+	if err != nil {
+		r.logger.Error("Failed to get or create question bank", map[string]any{
+			"episode_id": episodeID,
+			"error":      err.Error(),
+		})
+		return QuestionBank{}, err
+	}
+
+	return result, nil
+}
+
+func (r *QuizRepository) GetQuestionBankByID(bankID int) (QuestionBank, error) {
+	r.logger.Debug("Fetching question bank by ID", map[string]any{
+		"bank_id": bankID,
+	})
+
+	query := `
+		SELECT id, episode_id, multiple_choice, true_false, open_ended, difficulty, version, archived_at, created_at
+		FROM question_banks
+		WHERE id = $1
+	`
+
+	result, err := r.bankRepo.Executor.QueryItem(query, bankID)
+	if err != nil {
+		r.logger.Error("Failed to fetch question bank", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return QuestionBank{}, err
+	}
+
+	return result, nil
+}
+
+// GetActiveQuestionBanksByEpisodeID returns the active question banks of an episode,
+// reporting whether sessions were started on them
+func (r *QuizRepository) GetActiveQuestionBanksByEpisodeID(episodeID int) ([]QuestionBank, error) {
+	r.logger.Debug("Fetching active question banks", map[string]any{
+		"episode_id": episodeID,
+	})
+
+	query := `
+		SELECT
+			b.id, b.episode_id, b.multiple_choice, b.true_false, b.open_ended, b.difficulty,
+			b.version, b.archived_at, b.created_at,
+			EXISTS (SELECT 1 FROM user_quiz_sessions s WHERE s.bank_id = b.id) as in_use
+		FROM question_banks b
+		WHERE b.episode_id = $1 AND b.archived_at IS NULL
+		ORDER BY b.id
+	`
+
+	result, err := r.bankRepo.Executor.QueryList(query, episodeID)
+	if err != nil {
+		r.logger.Error("Failed to fetch question banks", map[string]any{
+			"episode_id": episodeID,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ReplaceQuestionBank archives a question bank and creates its next version with the given
+// questions in one transaction, so the configuration keeps its active bank when saving fails
+func (r *QuizRepository) ReplaceQuestionBank(bankID int, questions []Question) (QuestionBank, []Question, error) {
+	r.logger.Debug("Replacing question bank", map[string]any{
+		"bank_id": bankID,
+		"count":   len(questions),
+	})
+
+	query := `
+		WITH archived AS (
+			UPDATE question_banks SET archived_at = NOW()
+			WHERE id = $1 AND archived_at IS NULL
+			RETURNING episode_id, multiple_choice, true_false, open_ended, difficulty, version
+		)
+		INSERT INTO question_banks (episode_id, multiple_choice, true_false, open_ended, difficulty, version)
+		SELECT episode_id, multiple_choice, true_false, open_ended, difficulty, version + 1
+		FROM archived
+		RETURNING id, episode_id, multiple_choice, true_false, open_ended, difficulty, version, archived_at, created_at
+	`
+
+	var next QuestionBank
+	var savedQuestions []Question
+	err := utils.RunInTransaction(r.bankRepo.Executor.Begin, func(tx utils.Transaction) error {
+		txRepo := r.withTx(tx)
+
+		var err error
+		next, err = txRepo.bankRepo.Executor.QueryItem(query, bankID)
+		if err != nil {
+			return err
+		}
+		savedQuestions, err = txRepo.createBankQuestions(next.ID, questions)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to replace question bank", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return QuestionBank{}, nil, err
+	}
+
+	return next, savedQuestions, nil
+}
+
+// BumpQuestionBankVersion increments the version of a question bank whose questions are
+// regenerated in place and clears its questions in one transaction, so they're generated again
+func (r *QuizRepository) BumpQuestionBankVersion(bankID int) (QuestionBank, error) {
+	r.logger.Debug("Bumping question bank version", map[string]any{
+		"bank_id": bankID,
+	})
+
+	query := `
+		UPDATE question_banks SET version = version + 1
+		WHERE id = $1 AND archived_at IS NULL
+		RETURNING id, episode_id, multiple_choice, true_false, open_ended, difficulty, version, archived_at, created_at
+	`
+
+	var result QuestionBank
+	err := utils.RunInTransaction(r.bankRepo.Executor.Begin, func(tx utils.Transaction) error {
+		txRepo := r.withTx(tx)
+
+		var err error
+		result, err = txRepo.bankRepo.Executor.QueryItem(query, bankID)
+		if err != nil {
+			return err
+		}
+		return txRepo.DeleteQuestionsByBankID(bankID)
+	})
+	if err != nil {
+		r.logger.Error("Failed to bump question bank version", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return QuestionBank{}, err
 	}
+
+	return result, nil
 }
 
 // Question operations
@@ -32,9 +240,13 @@ func (r *QuizRepository) CreateQuestion(question Question) (Question, error) {
 	})
 
 	query := `
-		INSERT INTO questions (episode_id, question_text, type, position)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, episode_id, question_text, type, position, created_at, updated_at
+		INSERT INTO questions (episode_id, question_text, type, position, bank_id,
+			source_start_position, source_end_position, source_start_time, source_end_time,
+			reference_answer, key_points)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id, episode_id, bank_id, question_text, type, position,
+			source_start_position, source_end_position, source_start_time, source_end_time,
+			reference_answer, key_points, created_at, updated_at
 	`
 
 	result, err := r.questionRepo.Executor.QueryItem(query,
@@ -42,6 +254,13 @@ func (r *QuizRepository) CreateQuestion(question Question) (Question, error) {
 		question.QuestionText,
 		question.Type,
 		question.Position,
+		question.BankID,
+		question.SourceStartPosition,
+		question.SourceEndPosition,
+		question.SourceStartTime,
+		question.SourceEndTime,
+		question.ReferenceAnswer,
+		question.KeyPoints,
 	)
 	if err != nil {
 		r.logger.Error("Failed to create question", map[string]any{
@@ -87,7 +306,10 @@ func (r *QuizRepository) GetQuestionsByEpisodeID(episodeID int) ([]Question, err
 
 	query := `
 		SELECT
-			q.id, q.episode_id, q.question_text, q.type, q.position, q.created_at, q.updated_at,
+			q.id, q.episode_id, q.bank_id, q.question_text, q.type, q.position,
+			q.source_start_position, q.source_end_position, q.source_start_time, q.source_end_time,
+			q.reference_answer, q.key_points,
+			q.created_at, q.updated_at,
 			COALESCE(json_agg(
 				json_build_object(
 					'id', qo.id,
@@ -102,7 +324,7 @@ func (r *QuizRepository) GetQuestionsByEpisodeID(episodeID int) ([]Question, err
 		LEFT JOIN question_options qo ON q.id = qo.question_id
 		WHERE q.episode_id = $1
 		GROUP BY q.id
-		ORDER BY q.position
+		ORDER BY q.bank_id, q.position
 	`
 
 	result, err := r.questionRepo.Executor.QueryList(query, episodeID)
@@ -117,6 +339,46 @@ func (r *QuizRepository) GetQuestionsByEpisodeID(episodeID int) ([]Question, err
 	return result, nil
 }
 
+func (r *QuizRepository) GetQuestionsByBankID(bankID int) ([]Question, error) {
+	r.logger.Debug("Fetching questions by bank ID", map[string]any{
+		"bank_id": bankID,
+	})
+
+	query := `
+		SELECT
+			q.id, q.episode_id, q.bank_id, q.question_text, q.type, q.position,
+			q.source_start_position, q.source_end_position, q.source_start_time, q.source_end_time,
+			q.reference_answer, q.key_points,
+			q.created_at, q.updated_at,
+			COALESCE(json_agg(
+				json_build_object(
+					'id', qo.id,
+					'question_id', qo.question_id,
+					'option_text', qo.option_text,
+					'position', qo.position,
+					'is_correct', qo.is_correct,
+					'created_at', qo.created_at
+				) ORDER BY qo.position
+			) FILTER (WHERE qo.id IS NOT NULL), '[]') as options
+		FROM questions q
+		LEFT JOIN question_options qo ON q.id = qo.question_id
+		WHERE q.bank_id = $1
+		GROUP BY q.id
+		ORDER BY q.position
+	`
+
+	result, err := r.questionRepo.Executor.QueryList(query, bankID)
+	if err != nil {
+		r.logger.Error("Failed to fetch questions", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (r *QuizRepository) GetQuestionByID(questionID int) (Question, error) {
 	r.logger.Debug("Fetching question by ID", map[string]any{
 		"question_id": questionID,
@@ -124,7 +386,10 @@ func (r *QuizRepository) GetQuestionByID(questionID int) (Question, error) {
 
 	query := `
 		SELECT
-			q.id, q.episode_id, q.question_text, q.type, q.position, q.created_at, q.updated_at,
+			q.id, q.episode_id, q.bank_id, q.question_text, q.type, q.position,
+			q.source_start_position, q.source_end_position, q.source_start_time, q.source_end_time,
+			q.reference_answer, q.key_points,
+			q.created_at, q.updated_at,
 			COALESCE(json_agg(
 				json_build_object(
 					'id', qo.id,
@@ -171,8 +436,87 @@ func (r *QuizRepository) DeleteQuestionsByEpisodeID(episodeID int) error {
 	return nil
 }
 
+func (r *QuizRepository) DeleteQuestionsByBankID(bankID int) error {
+	r.logger.Debug("Deleting questions of bank", map[string]any{
+		"bank_id": bankID,
+	})
+
+	query := `DELETE FROM questions WHERE bank_id = $1`
+	err := r.questionRepo.Executor.Exec(query, bankID)
+	if err != nil {
+		r.logger.Error("Failed to delete questions", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// ReplaceBankQuestions replaces the questions of a bank with the given questions and their
+// options in one transaction, so a failed save never leaves a partially generated bank
+func (r *QuizRepository) ReplaceBankQuestions(bankID int, questions []Question) ([]Question, error) {
+	r.logger.Debug("Replacing questions of bank", map[string]any{
+		"bank_id": bankID,
+		"count":   len(questions),
+	})
+
+	var savedQuestions []Question
+	err := utils.RunInTransaction(r.questionRepo.Executor.Begin, func(tx utils.Transaction) error {
+		txRepo := r.withTx(tx)
+
+		if err := txRepo.DeleteQuestionsByBankID(bankID); err != nil {
+			return err
+		}
+
+		var err error
+		savedQuestions, err = txRepo.createBankQuestions(bankID, questions)
+		return err
+	})
+	if err != nil {
+		r.logger.Error("Failed to replace questions of bank", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return nil, err
+	}
+
+	return savedQuestions, nil
+}
+
+// createBankQuestions creates the questions of a bank with their options
+func (r *QuizRepository) createBankQuestions(bankID int, questions []Question) ([]Question, error) {
+	savedQuestions := []Question{}
+	for _, question := range questions {
+		question.BankID = bankID
+		savedQuestion, err := r.CreateQuestion(question)
+		if err != nil {
+			return nil, err
+		}
+
+		savedOptions := []QuestionOption{}
+		for _, option := range question.Options {
+			option.QuestionID = savedQuestion.ID
+			savedOption, err := r.CreateQuestionOption(option)
+			if err != nil {
+				return nil, err
+			}
+			savedOptions = append(savedOptions, savedOption)
+		}
+
+		savedQuestion.Options = savedOptions
+		savedQuestions = append(savedQuestions, savedQuestion)
+	}
+
+	return savedQuestions, nil
+}
+
 // Session operations
 
+// CreateSession starts the next attempt of a user at an episode. The attempt number is taken
+// from the previous attempts; when a concurrent start takes it first, the unique attempt
+// constraint rejects the insert and it's retried with the next number.
 func (r *QuizRepository) CreateSession(session UserQuizSession) (UserQuizSession, error) {
 	r.logger.Debug("Creating quiz session", map[string]any{
 		"user_id":    session.UserID,
@@ -181,37 +525,52 @@ func (r *QuizRepository) CreateSession(session UserQuizSession) (UserQuizSession
 
 	query := `
 		WITH inserted_session AS (
-			INSERT INTO user_quiz_sessions (user_id, episode_id, status, total_questions, answered_questions, correct_answers)
-			VALUES ($1, $2, $3, $4, $5, $6)
-			RETURNING id, user_id, episode_id, status, total_questions, answered_questions, correct_answers, started_at, completed_at, updated_at
+			INSERT INTO user_quiz_sessions (user_id, episode_id, status, total_questions, answered_questions, correct_answers, bank_id, shuffle_seed, attempt)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8,
+				(SELECT COALESCE(MAX(attempt), 0) + 1 FROM user_quiz_sessions WHERE user_id = $1 AND episode_id = $2))
+			RETURNING id, user_id, episode_id, bank_id, attempt, shuffle_seed, status, total_questions, answered_questions, correct_answers, score, started_at, completed_at, updated_at
 		)
 		SELECT
-			s.id, s.user_id, s.episode_id,
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
 			e.name as episode_name,
 			p.name as podcast_name,
-			s.status, s.total_questions, s.answered_questions, s.correct_answers,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
 			s.started_at, s.completed_at, s.updated_at
 		FROM inserted_session s
 		JOIN episodes e ON s.episode_id = e.id
 		JOIN podcasts p ON e.podcast_id = p.id
 	`
 
-	result, err := r.sessionRepo.Executor.QueryItem(query,
-		session.UserID,
-		session.EpisodeID,
-		session.Status,
-		session.TotalQuestions,
-		session.AnsweredQuestions,
-		session.CorrectAnswers,
-	)
-	if err != nil {
+	for retry := 0; ; retry++ {
+		result, err := r.sessionRepo.Executor.QueryItem(query,
+			session.UserID,
+			session.EpisodeID,
+			session.Status,
+			session.TotalQuestions,
+			session.AnsweredQuestions,
+			session.CorrectAnswers,
+			session.BankID,
+			session.ShuffleSeed,
+		)
+		if err == nil {
+			return result, nil
+		}
+
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" && pgErr.ConstraintName == sessionAttemptConstraint &&
+			retry < sessionAttemptRetries {
+			r.logger.Debug("Attempt number taken by a concurrent session, retrying", map[string]any{
+				"user_id":    session.UserID,
+				"episode_id": session.EpisodeID,
+			})
+			continue
+		}
+
 		r.logger.Error("Failed to create session", map[string]any{
 			"error": err.Error(),
 		})
 		return UserQuizSession{}, err
 	}
-
-	return result, nil
 }
 
 func (r *QuizRepository) GetSessionByID(sessionID int) (UserQuizSession, error) {
@@ -221,10 +580,10 @@ func (r *QuizRepository) GetSessionByID(sessionID int) (UserQuizSession, error)
 
 	query := `
 		SELECT
-			s.id, s.user_id, s.episode_id,
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
 			e.name as episode_name,
 			p.name as podcast_name,
-			s.status, s.total_questions, s.answered_questions, s.correct_answers,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
 			s.started_at, s.completed_at, s.updated_at
 		FROM user_quiz_sessions s
 		JOIN episodes e ON s.episode_id = e.id
@@ -253,10 +612,10 @@ func (r *QuizRepository) GetActiveSessionByUserAndEpisode(userID, episodeID int)
 	// Get the most recent session (completed or in_progress)
 	query := `
 		SELECT
-			s.id, s.user_id, s.episode_id,
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
 			e.name as episode_name,
 			p.name as podcast_name,
-			s.status, s.total_questions, s.answered_questions, s.correct_answers,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
 			s.started_at, s.completed_at, s.updated_at
 		FROM user_quiz_sessions s
 		JOIN episodes e ON s.episode_id = e.id
@@ -279,6 +638,55 @@ func (r *QuizRepository) GetActiveSessionByUserAndEpisode(userID, episodeID int)
 	return result, nil
 }
 
+// GetLatestSessionByUserAndBank returns the most recent session of a user on a question bank.
+// In-progress sessions on archived versions of the bank count too, so they are resumed with
+// their original questions.
+func (r *QuizRepository) GetLatestSessionByUserAndBank(userID, bankID int) (UserQuizSession, error) {
+	r.logger.Debug("Fetching session for user and bank", map[string]any{
+		"user_id": userID,
+		"bank_id": bankID,
+	})
+
+	query := `
+		SELECT
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
+			e.name as episode_name,
+			p.name as podcast_name,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
+			s.started_at, s.completed_at, s.updated_at
+		FROM user_quiz_sessions s
+		JOIN episodes e ON s.episode_id = e.id
+		JOIN podcasts p ON e.podcast_id = p.id
+		WHERE s.user_id = $1 AND (
+			s.bank_id = $2 OR (s.status = 'in_progress' AND s.bank_id IN (
+				SELECT old.id
+				FROM question_banks old
+				JOIN question_banks cur ON cur.id = $2
+				WHERE old.archived_at IS NOT NULL
+					AND old.episode_id = cur.episode_id
+					AND old.multiple_choice = cur.multiple_choice
+					AND old.true_false = cur.true_false
+					AND old.open_ended = cur.open_ended
+					AND old.difficulty = cur.difficulty
+			))
+		)
+		ORDER BY s.started_at DESC
+		LIMIT 1
+	`
+
+	result, err := r.sessionRepo.Executor.QueryItem(query, userID, bankID)
+	if err != nil {
+		r.logger.Error("Failed to fetch session", map[string]any{
+			"user_id": userID,
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return UserQuizSession{}, err
+	}
+
+	return result, nil
+}
+
 func (r *QuizRepository) GetSessionsByUserID(userID int) ([]UserQuizSession, error) {
 	r.logger.Debug("Fetching all sessions for user", map[string]any{
 		"user_id": userID,
@@ -286,10 +694,10 @@ func (r *QuizRepository) GetSessionsByUserID(userID int) ([]UserQuizSession, err
 
 	query := `
 		SELECT
-			s.id, s.user_id, s.episode_id,
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
 			e.name as episode_name,
 			p.name as podcast_name,
-			s.status, s.total_questions, s.answered_questions, s.correct_answers,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
 			s.started_at, s.completed_at, s.updated_at
 		FROM user_quiz_sessions s
 		JOIN episodes e ON s.episode_id = e.id
@@ -310,6 +718,41 @@ func (r *QuizRepository) GetSessionsByUserID(userID int) ([]UserQuizSession, err
 	return result, nil
 }
 
+// GetSessionsByUserAndEpisode returns the attempts of a user at the quiz of an episode,
+// newest first
+func (r *QuizRepository) GetSessionsByUserAndEpisode(userID, episodeID int) ([]UserQuizSession, error) {
+	r.logger.Debug("Fetching sessions for user and episode", map[string]any{
+		"user_id":    userID,
+		"episode_id": episodeID,
+	})
+
+	query := `
+		SELECT
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
+			e.name as episode_name,
+			p.name as podcast_name,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
+			s.started_at, s.completed_at, s.updated_at
+		FROM user_quiz_sessions s
+		JOIN episodes e ON s.episode_id = e.id
+		JOIN podcasts p ON e.podcast_id = p.id
+		WHERE s.user_id = $1 AND s.episode_id = $2
+		ORDER BY s.attempt DESC
+	`
+
+	result, err := r.sessionRepo.Executor.QueryList(query, userID, episodeID)
+	if err != nil {
+		r.logger.Error("Failed to fetch sessions for episode", map[string]any{
+			"user_id":    userID,
+			"episode_id": episodeID,
+			"error":      err.Error(),
+		})
+		return nil, err
+	}
+
+	return result, nil
+}
+
 func (r *QuizRepository) UpdateSession(session UserQuizSession) error {
 	r.logger.Debug("Updating session", map[string]any{
 		"session_id": session.ID,
@@ -317,14 +760,15 @@ func (r *QuizRepository) UpdateSession(session UserQuizSession) error {
 
 	query := `
 		UPDATE user_quiz_sessions
-		SET status = $1, answered_questions = $2, correct_answers = $3, completed_at = $4, updated_at = NOW()
-		WHERE id = $5
+		SET status = $1, answered_questions = $2, correct_answers = $3, score = $4, completed_at = $5, updated_at = NOW()
+		WHERE id = $6
 	`
 
 	err := r.sessionRepo.Executor.Exec(query,
 		session.Status,
 		session.AnsweredQuestions,
 		session.CorrectAnswers,
+		session.Score,
 		session.CompletedAt,
 		session.ID,
 	)
@@ -371,9 +815,11 @@ func (r *QuizRepository) CreateAnswer(answer UserAnswer) (UserAnswer, error) {
 	})
 
 	query := `
-		INSERT INTO user_answers (session_id, question_id, user_id, selected_option_id, text_answer, is_correct, feedback)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, session_id, question_id, user_id, selected_option_id, text_answer, is_correct, feedback, answered_at
+		INSERT INTO user_answers (session_id, question_id, user_id, selected_option_id, text_answer, is_correct, score,
+			key_points_hit, key_points_missed, feedback)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, session_id, question_id, user_id, selected_option_id, text_answer, is_correct, score,
+			key_points_hit, key_points_missed, feedback, answered_at
 	`
 
 	result, err := r.answerRepo.Executor.QueryItem(query,
@@ -383,6 +829,9 @@ func (r *QuizRepository) CreateAnswer(answer UserAnswer) (UserAnswer, error) {
 		answer.SelectedOptionID,
 		answer.TextAnswer,
 		answer.IsCorrect,
+		answer.Score,
+		answer.KeyPointsHit,
+		answer.KeyPointsMissed,
 		answer.Feedback,
 	)
 	if err != nil {
@@ -395,13 +844,90 @@ func (r *QuizRepository) CreateAnswer(answer UserAnswer) (UserAnswer, error) {
 	return result, nil
 }
 
+// RecordAnswer adds an answer to the counters of its session and completes the session with
+// its last answer. The counters are incremented on the stored row, so answers saved at the
+// same time all count; there are no rows when the session is no longer in progress.
+func (r *QuizRepository) RecordAnswer(answer UserAnswer) (UserQuizSession, error) {
+	r.logger.Debug("Recording answer in session", map[string]any{
+		"session_id": answer.SessionID,
+	})
+
+	correct := 0
+	if answer.IsCorrect {
+		correct = 1
+	}
+
+	query := `
+		WITH updated_session AS (
+			UPDATE user_quiz_sessions
+			SET answered_questions = answered_questions + 1,
+				correct_answers = correct_answers + $2,
+				score = score + $3,
+				status = CASE WHEN answered_questions + 1 >= total_questions THEN 'completed' ELSE status END,
+				completed_at = CASE WHEN answered_questions + 1 >= total_questions THEN NOW() ELSE completed_at END,
+				updated_at = NOW()
+			WHERE id = $1 AND status = 'in_progress'
+			RETURNING id, user_id, episode_id, bank_id, attempt, shuffle_seed, status, total_questions, answered_questions, correct_answers, score, started_at, completed_at, updated_at
+		)
+		SELECT
+			s.id, s.user_id, s.episode_id, s.bank_id, s.attempt, s.shuffle_seed,
+			e.name as episode_name,
+			p.name as podcast_name,
+			s.status, s.total_questions, s.answered_questions, s.correct_answers, s.score,
+			s.started_at, s.completed_at, s.updated_at
+		FROM updated_session s
+		JOIN episodes e ON s.episode_id = e.id
+		JOIN podcasts p ON e.podcast_id = p.id
+	`
+
+	result, err := r.sessionRepo.Executor.QueryItem(query, answer.SessionID, correct, answer.Score)
+	if err != nil {
+		r.logger.Error("Failed to record answer in session", map[string]any{
+			"session_id": answer.SessionID,
+			"error":      err.Error(),
+		})
+		return UserQuizSession{}, err
+	}
+
+	return result, nil
+}
+
+// SaveAnswer creates an answer and records it in the counters of its session in one
+// transaction, so the session stats always match the saved answers
+func (r *QuizRepository) SaveAnswer(answer UserAnswer) (UserAnswer, UserQuizSession, error) {
+	var savedAnswer UserAnswer
+	var session UserQuizSession
+	err := utils.RunInTransaction(r.answerRepo.Executor.Begin, func(tx utils.Transaction) error {
+		txRepo := r.withTx(tx)
+
+		result, err := txRepo.CreateAnswer(answer)
+		if err != nil {
+			return err
+		}
+
+		session, err = txRepo.RecordAnswer(answer)
+		if err != nil {
+			return err
+		}
+
+		savedAnswer = result
+		return nil
+	})
+	if err != nil {
+		return UserAnswer{}, UserQuizSession{}, err
+	}
+
+	return savedAnswer, session, nil
+}
+
 func (r *QuizRepository) GetAnswersBySessionID(sessionID int) ([]UserAnswer, error) {
 	r.logger.Debug("Fetching answers by session ID", map[string]any{
 		"session_id": sessionID,
 	})
 
 	query := `
-		SELECT id, session_id, question_id, user_id, selected_option_id, text_answer, is_correct, feedback, answered_at
+		SELECT id, session_id, question_id, user_id, selected_option_id, text_answer, is_correct, score,
+			key_points_hit, key_points_missed, feedback, answered_at
 		FROM user_answers
 		WHERE session_id = $1
 		ORDER BY answered_at
@@ -426,7 +952,8 @@ func (r *QuizRepository) GetAnswerBySessionAndQuestion(sessionID, questionID int
 	})
 
 	query := `
-		SELECT id, session_id, question_id, user_id, selected_option_id, text_answer, is_correct, feedback, answered_at
+		SELECT id, session_id, question_id, user_id, selected_option_id, text_answer, is_correct, score,
+			key_points_hit, key_points_missed, feedback, answered_at
 		FROM user_answers
 		WHERE session_id = $1 AND question_id = $2
 	`
@@ -443,3 +970,103 @@ func (r *QuizRepository) GetAnswerBySessionAndQuestion(sessionID, questionID int
 
 	return result, nil
 }
+
+// Preference operations
+
+func (r *QuizRepository) GetQuizPreferences(userID int) (QuizPreferences, error) {
+	r.logger.Debug("Fetching quiz preferences", map[string]any{
+		"user_id": userID,
+	})
+
+	query := `
+		SELECT user_id, multiple_choice, true_false, open_ended, difficulty, updated_at
+		FROM user_quiz_preferences
+		WHERE user_id = $1
+	`
+
+	result, err := r.preferenceRepo.Executor.QueryItem(query, userID)
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch quiz preferences", map[string]any{
+				"user_id": userID,
+				"error":   err.Error(),
+			})
+		}
+		return QuizPreferences{}, err
+	}
+
+	return result, nil
+}
+
+func (r *QuizRepository) SaveQuizPreferences(userID int, config QuizConfig) (QuizPreferences, error) {
+	r.logger.Debug("Saving quiz preferences", map[string]any{
+		"user_id": userID,
+	})
+
+	query := `
+		INSERT INTO user_quiz_preferences (user_id, multiple_choice, true_false, open_ended, difficulty)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id) DO UPDATE SET
+			multiple_choice = EXCLUDED.multiple_choice,
+			true_false = EXCLUDED.true_false,
+			open_ended = EXCLUDED.open_ended,
+			difficulty = EXCLUDED.difficulty,
+			updated_at = NOW()
+		RETURNING user_id, multiple_choice, true_false, open_ended, difficulty, updated_at
+	`
+
+	result, err := r.preferenceRepo.Executor.QueryItem(query,
+		userID,
+		config.MultipleChoice,
+		config.TrueFalse,
+		config.OpenEnded,
+		config.Difficulty,
+	)
+	if err != nil {
+		r.logger.Error("Failed to save quiz preferences", map[string]any{
+			"user_id": userID,
+			"error":   err.Error(),
+		})
+		return QuizPreferences{}, err
+	}
+
+	return result, nil
+}
+
+// Generation lock operations
+
+// LockQuestionBankGeneration runs fn holding the Postgres advisory lock guarding the question
+// generation of a bank, waiting up to timeout for the caller holding it. The lock is taken in a
+// transaction on a connection of its own, so it excludes callers of this process as well as other
+// instances, and it's released when fn returns.
+func (r *QuizRepository) LockQuestionBankGeneration(bankID int, timeout time.Duration, fn func() error) error {
+	err := r.lockConn(func(lockRepo *utils.Repository[generationLock]) error {
+		return utils.RunInTransaction(lockRepo.Executor.Begin, func(tx utils.Transaction) error {
+			txRepo := lockRepo.WithTx(tx)
+
+			// lock_timeout doesn't accept parameters; the value is a formatted integer
+			if err := txRepo.Executor.Exec(fmt.Sprintf("SET LOCAL lock_timeout = %d", timeout.Milliseconds())); err != nil {
+				return err
+			}
+			query := `SELECT pg_advisory_xact_lock(hashtext('question_bank_generation'), $1)`
+			if err := txRepo.Executor.Exec(query, bankID); err != nil {
+				var pgErr *pgconn.PgError
+				if errors.As(err, &pgErr) && pgErr.Code == "55P03" {
+					return ErrGenerationLockTimeout
+				}
+				return err
+			}
+
+			return fn()
+		})
+	})
+	if err != nil {
+		r.logger.Error("Failed to run question generation under lock", map[string]any{
+			"bank_id": bankID,
+			"error":   err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
@@ -1,11 +1,17 @@
 package quizzes
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"cribeapp.com/cribe-server/internal/core/logger"
 	"cribeapp.com/cribe-server/internal/utils"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/pashagolub/pgxmock/v4"
 )
 
 var repo = NewMockQuizRepository()
@@ -212,6 +218,74 @@ func TestQuizRepository_CreateSession(t *testing.T) {
 	})
 }
 
+func TestQuizRepository_CreateSession_Concurrent(t *testing.T) {
+	// attempts stands in for the table; both creates read it before either inserts
+	var mu sync.Mutex
+	attempts := map[int]bool{}
+	var read sync.WaitGroup
+	read.Add(2)
+	var reads atomic.Int32
+
+	repo := NewMockQuizRepository()
+	repo.sessionRepo.Executor.QueryItem = func(query string, args ...any) (UserQuizSession, error) {
+		mu.Lock()
+		next := len(attempts) + 1
+		mu.Unlock()
+		if reads.Add(1) <= 2 {
+			read.Done()
+			read.Wait()
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if attempts[next] {
+			return UserQuizSession{}, &pgconn.PgError{Code: "23505", ConstraintName: "user_quiz_sessions_attempt_key"}
+		}
+		attempts[next] = true
+		return UserQuizSession{UserID: args[0].(int), EpisodeID: args[1].(int), Attempt: next}, nil
+	}
+
+	var wg sync.WaitGroup
+	results := make([]UserQuizSession, 2)
+	errs := make([]error, 2)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = repo.CreateSession(UserQuizSession{UserID: 3, EpisodeID: 5, Status: InProgress})
+		}()
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+	}
+	if results[0].Attempt == results[1].Attempt {
+		t.Errorf("Expected distinct attempts, both got %d", results[0].Attempt)
+	}
+	if !attempts[1] || !attempts[2] {
+		t.Errorf("Expected attempts 1 and 2, got %v", attempts)
+	}
+}
+
+func TestQuizRepository_CreateSession_OtherConflict(t *testing.T) {
+	repo := NewMockQuizRepository()
+	calls := 0
+	repo.sessionRepo.Executor.QueryItem = func(query string, args ...any) (UserQuizSession, error) {
+		calls++
+		return UserQuizSession{}, &pgconn.PgError{Code: "23505", ConstraintName: "user_quiz_sessions_pkey"}
+	}
+
+	if _, err := repo.CreateSession(UserQuizSession{UserID: 3, EpisodeID: 5, Status: InProgress}); err == nil {
+		t.Fatal("Expected an error")
+	}
+	if calls != 1 {
+		t.Errorf("Expected no retry for another constraint, got %d calls", calls)
+	}
+}
+
 func TestQuizRepository_GetSessionByID(t *testing.T) {
 	t.Run("should get a session by ID", func(t *testing.T) {
 		session, err := repo.GetSessionByID(1)
@@ -469,3 +543,265 @@ func TestQuizRepository_DeleteSession(t *testing.T) {
 		}
 	})
 }
+
+// anyArgs matches n query arguments of any value
+func anyArgs(n int) []any {
+	args := make([]any, n)
+	for i := range args {
+		args[i] = pgxmock.AnyArg()
+	}
+	return args
+}
+
+func TestQuizRepository_ReplaceBankQuestions(t *testing.T) {
+	questions := []Question{
+		{
+			EpisodeID:    1,
+			QuestionText: "Is Go compiled?",
+			Type:         TrueFalse,
+			Options: []QuestionOption{
+				{OptionText: "True", Position: 0, IsCorrect: true},
+				{OptionText: "False", Position: 1},
+			},
+		},
+	}
+
+	t.Run("should commit the questions and options together", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		txRepo := NewMockQuizRepository()
+		txRepo.questionRepo.Executor.Begin = utils.NewDatabase[Question](conn).Begin
+
+		conn.ExpectBegin()
+		conn.ExpectExec("DELETE FROM questions").WithArgs(7).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		conn.ExpectQuery("INSERT INTO questions").WithArgs(anyArgs(11)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "episode_id", "bank_id", "question_text", "type"}).AddRow(10, 1, 7, "Is Go compiled?", TrueFalse))
+		conn.ExpectQuery("INSERT INTO question_options").WithArgs(anyArgs(4)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "question_id", "option_text", "is_correct"}).AddRow(20, 10, "True", true))
+		conn.ExpectQuery("INSERT INTO question_options").WithArgs(anyArgs(4)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "question_id", "option_text", "is_correct"}).AddRow(21, 10, "False", false))
+		conn.ExpectCommit()
+
+		saved, err := txRepo.ReplaceBankQuestions(7, questions)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if len(saved) != 1 || saved[0].ID != 10 {
+			t.Fatalf("Expected question 10 to be saved, got %+v", saved)
+		}
+		if len(saved[0].Options) != 2 {
+			t.Errorf("Expected 2 options, got %d", len(saved[0].Options))
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("should roll back when an option fails to save", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		txRepo := NewMockQuizRepository()
+		txRepo.questionRepo.Executor.Begin = utils.NewDatabase[Question](conn).Begin
+
+		conn.ExpectBegin()
+		conn.ExpectExec("DELETE FROM questions").WithArgs(7).WillReturnResult(pgxmock.NewResult("DELETE", 2))
+		conn.ExpectQuery("INSERT INTO questions").WithArgs(anyArgs(11)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "episode_id", "bank_id", "question_text", "type"}).AddRow(10, 1, 7, "Is Go compiled?", TrueFalse))
+		conn.ExpectQuery("INSERT INTO question_options").WithArgs(anyArgs(4)...).WillReturnError(fmt.Errorf("option save failed"))
+		conn.ExpectRollback()
+
+		_, err := txRepo.ReplaceBankQuestions(7, questions)
+		if err == nil {
+			t.Error("Expected error, got nil")
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+}
+
+func TestQuizRepository_ReplaceQuestionBank(t *testing.T) {
+	questions := []Question{{EpisodeID: 1, QuestionText: "Is Go compiled?", Type: OpenEnded}}
+	bankColumns := []string{"id", "episode_id", "version"}
+
+	t.Run("should archive the bank with the new version's questions saved", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		txRepo := NewMockQuizRepository()
+		txRepo.bankRepo.Executor.Begin = utils.NewDatabase[QuestionBank](conn).Begin
+
+		conn.ExpectBegin()
+		conn.ExpectQuery("archived_at = NOW()").WithArgs(7).
+			WillReturnRows(pgxmock.NewRows(bankColumns).AddRow(8, 1, 2))
+		conn.ExpectQuery("INSERT INTO questions").WithArgs(anyArgs(11)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "bank_id", "question_text"}).AddRow(10, 8, "Is Go compiled?"))
+		conn.ExpectCommit()
+
+		next, saved, err := txRepo.ReplaceQuestionBank(7, questions)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if next.ID != 8 || next.Version != 2 {
+			t.Errorf("Expected version 2 in bank 8, got %+v", next)
+		}
+		if len(saved) != 1 || saved[0].BankID != 8 {
+			t.Errorf("Expected the question saved in bank 8, got %+v", saved)
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("should roll back the archive when a question fails to save", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		txRepo := NewMockQuizRepository()
+		txRepo.bankRepo.Executor.Begin = utils.NewDatabase[QuestionBank](conn).Begin
+
+		conn.ExpectBegin()
+		conn.ExpectQuery("archived_at = NOW()").WithArgs(7).
+			WillReturnRows(pgxmock.NewRows(bankColumns).AddRow(8, 1, 2))
+		conn.ExpectQuery("INSERT INTO questions").WithArgs(anyArgs(11)...).WillReturnError(fmt.Errorf("question save failed"))
+		conn.ExpectRollback()
+
+		if _, _, err := txRepo.ReplaceQuestionBank(7, questions); err == nil {
+			t.Error("Expected error, got nil")
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+}
+
+func TestQuizRepository_SaveAnswer(t *testing.T) {
+	answer := UserAnswer{SessionID: 1, QuestionID: 2, UserID: 3, IsCorrect: true, Score: 1}
+	sessionColumns := []string{"id", "status", "answered_questions", "correct_answers", "score", "total_questions"}
+
+	t.Run("should commit the answer with the session counters incremented in SQL", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		txRepo := NewMockQuizRepository()
+		txRepo.answerRepo.Executor.Begin = utils.NewDatabase[UserAnswer](conn).Begin
+
+		conn.ExpectBegin()
+		conn.ExpectQuery("INSERT INTO user_answers").WithArgs(anyArgs(10)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "session_id", "question_id", "user_id", "is_correct"}).AddRow(5, 1, 2, 3, true))
+		conn.ExpectQuery(`answered_questions = answered_questions \+ 1`).WithArgs(1, 1, 1.0).
+			WillReturnRows(pgxmock.NewRows(sessionColumns).AddRow(1, InProgress, 2, 2, 2.0, 3))
+		conn.ExpectCommit()
+
+		saved, session, err := txRepo.SaveAnswer(answer)
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if saved.ID != 5 {
+			t.Errorf("Expected answer ID 5, got %d", saved.ID)
+		}
+		if session.AnsweredQuestions != 2 || session.Score != 2 {
+			t.Errorf("Expected the stored session counters, got %+v", session)
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("should roll back the answer when the session is no longer in progress", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		txRepo := NewMockQuizRepository()
+		txRepo.answerRepo.Executor.Begin = utils.NewDatabase[UserAnswer](conn).Begin
+
+		conn.ExpectBegin()
+		conn.ExpectQuery("INSERT INTO user_answers").WithArgs(anyArgs(10)...).
+			WillReturnRows(pgxmock.NewRows([]string{"id", "session_id", "question_id", "user_id", "is_correct"}).AddRow(5, 1, 2, 3, true))
+		conn.ExpectQuery("UPDATE user_quiz_sessions").WithArgs(anyArgs(3)...).WillReturnRows(pgxmock.NewRows(sessionColumns))
+		conn.ExpectRollback()
+
+		_, _, err := txRepo.SaveAnswer(answer)
+		if err == nil || err.Error() != "no rows in result set" {
+			t.Errorf("Expected no rows, got %v", err)
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+}
+
+func TestQuizRepository_LockQuestionBankGeneration(t *testing.T) {
+	withLockConn := func(repo *QuizRepository, conn pgxmock.PgxConnIface) {
+		repo.lockConn = func(fn func(repo *utils.Repository[generationLock]) error) error {
+			db := utils.NewDatabase[generationLock](conn)
+			return fn(utils.NewRepository(utils.WithQueryExecutor(utils.QueryExecutor[generationLock]{
+				Exec:  db.Exec,
+				Begin: db.Begin,
+			})))
+		}
+	}
+
+	t.Run("should hold a transaction-scoped lock while fn runs", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		repo := NewMockQuizRepository()
+		withLockConn(repo, conn)
+
+		conn.ExpectBegin()
+		conn.ExpectExec("SET LOCAL lock_timeout = 1000").WillReturnResult(pgxmock.NewResult("SET", 0))
+		conn.ExpectExec("pg_advisory_xact_lock").WithArgs(7).WillReturnResult(pgxmock.NewResult("SELECT", 1))
+		conn.ExpectCommit()
+
+		ran := false
+		err := repo.LockQuestionBankGeneration(7, time.Second, func() error {
+			ran = true
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		if !ran {
+			t.Error("Expected fn to run")
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+
+	t.Run("should report a lock timeout without running fn", func(t *testing.T) {
+		conn, _ := pgxmock.NewConn()
+		defer func() { _ = conn.Close(context.Background()) }()
+
+		repo := NewMockQuizRepository()
+		withLockConn(repo, conn)
+
+		conn.ExpectBegin()
+		conn.ExpectExec("SET LOCAL lock_timeout").WillReturnResult(pgxmock.NewResult("SET", 0))
+		conn.ExpectExec("pg_advisory_xact_lock").WithArgs(7).WillReturnError(&pgconn.PgError{Code: "55P03"})
+		conn.ExpectRollback()
+
+		err := repo.LockQuestionBankGeneration(7, time.Second, func() error {
+			t.Error("Expected fn not to run")
+			return nil
+		})
+		if err != ErrGenerationLockTimeout {
+			t.Errorf("Expected ErrGenerationLockTimeout, got %v", err)
+		}
+
+		if err := conn.ExpectationsWereMet(); err != nil {
+			t.Errorf("Unmet expectations: %v", err)
+		}
+	})
+}
@@ -7,9 +7,8 @@ import (
 	"cribeapp.com/cribe-server/internal/routes/transcripts"
 )
 
-func HandleHTTPRequests() func(http.ResponseWriter, *http.Request) {
+func HandleHTTPRequests(transcriptRepo *transcripts.TranscriptRepository) func(http.ResponseWriter, *http.Request) {
 	repo := NewQuizRepository()
-	transcriptRepo := transcripts.NewTranscriptRepository()
 	llmClient := llm.NewClient()
 
 	service := NewQuizService(*repo, transcriptRepo, llmClient)
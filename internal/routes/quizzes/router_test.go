@@ -10,6 +10,7 @@ import (
 	"cribeapp.com/cribe-server/internal/errors"
 	"cribeapp.com/cribe-server/internal/middlewares"
 	"cribeapp.com/cribe-server/internal/routes/migrations"
+	"cribeapp.com/cribe-server/internal/routes/transcripts"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
@@ -18,7 +19,7 @@ var log = logger.NewCoreLogger("QuizzesRouterTest")
 // handlerWithAuth injects userID into context for authenticated routes
 func handlerWithAuth(w http.ResponseWriter, r *http.Request) {
 	ctx := context.WithValue(r.Context(), middlewares.UserIDContextKey, utils.TestUserID)
-	HandleHTTPRequests()(w, r.WithContext(ctx))
+	HandleHTTPRequests(transcripts.NewTranscriptRepository())(w, r.WithContext(ctx))
 }
 
 func TestQuizzes_IntegrationTests(t *testing.T) {
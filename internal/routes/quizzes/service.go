@@ -9,15 +9,35 @@ import (
 	"cribeapp.com/cribe-server/internal/core/logger"
 	"cribeapp.com/cribe-server/internal/errors"
 	"cribeapp.com/cribe-server/internal/routes/transcripts"
+	"golang.org/x/sync/singleflight"
 )
 
 const (
+	// Default quiz configuration
+	DefaultMultipleChoiceCount = 1
+	DefaultTrueFalseCount      = 1
+	DefaultOpenEndedCount      = 1
+	DefaultDifficulty          = Medium
+
 	// Question generation settings
-	TotalQuestionsPerEpisode = 3
-	MultipleChoiceCount      = 1
-	TrueFalseCount           = 1
-	OpenEndedCount           = 1
-	MultipleChoiceOptions    = 4
+	MaxQuestionsPerQuiz   = 15
+	MultipleChoiceOptions = 4
+	// MaxQuestionRepairAttempts is how many times the LLM is asked to fix invalid questions
+	MaxQuestionRepairAttempts = 2
+	// Generation replies get tokens per question, each carrying options, a source and a rubric
+	GenerationBaseTokens        = 500
+	GenerationTokensPerQuestion = 400
+	// Source quotes must be long enough to locate a single passage and short enough to replay
+	MinSourceQuoteWords = 3
+	MaxSourceQuoteWords = 60
+	// Open-ended questions are graded against a rubric of key points
+	MinKeyPoints = 2
+	MaxKeyPoints = 5
+	// OpenEndedPassingScore is the score from which an open-ended answer counts as correct
+	OpenEndedPassingScore = 0.5
+
+	// GenerationLockTimeout is how long to wait while another caller generates a bank's questions
+	GenerationLockTimeout = 2 * time.Minute
 )
 
 // TranscriptRepo interface defines methods needed from transcript repository
@@ -31,6 +51,8 @@ type QuizService struct {
 	transcriptRepo TranscriptRepo
 	llmClient      llm.LLMClient
 	logger         *logger.ContextualLogger
+	// generations makes concurrent callers in this process share one question generation
+	generations *singleflight.Group
 }
 
 func NewQuizService(repo QuizRepository, transcriptRepo TranscriptRepo, llmClient llm.LLMClient) *QuizService {
@@ -39,6 +61,7 @@ func NewQuizService(repo QuizRepository, transcriptRepo TranscriptRepo, llmClien
 		transcriptRepo: transcriptRepo,
 		llmClient:      llmClient,
 		logger:         logger.NewServiceLogger("QuizService"),
+		generations:    &singleflight.Group{},
 	}
 }
 
@@ -56,7 +79,7 @@ func (s *QuizService) GetSessionWithDetailsByID(sessionID int) (QuizSessionDetai
 		}
 	}
 
-	questions, err := s.repo.GetQuestionsByEpisodeID(session.EpisodeID)
+	config, questions, err := s.getSessionBank(session)
 	if err != nil {
 		return QuizSessionDetail{}, &errors.ErrorResponse{
 			Message: errors.DatabaseError,
@@ -74,6 +97,7 @@ func (s *QuizService) GetSessionWithDetailsByID(sessionID int) (QuizSessionDetai
 
 	return QuizSessionDetail{
 		Session:   session,
+		Config:    config,
 		Questions: questions,
 		Answers:   answers,
 	}, nil
@@ -91,7 +115,7 @@ func (s *QuizService) GetSessionsWithDetailsByUserID(userID int) ([]QuizSessionD
 	}
 
 	for _, session := range sessions {
-		questions, err := s.repo.GetQuestionsByEpisodeID(session.EpisodeID)
+		config, questions, err := s.getSessionBank(session)
 		if err != nil && err != sql.ErrNoRows {
 			s.logger.Error("Failed to fetch questions for session", map[string]any{
 				"session_id": session.ID,
@@ -111,6 +135,7 @@ func (s *QuizService) GetSessionsWithDetailsByUserID(userID int) ([]QuizSessionD
 
 		result = append(result, QuizSessionDetail{
 			Session:   session,
+			Config:    config,
 			Questions: questions,
 			Answers:   answers,
 		})
@@ -119,132 +144,124 @@ func (s *QuizService) GetSessionsWithDetailsByUserID(userID int) ([]QuizSessionD
 	return result, nil
 }
 
-// StartSession starts a new quiz session for a user
-func (s *QuizService) GetOrCreateSessionWithDetails(userID int, episodeID int) (QuizSessionDetail, *errors.ErrorResponse) {
+// GetOrCreateSessionWithDetails returns the latest session of a user on the question bank
+// matching the quiz configuration, starting one if there is none. The configuration is the
+// user's preferences with the fields set by the request replaced, and the bank's questions
+// are generated the first time it is requested. An in-progress session on an archived
+// version of the bank is resumed with its original questions.
+func (s *QuizService) GetOrCreateSessionWithDetails(userID int, episodeID int, override *QuizConfigRequest) (QuizSessionDetail, *errors.ErrorResponse) {
 	s.logger.Info("Starting quiz session", map[string]any{
 		"user_id":    userID,
 		"episode_id": episodeID,
 	})
 
-	// Get questions count
-	questions, _ := s.repo.GetQuestionsByEpisodeID(episodeID)
-
-	if len(questions) == 0 {
-		s.logger.Error("No questions available for episode", map[string]any{
-			"episode_id": episodeID,
-		})
+	config, errResp := s.resolveQuizConfig(userID, override)
+	if errResp != nil {
+		return QuizSessionDetail{}, errResp
+	}
 
-		transcriptText, errResp := s.getTranscriptText(episodeID)
-		if errResp != nil {
-			s.logger.Error("Failed to get transcript text for question generation", map[string]any{
-				"error": errResp.Message,
-			})
-			return QuizSessionDetail{}, errResp
+	bank, err := s.repo.GetOrCreateQuestionBank(episodeID, config)
+	if err != nil {
+		return QuizSessionDetail{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch question bank",
 		}
+	}
 
-		// Generate questions using LLM
-		llmQuestions, err := s.generateQuestionsWithLLM(transcriptText)
+	session, err := s.repo.GetLatestSessionByUserAndBank(userID, bank.ID)
+	if err == nil {
+		sessionConfig, questions, err := s.getSessionBank(session)
 		if err != nil {
-			s.logger.Error("Failed to generate questions with LLM", map[string]any{
-				"error": err.Error(),
-			})
 			return QuizSessionDetail{}, &errors.ErrorResponse{
-				Message: errors.InternalServerError,
-				Details: "Failed to generate questions",
+				Message: errors.DatabaseError,
+				Details: "Failed to fetch questions",
 			}
 		}
 
-		for i, llmQ := range llmQuestions {
-			// Validate question type before creating
-			questionType := QuestionType(llmQ.Type)
-			if questionType != MultipleChoice && questionType != TrueFalse && questionType != OpenEnded {
-				s.logger.Error("Invalid question type from LLM, skipping question", map[string]any{
-					"type":          llmQ.Type,
-					"question_text": llmQ.QuestionText,
-				})
-				continue
-			}
+		answers, _ := s.repo.GetAnswersBySessionID(session.ID)
 
-			question := Question{
-				EpisodeID:    episodeID,
-				QuestionText: llmQ.QuestionText,
-				Type:         questionType,
-				Position:     i,
-			}
-			savedQuestion, err := s.repo.CreateQuestion(question)
-			if err != nil {
-				s.logger.Error("Failed to save generated question", map[string]any{
-					"error": err.Error(),
-				})
-				continue
-			}
+		return QuizSessionDetail{
+			Session:   session,
+			Config:    sessionConfig,
+			Questions: questions,
+			Answers:   answers,
+		}, nil
+	}
 
-			// Save options if applicable
-			var savedOptions []QuestionOption
-			if questionType == MultipleChoice || questionType == TrueFalse {
-				for j, opt := range llmQ.Options {
-					option := QuestionOption{
-						QuestionID: savedQuestion.ID,
-						OptionText: opt.Text,
-						Position:   j,
-						IsCorrect:  opt.IsCorrect,
-					}
-
-					savedOption, err := s.repo.CreateQuestionOption(option)
-					if err != nil {
-						s.logger.Error("Failed to save option", map[string]any{
-							"error": err.Error(),
-						})
-						continue
-					}
-					savedOptions = append(savedOptions, savedOption)
-				}
-			}
+	s.logger.Info("No existing session for question bank", map[string]any{
+		"bank_id": bank.ID,
+		"error":   err.Error(),
+	})
+
+	return s.startSession(userID, bank, nil)
+}
+
+// startSession starts an attempt of a user on a question bank, generating its questions
+// the first time. Retakes pass a shuffle seed to get a fresh order of questions and options.
+func (s *QuizService) startSession(userID int, bank QuestionBank, shuffleSeed *int64) (QuizSessionDetail, *errors.ErrorResponse) {
+	questions, _ := s.repo.GetQuestionsByBankID(bank.ID)
+
+	if len(questions) == 0 {
+		s.logger.Info("No questions available for question bank", map[string]any{
+			"episode_id": bank.EpisodeID,
+			"bank_id":    bank.ID,
+		})
 
-			savedQuestion.Options = savedOptions
-			questions = append(questions, savedQuestion)
+		var errResp *errors.ErrorResponse
+		questions, errResp = s.generateQuestionsOnce(bank)
+		if errResp != nil {
+			return QuizSessionDetail{}, errResp
 		}
 	}
 
-	session, err := s.repo.GetActiveSessionByUserAndEpisode(userID, episodeID)
-
+	session, err := s.repo.CreateSession(UserQuizSession{
+		UserID:            userID,
+		EpisodeID:         bank.EpisodeID,
+		BankID:            &bank.ID,
+		ShuffleSeed:       shuffleSeed,
+		Status:            InProgress,
+		TotalQuestions:    len(questions),
+		AnsweredQuestions: 0,
+		CorrectAnswers:    0,
+	})
 	if err != nil {
-		s.logger.Error("Failed to check for existing session", map[string]any{
+		s.logger.Error("Failed to create session", map[string]any{
 			"error": err.Error(),
 		})
-		session, err := s.repo.CreateSession(UserQuizSession{
-			UserID:            userID,
-			EpisodeID:         episodeID,
-			Status:            InProgress,
-			TotalQuestions:    len(questions),
-			AnsweredQuestions: 0,
-			CorrectAnswers:    0,
-		})
-		if err != nil {
-			s.logger.Error("Failed to create session", map[string]any{
-				"error": err.Error(),
-			})
-			return QuizSessionDetail{}, &errors.ErrorResponse{
-				Message: errors.DatabaseError,
-				Details: "Failed to create session",
-			}
+		return QuizSessionDetail{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to create session",
 		}
-		return QuizSessionDetail{
-			Session:   session,
-			Questions: questions,
-			Answers:   []UserAnswer{},
-		}, nil
 	}
 
-	answers, _ := s.repo.GetAnswersBySessionID(session.ID)
-
 	return QuizSessionDetail{
 		Session:   session,
-		Questions: questions,
-		Answers:   answers,
+		Config:    &bank.QuizConfig,
+		Questions: orderQuestions(questions, session.ShuffleSeed),
+		Answers:   []UserAnswer{},
 	}, nil
 }
 
+// getSessionBank returns the configuration and questions of the bank a session was started
+// on. Sessions started on an episode without questions have no bank.
+func (s *QuizService) getSessionBank(session UserQuizSession) (*QuizConfig, []Question, error) {
+	if session.BankID == nil {
+		return nil, []Question{}, nil
+	}
+
+	bank, err := s.repo.GetQuestionBankByID(*session.BankID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	questions, err := s.repo.GetQuestionsByBankID(bank.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &bank.QuizConfig, orderQuestions(questions, session.ShuffleSeed), nil
+}
+
 // UpdateSessionStatus updates the session status (complete/abandon)
 func (s *QuizService) UpdateSessionStatus(sessionID, userID int, status SessionStatus) (UserQuizSession, *errors.ErrorResponse) {
 	// Get session and verify ownership
@@ -348,8 +365,16 @@ func (s *QuizService) SubmitAnswer(sessionID, userID, questionID int, request Su
 		}
 	}
 
+	// Verify question belongs to the session's question bank
+	if session.BankID != nil && question.BankID != *session.BankID {
+		return UserAnswer{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Question does not belong to this session's quiz",
+		}
+	}
+
 	// Evaluate answer
-	isCorrect, feedback, err := s.evaluateAnswer(question, request)
+	evaluation, err := s.evaluateAnswer(question, request)
 	if err != nil {
 		return UserAnswer{}, &errors.ErrorResponse{
 			Message: errors.InternalServerError,
@@ -357,64 +382,55 @@ func (s *QuizService) SubmitAnswer(sessionID, userID, questionID int, request Su
 		}
 	}
 
-	// Save answer
 	answer := UserAnswer{
 		SessionID:        sessionID,
 		QuestionID:       questionID,
 		UserID:           userID,
 		SelectedOptionID: request.SelectedOptionID,
 		TextAnswer:       request.TextAnswer,
-		IsCorrect:        isCorrect,
-		Feedback:         feedback,
+		IsCorrect:        evaluation.IsCorrect,
+		Score:            evaluation.Score,
+		KeyPointsHit:     evaluation.KeyPointsHit,
+		KeyPointsMissed:  evaluation.KeyPointsMissed,
+		Feedback:         withSourceHint(evaluation.Feedback, question),
 	}
 
-	savedAnswer, err := s.repo.CreateAnswer(answer)
+	// Save the answer and count it in the session stats together
+	savedAnswer, session, err := s.repo.SaveAnswer(answer)
 	if err != nil {
-		return UserAnswer{}, &errors.ErrorResponse{
-			Message: errors.DatabaseError,
-			Details: "Failed to save answer",
+		if err.Error() == "no rows in result set" {
+			return UserAnswer{}, &errors.ErrorResponse{
+				Message: errors.ValidationError,
+				Details: "Cannot submit answers to a completed or abandoned session",
+			}
 		}
-	}
-
-	// Update session stats
-	session.AnsweredQuestions++
-	if isCorrect {
-		session.CorrectAnswers++
-	}
-
-	// Auto-complete session if all questions answered
-	if session.AnsweredQuestions >= session.TotalQuestions {
-		session.Status = Completed
-		now := time.Now()
-		session.CompletedAt = &now
-	}
-
-	if err := s.repo.UpdateSession(session); err != nil {
-		s.logger.Error("Failed to update session", map[string]any{
+		s.logger.Error("Failed to save answer", map[string]any{
 			"session_id": sessionID,
 			"error":      err.Error(),
 		})
 		return UserAnswer{}, &errors.ErrorResponse{
 			Message: errors.DatabaseError,
-			Details: "Failed to update session stats",
+			Details: "Failed to save answer",
 		}
 	}
 
 	s.logger.Info("Answer submitted successfully", map[string]any{
 		"answer_id":  savedAnswer.ID,
-		"is_correct": isCorrect,
+		"is_correct": evaluation.IsCorrect,
+		"score":      evaluation.Score,
+		"status":     session.Status,
 	})
 
 	return savedAnswer, nil
 }
 
 // evaluateAnswer evaluates the user's answer and generates feedback
-func (s *QuizService) evaluateAnswer(question Question, request SubmitAnswerRequest) (bool, string, error) {
+func (s *QuizService) evaluateAnswer(question Question, request SubmitAnswerRequest) (answerEvaluation, error) {
 	switch question.Type {
 	case MultipleChoice, TrueFalse:
 		// Validate that SelectedOptionID is provided
 		if request.SelectedOptionID == nil {
-			return false, "Selected option ID is required for multiple choice and true/false questions", nil
+			return answerEvaluation{Feedback: "Selected option ID is required for multiple choice and true/false questions"}, nil
 		}
 
 		// Find the selected option
@@ -427,12 +443,12 @@ func (s *QuizService) evaluateAnswer(question Question, request SubmitAnswerRequ
 		}
 
 		if selectedOption == nil {
-			return false, "Invalid option selected", nil
+			return answerEvaluation{Feedback: "Invalid option selected"}, nil
 		}
 
 		if selectedOption.IsCorrect {
 			feedback := s.generateFeedbackWithLLM(question, selectedOption.OptionText, true)
-			return true, feedback, nil
+			return answerEvaluation{IsCorrect: true, Score: 1, Feedback: feedback}, nil
 		}
 
 		// Find correct option for feedback
@@ -446,22 +462,22 @@ func (s *QuizService) evaluateAnswer(question Question, request SubmitAnswerRequ
 
 		if correctOption != nil {
 			feedback := s.generateFeedbackWithLLM(question, selectedOption.OptionText, false)
-			return false, fmt.Sprintf("Incorrect. The correct answer is: %s. %s",
-				correctOption.OptionText, feedback), nil
+			return answerEvaluation{Feedback: fmt.Sprintf("Incorrect. The correct answer is: %s. %s",
+				correctOption.OptionText, feedback)}, nil
 		}
 
-		return false, "Incorrect answer.", nil
+		return answerEvaluation{Feedback: "Incorrect answer."}, nil
 
 	case OpenEnded:
 		// Validate that TextAnswer is provided
 		if request.TextAnswer == nil {
-			return false, "Text answer is required for open-ended questions", nil
+			return answerEvaluation{Feedback: "Text answer is required for open-ended questions"}, nil
 		}
 
-		// Use LLM to evaluate open-ended answer
+		// Use LLM to grade the open-ended answer against its rubric
 		return s.evaluateOpenEndedAnswer(question, *request.TextAnswer)
 
 	default:
-		return false, "Unknown question type", fmt.Errorf("unknown question type: %s", question.Type)
+		return answerEvaluation{Feedback: "Unknown question type"}, fmt.Errorf("unknown question type: %s", question.Type)
 	}
 }
@@ -1,22 +1,38 @@
 package quizzes
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"testing"
 	"time"
 
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
 	"cribeapp.com/cribe-server/internal/routes/transcripts"
 )
 
 // Helpers
 
+// quizTranscriptText is the transcript of the mocked episodes, which makeQuizJSON quotes
+const quizTranscriptText = "Go is a programming language created at Google. Goroutines make concurrency cheap."
+
+// quizChunks turns the text into one chunk per word, one second each
+func quizChunks(text string) []transcripts.TranscriptChunk {
+	words := strings.Fields(text)
+	chunks := make([]transcripts.TranscriptChunk, len(words))
+	for i, word := range words {
+		chunks[i] = transcripts.TranscriptChunk{Position: i, StartTime: float64(i), EndTime: float64(i) + 0.5, Text: word}
+	}
+	return chunks
+}
+
 func makeQuizJSON(questionCount int) string {
 	if questionCount == 1 {
-		return `{"questions": [{"question_text": "What is Go?", "type": "multiple_choice", "options": [{"text": "A language", "is_correct": true}, {"text": "A tool", "is_correct": false}]}]}`
+		return `{"questions": [{"question_text": "What is Go?", "type": "multiple_choice", "options": [{"text": "A language", "is_correct": true}, {"text": "A tool", "is_correct": false}], "source": {"quote": "Go is a programming language", "start": "0:00"}}]}`
 	}
-	return `{"questions": [{"question_text": "What is Go?", "type": "multiple_choice", "options": [{"text": "A programming language", "is_correct": true}, {"text": "A game", "is_correct": false}, {"text": "A car", "is_correct": false}, {"text": "A bird", "is_correct": false}]}, {"question_text": "Go is statically typed", "type": "true_false", "options": [{"text": "True", "is_correct": true}, {"text": "False", "is_correct": false}]}, {"question_text": "Explain goroutines", "type": "open_ended"}]}`
+	return `{"questions": [{"question_text": "What is Go?", "type": "multiple_choice", "options": [{"text": "A programming language", "is_correct": true}, {"text": "A game", "is_correct": false}, {"text": "A car", "is_correct": false}, {"text": "A bird", "is_correct": false}], "source": {"quote": "Go is a programming language", "start": "0:00"}}, {"question_text": "Go was created at Google", "type": "true_false", "options": [{"text": "True", "is_correct": true}, {"text": "False", "is_correct": false}], "source": {"quote": "created at Google", "start": "0:05"}}, {"question_text": "Explain goroutines", "type": "open_ended", "reference_answer": "Goroutines make concurrency cheap in Go.", "key_points": ["Goroutines are lightweight", "Concurrency is cheap"], "source": {"quote": "Goroutines make concurrency cheap", "start": "0:08"}}]}`
 }
 
 func setupQuizService(llmClient *MockLLMClient, transcriptRepo *MockTranscriptRepository) *QuizService {
@@ -135,9 +151,9 @@ func TestQuizService_evaluateAnswer(t *testing.T) {
 		correctOptionID := 1
 		question := Question{ID: 1, Type: MultipleChoice, Options: []QuestionOption{{ID: 1, IsCorrect: true}}}
 		request := SubmitAnswerRequest{QuestionID: 1, SelectedOptionID: &correctOptionID}
-		isCorrect, _, err := svc.evaluateAnswer(question, request)
-		if err != nil || !isCorrect {
-			t.Errorf("expected correct answer, got err=%v isCorrect=%v", err, isCorrect)
+		evaluation, err := svc.evaluateAnswer(question, request)
+		if err != nil || !evaluation.IsCorrect || evaluation.Score != 1 {
+			t.Errorf("expected correct answer with full score, got err=%v evaluation=%+v", err, evaluation)
 		}
 	})
 
@@ -146,19 +162,21 @@ func TestQuizService_evaluateAnswer(t *testing.T) {
 		wrongOptionID := 2
 		question := Question{ID: 1, Type: MultipleChoice, Options: []QuestionOption{{ID: 1, OptionText: "4", IsCorrect: true}, {ID: 2, IsCorrect: false}}}
 		request := SubmitAnswerRequest{QuestionID: 1, SelectedOptionID: &wrongOptionID}
-		isCorrect, feedback, err := svc.evaluateAnswer(question, request)
+		evaluation, err := svc.evaluateAnswer(question, request)
+		isCorrect, feedback := evaluation.IsCorrect, evaluation.Feedback
 		if err != nil || isCorrect || !strings.Contains(feedback, "Incorrect") {
 			t.Errorf("expected incorrect with feedback, got err=%v isCorrect=%v feedback=%s", err, isCorrect, feedback)
 		}
 	})
 
 	t.Run("OpenEnded - evaluates with LLM", func(t *testing.T) {
-		mockLLM := &MockLLMClient{ChatResponse: makeLLMResponse(`{"is_correct": true, "feedback": "Well explained!"}`)}
+		mockLLM := &MockLLMClient{ChatResponse: makeLLMResponse(`{"score": 0.75, "key_points_hit": [], "key_points_missed": [], "feedback": "Well explained!"}`)}
 		svc := setupQuizService(mockLLM, nil)
 		textAnswer := "Answer"
 		question := Question{ID: 1, Type: OpenEnded}
 		request := SubmitAnswerRequest{QuestionID: 1, TextAnswer: &textAnswer}
-		isCorrect, feedback, err := svc.evaluateAnswer(question, request)
+		evaluation, err := svc.evaluateAnswer(question, request)
+		isCorrect, feedback := evaluation.IsCorrect, evaluation.Feedback
 		if err != nil || !isCorrect || feedback != "Well explained!" {
 			t.Errorf("expected correct with feedback, got err=%v isCorrect=%v feedback=%s", err, isCorrect, feedback)
 		}
@@ -168,7 +186,8 @@ func TestQuizService_evaluateAnswer(t *testing.T) {
 		svc := setupQuizService(nil, nil)
 		question := Question{ID: 1, Type: OpenEnded}
 		request := SubmitAnswerRequest{QuestionID: 1, TextAnswer: nil}
-		isCorrect, feedback, err := svc.evaluateAnswer(question, request)
+		evaluation, err := svc.evaluateAnswer(question, request)
+		isCorrect, feedback := evaluation.IsCorrect, evaluation.Feedback
 		if err != nil || isCorrect || feedback != "Text answer is required for open-ended questions" {
 			t.Errorf("expected error feedback for nil TextAnswer, got err=%v isCorrect=%v feedback=%s", err, isCorrect, feedback)
 		}
@@ -189,7 +208,8 @@ func TestQuizService_evaluateAnswer(t *testing.T) {
 			SelectedOptionID: &optionID,
 		}
 
-		isCorrect, feedback, err := svc.evaluateAnswer(question, request)
+		evaluation, err := svc.evaluateAnswer(question, request)
+		isCorrect, feedback := evaluation.IsCorrect, evaluation.Feedback
 
 		if err == nil {
 			t.Error("expected error for unknown question type")
@@ -203,6 +223,107 @@ func TestQuizService_evaluateAnswer(t *testing.T) {
 	})
 }
 
+func TestQuizService_SubmitAnswer_PartialCredit(t *testing.T) {
+	mockLLM := &MockLLMClient{
+		Responses:    []llm.ChatCompletionResponse{makeLLMResponse(makeQuizJSON(3))},
+		ChatResponse: makeLLMResponse(`{"score": 0.5, "key_points_hit": ["Concurrency is cheap"], "key_points_missed": ["Goroutines are lightweight"], "feedback": "Half of it."}`),
+	}
+	svc := setupQuizService(mockLLM, &MockTranscriptRepository{
+		GetTranscriptByEpisodeIDFunc: func(int) (transcripts.Transcript, error) {
+			return transcripts.Transcript{ID: 1, Status: "complete"}, nil
+		},
+		GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
+			return quizChunks(quizTranscriptText), nil
+		},
+	})
+	detail, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
+	if errResp != nil {
+		t.Fatalf("unexpected error: %v", errResp.Details)
+	}
+	openEnded := detail.Questions[2]
+	textAnswer := "Concurrency is cheap"
+
+	answer, errResp := svc.SubmitAnswer(detail.Session.ID, 1, openEnded.ID, SubmitAnswerRequest{QuestionID: openEnded.ID, TextAnswer: &textAnswer})
+
+	if errResp != nil {
+		t.Fatalf("unexpected error: %v", errResp.Details)
+	}
+	if !strings.Contains(mockLLM.LastRequest.Messages[1].Content, "Key points:\n- Goroutines are lightweight\n- Concurrency is cheap") {
+		t.Errorf("expected the rubric in the grading prompt, got %q", mockLLM.LastRequest.Messages[1].Content)
+	}
+	if !answer.IsCorrect || answer.Score != 0.5 || strings.Join(answer.KeyPointsHit, "|") != "Concurrency is cheap" || strings.Join(answer.KeyPointsMissed, "|") != "Goroutines are lightweight" {
+		t.Errorf("expected partial credit with the key points hit and missed, got %+v", answer)
+	}
+	session, _ := svc.repo.GetSessionByID(detail.Session.ID)
+	if session.Score != 0.5 || session.CorrectAnswers != 1 {
+		t.Errorf("expected the session score to add the partial credit, got %+v", session)
+	}
+}
+
+// gradingGateLLMClient answers chat calls right away, except open-ended gradings, which
+// signal grading and wait for release
+type gradingGateLLMClient struct {
+	grading chan struct{}
+	release chan struct{}
+}
+
+func (c *gradingGateLLMClient) Chat(ctx context.Context, req llm.ChatRequest) (llm.ChatCompletionResponse, error) {
+	if req.Messages[0].Content == EvaluateOpenEndedSystemPrompt {
+		close(c.grading)
+		<-c.release
+		return makeLLMResponse(`{"score": 0.5, "key_points_hit": [], "key_points_missed": [], "feedback": "Half of it."}`), nil
+	}
+	return makeLLMResponse("Right."), nil
+}
+
+func TestQuizService_SubmitAnswer_Interleaved(t *testing.T) {
+	svc := setupRegenerationService()
+	detail, errResp := svc.GetOrCreateSessionWithDetails(2, 1, nil)
+	if errResp != nil {
+		t.Fatalf("unexpected error: %v", errResp.Details)
+	}
+	client := &gradingGateLLMClient{grading: make(chan struct{}), release: make(chan struct{})}
+	svc.llmClient = client
+	sessionID := detail.Session.ID
+	choice := func(question Question) SubmitAnswerRequest {
+		for _, option := range question.Options {
+			if option.IsCorrect {
+				return SubmitAnswerRequest{QuestionID: question.ID, SelectedOptionID: &option.ID}
+			}
+		}
+		t.Fatalf("no correct option for question %d", question.ID)
+		return SubmitAnswerRequest{}
+	}
+
+	// The open-ended answer reads the session, then waits for its grading while the other
+	// answers of the session are saved
+	textAnswer := "Concurrency is cheap"
+	openEnded := detail.Questions[2]
+	done := make(chan *errors.ErrorResponse)
+	go func() {
+		_, errResp := svc.SubmitAnswer(sessionID, 2, openEnded.ID, SubmitAnswerRequest{QuestionID: openEnded.ID, TextAnswer: &textAnswer})
+		done <- errResp
+	}()
+	<-client.grading
+	for _, question := range detail.Questions[:2] {
+		if _, errResp := svc.SubmitAnswer(sessionID, 2, question.ID, choice(question)); errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+	}
+	close(client.release)
+	if errResp := <-done; errResp != nil {
+		t.Fatalf("unexpected error: %v", errResp.Details)
+	}
+
+	session, _ := svc.repo.GetSessionByID(sessionID)
+	if session.AnsweredQuestions != 3 || session.CorrectAnswers != 3 || session.Score != 2.5 {
+		t.Errorf("expected every answer counted, got %+v", session)
+	}
+	if session.Status != Completed || session.CompletedAt == nil {
+		t.Errorf("expected the session completed by its last answer, got %+v", session)
+	}
+}
+
 func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 	t.Run("successful generation and saving with multiple question types", func(t *testing.T) {
 		// Create LLM response with different question types
@@ -216,18 +337,23 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 					{Text: "A car", IsCorrect: false},
 					{Text: "A bird", IsCorrect: false},
 				},
+				Source: &LLMQuestionSource{Quote: "Go is a programming language", Start: "0:00"},
 			},
 			{
-				QuestionText: "Go is statically typed",
+				QuestionText: "Go was created at Google",
 				Type:         "true_false",
 				Options: []LLMQuestionOption{
 					{Text: "True", IsCorrect: true},
 					{Text: "False", IsCorrect: false},
 				},
+				Source: &LLMQuestionSource{Quote: "created at Google", Start: "0:05"},
 			},
 			{
-				QuestionText: "Explain goroutines",
-				Type:         "open_ended",
+				QuestionText:    "Explain goroutines",
+				Type:            "open_ended",
+				ReferenceAnswer: " Goroutines make concurrency cheap in Go. ",
+				KeyPoints:       []string{"Goroutines are lightweight", " Concurrency is cheap "},
+				Source:          &LLMQuestionSource{Quote: "Goroutines make concurrency cheap", Start: "0:08"},
 			},
 		}}
 		jsonBytes, _ := json.Marshal(llmResp)
@@ -238,7 +364,7 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 				return transcripts.Transcript{ID: 1, Status: "complete"}, nil
 			},
 			GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
-				return []transcripts.TranscriptChunk{{Text: "Test transcript"}}, nil
+				return quizChunks(quizTranscriptText), nil
 			},
 		}
 
@@ -246,7 +372,7 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 		svc := NewQuizService(*repo, mockTranscript, mockLLM)
 
 		// Call GetOrCreateSessionWithDetails which triggers question generation
-		detail, errResp := svc.GetOrCreateSessionWithDetails(1, 1)
+		detail, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
 
 		if errResp != nil {
 			t.Fatalf("unexpected error: %v", errResp.Details)
@@ -278,6 +404,10 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 		if !foundCorrect {
 			t.Error("expected to find correct option marked as IsCorrect")
 		}
+		// Verify the question is grounded in the transcript span of its source quote
+		if mcQuestion.SourceStartPosition == nil || *mcQuestion.SourceStartPosition != 0 || *mcQuestion.SourceEndPosition != 4 || *mcQuestion.SourceEndTime != 4.5 {
+			t.Errorf("expected the source span at chunks 0-4, got %v-%v", mcQuestion.SourceStartPosition, mcQuestion.SourceEndPosition)
+		}
 
 		// Verify true/false question
 		tfQuestion := detail.Questions[1]
@@ -296,6 +426,13 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 		if len(oeQuestion.Options) != 0 {
 			t.Errorf("expected 0 options for open-ended, got %d", len(oeQuestion.Options))
 		}
+		// Verify the open-ended question keeps its rubric for grading
+		if oeQuestion.ReferenceAnswer == nil || *oeQuestion.ReferenceAnswer != "Goroutines make concurrency cheap in Go." || strings.Join(oeQuestion.KeyPoints, "|") != "Goroutines are lightweight|Concurrency is cheap" {
+			t.Errorf("expected the trimmed reference answer and key points, got %v %v", oeQuestion.ReferenceAnswer, oeQuestion.KeyPoints)
+		}
+		if mcQuestion.ReferenceAnswer != nil || mcQuestion.KeyPoints != nil {
+			t.Errorf("expected no rubric for multiple choice, got %v %v", mcQuestion.ReferenceAnswer, mcQuestion.KeyPoints)
+		}
 	})
 
 	t.Run("LLM generation fails", func(t *testing.T) {
@@ -305,14 +442,14 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 				return transcripts.Transcript{ID: 1, Status: "complete"}, nil
 			},
 			GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
-				return []transcripts.TranscriptChunk{{Text: "Test"}}, nil
+				return quizChunks(quizTranscriptText), nil
 			},
 		}
 
 		repo := NewMockQuizRepository()
 		svc := NewQuizService(*repo, mockTranscript, mockLLM)
 
-		_, errResp := svc.GetOrCreateSessionWithDetails(1, 1)
+		_, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
 
 		if errResp == nil {
 			t.Fatal("expected error, got nil")
@@ -322,7 +459,7 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 		}
 	})
 
-	t.Run("question save continues on option save failure", func(t *testing.T) {
+	t.Run("option save failure fails question generation", func(t *testing.T) {
 		llmResp := LLMQuestionsResponse{Questions: []LLMQuestion{
 			{
 				QuestionText: "Test question",
@@ -330,7 +467,10 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 				Options: []LLMQuestionOption{
 					{Text: "Option 1", IsCorrect: true},
 					{Text: "Option 2", IsCorrect: false},
+					{Text: "Option 3", IsCorrect: false},
+					{Text: "Option 4", IsCorrect: false},
 				},
+				Source: &LLMQuestionSource{Quote: "Go is a programming language", Start: "0:00"},
 			},
 		}}
 		jsonBytes, _ := json.Marshal(llmResp)
@@ -341,7 +481,7 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 				return transcripts.Transcript{ID: 1, Status: "complete"}, nil
 			},
 			GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
-				return []transcripts.TranscriptChunk{{Text: "Test"}}, nil
+				return quizChunks(quizTranscriptText), nil
 			},
 		}
 
@@ -361,20 +501,95 @@ func TestQuizService_GenerateAndSaveQuestions(t *testing.T) {
 
 		svc := NewQuizService(*repo, mockTranscript, mockLLM)
 
-		detail, errResp := svc.GetOrCreateSessionWithDetails(1, 1)
+		_, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
+
+		// Questions and options are saved in one transaction, so a failed option fails the quiz
+		if errResp == nil {
+			t.Fatal("expected error, got nil")
+		}
+		if errResp.Details != "Failed to save generated questions" {
+			t.Errorf("expected 'Failed to save generated questions', got %q", errResp.Details)
+		}
+	})
+}
 
+func TestQuizService_GetOrCreateSessionWithDetails_Config(t *testing.T) {
+	mockTranscript := &MockTranscriptRepository{
+		GetTranscriptByEpisodeIDFunc: func(int) (transcripts.Transcript, error) {
+			return transcripts.Transcript{ID: 1, Status: "complete"}, nil
+		},
+		GetChunksByTranscriptIDFunc: func(int) ([]transcripts.TranscriptChunk, error) {
+			return quizChunks(quizTranscriptText), nil
+		},
+	}
+
+	t.Run("generates a question bank per configuration", func(t *testing.T) {
+		mockLLM := &MockLLMClient{ChatResponse: makeLLMResponse(makeQuizJSON(3))}
+		svc := setupQuizService(mockLLM, mockTranscript)
+		multipleChoice, difficulty := 1, Hard
+		zero := 0
+
+		defaultQuiz, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+		hardQuiz, errResp := svc.GetOrCreateSessionWithDetails(1, 1, &QuizConfigRequest{
+			MultipleChoice: &multipleChoice,
+			TrueFalse:      &zero,
+			OpenEnded:      &zero,
+			Difficulty:     &difficulty,
+		})
 		if errResp != nil {
 			t.Fatalf("unexpected error: %v", errResp.Details)
 		}
 
-		// Question should still be saved even if option save fails
-		if len(detail.Questions) != 1 {
-			t.Errorf("expected 1 question, got %d", len(detail.Questions))
+		prompt := mockLLM.LastRequest.Messages[0].Content
+		if !strings.Contains(prompt, "Generate exactly 1 questions total") || !strings.Contains(prompt, difficultyGuidelines[Hard]) {
+			t.Errorf("expected the prompt to follow the configuration, got %s", prompt)
+		}
+		if len(hardQuiz.Questions) != 1 || hardQuiz.Questions[0].Type != MultipleChoice {
+			t.Errorf("expected the extra questions of the LLM to be dropped, got %+v", hardQuiz.Questions)
+		}
+		if *hardQuiz.Session.BankID == *defaultQuiz.Session.BankID || hardQuiz.Session.ID == defaultQuiz.Session.ID {
+			t.Error("expected separate banks and sessions per configuration")
+		}
+		if hardQuiz.Config == nil || hardQuiz.Config.Difficulty != Hard || hardQuiz.Session.TotalQuestions != 1 {
+			t.Errorf("unexpected session for the configuration: %+v %+v", hardQuiz.Session, hardQuiz.Config)
+		}
+
+		// The same configuration returns the same session without generating again
+		mockLLM.ChatError = fmt.Errorf("should not be called")
+		again, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
+		if errResp != nil || again.Session.ID != defaultQuiz.Session.ID || len(again.Questions) != 3 {
+			t.Errorf("expected the existing default session, got %+v %v", again.Session, errResp)
+		}
+	})
+
+	t.Run("uses the user's preferences", func(t *testing.T) {
+		mockLLM := &MockLLMClient{ChatResponse: makeLLMResponse(makeQuizJSON(3))}
+		svc := setupQuizService(mockLLM, mockTranscript)
+		if _, errResp := svc.UpdateQuizPreferences(1, QuizConfig{TrueFalse: 1, OpenEnded: 1, Difficulty: Easy}); errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
+		}
+
+		detail, errResp := svc.GetOrCreateSessionWithDetails(1, 1, nil)
+
+		if errResp != nil {
+			t.Fatalf("unexpected error: %v", errResp.Details)
 		}
+		if *detail.Config != (QuizConfig{TrueFalse: 1, OpenEnded: 1, Difficulty: Easy}) || len(detail.Questions) != 2 {
+			t.Errorf("expected the preferred configuration, got %+v with %d questions", detail.Config, len(detail.Questions))
+		}
+	})
+
+	t.Run("rejects an invalid configuration", func(t *testing.T) {
+		svc := setupQuizService(nil, mockTranscript)
+		zero := 0
+
+		_, errResp := svc.GetOrCreateSessionWithDetails(1, 1, &QuizConfigRequest{MultipleChoice: &zero, TrueFalse: &zero, OpenEnded: &zero})
 
-		// Only one option should be saved (the second one succeeded)
-		if len(detail.Questions[0].Options) != 1 {
-			t.Errorf("expected 1 option (one failed), got %d", len(detail.Questions[0].Options))
+		if errResp == nil || errResp.Message != "Validation error" {
+			t.Errorf("expected a validation error, got %v", errResp)
 		}
 	})
 }
@@ -0,0 +1,179 @@
+package quizzes
+
+import (
+	"fmt"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/routes/transcripts"
+)
+
+// groundedQuestion is a valid generated question with the transcript span of its source
+type groundedQuestion struct {
+	LLMQuestion
+	Span questionSpan
+}
+
+// questionSpan is the range of transcript chunks a source quote was found at
+type questionSpan struct {
+	StartPosition int
+	EndPosition   int
+	StartTime     float64
+	EndTime       float64
+}
+
+// validateGeneratedQuestions validates the questions of an LLM reply, reporting a reply that
+// couldn't be parsed as its only problem so the LLM can repair it too
+func validateGeneratedQuestions(generated generatedQuestions, config QuizConfig, chunks []transcripts.TranscriptChunk) ([]groundedQuestion, []string) {
+	if generated.parseErr != nil {
+		return nil, []string{fmt.Sprintf("The response is not a valid JSON object (%s); it may have been cut off, so keep every field concise", generated.parseErr)}
+	}
+	return validateQuestions(generated.questions, config, chunks)
+}
+
+// validateQuestions checks the questions generated by the LLM against the configuration of
+// their bank and locates their source quotes in the transcript chunks. It returns the valid
+// questions, at most the configured count of each type in the order of the LLM, and a
+// description of every problem found, for the LLM to repair.
+func validateQuestions(llmQuestions []LLMQuestion, config QuizConfig, chunks []transcripts.TranscriptChunk) ([]groundedQuestion, []string) {
+	var problems []string
+	counts := map[QuestionType]int{}
+	valid := make([]groundedQuestion, 0, config.TotalQuestions())
+
+	for i, llmQ := range llmQuestions {
+		questionProblems := questionProblems(llmQ)
+		span, sourceProblem := locateSource(llmQ.Source, chunks)
+		if sourceProblem != "" {
+			questionProblems = append(questionProblems, sourceProblem)
+		}
+		if len(questionProblems) > 0 {
+			for _, problem := range questionProblems {
+				problems = append(problems, fmt.Sprintf("Question %d: %s", i+1, problem))
+			}
+			continue
+		}
+
+		questionType := QuestionType(llmQ.Type)
+		if counts[questionType] == config.Count(questionType) {
+			continue
+		}
+		counts[questionType]++
+		valid = append(valid, groundedQuestion{LLMQuestion: llmQ, Span: span})
+	}
+
+	for _, questionType := range []QuestionType{MultipleChoice, TrueFalse, OpenEnded} {
+		if want := config.Count(questionType); counts[questionType] < want {
+			problems = append(problems, fmt.Sprintf("Expected %d valid %s questions, got %d", want, questionType, counts[questionType]))
+		}
+	}
+
+	return valid, problems
+}
+
+// questionProblems describes what makes a generated question invalid
+func questionProblems(llmQ LLMQuestion) []string {
+	var problems []string
+	if strings.TrimSpace(llmQ.QuestionText) == "" {
+		problems = append(problems, "question_text is empty")
+	}
+
+	switch QuestionType(llmQ.Type) {
+	case MultipleChoice:
+		if len(llmQ.Options) != MultipleChoiceOptions {
+			problems = append(problems, fmt.Sprintf("multiple_choice questions need exactly %d options, got %d", MultipleChoiceOptions, len(llmQ.Options)))
+		}
+		problems = append(problems, optionProblems(llmQ.Options)...)
+	case TrueFalse:
+		texts := make([]string, len(llmQ.Options))
+		for i, option := range llmQ.Options {
+			texts[i] = normalizeOptionText(option.Text)
+		}
+		if len(texts) != 2 || !((texts[0] == "true" && texts[1] == "false") || (texts[0] == "false" && texts[1] == "true")) {
+			problems = append(problems, `true_false questions need exactly the options "True" and "False"`)
+		}
+		problems = append(problems, optionProblems(llmQ.Options)...)
+	case OpenEnded:
+		if len(llmQ.Options) > 0 {
+			problems = append(problems, "open_ended questions must not have options")
+		}
+		problems = append(problems, rubricProblems(llmQ)...)
+	default:
+		problems = append(problems, fmt.Sprintf("unknown type %q", llmQ.Type))
+	}
+
+	return problems
+}
+
+// rubricProblems checks the reference answer and key points of an open-ended question
+func rubricProblems(llmQ LLMQuestion) []string {
+	var problems []string
+	if strings.TrimSpace(llmQ.ReferenceAnswer) == "" {
+		problems = append(problems, "open_ended questions need a reference_answer")
+	}
+	if len(llmQ.KeyPoints) < MinKeyPoints || len(llmQ.KeyPoints) > MaxKeyPoints {
+		problems = append(problems, fmt.Sprintf("open_ended questions need between %d and %d key_points, got %d", MinKeyPoints, MaxKeyPoints, len(llmQ.KeyPoints)))
+	}
+	seen := map[string]bool{}
+	for _, keyPoint := range llmQ.KeyPoints {
+		text := normalizeOptionText(keyPoint)
+		if text == "" {
+			problems = append(problems, "a key point is empty")
+		} else if seen[text] {
+			problems = append(problems, fmt.Sprintf("key point %q is duplicated", strings.TrimSpace(keyPoint)))
+		}
+		seen[text] = true
+	}
+	return problems
+}
+
+// locateSource finds the source quote of a question word for word in the transcript chunks,
+// describing the problem when it can't be used
+func locateSource(source *LLMQuestionSource, chunks []transcripts.TranscriptChunk) (questionSpan, string) {
+	if source == nil || strings.TrimSpace(source.Quote) == "" {
+		return questionSpan{}, "source quote is missing"
+	}
+
+	if words := len(strings.Fields(source.Quote)); words < MinSourceQuoteWords || words > MaxSourceQuoteWords {
+		return questionSpan{}, fmt.Sprintf("source quote must have between %d and %d words, got %d", MinSourceQuoteWords, MaxSourceQuoteWords, words)
+	}
+
+	// The start only picks between repeated quotes, so an unreadable one is ignored
+	near, _ := transcripts.ParseTimestamp(source.Start)
+	from, to, ok := transcripts.LocateQuote(source.Quote, chunks, near)
+	if !ok {
+		return questionSpan{}, fmt.Sprintf("source quote %q was not found word for word in the transcript", strings.TrimSpace(source.Quote))
+	}
+
+	return questionSpan{
+		StartPosition: chunks[from].Position,
+		EndPosition:   chunks[to].Position,
+		StartTime:     chunks[from].StartTime,
+		EndTime:       chunks[to].EndTime,
+	}, ""
+}
+
+// optionProblems checks the options of a multiple choice or true/false question
+func optionProblems(options []LLMQuestionOption) []string {
+	var problems []string
+	correct := 0
+	seen := map[string]bool{}
+	for _, option := range options {
+		text := normalizeOptionText(option.Text)
+		if text == "" {
+			problems = append(problems, "an option text is empty")
+		} else if seen[text] {
+			problems = append(problems, fmt.Sprintf("option %q is duplicated", strings.TrimSpace(option.Text)))
+		}
+		seen[text] = true
+		if option.IsCorrect {
+			correct++
+		}
+	}
+	if correct != 1 {
+		problems = append(problems, fmt.Sprintf("exactly one option must be correct, got %d", correct))
+	}
+	return problems
+}
+
+func normalizeOptionText(text string) string {
+	return strings.ToLower(strings.TrimSpace(text))
+}
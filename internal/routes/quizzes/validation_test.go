@@ -0,0 +1,116 @@
+package quizzes
+
+import (
+	"strings"
+	"testing"
+)
+
+func multipleChoice(text string, options ...LLMQuestionOption) LLMQuestion {
+	return LLMQuestion{QuestionText: text, Type: string(MultipleChoice), Options: options}
+}
+
+func option(text string, isCorrect bool) LLMQuestionOption {
+	return LLMQuestionOption{Text: text, IsCorrect: isCorrect}
+}
+
+func openEndedQuestion(text string, keyPoints ...string) LLMQuestion {
+	if len(keyPoints) == 0 {
+		keyPoints = []string{"Goroutines are lightweight", "Concurrency is cheap"}
+	}
+	return LLMQuestion{QuestionText: text, Type: string(OpenEnded), ReferenceAnswer: "Goroutines make concurrency cheap in Go.", KeyPoints: keyPoints}
+}
+
+func TestQuestionProblems(t *testing.T) {
+	tests := []struct {
+		name     string
+		question LLMQuestion
+		want     string
+	}{
+		{"valid multiple choice", multipleChoice("Q", option("A", true), option("B", false), option("C", false), option("D", false)), ""},
+		{"empty text", multipleChoice(" ", option("A", true), option("B", false), option("C", false), option("D", false)), "question_text is empty"},
+		{"two correct options", multipleChoice("Q", option("A", true), option("B", true), option("C", false), option("D", false)), "exactly one option must be correct, got 2"},
+		{"no correct option", multipleChoice("Q", option("A", false), option("B", false), option("C", false), option("D", false)), "got 0"},
+		{"wrong option count", multipleChoice("Q", option("A", true), option("B", false)), "exactly 4 options, got 2"},
+		{"duplicate options", multipleChoice("Q", option("A", true), option("b", false), option(" B ", false), option("D", false)), `option "B" is duplicated`},
+		{"empty option", multipleChoice("Q", option("A", true), option("", false), option("C", false), option("D", false)), "an option text is empty"},
+		{"valid true/false", LLMQuestion{QuestionText: "Q", Type: "true_false", Options: []LLMQuestionOption{option("false", false), option("True", true)}}, ""},
+		{"true/false with other options", LLMQuestion{QuestionText: "Q", Type: "true_false", Options: []LLMQuestionOption{option("Yes", true), option("No", false)}}, `exactly the options "True" and "False"`},
+		{"valid open-ended", openEndedQuestion("Q"), ""},
+		{"open-ended with options", LLMQuestion{QuestionText: "Q", Type: "open_ended", Options: []LLMQuestionOption{option("A", true)}}, "must not have options"},
+		{"open-ended without reference answer", LLMQuestion{QuestionText: "Q", Type: "open_ended", KeyPoints: []string{"A", "B"}}, "need a reference_answer"},
+		{"open-ended with one key point", openEndedQuestion("Q", "A"), "between 2 and 5 key_points, got 1"},
+		{"duplicate key points", openEndedQuestion("Q", "Cheap", " cheap "), `key point "cheap" is duplicated`},
+		{"empty key point", openEndedQuestion("Q", "A", " "), "a key point is empty"},
+		{"unknown type", LLMQuestion{QuestionText: "Q", Type: "essay"}, `unknown type "essay"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems := strings.Join(questionProblems(tt.question), "; ")
+
+			if tt.want == "" && problems != "" {
+				t.Errorf("expected no problems, got %s", problems)
+			}
+			if !strings.Contains(problems, tt.want) {
+				t.Errorf("expected a problem containing %q, got %q", tt.want, problems)
+			}
+		})
+	}
+}
+
+func TestValidateQuestions(t *testing.T) {
+	source := &LLMQuestionSource{Quote: "Go is a programming language", Start: "0:00"}
+	valid := multipleChoice("Q", option("A", true), option("B", false), option("C", false), option("D", false))
+	valid.Source = source
+	invalid := multipleChoice("Bad", option("A", true))
+	invalid.Source = source
+	openEnded := openEndedQuestion("Explain")
+	openEnded.Source = &LLMQuestionSource{Quote: "Goroutines make concurrency cheap.", Start: "0:08"}
+	ungrounded := openEndedQuestion("Explain more")
+
+	questions, problems := validateQuestions([]LLMQuestion{invalid, valid, valid, ungrounded, openEnded}, QuizConfig{MultipleChoice: 1, TrueFalse: 1, OpenEnded: 1, Difficulty: Medium}, quizChunks(quizTranscriptText))
+
+	if len(questions) != 2 || questions[0].QuestionText != "Q" || questions[1].Type != "open_ended" {
+		t.Errorf("expected the valid questions within the configured counts, got %+v", questions)
+	}
+	if span := questions[1].Span; span.StartPosition != 8 || span.EndPosition != 11 || span.StartTime != 8 || span.EndTime != 11.5 {
+		t.Errorf("expected the open-ended source at chunks 8-11, got %+v", span)
+	}
+	want := []string{
+		"Question 1: multiple_choice questions need exactly 4 options, got 1",
+		"Question 4: source quote is missing",
+		"Expected 1 valid true_false questions, got 0",
+	}
+	if strings.Join(problems, "|") != strings.Join(want, "|") {
+		t.Errorf("expected problems %v, got %v", want, problems)
+	}
+}
+
+func TestLocateSource(t *testing.T) {
+	chunks := quizChunks(quizTranscriptText)
+
+	tests := []struct {
+		name   string
+		source *LLMQuestionSource
+		want   string
+	}{
+		{"missing source", nil, "source quote is missing"},
+		{"empty quote", &LLMQuestionSource{Quote: " "}, "source quote is missing"},
+		{"quote too short", &LLMQuestionSource{Quote: "Google"}, "between 3 and 60 words, got 1"},
+		{"quote not in the transcript", &LLMQuestionSource{Quote: "Go was created at Bell Labs"}, "was not found word for word"},
+		{"quote with other casing and punctuation", &LLMQuestionSource{Quote: "created at google", Start: "not a timestamp"}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			span, problem := locateSource(tt.source, chunks)
+
+			if !strings.Contains(problem, tt.want) || (tt.want == "" && problem != "") {
+				t.Errorf("expected a problem containing %q, got %q", tt.want, problem)
+			}
+			if tt.want == "" && (span.StartPosition != 5 || span.EndPosition != 7) {
+				t.Errorf("expected the quote at chunks 5-7, got %+v", span)
+			}
+		})
+	}
+}
@@ -0,0 +1,188 @@
+package transcripts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// interruptionMinOverlap ignores overlaps shorter than this many seconds, which are
+// usually word boundary jitter from diarization rather than someone talking over another
+const interruptionMinOverlap = 0.2
+
+// GetAnalytics returns the speaking statistics of an episode transcript. They are cached
+// when the transcript completes and computed on demand when the cache is missing.
+func (s *Service) GetAnalytics(episodeID int) (TranscriptAnalytics, *errors.ErrorResponse) {
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return TranscriptAnalytics{}, errResp
+	}
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return TranscriptAnalytics{}, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Analytics are available once the transcript is complete",
+		}
+	}
+
+	speakers, err := s.repo.GetSpeakersByTranscriptID(transcript.ID)
+	if err != nil {
+		return TranscriptAnalytics{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch speakers",
+		}
+	}
+
+	analytics, err := s.repo.GetAnalytics(transcript.ID)
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			return TranscriptAnalytics{}, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to fetch analytics",
+			}
+		}
+
+		if analytics, err = s.refreshAnalytics(transcript.ID); err != nil {
+			return TranscriptAnalytics{}, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to compute analytics",
+			}
+		}
+	}
+
+	return withSpeakerNames(analytics, speakers), nil
+}
+
+// scheduleAnalytics recomputes the cached analytics of an episode in the background
+func (s *Service) scheduleAnalytics(episodeID int) {
+	if s.analytics == nil {
+		return
+	}
+
+	s.analytics.schedule(episodeID, func() {
+		transcript, errResp := s.getEditableTranscript(episodeID)
+		if errResp != nil {
+			return
+		}
+
+		if _, err := s.refreshAnalytics(transcript.ID); err != nil {
+			s.log.Error("Failed to compute transcript analytics", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+	})
+}
+
+// refreshAnalytics computes the analytics of a transcript from its chunks and caches them
+func (s *Service) refreshAnalytics(transcriptID int) (TranscriptAnalytics, error) {
+	chunks, err := s.repo.GetChunksByTranscriptID(transcriptID)
+	if err != nil {
+		return TranscriptAnalytics{}, fmt.Errorf("failed to fetch chunks: %w", err)
+	}
+
+	analytics := computeAnalytics(transcriptID, chunks)
+	if err := s.repo.SaveAnalytics(analytics); err != nil {
+		return TranscriptAnalytics{}, fmt.Errorf("failed to save analytics: %w", err)
+	}
+
+	return analytics, nil
+}
+
+// computeAnalytics derives speaking statistics from the chunk timings. A turn is a run of
+// consecutive chunks of one speaker, and a turn that starts before the previous one ended
+// counts as an interruption by its speaker. Names are left for withSpeakerNames so renames
+// don't require a recompute.
+func computeAnalytics(transcriptID int, chunks []TranscriptChunk) TranscriptAnalytics {
+	analytics := TranscriptAnalytics{
+		TranscriptID: transcriptID,
+		Speakers:     []SpeakerAnalytics{},
+		ComputedAt:   time.Now(),
+	}
+	if len(chunks) == 0 {
+		return analytics
+	}
+
+	bySpeaker := make(map[int]*SpeakerAnalytics)
+	var previous *Monologue
+
+	for i := 0; i < len(chunks); {
+		speakerIndex := chunks[i].SpeakerIndex
+		turn := Monologue{StartPosition: chunks[i].Position, Start: chunks[i].StartTime, End: chunks[i].EndTime}
+		for ; i < len(chunks) && chunks[i].SpeakerIndex == speakerIndex; i++ {
+			turn.EndPosition = chunks[i].Position
+			turn.End = math.Max(turn.End, chunks[i].EndTime)
+			turn.Words += len(strings.Fields(chunks[i].Text))
+		}
+		turn.Duration = round(turn.End-turn.Start, 2)
+
+		speaker, ok := bySpeaker[speakerIndex]
+		if !ok {
+			speaker = &SpeakerAnalytics{SpeakerIndex: speakerIndex}
+			bySpeaker[speakerIndex] = speaker
+		}
+		speaker.TalkTime += turn.End - turn.Start
+		speaker.Words += turn.Words
+		speaker.Turns++
+		if speaker.Turns == 1 || turn.Duration > speaker.LongestMonologue.Duration {
+			speaker.LongestMonologue = turn
+		}
+		if previous != nil && previous.End-turn.Start >= interruptionMinOverlap {
+			speaker.Interruptions++
+			analytics.Interruptions++
+		}
+
+		analytics.Words += turn.Words
+		analytics.Turns++
+		analytics.Duration = math.Max(analytics.Duration, turn.End)
+		previous = &turn
+	}
+
+	analytics.Duration = round(analytics.Duration-chunks[0].StartTime, 2)
+	analytics.WordsPerMinute = wordsPerMinute(analytics.Words, analytics.Duration)
+
+	totalTalkTime := 0.0
+	for _, speaker := range bySpeaker {
+		totalTalkTime += speaker.TalkTime
+	}
+	for _, speaker := range bySpeaker {
+		speaker.WordsPerMinute = wordsPerMinute(speaker.Words, speaker.TalkTime)
+		if totalTalkTime > 0 {
+			speaker.TalkShare = round(speaker.TalkTime/totalTalkTime, 3)
+		}
+		speaker.TalkTime = round(speaker.TalkTime, 2)
+		analytics.Speakers = append(analytics.Speakers, *speaker)
+	}
+	sort.Slice(analytics.Speakers, func(i, j int) bool {
+		return analytics.Speakers[i].SpeakerIndex < analytics.Speakers[j].SpeakerIndex
+	})
+
+	return analytics
+}
+
+// withSpeakerNames fills in the current speaker names
+func withSpeakerNames(analytics TranscriptAnalytics, speakers []TranscriptSpeaker) TranscriptAnalytics {
+	names := speakerNames(speakers)
+	named := make([]SpeakerAnalytics, len(analytics.Speakers))
+	for i, speaker := range analytics.Speakers {
+		speaker.Name = speakerName(names, speaker.SpeakerIndex)
+		named[i] = speaker
+	}
+	analytics.Speakers = named
+	return analytics
+}
+
+func wordsPerMinute(words int, seconds float64) float64 {
+	if seconds <= 0 {
+		return 0
+	}
+	return round(float64(words)/(seconds/60), 1)
+}
+
+func round(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,158 @@
+package transcripts
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupAnalyticsService mocks a transcript with the given chunks and cached analytics (nil
+// for none), and records the analytics written by SaveAnalytics
+func setupAnalyticsService(status TranscriptStatus, chunks []TranscriptChunk, cached *TranscriptAnalytics) (*Service, *[]TranscriptAnalytics) {
+	service, _ := setupSpeakerService(status)
+	service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+		return chunks, nil
+	}
+
+	var saved []TranscriptAnalytics
+	service.repo.analyticsRepo.Executor = utils.QueryExecutor[TranscriptAnalytics]{
+		QueryItem: func(query string, args ...any) (TranscriptAnalytics, error) {
+			if cached == nil {
+				return TranscriptAnalytics{}, fmt.Errorf("no rows in result set")
+			}
+			return *cached, nil
+		},
+		Exec: func(query string, args ...any) error {
+			saved = append(saved, TranscriptAnalytics{TranscriptID: args[0].(int), Words: args[2].(int)})
+			return nil
+		},
+	}
+
+	return service, &saved
+}
+
+func TestComputeAnalytics(t *testing.T) {
+	chunk := func(position, speaker int, start, end float64, text string) TranscriptChunk {
+		return TranscriptChunk{Position: position, SpeakerIndex: speaker, StartTime: start, EndTime: end, Text: text}
+	}
+	chunks := []TranscriptChunk{
+		chunk(0, 0, 0, 10, "one two three four five"),
+		chunk(1, 0, 10, 20, "six seven eight nine ten"),
+		// Speaker 1 starts talking over speaker 0
+		chunk(2, 1, 18, 30, "eleven twelve"),
+		// Speaker 0 starts right as speaker 1 stops, which isn't an interruption
+		chunk(3, 0, 29.9, 40, "thirteen"),
+	}
+
+	analytics := computeAnalytics(10, chunks)
+
+	if analytics.TranscriptID != 10 || analytics.Duration != 40 || analytics.Words != 13 || analytics.Turns != 3 || analytics.Interruptions != 1 {
+		t.Fatalf("Unexpected totals: %+v", analytics)
+	}
+	if analytics.WordsPerMinute != 19.5 {
+		t.Errorf("Expected 19.5 words per minute, got %v", analytics.WordsPerMinute)
+	}
+	if len(analytics.Speakers) != 2 {
+		t.Fatalf("Expected 2 speakers, got %+v", analytics.Speakers)
+	}
+
+	host, guest := analytics.Speakers[0], analytics.Speakers[1]
+	if host.SpeakerIndex != 0 || host.Words != 11 || host.Turns != 2 || host.TalkTime != 30.1 || host.Interruptions != 0 {
+		t.Errorf("Unexpected host analytics: %+v", host)
+	}
+	if host.WordsPerMinute != 21.9 || host.TalkShare != 0.715 {
+		t.Errorf("Unexpected host rates: %+v", host)
+	}
+	wantMonologue := Monologue{StartPosition: 0, EndPosition: 1, Start: 0, End: 20, Duration: 20, Words: 10}
+	if host.LongestMonologue != wantMonologue {
+		t.Errorf("Expected longest monologue %+v, got %+v", wantMonologue, host.LongestMonologue)
+	}
+	if guest.SpeakerIndex != 1 || guest.Words != 2 || guest.Turns != 1 || guest.TalkTime != 12 || guest.Interruptions != 1 {
+		t.Errorf("Unexpected guest analytics: %+v", guest)
+	}
+
+	if empty := computeAnalytics(10, nil); empty.Turns != 0 || empty.Speakers == nil {
+		t.Errorf("Expected empty analytics for an empty transcript, got %+v", empty)
+	}
+}
+
+func TestTranscriptService_GetAnalytics(t *testing.T) {
+	t.Run("computes and caches missing analytics", func(t *testing.T) {
+		service, saved := setupAnalyticsService(TranscriptStatusComplete, summaryChunks(6), nil)
+
+		analytics, errResp := service.GetAnalytics(1)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if analytics.Words != 6 || len(analytics.Speakers) != 2 {
+			t.Errorf("Unexpected analytics: %+v", analytics)
+		}
+		if analytics.Speakers[0].Name != "Speaker 0" || analytics.Speakers[1].Name != "Jane Doe" {
+			t.Errorf("Expected the current speaker names, got %+v", analytics.Speakers)
+		}
+		if len(*saved) != 1 || (*saved)[0].TranscriptID != 10 || (*saved)[0].Words != 6 {
+			t.Errorf("Expected the analytics to be cached, got %+v", *saved)
+		}
+	})
+
+	t.Run("returns cached analytics with current names", func(t *testing.T) {
+		cached := &TranscriptAnalytics{TranscriptID: 10, Words: 42, Speakers: []SpeakerAnalytics{{SpeakerIndex: 1, Name: "Old name"}}}
+		service, saved := setupAnalyticsService(TranscriptStatusComplete, nil, cached)
+
+		analytics, errResp := service.GetAnalytics(1)
+
+		if errResp != nil || analytics.Words != 42 || analytics.Speakers[0].Name != "Jane Doe" {
+			t.Errorf("Expected the cached analytics, got %+v, %v", analytics, errResp)
+		}
+		if len(*saved) != 0 {
+			t.Errorf("Expected no recompute, got %+v", *saved)
+		}
+	})
+
+	t.Run("returns not found until the transcript is complete", func(t *testing.T) {
+		service, _ := setupAnalyticsService(TranscriptStatusProcessing, nil, nil)
+
+		_, errResp := service.GetAnalytics(1)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptHandler_Analytics(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"analytics", http.MethodGet, "/transcripts/1/analytics", http.StatusOK, `"words_per_minute":`},
+		{"exported with the transcript", http.MethodGet, "/transcripts/1/export", http.StatusOK, `"analytics":{"transcript_id":10`},
+		{"method not allowed", http.MethodPost, "/transcripts/1/analytics", http.StatusMethodNotAllowed, ""},
+		{"unknown subpath", http.MethodGet, "/transcripts/1/analytics/speakers", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupAnalyticsService(TranscriptStatusComplete, summaryChunks(3), nil)
+
+			w := httptest.NewRecorder()
+			NewTranscriptHandler(service).HandleRequest(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,141 @@
+package transcripts
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// handleAnnotations routes /transcripts/:episode_id/annotations/* requests. Annotations
+// belong to the requesting user; other users' annotations are not found.
+func (h *TranscriptHandler) handleAnnotations(w http.ResponseWriter, r *http.Request, episodeID int, parts []string) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	switch len(parts) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			// GET /transcripts/:episode_id/annotations
+			annotations, errResp := h.service.GetAnnotations(episodeID, userID)
+			if errResp != nil {
+				h.encodeError(w, errResp)
+				return
+			}
+			utils.EncodeResponse(w, http.StatusOK, annotations)
+		case http.MethodPost:
+			// POST /transcripts/:episode_id/annotations
+			h.handleCreateAnnotation(w, r, episodeID, userID)
+		default:
+			utils.NotAllowed(w)
+		}
+	case 1:
+		annotationID, err := strconv.Atoi(parts[0])
+		if err != nil {
+			utils.NotFound(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodPatch:
+			// PATCH /transcripts/:episode_id/annotations/:annotation_id
+			h.handleUpdateAnnotation(w, r, episodeID, userID, annotationID)
+		case http.MethodDelete:
+			// DELETE /transcripts/:episode_id/annotations/:annotation_id
+			if errResp := h.service.DeleteAnnotation(episodeID, userID, annotationID); errResp != nil {
+				h.encodeError(w, errResp)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			utils.NotAllowed(w)
+		}
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+func (h *TranscriptHandler) handleCreateAnnotation(w http.ResponseWriter, r *http.Request, episodeID, userID int) {
+	req, errResp := utils.DecodeBody[CreateAnnotationRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	annotation, errResp := h.service.CreateAnnotation(episodeID, userID, req)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusCreated, annotation)
+}
+
+func (h *TranscriptHandler) handleUpdateAnnotation(w http.ResponseWriter, r *http.Request, episodeID, userID, annotationID int) {
+	req, errResp := utils.DecodeBody[UpdateAnnotationRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	annotation, errResp := h.service.UpdateAnnotation(episodeID, userID, annotationID, req)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, annotation)
+}
+
+// handleSearchAnnotations lists the user's annotations across all episodes
+func (h *TranscriptHandler) handleSearchAnnotations(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+	query := r.URL.Query()
+
+	limit := DefaultAnnotationLimit
+	if value := query.Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > MaxAnnotationLimit {
+			utils.EncodeResponse(w, http.StatusBadRequest, &errors.ErrorResponse{
+				Message: errors.ValidationError,
+				Details: fmt.Sprintf("limit must be a number between 1 and %d", MaxAnnotationLimit),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	offset := 0
+	if value := query.Get("offset"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			utils.EncodeResponse(w, http.StatusBadRequest, &errors.ErrorResponse{
+				Message: errors.ValidationError,
+				Details: "offset must be a non-negative number",
+			})
+			return
+		}
+		offset = parsed
+	}
+
+	annotations, errResp := h.service.SearchAnnotations(userID, query.Get("q"), limit, offset)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, annotations)
+}
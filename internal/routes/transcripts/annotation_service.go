@@ -0,0 +1,193 @@
+package transcripts
+
+import (
+	"fmt"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+const (
+	DefaultAnnotationLimit = 50
+	MaxAnnotationLimit     = 100
+	// maxAnnotationSearchLength keeps the ILIKE patterns of note searches reasonable
+	maxAnnotationSearchLength = 200
+)
+
+// CreateAnnotation highlights a range of a complete transcript for a user. The range is
+// anchored to the start time of its first chunk and the end time of its last one.
+func (s *Service) CreateAnnotation(episodeID, userID int, req CreateAnnotationRequest) (Annotation, *errors.ErrorResponse) {
+	s.log.Info("Creating annotation", map[string]any{
+		"episodeID":     episodeID,
+		"startPosition": *req.StartPosition,
+		"endPosition":   *req.EndPosition,
+	})
+
+	annotation := Annotation{
+		UserID:    userID,
+		EpisodeID: episodeID,
+		Color:     req.Color,
+		Note:      strings.TrimSpace(req.Note),
+	}
+	if annotation.Color == "" {
+		annotation.Color = DefaultAnnotationColor
+	}
+
+	if errResp := s.anchorAnnotation(&annotation, *req.StartPosition, *req.EndPosition); errResp != nil {
+		return Annotation{}, errResp
+	}
+
+	created, err := s.repo.CreateAnnotation(annotation)
+	if err != nil {
+		return Annotation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to save annotation",
+		}
+	}
+
+	return created, nil
+}
+
+// GetAnnotations returns the annotations of a user on an episode in transcript order
+func (s *Service) GetAnnotations(episodeID, userID int) ([]Annotation, *errors.ErrorResponse) {
+	annotations, err := s.repo.GetAnnotationsByEpisodeID(userID, episodeID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch annotations",
+		}
+	}
+	if annotations == nil {
+		annotations = []Annotation{}
+	}
+
+	return annotations, nil
+}
+
+// SearchAnnotations returns the annotations of a user across all episodes, most recently
+// updated first, optionally matching the search in the note, annotated text or episode name
+func (s *Service) SearchAnnotations(userID int, search string, limit, offset int) ([]Annotation, *errors.ErrorResponse) {
+	search = strings.TrimSpace(search)
+	if len(search) > maxAnnotationSearchLength {
+		return nil, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: fmt.Sprintf("Search must be at most %d characters", maxAnnotationSearchLength),
+		}
+	}
+
+	annotations, err := s.repo.SearchAnnotations(userID, search, limit, offset)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to search annotations",
+		}
+	}
+	if annotations == nil {
+		annotations = []Annotation{}
+	}
+
+	return annotations, nil
+}
+
+// UpdateAnnotation changes the range, color or note of one of the user's annotations
+func (s *Service) UpdateAnnotation(episodeID, userID, annotationID int, req UpdateAnnotationRequest) (Annotation, *errors.ErrorResponse) {
+	annotation, errResp := s.getAnnotation(episodeID, userID, annotationID)
+	if errResp != nil {
+		return Annotation{}, errResp
+	}
+
+	if req.StartPosition != nil {
+		if errResp := s.anchorAnnotation(&annotation, *req.StartPosition, *req.EndPosition); errResp != nil {
+			return Annotation{}, errResp
+		}
+	}
+	if req.Color != nil {
+		annotation.Color = *req.Color
+	}
+	if req.Note != nil {
+		annotation.Note = strings.TrimSpace(*req.Note)
+	}
+
+	updated, err := s.repo.UpdateAnnotation(annotation)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return Annotation{}, annotationNotFound()
+		}
+		return Annotation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to update annotation",
+		}
+	}
+
+	return updated, nil
+}
+
+// DeleteAnnotation removes one of the user's annotations
+func (s *Service) DeleteAnnotation(episodeID, userID, annotationID int) *errors.ErrorResponse {
+	if err := s.repo.DeleteAnnotation(annotationID, userID, episodeID); err != nil {
+		if err.Error() == "no rows in result set" {
+			return annotationNotFound()
+		}
+		return &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to delete annotation",
+		}
+	}
+
+	return nil
+}
+
+func (s *Service) getAnnotation(episodeID, userID, annotationID int) (Annotation, *errors.ErrorResponse) {
+	annotation, err := s.repo.GetAnnotation(annotationID, userID, episodeID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return Annotation{}, annotationNotFound()
+		}
+		return Annotation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch annotation",
+		}
+	}
+
+	return annotation, nil
+}
+
+// anchorAnnotation sets the range of the annotation and the times it is anchored to, from
+// the chunks of the episode's complete transcript
+func (s *Service) anchorAnnotation(annotation *Annotation, startPosition, endPosition int) *errors.ErrorResponse {
+	transcript, errResp := s.getTranscript(annotation.EpisodeID)
+	if errResp != nil {
+		return errResp
+	}
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Transcript must be complete before it can be annotated",
+		}
+	}
+
+	chunks, err := s.repo.GetChunksInRange(transcript.ID, startPosition, endPosition)
+	if err != nil {
+		return &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript chunks",
+		}
+	}
+	if len(chunks) != endPosition-startPosition+1 {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Range is outside the transcript",
+		}
+	}
+
+	annotation.StartPosition, annotation.EndPosition = startPosition, endPosition
+	annotation.StartTime, annotation.EndTime = chunks[0].StartTime, chunks[len(chunks)-1].EndTime
+	return nil
+}
+
+func annotationNotFound() *errors.ErrorResponse {
+	return &errors.ErrorResponse{
+		Message: errors.DatabaseNotFound,
+		Details: "Annotation not found",
+	}
+}
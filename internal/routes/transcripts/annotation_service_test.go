@@ -0,0 +1,199 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupAnnotationService mocks a complete transcript of ten one-second chunks and records
+// the arguments of the annotation queries. Annotation 3 belongs to user 5 on episode 1.
+func setupAnnotationService() (*Service, *[]any) {
+	service, _ := setupSpeakerService(TranscriptStatusComplete)
+	var saved []any
+
+	service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+		chunks := []TranscriptChunk{}
+		for position := args[1].(int); position <= min(args[2].(int), 9); position++ {
+			chunks = append(chunks, TranscriptChunk{Position: position, StartTime: float64(position), EndTime: float64(position) + 0.5})
+		}
+		return chunks, nil
+	}
+	service.repo.annotationRepo.Executor = utils.QueryExecutor[Annotation]{
+		QueryItem: func(query string, args ...any) (Annotation, error) {
+			switch {
+			case strings.Contains(query, "INSERT"):
+				saved = args
+				return Annotation{ID: 3, UserID: args[0].(int), Color: args[6].(string), Note: args[7].(string)}, nil
+			case strings.Contains(query, "UPDATE"):
+				saved = args
+				return Annotation{ID: 3, Color: args[7].(string), Note: args[8].(string)}, nil
+			case args[0].(int) == 3 && args[1].(int) == 5 && args[2].(int) == 1:
+				return Annotation{ID: 3, UserID: 5, EpisodeID: 1, StartPosition: 2, EndPosition: 4, StartTime: 2, EndTime: 4.5, Color: "green", Note: "Keep"}, nil
+			}
+			return Annotation{}, fmt.Errorf("no rows in result set")
+		},
+		QueryList: func(query string, args ...any) ([]Annotation, error) {
+			saved = args
+			return nil, nil
+		},
+	}
+
+	return service, &saved
+}
+
+func TestTranscriptService_CreateAnnotation(t *testing.T) {
+	t.Run("anchors the range to the chunk times", func(t *testing.T) {
+		service, saved := setupAnnotationService()
+		start, end := 2, 4
+
+		annotation, errResp := service.CreateAnnotation(1, 5, CreateAnnotationRequest{StartPosition: &start, EndPosition: &end, Note: " Great point "})
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		want := []any{5, 1, 2, 4, 2.0, 4.5, DefaultAnnotationColor, "Great point"}
+		if fmt.Sprint(*saved) != fmt.Sprint(want) {
+			t.Errorf("Expected %v to be saved, got %v", want, *saved)
+		}
+		if annotation.ID != 3 || annotation.Color != DefaultAnnotationColor {
+			t.Errorf("Unexpected annotation: %+v", annotation)
+		}
+	})
+
+	t.Run("rejects a range outside the transcript", func(t *testing.T) {
+		service, _ := setupAnnotationService()
+		start, end := 8, 12
+
+		_, errResp := service.CreateAnnotation(1, 5, CreateAnnotationRequest{StartPosition: &start, EndPosition: &end})
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError {
+			t.Errorf("Expected a validation error, got %v", errResp)
+		}
+	})
+
+	t.Run("requires a complete transcript", func(t *testing.T) {
+		service, _ := setupAnnotationService()
+		service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+			return Transcript{ID: 10, EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+		}
+		start, end := 0, 1
+
+		_, errResp := service.CreateAnnotation(1, 5, CreateAnnotationRequest{StartPosition: &start, EndPosition: &end})
+
+		if errResp == nil || errResp.Details != "Transcript must be complete before it can be annotated" {
+			t.Errorf("Expected a validation error, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptService_UpdateAnnotation(t *testing.T) {
+	t.Run("keeps the fields that are not set", func(t *testing.T) {
+		service, saved := setupAnnotationService()
+		color := "blue"
+
+		annotation, errResp := service.UpdateAnnotation(1, 5, 3, UpdateAnnotationRequest{Color: &color})
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		want := []any{3, 5, 1, 2, 4, 2.0, 4.5, "blue", "Keep"}
+		if fmt.Sprint(*saved) != fmt.Sprint(want) {
+			t.Errorf("Expected %v to be saved, got %v", want, *saved)
+		}
+		if annotation.Color != "blue" {
+			t.Errorf("Unexpected annotation: %+v", annotation)
+		}
+	})
+
+	t.Run("anchors a new range", func(t *testing.T) {
+		service, saved := setupAnnotationService()
+		start, end := 6, 7
+
+		if _, errResp := service.UpdateAnnotation(1, 5, 3, UpdateAnnotationRequest{StartPosition: &start, EndPosition: &end}); errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+
+		if args := *saved; args[3] != 6 || args[4] != 7 || args[5] != 6.0 || args[6] != 7.5 {
+			t.Errorf("Expected the new range to be anchored, got %v", args)
+		}
+	})
+
+	t.Run("does not find annotations of other users", func(t *testing.T) {
+		service, _ := setupAnnotationService()
+		note := "Mine now"
+
+		_, errResp := service.UpdateAnnotation(1, 6, 3, UpdateAnnotationRequest{Note: &note})
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptService_SearchAnnotations(t *testing.T) {
+	service, saved := setupAnnotationService()
+
+	annotations, errResp := service.SearchAnnotations(5, " 50%_off ", 20, 40)
+
+	if errResp != nil {
+		t.Fatalf("Expected no error, got %v", errResp)
+	}
+	if annotations == nil || len(annotations) != 0 {
+		t.Errorf("Expected an empty list, got %v", annotations)
+	}
+	want := []any{5, `%50\%\_off%`, 20, 40}
+	if fmt.Sprint(*saved) != fmt.Sprint(want) {
+		t.Errorf("Expected query arguments %v, got %v", want, *saved)
+	}
+
+	if _, errResp := service.SearchAnnotations(5, strings.Repeat("a", maxAnnotationSearchLength+1), 20, 0); errResp == nil {
+		t.Error("Expected a long search to be rejected")
+	}
+}
+
+func TestTranscriptHandler_Annotations(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		wantStatus int
+	}{
+		{"list", http.MethodGet, "/transcripts/1/annotations", "", http.StatusOK},
+		{"create", http.MethodPost, "/transcripts/1/annotations", `{"start_position": 1, "end_position": 2, "color": "pink"}`, http.StatusCreated},
+		{"create with unknown color", http.MethodPost, "/transcripts/1/annotations", `{"start_position": 1, "end_position": 2, "color": "black"}`, http.StatusBadRequest},
+		{"create with reversed range", http.MethodPost, "/transcripts/1/annotations", `{"start_position": 2, "end_position": 1}`, http.StatusBadRequest},
+		{"update", http.MethodPatch, "/transcripts/1/annotations/3", `{"note": "Later"}`, http.StatusOK},
+		{"update with half a range", http.MethodPatch, "/transcripts/1/annotations/3", `{"start_position": 1}`, http.StatusBadRequest},
+		{"update nothing", http.MethodPatch, "/transcripts/1/annotations/3", `{}`, http.StatusBadRequest},
+		{"delete", http.MethodDelete, "/transcripts/1/annotations/3", "", http.StatusNoContent},
+		{"delete another user's annotation", http.MethodDelete, "/transcripts/1/annotations/4", "", http.StatusNotFound},
+		{"wrong method", http.MethodPut, "/transcripts/1/annotations/3", "", http.StatusMethodNotAllowed},
+		{"all my notes", http.MethodGet, "/transcripts/annotations?q=idea&limit=5", "", http.StatusOK},
+		{"all my notes with bad offset", http.MethodGet, "/transcripts/annotations?offset=-1", "", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupAnnotationService()
+			handler := NewTranscriptHandler(service)
+
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middlewares.UserIDContextKey, 5))
+			w := httptest.NewRecorder()
+			handler.HandleRequest(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,244 @@
+package transcripts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// chapterCandidate is a chapter proposed by the LLM, before alignment to the chunks
+type chapterCandidate struct {
+	Title string
+	Start float64
+}
+
+// llmChapters is the LLM response for one transcript window and for the merge step
+type llmChapters struct {
+	Chapters []struct {
+		Title string `json:"title"`
+		Start string `json:"start"`
+	} `json:"chapters"`
+}
+
+// GetChapters returns the chapters of an episode. When an episode with a complete transcript
+// has none yet, or their last generation was abandoned or failed a while ago (see
+// needsRegeneration), generation is started and a processing placeholder is returned.
+func (s *Service) GetChapters(episodeID int) (EpisodeChapters, *errors.ErrorResponse) {
+	chapters, err := s.repo.GetChaptersByEpisodeID(episodeID)
+	if err == nil && !needsRegeneration(chapters.Status, chapters.UpdatedAt) {
+		return chapters, nil
+	}
+	if err != nil && err.Error() != "no rows in result set" {
+		return EpisodeChapters{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch chapters",
+		}
+	}
+
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return EpisodeChapters{}, errResp
+	}
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return EpisodeChapters{}, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Chapters are available once the transcript is complete",
+		}
+	}
+
+	if !s.llmAvailable() {
+		return EpisodeChapters{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "LLM client not configured",
+		}
+	}
+
+	s.scheduleChapters(episodeID)
+
+	return EpisodeChapters{
+		EpisodeID:    episodeID,
+		TranscriptID: transcript.ID,
+		Status:       string(TranscriptStatusProcessing),
+		Chapters:     []Chapter{},
+	}, nil
+}
+
+// scheduleChapters (re)generates the chapters of an episode in the background
+func (s *Service) scheduleChapters(episodeID int) {
+	if s.chapters == nil || !s.llmAvailable() {
+		return
+	}
+
+	s.chapters.schedule(episodeID, func() {
+		if err := s.generateChapters(episodeID); err != nil {
+			s.log.Error("Failed to generate episode chapters", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+	})
+}
+
+// generateChapters proposes chapters for each transcript window, merges the chapters
+// split at window boundaries, and aligns them to the chunk start times
+func (s *Service) generateChapters(episodeID int) error {
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return fmt.Errorf("%s", errResp.Details)
+	}
+
+	if _, err := s.repo.StartChapters(episodeID, transcript.ID); err != nil {
+		return fmt.Errorf("failed to start chapters: %w", err)
+	}
+
+	fail := func(err error) error {
+		_ = s.repo.FailChapters(episodeID, err.Error())
+		return err
+	}
+
+	episode, chunks, windows, err := s.loadTranscriptWindows(episodeID, transcript.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	s.log.Info("Generating episode chapters", map[string]any{
+		"episodeID": episodeID,
+		"windows":   len(windows),
+	})
+
+	candidates, err := s.chapterizeWindows(episode.Name, windows)
+	if err != nil {
+		return fail(err)
+	}
+
+	// A single window already saw the whole episode
+	if len(windows) > 1 {
+		candidates, err = s.mergeChapters(episode, candidates)
+		if err != nil {
+			return fail(err)
+		}
+	}
+
+	chapters := alignChapters(candidates, chunks)
+	if len(chapters) == 0 {
+		return fail(fmt.Errorf("LLM returned no chapters"))
+	}
+
+	if err := s.repo.CompleteChapters(episodeID, chapters); err != nil {
+		return fmt.Errorf("failed to save chapters: %w", err)
+	}
+
+	s.log.Info("Episode chapters generated", map[string]any{
+		"episodeID": episodeID,
+		"chapters":  len(chapters),
+	})
+
+	return nil
+}
+
+// chapterizeWindows proposes chapters for the windows concurrently, keeping only starts
+// that fall inside their window
+func (s *Service) chapterizeWindows(episodeName string, windows []transcriptWindow) ([]chapterCandidate, error) {
+	parts, err := mapWindows(windows, func(i int, window transcriptWindow) (llmChapters, error) {
+		return chatJSON[llmChapters](s.llmClient, llm.ChatRequest{
+			Messages: []llm.Message{
+				{Role: "system", Content: ChapterizeWindowSystemPrompt},
+				{Role: "user", Content: ChapterizeWindowUserPrompt(episodeName, i+1, len(windows), window.Text)},
+			},
+			MaxTokens: 500,
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to chapterize %w", err)
+	}
+
+	candidates := []chapterCandidate{}
+	for i, part := range parts {
+		// Timestamps are rendered in whole seconds
+		candidates = append(candidates, toChapterCandidates(part, math.Floor(windows[i].Start), windows[i].End)...)
+	}
+
+	return candidates, nil
+}
+
+// mergeChapters asks the LLM to join candidates that continue the same topic across windows
+func (s *Service) mergeChapters(episode Episode, candidates []chapterCandidate) ([]chapterCandidate, error) {
+	var candidatesText strings.Builder
+	for _, candidate := range candidates {
+		fmt.Fprintf(&candidatesText, "[%s] %s\n", FormatTimestamp(candidate.Start), candidate.Title)
+	}
+
+	merged, err := chatJSON[llmChapters](s.llmClient, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: MergeChaptersSystemPrompt},
+			{Role: "user", Content: MergeChaptersUserPrompt(episode.Name, episode.Description, candidatesText.String())},
+		},
+		MaxTokens: 800,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to merge chapters: %w", err)
+	}
+
+	return toChapterCandidates(merged, 0, math.Inf(1)), nil
+}
+
+// toChapterCandidates converts the chapters of an LLM response, dropping chapters without
+// a title or with a start that can't be read or is outside [from, to]
+func toChapterCandidates(response llmChapters, from, to float64) []chapterCandidate {
+	candidates := make([]chapterCandidate, 0, len(response.Chapters))
+	for _, chapter := range response.Chapters {
+		title := strings.TrimSpace(chapter.Title)
+		start, ok := ParseTimestamp(chapter.Start)
+		if title == "" || !ok || start < from || start > to {
+			continue
+		}
+		candidates = append(candidates, chapterCandidate{Title: title, Start: start})
+	}
+	return candidates
+}
+
+// alignChapters moves each chapter start to the first chunk starting at or after it, so
+// chapters begin on a word. The first chapter is extended to the start of the transcript
+// and chapters landing on the same chunk as an earlier one are dropped.
+func alignChapters(candidates []chapterCandidate, chunks []TranscriptChunk) []Chapter {
+	chapters := []Chapter{}
+	if len(chunks) == 0 {
+		return chapters
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Start < candidates[j].Start })
+
+	for _, candidate := range candidates {
+		i := sort.Search(len(chunks), func(i int) bool { return chunks[i].StartTime >= candidate.Start })
+		if i == len(chunks) {
+			continue
+		}
+		if len(chapters) == 0 {
+			i = 0
+		} else if chunks[i].Position <= chapters[len(chapters)-1].StartPosition {
+			continue
+		}
+		chapters = append(chapters, Chapter{
+			Title:         candidate.Title,
+			Start:         chunks[i].StartTime,
+			StartPosition: chunks[i].Position,
+		})
+	}
+
+	last := chunks[len(chunks)-1]
+	for i := range chapters {
+		if i+1 < len(chapters) {
+			chapters[i].End = chapters[i+1].Start
+			chapters[i].EndPosition = chapters[i+1].StartPosition - 1
+		} else {
+			chapters[i].End = last.EndTime
+			chapters[i].EndPosition = last.Position
+		}
+	}
+
+	return chapters
+}
@@ -0,0 +1,272 @@
+package transcripts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupChapterService mocks a complete transcript with the given chunks and records the
+// chapters written by CompleteChapters
+func setupChapterService(client llm.LLMClient, chunks []TranscriptChunk) (*Service, chan []Chapter) {
+	service, _ := setupSummaryService(client, chunks)
+	service.chapters = newGenerationQueue[int]()
+	completed := make(chan []Chapter, 1)
+
+	service.repo.chapterRepo.Executor = utils.QueryExecutor[EpisodeChapters]{
+		QueryItem: func(query string, args ...any) (EpisodeChapters, error) {
+			if strings.Contains(query, "INSERT") {
+				return EpisodeChapters{EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+			}
+			return EpisodeChapters{}, fmt.Errorf("no rows in result set")
+		},
+		Exec: func(query string, args ...any) error {
+			if strings.Contains(query, "'complete'") {
+				completed <- args[1].([]Chapter)
+			}
+			return nil
+		},
+	}
+
+	return service, completed
+}
+
+func TestAlignChapters(t *testing.T) {
+	chunks := []TranscriptChunk{
+		{Position: 0, StartTime: 0.4, EndTime: 1},
+		{Position: 1, StartTime: 60.2, EndTime: 61},
+		{Position: 3, StartTime: 120.7, EndTime: 121},
+		{Position: 4, StartTime: 180, EndTime: 182.5},
+	}
+
+	chapters := alignChapters([]chapterCandidate{
+		{Title: "Guest", Start: 120},
+		{Title: "Intro", Start: 30},
+		{Title: "Same chunk", Start: 120.5},
+		{Title: "Topic", Start: 60},
+		{Title: "After the end", Start: 200},
+	}, chunks)
+
+	want := []Chapter{
+		{Title: "Intro", Start: 0.4, End: 60.2, StartPosition: 0, EndPosition: 0},
+		{Title: "Topic", Start: 60.2, End: 120.7, StartPosition: 1, EndPosition: 2},
+		{Title: "Guest", Start: 120.7, End: 182.5, StartPosition: 3, EndPosition: 4},
+	}
+	if len(chapters) != len(want) {
+		t.Fatalf("Expected %d chapters, got %+v", len(want), chapters)
+	}
+	for i := range want {
+		if chapters[i] != want[i] {
+			t.Errorf("Expected chapter %d to be %+v, got %+v", i, want[i], chapters[i])
+		}
+	}
+
+	if chapters := alignChapters([]chapterCandidate{{Title: "Intro"}}, nil); len(chapters) != 0 {
+		t.Errorf("Expected no chapters without chunks, got %+v", chapters)
+	}
+}
+
+func TestToChapterCandidates(t *testing.T) {
+	response, err := utils.DecodeResponse[llmChapters](`{"chapters": [
+		{"title": " Intro ", "start": "1:00"},
+		{"title": "", "start": "1:30"},
+		{"title": "Unreadable", "start": "soon"},
+		{"title": "Too late", "start": "9:00"}
+	]}`)
+	if err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+
+	candidates := toChapterCandidates(response, 60, 300)
+
+	if len(candidates) != 1 || candidates[0] != (chapterCandidate{Title: "Intro", Start: 60}) {
+		t.Errorf("Expected only the intro, got %+v", candidates)
+	}
+}
+
+func TestEpisodeChapters_PodcastChapters(t *testing.T) {
+	chapters := EpisodeChapters{Chapters: []Chapter{
+		{Title: "Intro", Start: 0, End: 60.5},
+		{Title: "Interview", Start: 60.5, End: 300},
+	}}
+
+	body, err := json.Marshal(chapters.PodcastChapters())
+	if err != nil {
+		t.Fatalf("Failed to encode chapters: %v", err)
+	}
+
+	want := `{"version":"1.2.0","chapters":[{"startTime":0,"endTime":60.5,"title":"Intro"},{"startTime":60.5,"endTime":300,"title":"Interview"}]}`
+	if string(body) != want {
+		t.Errorf("Expected %s, got %s", want, body)
+	}
+}
+
+func TestTranscriptService_GenerateChapters(t *testing.T) {
+	t.Run("single window is aligned without merging", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			ChapterizeWindowSystemPrompt: `{"chapters": [{"title": "Welcome", "start": "0:00"}, {"title": "Second half", "start": "1:30"}]}`,
+		}}
+		service, completed := setupChapterService(client, summaryChunks(6))
+
+		if err := service.generateChapters(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		chapters := <-completed
+		if len(chapters) != 2 || chapters[1].Title != "Second half" || chapters[1].StartPosition != 3 || chapters[1].End != 151 {
+			t.Errorf("Unexpected chapters: %+v", chapters)
+		}
+		if calls := client.calls.Load(); calls != 1 {
+			t.Errorf("Expected a single LLM request, got %d", calls)
+		}
+	})
+
+	t.Run("merges the chapters of several windows", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			ChapterizeWindowSystemPrompt: `{"chapters": [{"title": "Part", "start": "0:00"}]}`,
+			MergeChaptersSystemPrompt:    `{"chapters": [{"title": "Whole episode", "start": "0:00"}]}`,
+		}}
+		chunks := make([]TranscriptChunk, transcriptWindowWords+1)
+		for i := range chunks {
+			chunks[i] = TranscriptChunk{Position: i, StartTime: float64(i), EndTime: float64(i) + 0.5, Text: "word"}
+		}
+		service, completed := setupChapterService(client, chunks)
+
+		if err := service.generateChapters(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		chapters := <-completed
+		if len(chapters) != 1 || chapters[0].Title != "Whole episode" || chapters[0].EndPosition != transcriptWindowWords {
+			t.Errorf("Unexpected chapters: %+v", chapters)
+		}
+		if calls := client.calls.Load(); calls != 3 {
+			t.Errorf("Expected 2 window requests and 1 merge request, got %d", calls)
+		}
+	})
+
+	t.Run("fails when the LLM returns no usable chapter", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			ChapterizeWindowSystemPrompt: `{"chapters": [{"title": "Later", "start": "nonsense"}]}`,
+		}}
+		service, _ := setupChapterService(client, summaryChunks(3))
+
+		if err := service.generateChapters(1); err == nil || !strings.Contains(err.Error(), "no chapters") {
+			t.Errorf("Expected a no chapters error, got %v", err)
+		}
+	})
+}
+
+func TestTranscriptService_GetChapters(t *testing.T) {
+	t.Run("starts generation for a complete transcript without chapters", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			ChapterizeWindowSystemPrompt: `{"chapters": [{"title": "Welcome", "start": "0:00"}]}`,
+		}}
+		service, completed := setupChapterService(client, summaryChunks(3))
+
+		chapters, errResp := service.GetChapters(1)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if chapters.Status != string(TranscriptStatusProcessing) || chapters.TranscriptID != 10 {
+			t.Errorf("Expected a processing placeholder, got %+v", chapters)
+		}
+		select {
+		case <-completed:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the chapters to be generated")
+		}
+	})
+
+	t.Run("returns not found until the transcript is complete", func(t *testing.T) {
+		service, _ := setupChapterService(&MockLLMClient{}, nil)
+		service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+			return Transcript{ID: 10, EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+		}
+
+		_, errResp := service.GetChapters(1)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptService_CorrectionsRegenerateChapters(t *testing.T) {
+	service, completed := setupChapterService(&scriptedLLMClient{replies: map[string]string{
+		ChapterizeWindowSystemPrompt: `{"chapters": [{"title": "Merged", "start": "0:00"}]}`,
+	}}, summaryChunks(3))
+
+	if _, errResp := service.MergeSpeakers(1, 0, 1); errResp != nil {
+		t.Fatalf("Expected no error, got %v", errResp)
+	}
+
+	select {
+	case chapters := <-completed:
+		if len(chapters) != 1 || chapters[0].Title != "Merged" {
+			t.Errorf("Unexpected chapters: %+v", chapters)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected merging speakers to regenerate the chapters")
+	}
+}
+
+func TestTranscriptHandler_Chapters(t *testing.T) {
+	stored := EpisodeChapters{EpisodeID: 1, Status: string(TranscriptStatusComplete), Chapters: []Chapter{{Title: "Intro", End: 60}}}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"chapters", http.MethodGet, "/episodes/1/chapters", http.StatusOK, `"start_position":0`},
+		{"podcasting 2.0 format", http.MethodGet, "/episodes/1/chapters?format=podcast", http.StatusOK, `"startTime":0`},
+		{"unknown format", http.MethodGet, "/episodes/1/chapters?format=xml", http.StatusBadRequest, ""},
+		{"wrong method", http.MethodDelete, "/episodes/1/chapters", http.StatusMethodNotAllowed, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupChapterService(&MockLLMClient{}, nil)
+			service.repo.chapterRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeChapters, error) {
+				return stored, nil
+			}
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			handler.HandleRequest(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+
+	t.Run("podcasting 2.0 content type", func(t *testing.T) {
+		service, _ := setupChapterService(&MockLLMClient{}, nil)
+		service.repo.chapterRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeChapters, error) {
+			return stored, nil
+		}
+
+		w := httptest.NewRecorder()
+		NewTranscriptHandler(service).HandleRequest(w, httptest.NewRequest(http.MethodGet, "/episodes/1/chapters?format=podcast", nil))
+
+		if contentType := w.Header().Get("Content-Type"); contentType != "application/json+chapters" {
+			t.Errorf("Expected application/json+chapters, got %s", contentType)
+		}
+	})
+}
@@ -0,0 +1,437 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+const (
+	// chatPassageWords is the size of the transcript passages retrieved for a question
+	chatPassageWords = 80
+	// chatContextPassages is how many passages are sent with each question
+	chatContextPassages = 6
+	// chatHistoryMessages is how many earlier messages are sent to keep the conversation going
+	chatHistoryMessages = 10
+	chatTimeout         = 2 * time.Minute
+)
+
+// citationPattern matches [2] or [1, 3] markers with the space before them
+var citationPattern = regexp.MustCompile(`\s*\[(\d+(?:\s*,\s*\d+)*)\]`)
+
+// chatPassage is a short run of chunks that can be retrieved and cited
+type chatPassage struct {
+	StartPosition int
+	EndPosition   int
+	Start         float64
+	End           float64
+	// Text is the plain transcript text; Rendered labels each speaker turn
+	Text     string
+	Rendered string
+	tokens   []string
+}
+
+// AskEpisode answers a question about an episode from the most relevant transcript passages
+// and stores the exchange. A new conversation is started when the request has no
+// conversation_id. When onDelta is set the answer is passed to it while it is generated.
+func (s *Service) AskEpisode(ctx context.Context, episodeID, userID int, req AskEpisodeRequest, onDelta func(content string) error) (EpisodeAnswer, *errors.ErrorResponse) {
+	s.log.Info("Answering episode question", map[string]any{
+		"episodeID":      episodeID,
+		"conversationID": req.ConversationID,
+	})
+
+	question := strings.TrimSpace(req.Message)
+	if question == "" {
+		return EpisodeAnswer{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Message cannot be blank",
+		}
+	}
+
+	if !s.llmAvailable() {
+		return EpisodeAnswer{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "LLM client not configured",
+		}
+	}
+
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return EpisodeAnswer{}, errResp
+	}
+
+	var history []ConversationMessage
+	if req.ConversationID != nil {
+		conversation, errResp := s.GetConversation(episodeID, userID, *req.ConversationID)
+		if errResp != nil {
+			return EpisodeAnswer{}, errResp
+		}
+		history = conversation.Messages
+	}
+
+	episode, chunks, names, err := s.loadChatTranscript(episodeID, transcript.ID)
+	if err != nil {
+		return EpisodeAnswer{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript",
+		}
+	}
+
+	passages := retrievePassages(buildChatPassages(chunks, names, chatPassageWords), chatQuery(question, history), chatContextPassages)
+
+	content, err := s.completeChat(ctx, llm.ChatRequest{
+		Messages:  chatMessages(episode, passages, history, question),
+		MaxTokens: 700,
+	}, onDelta)
+	if err != nil {
+		s.log.Error("Failed to answer episode question", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return EpisodeAnswer{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "Failed to generate an answer",
+		}
+	}
+
+	conversationID := 0
+	if req.ConversationID != nil {
+		conversationID = *req.ConversationID
+	} else {
+		conversation, err := s.repo.CreateConversation(episodeID, userID)
+		if err != nil {
+			return EpisodeAnswer{}, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to create conversation",
+			}
+		}
+		conversationID = conversation.ID
+	}
+
+	messages, err := s.repo.SaveConversationExchange(conversationID, question, content, extractCitations(content, passages))
+	if err != nil {
+		return EpisodeAnswer{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to save conversation",
+		}
+	}
+
+	answer := EpisodeAnswer{ConversationID: conversationID}
+	for _, message := range messages {
+		if message.Role == "assistant" {
+			answer.Message = message
+		}
+	}
+
+	return answer, nil
+}
+
+// GetConversation returns a conversation of the user about the episode with its messages
+func (s *Service) GetConversation(episodeID, userID, conversationID int) (Conversation, *errors.ErrorResponse) {
+	conversation, err := s.repo.GetConversationByID(conversationID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return Conversation{}, &errors.ErrorResponse{
+				Message: errors.DatabaseNotFound,
+				Details: "Conversation not found",
+			}
+		}
+		return Conversation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch conversation",
+		}
+	}
+
+	// Other users' conversations are reported as missing rather than forbidden
+	if conversation.UserID != userID || conversation.EpisodeID != episodeID {
+		return Conversation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Conversation not found",
+		}
+	}
+
+	messages, err := s.repo.GetConversationMessages(conversationID)
+	if err != nil {
+		return Conversation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch conversation messages",
+		}
+	}
+	conversation.Messages = messages
+
+	return conversation, nil
+}
+
+func (s *Service) loadChatTranscript(episodeID, transcriptID int) (Episode, []TranscriptChunk, map[int]string, error) {
+	episode, err := s.repo.GetEpisodeByID(episodeID)
+	if err != nil {
+		return Episode{}, nil, nil, err
+	}
+
+	chunks, err := s.repo.GetChunksByTranscriptID(transcriptID)
+	if err != nil {
+		return Episode{}, nil, nil, err
+	}
+
+	speakers, err := s.repo.GetSpeakersByTranscriptID(transcriptID)
+	if err != nil {
+		return Episode{}, nil, nil, err
+	}
+
+	return episode, chunks, speakerNames(speakers), nil
+}
+
+// completeChat sends the request, streaming the reply to onDelta when the client supports it
+func (s *Service) completeChat(ctx context.Context, req llm.ChatRequest, onDelta func(content string) error) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, chatTimeout)
+	defer cancel()
+
+	var (
+		response llm.ChatCompletionResponse
+		err      error
+	)
+	streaming, canStream := s.llmClient.(llm.StreamingLLMClient)
+	if onDelta != nil && canStream {
+		response, err = streaming.ChatStream(ctx, req, onDelta)
+	} else {
+		response, err = s.llmClient.Chat(ctx, req)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if len(response.Choices) == 0 || strings.TrimSpace(response.Choices[0].Message.Content) == "" {
+		return "", fmt.Errorf("no response from LLM")
+	}
+	content := strings.TrimSpace(response.Choices[0].Message.Content)
+
+	if onDelta != nil && !canStream {
+		if err := onDelta(content); err != nil {
+			return "", err
+		}
+	}
+
+	return content, nil
+}
+
+// chatMessages builds the LLM conversation: the recent history without its citation
+// markers, then the question with the retrieved passages numbered from 1
+func chatMessages(episode Episode, passages []chatPassage, history []ConversationMessage, question string) []llm.Message {
+	messages := []llm.Message{{Role: "system", Content: AskEpisodeSystemPrompt}}
+
+	if len(history) > chatHistoryMessages {
+		history = history[len(history)-chatHistoryMessages:]
+	}
+	for _, message := range history {
+		content := strings.Join(strings.Fields(citationPattern.ReplaceAllString(message.Content, "")), " ")
+		messages = append(messages, llm.Message{Role: message.Role, Content: content})
+	}
+
+	var excerpts strings.Builder
+	for i, passage := range passages {
+		fmt.Fprintf(&excerpts, "[%d] (%s-%s)\n%s\n\n", i+1, FormatTimestamp(passage.Start), FormatTimestamp(passage.End), passage.Rendered)
+	}
+
+	return append(messages, llm.Message{
+		Role:    "user",
+		Content: AskEpisodeUserPrompt(episode.Name, episode.Description, strings.TrimSpace(excerpts.String()), question),
+	})
+}
+
+// chatQuery adds the previous question to the current one, so follow-ups like "what
+// did she say next?" still retrieve the passages of the topic being discussed
+func chatQuery(question string, history []ConversationMessage) string {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == "user" {
+			return question + " " + history[i].Content
+		}
+	}
+	return question
+}
+
+// buildChatPassages splits the transcript into passages of about maxWords words, ending
+// a passage early on a speaker change once it has half of them
+func buildChatPassages(chunks []TranscriptChunk, names map[int]string, maxWords int) []chatPassage {
+	var (
+		passages []chatPassage
+		current  *chatPassage
+		text     []string
+		rendered strings.Builder
+		speaker  int
+	)
+
+	flush := func() {
+		if current != nil {
+			current.Text = strings.Join(text, " ")
+			current.Rendered = rendered.String()
+			current.tokens = tokenize(current.Text)
+			passages = append(passages, *current)
+		}
+		current, text = nil, nil
+		rendered.Reset()
+	}
+
+	for _, chunk := range chunks {
+		if current != nil && chunk.SpeakerIndex != speaker && len(text) >= maxWords/2 {
+			flush()
+		}
+
+		if current == nil {
+			current = &chatPassage{StartPosition: chunk.Position, Start: chunk.StartTime}
+		}
+		if rendered.Len() == 0 || chunk.SpeakerIndex != speaker {
+			if rendered.Len() > 0 {
+				rendered.WriteString("\n")
+			}
+			fmt.Fprintf(&rendered, "%s:", speakerName(names, chunk.SpeakerIndex))
+			speaker = chunk.SpeakerIndex
+		}
+		rendered.WriteString(" " + chunk.Text)
+		text = append(text, chunk.Text)
+		current.EndPosition, current.End = chunk.Position, chunk.EndTime
+
+		if len(text) >= maxWords {
+			flush()
+		}
+	}
+	flush()
+
+	return passages
+}
+
+// retrievePassages ranks the passages against the query with BM25 and returns the best
+// limit passages in transcript order. When no passage shares a term with the query (e.g.
+// "what is this episode about?") passages spread across the episode are returned instead.
+func retrievePassages(passages []chatPassage, query string, limit int) []chatPassage {
+	if len(passages) <= limit {
+		return passages
+	}
+
+	const k1, b = 1.2, 0.75
+
+	documentFrequency := make(map[string]int)
+	totalLength := 0
+	for _, passage := range passages {
+		seen := make(map[string]bool)
+		for _, token := range passage.tokens {
+			if !seen[token] {
+				seen[token] = true
+				documentFrequency[token]++
+			}
+		}
+		totalLength += len(passage.tokens)
+	}
+	averageLength := math.Max(float64(totalLength)/float64(len(passages)), 1)
+
+	queryTerms := make(map[string]bool)
+	for _, token := range tokenize(query) {
+		queryTerms[token] = true
+	}
+
+	scores := make([]float64, len(passages))
+	for i, passage := range passages {
+		frequency := make(map[string]int)
+		for _, token := range passage.tokens {
+			if queryTerms[token] {
+				frequency[token]++
+			}
+		}
+		for term, tf := range frequency {
+			n := float64(documentFrequency[term])
+			idf := math.Log(1 + (float64(len(passages))-n+0.5)/(n+0.5))
+			norm := k1 * (1 - b + b*float64(len(passage.tokens))/averageLength)
+			scores[i] += idf * float64(tf) * (k1 + 1) / (float64(tf) + norm)
+		}
+	}
+
+	ranked := make([]int, len(passages))
+	for i := range ranked {
+		ranked[i] = i
+	}
+	sort.SliceStable(ranked, func(i, j int) bool { return scores[ranked[i]] > scores[ranked[j]] })
+
+	var selected []int
+	if scores[ranked[0]] > 0 {
+		for _, i := range ranked[:limit] {
+			if scores[i] > 0 {
+				selected = append(selected, i)
+			}
+		}
+	} else {
+		for n := range limit {
+			selected = append(selected, n*len(passages)/limit)
+		}
+	}
+	sort.Ints(selected)
+
+	result := make([]chatPassage, len(selected))
+	for i, index := range selected {
+		result[i] = passages[index]
+	}
+	return result
+}
+
+// extractCitations maps the [n] markers of an answer to the passages they refer to, in
+// order of first use. Markers outside the excerpts are ignored.
+func extractCitations(content string, passages []chatPassage) []Citation {
+	citations := []Citation{}
+	seen := make(map[int]bool)
+
+	for _, match := range citationPattern.FindAllStringSubmatch(content, -1) {
+		for _, value := range strings.Split(match[1], ",") {
+			ref, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || ref < 1 || ref > len(passages) || seen[ref] {
+				continue
+			}
+			seen[ref] = true
+			passage := passages[ref-1]
+			citations = append(citations, Citation{
+				Ref:           ref,
+				StartPosition: passage.StartPosition,
+				EndPosition:   passage.EndPosition,
+				Start:         passage.Start,
+				End:           passage.End,
+				Text:          passage.Text,
+			})
+		}
+	}
+
+	return citations
+}
+
+// chatStopWords are frequent English words that carry no meaning for retrieval
+var chatStopWords = map[string]bool{
+	"the": true, "and": true, "for": true, "are": true, "but": true, "not": true, "you": true,
+	"all": true, "can": true, "had": true, "her": true, "was": true, "one": true, "our": true,
+	"out": true, "has": true, "have": true, "his": true, "how": true, "its": true, "who": true,
+	"did": true, "does": true, "this": true, "that": true, "with": true, "they": true, "what": true,
+	"when": true, "where": true, "which": true, "about": true, "there": true, "their": true,
+	"from": true, "were": true, "been": true, "would": true, "could": true, "should": true,
+	"said": true, "say": true, "says": true, "just": true, "like": true, "into": true, "than": true,
+	"then": true, "them": true, "these": true, "those": true, "your": true, "yeah": true,
+}
+
+// tokenize lowercases the text and splits it into words of at least three letters or
+// digits, dropping stop words
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	tokens := words[:0]
+	for _, word := range words {
+		if len([]rune(word)) >= 3 && !chatStopWords[word] {
+			tokens = append(tokens, word)
+		}
+	}
+	return tokens
+}
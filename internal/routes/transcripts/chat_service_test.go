@@ -0,0 +1,324 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// streamingLLMClient replies with fixed text, streamed in the given pieces
+type streamingLLMClient struct {
+	deltas []string
+	last   llm.ChatRequest
+}
+
+func (m *streamingLLMClient) Chat(ctx context.Context, req llm.ChatRequest) (llm.ChatCompletionResponse, error) {
+	m.last = req
+	return llm.ChatCompletionResponse{
+		Choices: []llm.Choice{{Message: llm.Message{Content: strings.Join(m.deltas, "")}}},
+	}, nil
+}
+
+func (m *streamingLLMClient) ChatStream(ctx context.Context, req llm.ChatRequest, onDelta func(content string) error) (llm.ChatCompletionResponse, error) {
+	for _, delta := range m.deltas {
+		if err := onDelta(delta); err != nil {
+			return llm.ChatCompletionResponse{}, err
+		}
+	}
+	return m.Chat(ctx, req)
+}
+
+type savedExchange struct {
+	conversationID int
+	question       string
+	answer         string
+	citations      []Citation
+}
+
+// setupChatService mocks a complete transcript with a passage about pasta followed by one
+// about Lisbon. Conversation 7 belongs to user 5 on episode 1 and has one earlier exchange.
+func setupChatService(client llm.LLMClient) (*Service, *[]savedExchange) {
+	pasta := strings.Fields("fresh pasta needs eggs and flour")
+	lisbon := strings.Fields("we travel to lisbon by train for its trams")
+	chunks := make([]TranscriptChunk, 100)
+	for i := range chunks {
+		speaker, word := 0, pasta[i%len(pasta)]
+		if i >= 50 {
+			speaker, word = 1, lisbon[i%len(lisbon)]
+		}
+		chunks[i] = TranscriptChunk{Position: i, SpeakerIndex: speaker, StartTime: float64(i), EndTime: float64(i) + 0.5, Text: word}
+	}
+	service, _ := setupSummaryService(client, chunks)
+	var saved []savedExchange
+
+	service.repo.convRepo.Executor = utils.QueryExecutor[Conversation]{
+		QueryItem: func(query string, args ...any) (Conversation, error) {
+			if strings.Contains(query, "INSERT") {
+				return Conversation{ID: 42, EpisodeID: args[0].(int), UserID: args[1].(int)}, nil
+			}
+			if args[0].(int) != 7 {
+				return Conversation{}, fmt.Errorf("no rows in result set")
+			}
+			return Conversation{ID: 7, EpisodeID: 1, UserID: 5}, nil
+		},
+	}
+	service.repo.messageRepo.Executor = utils.QueryExecutor[ConversationMessage]{
+		QueryList: func(query string, args ...any) ([]ConversationMessage, error) {
+			if strings.Contains(query, "INSERT") {
+				exchange := savedExchange{args[0].(int), args[1].(string), args[2].(string), args[3].([]Citation)}
+				saved = append(saved, exchange)
+				return []ConversationMessage{
+					{ID: 2, ConversationID: exchange.conversationID, Role: "assistant", Content: exchange.answer, Citations: exchange.citations},
+					{ID: 1, ConversationID: exchange.conversationID, Role: "user", Content: exchange.question},
+				}, nil
+			}
+			return []ConversationMessage{
+				{ID: 1, ConversationID: 7, Role: "user", Content: "How do you make pasta?"},
+				{ID: 2, ConversationID: 7, Role: "assistant", Content: "With eggs and flour [1]."},
+			}, nil
+		},
+	}
+
+	return service, &saved
+}
+
+func TestBuildChatPassages(t *testing.T) {
+	chunks := []TranscriptChunk{
+		{Position: 0, SpeakerIndex: 0, StartTime: 0, EndTime: 1, Text: "one"},
+		{Position: 1, SpeakerIndex: 1, StartTime: 1, EndTime: 2, Text: "two"},
+		{Position: 2, SpeakerIndex: 1, StartTime: 2, EndTime: 3, Text: "three"},
+		{Position: 3, SpeakerIndex: 0, StartTime: 3, EndTime: 4, Text: "four"},
+		{Position: 4, SpeakerIndex: 0, StartTime: 4, EndTime: 5, Text: "five"},
+	}
+
+	passages := buildChatPassages(chunks, map[int]string{1: "Jane Doe"}, 4)
+
+	if len(passages) != 2 {
+		t.Fatalf("Expected 2 passages, got %+v", passages)
+	}
+	first := passages[0]
+	if first.StartPosition != 0 || first.EndPosition != 2 || first.End != 3 || first.Text != "one two three" {
+		t.Errorf("Expected the first passage to end at the speaker change, got %+v", first)
+	}
+	if first.Rendered != "Speaker 0: one\nJane Doe: two three" {
+		t.Errorf("Unexpected rendered passage: %q", first.Rendered)
+	}
+	if passages[1].Rendered != "Speaker 0: four five" {
+		t.Errorf("Expected the second passage to start with its speaker, got %q", passages[1].Rendered)
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := strings.Join(tokenize("What did Zoë say about the 2019 São-Paulo trip? It's OK."), ",")
+
+	if got != "zoë,2019,são,paulo,trip" {
+		t.Errorf("Unexpected tokens: %s", got)
+	}
+}
+
+func TestRetrievePassages(t *testing.T) {
+	passages := make([]chatPassage, 10)
+	for i := range passages {
+		passages[i] = chatPassage{StartPosition: i, tokens: tokenize(fmt.Sprintf("filler words number%d", i))}
+	}
+	passages[7].tokens = tokenize("lisbon trams and lisbon pastries")
+	passages[2].tokens = tokenize("we went to lisbon once")
+
+	t.Run("returns matching passages in transcript order", func(t *testing.T) {
+		result := retrievePassages(passages, "Tell me about Lisbon trams", 3)
+
+		if len(result) != 2 || result[0].StartPosition != 2 || result[1].StartPosition != 7 {
+			t.Errorf("Expected passages 2 and 7, got %+v", result)
+		}
+	})
+
+	t.Run("spreads passages over the episode without matches", func(t *testing.T) {
+		result := retrievePassages(passages, "what is it?", 3)
+
+		if len(result) != 3 || result[0].StartPosition != 0 || result[1].StartPosition != 3 || result[2].StartPosition != 6 {
+			t.Errorf("Expected passages 0, 3 and 6, got %+v", result)
+		}
+	})
+
+	t.Run("returns every passage of short transcripts", func(t *testing.T) {
+		if result := retrievePassages(passages[:2], "lisbon", 3); len(result) != 2 {
+			t.Errorf("Expected both passages, got %+v", result)
+		}
+	})
+}
+
+func TestExtractCitations(t *testing.T) {
+	passages := []chatPassage{
+		{StartPosition: 0, EndPosition: 4, Start: 0, End: 9, Text: "first"},
+		{StartPosition: 5, EndPosition: 9, Start: 10, End: 19, Text: "second"},
+		{StartPosition: 10, EndPosition: 14, Start: 20, End: 29, Text: "third"},
+	}
+
+	citations := extractCitations("Trams are yellow [3]. Pastries [1, 3] and more [9][3].", passages)
+
+	if len(citations) != 2 {
+		t.Fatalf("Expected 2 citations, got %+v", citations)
+	}
+	if citations[0] != (Citation{Ref: 3, StartPosition: 10, EndPosition: 14, Start: 20, End: 29, Text: "third"}) || citations[1].Ref != 1 {
+		t.Errorf("Unexpected citations: %+v", citations)
+	}
+
+	if citations := extractCitations("No sources.", passages); citations == nil || len(citations) != 0 {
+		t.Errorf("Expected an empty list, got %+v", citations)
+	}
+}
+
+func TestChatMessages(t *testing.T) {
+	history := make([]ConversationMessage, chatHistoryMessages+2)
+	for i := range history {
+		history[i] = ConversationMessage{Role: "user", Content: fmt.Sprintf("message %d [1]", i)}
+	}
+
+	messages := chatMessages(Episode{Name: "Pilot"}, []chatPassage{{Start: 65, End: 70, Rendered: "Jane Doe: hello"}}, history, "Who?")
+
+	if len(messages) != chatHistoryMessages+2 {
+		t.Fatalf("Expected system, %d history messages and the question, got %d", chatHistoryMessages, len(messages))
+	}
+	if messages[1].Content != "message 2" {
+		t.Errorf("Expected the oldest kept message without citations, got %q", messages[1].Content)
+	}
+	if question := messages[len(messages)-1].Content; !strings.Contains(question, "[1] (1:05-1:10)\nJane Doe: hello") || !strings.HasSuffix(question, "Question: Who?") {
+		t.Errorf("Unexpected question prompt: %s", question)
+	}
+}
+
+func TestTranscriptService_AskEpisode(t *testing.T) {
+	t.Run("starts a conversation and streams the answer", func(t *testing.T) {
+		client := &streamingLLMClient{deltas: []string{"You take the ", "train [2]."}}
+		service, saved := setupChatService(client)
+
+		var streamed []string
+		answer, errResp := service.AskEpisode(context.Background(), 1, 5, AskEpisodeRequest{Message: " How do they get to Lisbon? "}, func(content string) error {
+			streamed = append(streamed, content)
+			return nil
+		})
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if len(streamed) != 2 {
+			t.Errorf("Expected 2 deltas, got %q", streamed)
+		}
+		if answer.ConversationID != 42 || answer.Message.Role != "assistant" || answer.Message.Content != "You take the train [2]." {
+			t.Errorf("Unexpected answer: %+v", answer)
+		}
+		if len(*saved) != 1 || (*saved)[0].question != "How do they get to Lisbon?" {
+			t.Fatalf("Expected the exchange to be saved, got %+v", *saved)
+		}
+		if citations := (*saved)[0].citations; len(citations) != 1 || citations[0].Ref != 2 || !strings.Contains(citations[0].Text, "lisbon") {
+			t.Errorf("Expected a citation of the Lisbon passage, got %+v", citations)
+		}
+	})
+
+	t.Run("continues a conversation with its history", func(t *testing.T) {
+		client := &streamingLLMClient{deltas: []string{"Eggs [1]."}}
+		service, saved := setupChatService(client)
+		id := 7
+
+		answer, errResp := service.AskEpisode(context.Background(), 1, 5, AskEpisodeRequest{ConversationID: &id, Message: "And what else?"}, func(string) error { return nil })
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if answer.ConversationID != 7 || (*saved)[0].conversationID != 7 {
+			t.Errorf("Expected conversation 7, got %+v", answer)
+		}
+		if len(client.last.Messages) != 4 || client.last.Messages[2].Content != "With eggs and flour." {
+			t.Errorf("Expected the history before the question, got %+v", client.last.Messages)
+		}
+	})
+
+	t.Run("falls back to a single delta without streaming support", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{AskEpisodeSystemPrompt: "Pasta [1]."}}
+		service, _ := setupChatService(client)
+
+		var streamed []string
+		answer, errResp := service.AskEpisode(context.Background(), 1, 5, AskEpisodeRequest{Message: "Pasta?"}, func(content string) error {
+			streamed = append(streamed, content)
+			return nil
+		})
+
+		if errResp != nil || answer.Message.Content != "Pasta [1]." || len(streamed) != 1 {
+			t.Errorf("Expected the whole answer as one delta, got %q, %+v, %v", streamed, answer, errResp)
+		}
+	})
+
+	t.Run("hides conversations of other users", func(t *testing.T) {
+		service, _ := setupChatService(&streamingLLMClient{})
+		id := 7
+
+		_, errResp := service.AskEpisode(context.Background(), 1, 6, AskEpisodeRequest{ConversationID: &id, Message: "Hi"}, nil)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+
+	t.Run("rejects a blank message", func(t *testing.T) {
+		service, _ := setupChatService(&streamingLLMClient{})
+
+		_, errResp := service.AskEpisode(context.Background(), 1, 5, AskEpisodeRequest{Message: "   "}, nil)
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError {
+			t.Errorf("Expected a validation error, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptHandler_Chat(t *testing.T) {
+	request := func(method, path, body, accept string) *http.Request {
+		r := httptest.NewRequest(method, path, strings.NewReader(body))
+		if accept != "" {
+			r.Header.Set("Accept", accept)
+		}
+		return r.WithContext(context.WithValue(r.Context(), middlewares.UserIDContextKey, 5))
+	}
+
+	tests := []struct {
+		name       string
+		request    *http.Request
+		wantStatus int
+		wantBody   []string
+	}{
+		{"answers with JSON", request(http.MethodPost, "/episodes/1/chat", `{"message": "Lisbon?"}`, ""), http.StatusOK, []string{`"conversation_id":42`, `"citations":[{"ref":2`}},
+		{"streams over SSE", request(http.MethodPost, "/episodes/1/chat", `{"message": "Lisbon?"}`, "text/event-stream"), http.StatusOK, []string{"event: delta\ndata: {\"content\":\"By train \"}", "event: message\ndata: {\"conversation_id\":42", "event: complete"}},
+		{"unknown conversation before streaming", request(http.MethodPost, "/episodes/1/chat", `{"conversation_id": 3, "message": "Hi"}`, "text/event-stream"), http.StatusNotFound, nil},
+		{"missing message", request(http.MethodPost, "/episodes/1/chat", `{}`, ""), http.StatusBadRequest, nil},
+		{"history", request(http.MethodGet, "/episodes/1/chat/7", "", ""), http.StatusOK, []string{`"role":"assistant"`}},
+		{"history of another episode", request(http.MethodGet, "/episodes/2/chat/7", "", ""), http.StatusNotFound, nil},
+		{"wrong method", request(http.MethodGet, "/episodes/1/chat", "", ""), http.StatusMethodNotAllowed, nil},
+		{"unknown sub-path", request(http.MethodGet, "/episodes/1/summary/7", "", ""), http.StatusNotFound, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupChatService(&streamingLLMClient{deltas: []string{"By train ", "[2]."}})
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			handler.HandleRequest(w, tt.request)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			for _, want := range tt.wantBody {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("Expected body to contain %q, got %s", want, w.Body.String())
+				}
+			}
+		})
+	}
+}
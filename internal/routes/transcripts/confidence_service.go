@@ -0,0 +1,191 @@
+package transcripts
+
+import (
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// lowConfidenceMergeGap is how many confident words may separate two low-confidence
+// runs before they are reported as separate spans
+const lowConfidenceMergeGap = 1
+
+// wordConfidence returns the confidence of a word, or nil when the provider does not
+// report one (Whisper sends none, so a zero would flag every word)
+func wordConfidence(word transcription.Word) *float64 {
+	if word.Confidence <= 0 {
+		return nil
+	}
+	confidence := word.Confidence
+	return &confidence
+}
+
+// GetLowConfidenceSpans groups adjacent words below the threshold into spans for review.
+// Words already covered by an active correction are considered reviewed and skipped.
+func (s *Service) GetLowConfidenceSpans(episodeID int, threshold float64) (LowConfidenceReport, *errors.ErrorResponse) {
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return LowConfidenceReport{}, errResp
+	}
+
+	chunks, err := s.repo.GetChunksByTranscriptID(transcript.ID)
+	if err != nil {
+		return LowConfidenceReport{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript chunks",
+		}
+	}
+
+	revisions, err := s.repo.GetRevisionsByTranscriptID(transcript.ID)
+	if err != nil {
+		return LowConfidenceReport{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch revisions",
+		}
+	}
+
+	return LowConfidenceReport{
+		EpisodeID: episodeID,
+		Threshold: threshold,
+		Spans:     groupLowConfidenceSpans(chunks, reviewedPositions(revisions), threshold),
+	}, nil
+}
+
+// reviewedPositions returns the chunk positions whose text a person corrected.
+// Reverted revisions and reverts restore machine text, so they don't count.
+func reviewedPositions(revisions []TranscriptRevision) map[int]bool {
+	reviewed := make(map[int]bool)
+	for _, revision := range revisions {
+		if revision.RevertedAt != nil || revision.RevertsRevisionID != nil {
+			continue
+		}
+		for position := revision.StartPosition; position <= revision.EndPosition; position++ {
+			reviewed[position] = true
+		}
+	}
+	return reviewed
+}
+
+// groupLowConfidenceSpans walks the chunks in order and groups low-confidence words of the
+// same speaker, bridging up to lowConfidenceMergeGap confident words between them
+func groupLowConfidenceSpans(chunks []TranscriptChunk, reviewed map[int]bool, threshold float64) []LowConfidenceSpan {
+	spans := []LowConfidenceSpan{}
+
+	var (
+		current []TranscriptChunk // chunks of the open span, including bridged ones
+		flagged []float64         // confidences of the low-confidence words in the span
+		gap     int               // confident words since the last low-confidence word
+	)
+
+	flush := func() {
+		if len(flagged) > 0 {
+			spans = append(spans, newLowConfidenceSpan(current[:len(current)-gap], flagged))
+		}
+		current, flagged, gap = nil, nil, 0
+	}
+
+	for _, chunk := range chunks {
+		isLow := chunk.Confidence != nil && *chunk.Confidence < threshold && !reviewed[chunk.Position]
+
+		if len(current) > 0 && chunk.SpeakerIndex != current[0].SpeakerIndex {
+			flush()
+		}
+
+		switch {
+		case isLow:
+			current = append(current, chunk)
+			flagged = append(flagged, *chunk.Confidence)
+			gap = 0
+		case len(current) > 0 && gap < lowConfidenceMergeGap:
+			current = append(current, chunk)
+			gap++
+		default:
+			flush()
+		}
+	}
+	flush()
+
+	return spans
+}
+
+func newLowConfidenceSpan(chunks []TranscriptChunk, confidences []float64) LowConfidenceSpan {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	minConfidence, total := confidences[0], 0.0
+	for _, confidence := range confidences {
+		minConfidence = min(minConfidence, confidence)
+		total += confidence
+	}
+
+	first, last := chunks[0], chunks[len(chunks)-1]
+	return LowConfidenceSpan{
+		StartPosition: first.Position,
+		EndPosition:   last.Position,
+		SpeakerIndex:  first.SpeakerIndex,
+		Start:         first.StartTime,
+		End:           last.EndTime,
+		Text:          strings.Join(texts, " "),
+		MinConfidence: minConfidence,
+		AvgConfidence: total / float64(len(confidences)),
+	}
+}
+
+// ExportTranscript returns the complete transcript of an episode with speakers,
+// per-word confidence and speaker analytics
+func (s *Service) ExportTranscript(episodeID int) (TranscriptExport, *errors.ErrorResponse) {
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return TranscriptExport{}, errResp
+	}
+
+	speakers, err := s.repo.GetSpeakersByTranscriptID(transcript.ID)
+	if err != nil {
+		return TranscriptExport{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch speakers",
+		}
+	}
+
+	chunks, err := s.repo.GetChunksByTranscriptID(transcript.ID)
+	if err != nil {
+		return TranscriptExport{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript chunks",
+		}
+	}
+
+	export := TranscriptExport{
+		EpisodeID:  episodeID,
+		Transcript: transcript,
+		Speakers:   make([]Speaker, len(speakers)),
+		Chunks:     make([]Chunk, len(chunks)),
+	}
+	for i, speaker := range speakers {
+		export.Speakers[i] = Speaker{Index: speaker.SpeakerIndex, Name: speaker.SpeakerName, IsHumanSet: speaker.IsHumanSet}
+	}
+	for i, chunk := range chunks {
+		export.Chunks[i] = Chunk{
+			Position:     chunk.Position,
+			SpeakerIndex: chunk.SpeakerIndex,
+			Start:        chunk.StartTime,
+			End:          chunk.EndTime,
+			Text:         chunk.Text,
+			Confidence:   chunk.Confidence,
+		}
+	}
+
+	// Analytics are cached once the transcript completes; computing them from the chunks
+	// already loaded covers exports that race the background computation
+	analytics, err := s.repo.GetAnalytics(transcript.ID)
+	if err != nil {
+		analytics = computeAnalytics(transcript.ID, chunks)
+	}
+	analytics = withSpeakerNames(analytics, speakers)
+	export.Analytics = &analytics
+
+	return export, nil
+}
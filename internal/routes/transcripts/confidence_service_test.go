@@ -0,0 +1,178 @@
+package transcripts
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+)
+
+func confidence(value float64) *float64 {
+	return &value
+}
+
+// setupConfidenceService mocks a complete transcript whose chunks have the given confidences
+func setupConfidenceService(status TranscriptStatus, confidences []*float64, revisions []TranscriptRevision) *Service {
+	service, _ := setupSpeakerService(status)
+	service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+		chunks := make([]TranscriptChunk, len(confidences))
+		for i, c := range confidences {
+			chunks[i] = TranscriptChunk{Position: i, Text: string(rune('a' + i)), StartTime: float64(i), EndTime: float64(i) + 0.5, Confidence: c}
+		}
+		return chunks, nil
+	}
+	service.repo.revisionRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptRevision, error) {
+		return revisions, nil
+	}
+	return service
+}
+
+func TestWordConfidence(t *testing.T) {
+	if got := wordConfidence(transcription.Word{Confidence: 0.87}); got == nil || *got != 0.87 {
+		t.Errorf("Expected 0.87, got %v", got)
+	}
+	if got := wordConfidence(transcription.Word{}); got != nil {
+		t.Errorf("Expected nil for an unreported confidence, got %v", *got)
+	}
+}
+
+func TestGroupLowConfidenceSpans(t *testing.T) {
+	chunk := func(position, speaker int, c *float64) TranscriptChunk {
+		return TranscriptChunk{Position: position, SpeakerIndex: speaker, Text: string(rune('a' + position)), StartTime: float64(position), EndTime: float64(position) + 0.5, Confidence: c}
+	}
+	low, high := confidence(0.3), confidence(0.9)
+
+	tests := []struct {
+		name     string
+		chunks   []TranscriptChunk
+		reviewed map[int]bool
+		want     [][2]int
+	}{
+		{"no low words", []TranscriptChunk{chunk(0, 0, high), chunk(1, 0, high)}, nil, nil},
+		{"adjacent low words form one span", []TranscriptChunk{chunk(0, 0, high), chunk(1, 0, low), chunk(2, 0, low), chunk(3, 0, high)}, nil, [][2]int{{1, 2}}},
+		{"bridges one confident word", []TranscriptChunk{chunk(0, 0, low), chunk(1, 0, high), chunk(2, 0, low)}, nil, [][2]int{{0, 2}}},
+		{"splits on two confident words", []TranscriptChunk{chunk(0, 0, low), chunk(1, 0, high), chunk(2, 0, high), chunk(3, 0, low)}, nil, [][2]int{{0, 0}, {3, 3}}},
+		{"splits on speaker change", []TranscriptChunk{chunk(0, 0, low), chunk(1, 1, low)}, nil, [][2]int{{0, 0}, {1, 1}}},
+		{"ignores unknown confidence", []TranscriptChunk{chunk(0, 0, nil), chunk(1, 0, low)}, nil, [][2]int{{1, 1}}},
+		{"skips reviewed words", []TranscriptChunk{chunk(0, 0, low), chunk(1, 0, low)}, map[int]bool{0: true}, [][2]int{{1, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spans := groupLowConfidenceSpans(tt.chunks, tt.reviewed, 0.6)
+
+			if len(spans) != len(tt.want) {
+				t.Fatalf("Expected %d spans, got %+v", len(tt.want), spans)
+			}
+			for i, span := range spans {
+				if span.StartPosition != tt.want[i][0] || span.EndPosition != tt.want[i][1] {
+					t.Errorf("Expected span %v, got %+v", tt.want[i], span)
+				}
+			}
+		})
+	}
+
+	t.Run("summarizes the span", func(t *testing.T) {
+		spans := groupLowConfidenceSpans([]TranscriptChunk{chunk(0, 2, confidence(0.2)), chunk(1, 2, high), chunk(2, 2, confidence(0.4))}, nil, 0.6)
+
+		span := spans[0]
+		if span.Text != "a b c" || span.Start != 0 || span.End != 2.5 || span.SpeakerIndex != 2 {
+			t.Errorf("Unexpected span: %+v", span)
+		}
+		if span.MinConfidence != 0.2 || span.AvgConfidence < 0.299 || span.AvgConfidence > 0.301 {
+			t.Errorf("Expected min 0.2 and average 0.3 of the flagged words, got %+v", span)
+		}
+	})
+}
+
+func TestTranscriptService_GetLowConfidenceSpans(t *testing.T) {
+	confidences := []*float64{confidence(0.9), confidence(0.2), confidence(0.3), confidence(0.9), confidence(0.1)}
+
+	t.Run("reports spans not covered by active corrections", func(t *testing.T) {
+		now := time.Now()
+		revertedID := 3
+		revisions := []TranscriptRevision{
+			{ID: 1, StartPosition: 4, EndPosition: 4},
+			{ID: 2, StartPosition: 1, EndPosition: 1, RevertedAt: &now},
+			{ID: 4, StartPosition: 2, EndPosition: 2, RevertsRevisionID: &revertedID},
+		}
+		service := setupConfidenceService(TranscriptStatusComplete, confidences, revisions)
+
+		report, errResp := service.GetLowConfidenceSpans(1, 0.5)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if report.EpisodeID != 1 || report.Threshold != 0.5 || len(report.Spans) != 1 {
+			t.Fatalf("Unexpected report: %+v", report)
+		}
+		if span := report.Spans[0]; span.StartPosition != 1 || span.EndPosition != 2 {
+			t.Errorf("Expected span 1-2, got %+v", span)
+		}
+	})
+
+	t.Run("requires a complete transcript", func(t *testing.T) {
+		service := setupConfidenceService(TranscriptStatusProcessing, confidences, nil)
+
+		_, errResp := service.GetLowConfidenceSpans(1, 0.5)
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError {
+			t.Errorf("Expected validation error, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptService_ExportTranscript(t *testing.T) {
+	service := setupConfidenceService(TranscriptStatusComplete, []*float64{confidence(0.95), nil}, nil)
+
+	export, errResp := service.ExportTranscript(1)
+
+	if errResp != nil {
+		t.Fatalf("Expected no error, got %v", errResp)
+	}
+	if export.Transcript.ID != 10 || len(export.Speakers) != 1 || export.Speakers[0].Name != "Jane Doe" {
+		t.Errorf("Unexpected export: %+v", export)
+	}
+	if len(export.Chunks) != 2 || *export.Chunks[0].Confidence != 0.95 || export.Chunks[1].Confidence != nil {
+		t.Errorf("Expected chunks with confidence, got %+v", export.Chunks)
+	}
+
+	if _, errResp := service.ExportTranscript(2); errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+		t.Errorf("Expected not found for a missing transcript, got %v", errResp)
+	}
+}
+
+func TestTranscriptHandler_ConfidenceRoutes(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		wantStatus int
+	}{
+		{"low-confidence report", http.MethodGet, "/transcripts/1/low-confidence", http.StatusOK},
+		{"custom threshold", http.MethodGet, "/transcripts/1/low-confidence?threshold=0.8", http.StatusOK},
+		{"invalid threshold", http.MethodGet, "/transcripts/1/low-confidence?threshold=2", http.StatusBadRequest},
+		{"low-confidence wrong method", http.MethodPost, "/transcripts/1/low-confidence", http.StatusMethodNotAllowed},
+		{"low-confidence unknown transcript", http.MethodGet, "/transcripts/2/low-confidence", http.StatusNotFound},
+		{"export", http.MethodGet, "/transcripts/1/export", http.StatusOK},
+		{"export wrong method", http.MethodDelete, "/transcripts/1/export", http.StatusMethodNotAllowed},
+		{"export sub-path", http.MethodGet, "/transcripts/1/export/txt", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := setupConfidenceService(TranscriptStatusComplete, []*float64{confidence(0.2)}, nil)
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			handler.HandleRequest(w, httptest.NewRequest(tt.method, tt.url, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,241 @@
+package transcripts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// handleEpisodeContentRoutes routes /episodes/:episode_id/* requests
+func (h *TranscriptHandler) handleEpisodeContentRoutes(w http.ResponseWriter, r *http.Request, path string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
+		utils.NotFound(w, r)
+		return
+	}
+
+	// Only chat has sub-resources
+	if len(parts) > 2 && parts[1] != "chat" {
+		utils.NotFound(w, r)
+		return
+	}
+
+	episodeID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		utils.NotFound(w, r)
+		return
+	}
+
+	switch parts[1] {
+	case "summary":
+		// GET /episodes/:episode_id/summary
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleGetSummary(w, episodeID)
+	case "chapters":
+		// GET /episodes/:episode_id/chapters
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleGetChapters(w, r, episodeID)
+	case "highlights":
+		// GET /episodes/:episode_id/highlights
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleGetHighlights(w, episodeID)
+	case "chat":
+		h.handleChat(w, r, episodeID, parts[2:])
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+// handleGetSummary responds 202 while the summary is being generated for the first time
+func (h *TranscriptHandler) handleGetSummary(w http.ResponseWriter, episodeID int) {
+	summary, errResp := h.service.GetSummary(episodeID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	status := http.StatusOK
+	if summary.Status == string(TranscriptStatusProcessing) {
+		status = http.StatusAccepted
+	}
+
+	utils.EncodeResponse(w, status, summary)
+}
+
+// handleGetChapters returns the chapters, or with ?format=podcast the Podcasting 2.0 JSON
+// chapters document. Like summaries, it responds 202 while the chapters are generated.
+func (h *TranscriptHandler) handleGetChapters(w http.ResponseWriter, r *http.Request, episodeID int) {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "podcast" {
+		utils.EncodeResponse(w, http.StatusBadRequest, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "format must be 'podcast' or omitted",
+		})
+		return
+	}
+
+	chapters, errResp := h.service.GetChapters(episodeID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	status := http.StatusOK
+	if chapters.Status == string(TranscriptStatusProcessing) {
+		status = http.StatusAccepted
+	}
+
+	if format == "podcast" {
+		w.Header().Set("Content-Type", "application/json+chapters")
+		utils.EncodeResponse(w, status, chapters.PodcastChapters())
+		return
+	}
+
+	utils.EncodeResponse(w, status, chapters)
+}
+
+// handleGetHighlights responds 202 while the highlights are being generated, like summaries
+func (h *TranscriptHandler) handleGetHighlights(w http.ResponseWriter, episodeID int) {
+	highlights, errResp := h.service.GetHighlights(episodeID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	status := http.StatusOK
+	if highlights.Status == string(TranscriptStatusProcessing) {
+		status = http.StatusAccepted
+	}
+
+	utils.EncodeResponse(w, status, highlights)
+}
+
+// handleChat routes /episodes/:episode_id/chat/* requests
+func (h *TranscriptHandler) handleChat(w http.ResponseWriter, r *http.Request, episodeID int, parts []string) {
+	switch len(parts) {
+	case 0:
+		// POST /episodes/:episode_id/chat
+		if r.Method != http.MethodPost {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleAskEpisode(w, r, episodeID)
+	case 1:
+		// GET /episodes/:episode_id/chat/:conversation_id
+		conversationID, err := strconv.Atoi(parts[0])
+		if err != nil {
+			utils.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleGetConversation(w, r, episodeID, conversationID)
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+func (h *TranscriptHandler) handleGetConversation(w http.ResponseWriter, r *http.Request, episodeID, conversationID int) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	conversation, errResp := h.service.GetConversation(episodeID, userID, conversationID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, conversation)
+}
+
+// handleAskEpisode answers with JSON, or streams the answer over SSE when the client
+// accepts text/event-stream: "delta" events carry the text as it is generated, then a
+// "message" event carries the stored answer with its citations, then "complete".
+func (h *TranscriptHandler) handleAskEpisode(w http.ResponseWriter, r *http.Request, episodeID int) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	req, errResp := utils.DecodeBody[AskEpisodeRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if !strings.Contains(r.Header.Get("Accept"), "text/event-stream") {
+		answer, errResp := h.service.AskEpisode(r.Context(), episodeID, userID, req, nil)
+		if errResp != nil {
+			h.encodeError(w, errResp)
+			return
+		}
+		utils.EncodeResponse(w, http.StatusOK, answer)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		utils.EncodeResponse(w, http.StatusInternalServerError, map[string]string{
+			"error": "Streaming unsupported",
+		})
+		return
+	}
+
+	// The SSE response starts with the first delta, so errors found before the LLM
+	// answers (unknown episode or conversation) still get a regular status code
+	started := false
+	writeEvent := func(event string, payload any) error {
+		if !started {
+			w.Header().Set("Content-Type", "text/event-stream")
+			w.Header().Set("Cache-Control", "no-cache")
+			w.Header().Set("Connection", "keep-alive")
+			w.WriteHeader(http.StatusOK)
+			started = true
+		}
+		data, _ := json.Marshal(payload)
+		if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, data); err != nil {
+			return err
+		}
+		flusher.Flush()
+		return nil
+	}
+
+	answer, errResp := h.service.AskEpisode(r.Context(), episodeID, userID, req, func(content string) error {
+		return writeEvent("delta", map[string]string{"content": content})
+	})
+	if errResp != nil {
+		if !started {
+			h.encodeError(w, errResp)
+			return
+		}
+		h.log.Error("Episode chat stream error", map[string]any{
+			"episodeID": episodeID,
+			"error":     errResp.Details,
+		})
+		_ = writeEvent("error", map[string]string{"error": errResp.Details})
+		return
+	}
+
+	if err := writeEvent("message", answer); err != nil {
+		return
+	}
+	_ = writeEvent("complete", struct{}{})
+}
@@ -0,0 +1,287 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+const (
+	// transcriptWindowWords keeps each window request well within the model context
+	transcriptWindowWords = 2500
+	// windowMarkerInterval starts a new timestamped line in long monologues so
+	// sections and chapters can begin mid-turn
+	windowMarkerInterval = 60.0
+	// llmMaxParallel limits concurrent LLM requests for one generation
+	llmMaxParallel = 4
+	// staleGenerationAfter is how long a generation may stay processing before requests treat
+	// it as abandoned, e.g. by a restart, and generate it again
+	staleGenerationAfter = 30 * time.Minute
+	// retryFailedGenerationAfter is how long a failed generation is kept before requests
+	// generate it again, so clients polling one that keeps failing don't retry it on every read
+	retryFailedGenerationAfter = 10 * time.Minute
+)
+
+// transcriptWindow is a slice of the transcript rendered as timestamped speaker lines
+type transcriptWindow struct {
+	Start float64
+	End   float64
+	Text  string
+}
+
+// loadTranscriptWindows fetches the episode and the chunks of its transcript, and splits
+// the transcript into windows for LLM requests
+func (s *Service) loadTranscriptWindows(episodeID, transcriptID int) (Episode, []TranscriptChunk, []transcriptWindow, error) {
+	episode, err := s.repo.GetEpisodeByID(episodeID)
+	if err != nil {
+		return Episode{}, nil, nil, fmt.Errorf("failed to get episode: %w", err)
+	}
+
+	chunks, err := s.repo.GetChunksByTranscriptID(transcriptID)
+	if err != nil {
+		return Episode{}, nil, nil, fmt.Errorf("failed to get chunks: %w", err)
+	}
+
+	speakers, err := s.repo.GetSpeakersByTranscriptID(transcriptID)
+	if err != nil {
+		return Episode{}, nil, nil, fmt.Errorf("failed to get speakers: %w", err)
+	}
+
+	windows := buildTranscriptWindows(chunks, speakerNames(speakers), transcriptWindowWords)
+	if len(windows) == 0 {
+		return Episode{}, nil, nil, fmt.Errorf("transcript has no text")
+	}
+
+	return episode, chunks, windows, nil
+}
+
+// mapWindows runs fn for every window with at most llmMaxParallel calls at a time and
+// returns the results in window order, or the error of the first failed window
+func mapWindows[W, T any](windows []W, fn func(i int, window W) (T, error)) ([]T, error) {
+	results := make([]T, len(windows))
+	errs := make([]error, len(windows))
+
+	var wg sync.WaitGroup
+	semaphore := make(chan struct{}, llmMaxParallel)
+	for i, window := range windows {
+		wg.Add(1)
+		go func(i int, window W) {
+			defer wg.Done()
+			semaphore <- struct{}{}
+			defer func() { <-semaphore }()
+
+			results[i], errs[i] = fn(i, window)
+		}(i, window)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i+1, err)
+		}
+	}
+
+	return results, nil
+}
+
+// buildTranscriptWindows splits the transcript into windows of about maxWords words.
+// Each window is rendered as "[m:ss] Speaker: text" lines, starting a new line on every
+// speaker change and every windowMarkerInterval seconds.
+func buildTranscriptWindows(chunks []TranscriptChunk, names map[int]string, maxWords int) []transcriptWindow {
+	var (
+		windows     []transcriptWindow
+		text        strings.Builder
+		words       int
+		current     *transcriptWindow
+		lineStart   float64
+		lineSpeaker = -1
+	)
+
+	flush := func() {
+		if current != nil {
+			current.Text = text.String()
+			windows = append(windows, *current)
+		}
+		current, words, lineSpeaker = nil, 0, -1
+		text.Reset()
+	}
+
+	for _, chunk := range chunks {
+		if current == nil {
+			current = &transcriptWindow{Start: chunk.StartTime}
+		}
+
+		if chunk.SpeakerIndex != lineSpeaker || chunk.StartTime-lineStart >= windowMarkerInterval {
+			if text.Len() > 0 {
+				text.WriteString("\n")
+			}
+			fmt.Fprintf(&text, "[%s] %s:", FormatTimestamp(chunk.StartTime), speakerName(names, chunk.SpeakerIndex))
+			lineStart, lineSpeaker = chunk.StartTime, chunk.SpeakerIndex
+		}
+
+		text.WriteString(" ")
+		text.WriteString(chunk.Text)
+		current.End = chunk.EndTime
+		words++
+
+		if words >= maxWords {
+			flush()
+		}
+	}
+	flush()
+
+	return windows
+}
+
+// RenderTranscript renders the whole transcript as "[m:ss] Speaker N: text" lines, like the
+// windows sent to the LLM but without speaker names
+func RenderTranscript(chunks []TranscriptChunk) string {
+	windows := buildTranscriptWindows(chunks, nil, len(chunks))
+	if len(windows) == 0 {
+		return ""
+	}
+	return windows[0].Text
+}
+
+func speakerNames(speakers []TranscriptSpeaker) map[int]string {
+	names := make(map[int]string, len(speakers))
+	for _, speaker := range speakers {
+		names[speaker.SpeakerIndex] = speaker.SpeakerName
+	}
+	return names
+}
+
+func speakerName(names map[int]string, index int) string {
+	if name, ok := names[index]; ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("Speaker %d", index)
+}
+
+// FormatTimestamp renders seconds as m:ss, or h:mm:ss from one hour on
+func FormatTimestamp(seconds float64) string {
+	total := int(seconds)
+	hours, minutes, secs := total/3600, (total%3600)/60, total%60
+	if hours > 0 {
+		return fmt.Sprintf("%d:%02d:%02d", hours, minutes, secs)
+	}
+	return fmt.Sprintf("%d:%02d", minutes, secs)
+}
+
+// ParseTimestamp reads m:ss or h:mm:ss, optionally wrapped in brackets, as seconds
+func ParseTimestamp(value string) (float64, bool) {
+	parts := strings.Split(strings.Trim(strings.TrimSpace(value), "[]"), ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, false
+	}
+
+	total := 0
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		total = total*60 + n
+	}
+
+	return float64(total), true
+}
+
+// llmAvailable reports whether an LLM client is configured (nil interface or nil pointer)
+func (s *Service) llmAvailable() bool {
+	return s.llmClient != nil && !reflect.ValueOf(s.llmClient).IsNil()
+}
+
+// chatJSON sends a chat request and decodes the JSON object in the reply, ignoring
+// markdown code fences the model may add
+func chatJSON[T any](client llm.LLMClient, req llm.ChatRequest) (T, error) {
+	var zero T
+
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	response, err := client.Chat(ctx, req)
+	if err != nil {
+		return zero, err
+	}
+
+	if len(response.Choices) == 0 {
+		return zero, fmt.Errorf("no response from LLM")
+	}
+
+	content := strings.TrimSpace(response.Choices[0].Message.Content)
+	content = strings.TrimPrefix(content, "```json")
+	content = strings.TrimPrefix(content, "```")
+	content = strings.TrimSuffix(content, "```")
+	content = strings.TrimSpace(content)
+
+	result, err := utils.DecodeResponse[T](content)
+	if err != nil {
+		return zero, fmt.Errorf("failed to parse LLM response: %w", err)
+	}
+
+	return result, nil
+}
+
+// generationQueue runs at most one LLM generation per key, usually an episode. Requests
+// that arrive while one runs are coalesced into a single rerun, so a burst of corrections costs one
+// extra generation instead of one per correction.
+type generationQueue[K comparable] struct {
+	mu      sync.Mutex
+	running map[K]bool
+	rerun   map[K]bool
+}
+
+func newGenerationQueue[K comparable]() *generationQueue[K] {
+	return &generationQueue[K]{
+		running: make(map[K]bool),
+		rerun:   make(map[K]bool),
+	}
+}
+
+// schedule runs generate in the background unless it is already running for the key
+func (q *generationQueue[K]) schedule(key K, generate func()) {
+	q.mu.Lock()
+	if q.running[key] {
+		q.rerun[key] = true
+		q.mu.Unlock()
+		return
+	}
+	q.running[key] = true
+	q.mu.Unlock()
+
+	go func() {
+		for {
+			generate()
+
+			q.mu.Lock()
+			if !q.rerun[key] {
+				delete(q.running, key)
+				q.mu.Unlock()
+				return
+			}
+			delete(q.rerun, key)
+			q.mu.Unlock()
+		}
+	}()
+}
+
+// needsRegeneration reports whether a stored generation must run again because its last
+// attempt failed more than retryFailedGenerationAfter ago or it has been processing for
+// longer than staleGenerationAfter
+func needsRegeneration(status string, updatedAt time.Time) bool {
+	switch status {
+	case string(TranscriptStatusFailed):
+		return time.Since(updatedAt) > retryFailedGenerationAfter
+	case string(TranscriptStatusProcessing):
+		return time.Since(updatedAt) > staleGenerationAfter
+	}
+	return false
+}
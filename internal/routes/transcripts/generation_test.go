@@ -0,0 +1,122 @@
+package transcripts
+
+import (
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestBuildTranscriptWindows(t *testing.T) {
+	windows := buildTranscriptWindows(summaryChunks(6), map[int]string{1: "Jane Doe"}, 4)
+
+	if len(windows) != 2 {
+		t.Fatalf("Expected 2 windows, got %d", len(windows))
+	}
+	if windows[0].Start != 0 || windows[0].End != 91 || windows[1].Start != 120 || windows[1].End != 151 {
+		t.Errorf("Unexpected window bounds: %+v", windows)
+	}
+
+	want := "[0:00] Speaker 0: w0 w1\n[1:00] Speaker 0: w2\n[1:30] Jane Doe: w3"
+	if windows[0].Text != want {
+		t.Errorf("Expected %q, got %q", want, windows[0].Text)
+	}
+	if !strings.HasPrefix(windows[1].Text, "[2:00] Jane Doe: w4") {
+		t.Errorf("Expected the second window to restart the speaker line, got %q", windows[1].Text)
+	}
+
+	if windows := buildTranscriptWindows(nil, nil, 4); len(windows) != 0 {
+		t.Errorf("Expected no windows for an empty transcript, got %+v", windows)
+	}
+}
+
+func TestRenderTranscript(t *testing.T) {
+	want := "[0:00] Speaker 0: w0 w1\n[1:00] Speaker 0: w2\n[1:30] Speaker 1: w3 w4"
+	if got := RenderTranscript(summaryChunks(5)); got != want {
+		t.Errorf("Expected %q, got %q", want, got)
+	}
+
+	if got := RenderTranscript(nil); got != "" {
+		t.Errorf("Expected an empty transcript, got %q", got)
+	}
+}
+
+func TestTimestamps(t *testing.T) {
+	for seconds, want := range map[float64]string{0: "0:00", 75.9: "1:15", 3725: "1:02:05"} {
+		if got := FormatTimestamp(seconds); got != want {
+			t.Errorf("FormatTimestamp(%v) = %q, want %q", seconds, got, want)
+		}
+	}
+
+	for value, want := range map[string]float64{"1:15": 75, "[1:02:05]": 3725, " 0:07 ": 7} {
+		if got, ok := ParseTimestamp(value); !ok || got != want {
+			t.Errorf("ParseTimestamp(%q) = %v, %v, want %v", value, got, ok, want)
+		}
+	}
+
+	for _, value := range []string{"", "75", "a:bc", "1:-5", "1:2:3:4"} {
+		if _, ok := ParseTimestamp(value); ok {
+			t.Errorf("Expected ParseTimestamp(%q) to fail", value)
+		}
+	}
+}
+
+func TestGenerationQueue(t *testing.T) {
+	queue := newGenerationQueue[int]()
+	var runs atomic.Int32
+	release := make(chan struct{})
+	done := make(chan struct{}, 10)
+
+	generate := func() {
+		if runs.Add(1) == 1 {
+			<-release
+		}
+		done <- struct{}{}
+	}
+
+	queue.schedule(1, generate)
+	for range 3 {
+		queue.schedule(1, generate)
+	}
+	close(release)
+
+	for range 2 {
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for generation")
+		}
+	}
+
+	select {
+	case <-done:
+		t.Fatal("Expected scheduled reruns to be coalesced into one")
+	case <-time.After(50 * time.Millisecond):
+	}
+	if runs.Load() != 2 {
+		t.Errorf("Expected 2 runs, got %d", runs.Load())
+	}
+}
+
+func TestNeedsRegeneration(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     TranscriptStatus
+		updatedAgo time.Duration
+		want       bool
+	}{
+		{"complete", TranscriptStatusComplete, staleGenerationAfter * 2, false},
+		{"recently failed", TranscriptStatusFailed, 0, false},
+		{"failed", TranscriptStatusFailed, retryFailedGenerationAfter + time.Minute, true},
+		{"processing", TranscriptStatusProcessing, time.Minute, false},
+		{"stale processing", TranscriptStatusProcessing, staleGenerationAfter + time.Minute, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := needsRegeneration(string(tt.status), time.Now().Add(-tt.updatedAgo)); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -5,10 +5,14 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
+	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/middlewares"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
@@ -38,14 +42,380 @@ func (h *TranscriptHandler) HandleRequest(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	// Episode content derived from the transcript is served under /episodes
+	if path, ok := strings.CutPrefix(r.URL.Path, "/episodes"); ok {
+		h.handleEpisodeContentRoutes(w, r, path)
+		return
+	}
+
+	// Search across every transcript is served under /search
+	if path, ok := strings.CutPrefix(r.URL.Path, "/search"); ok {
+		h.handleSearchRoutes(w, r, path)
+		return
+	}
+
 	// Route based on path
 	path := strings.TrimPrefix(r.URL.Path, "/transcripts")
 
 	switch {
 	case path == "/stream/sse" && r.Method == "GET":
 		h.handleSSEStream(w, r)
+	case path == "/annotations":
+		// GET /transcripts/annotations?q=...&limit=...&offset=...
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleSearchAnnotations(w, r)
 	default:
+		h.handleEpisodeRoutes(w, r, path)
+	}
+}
+
+// handleEpisodeRoutes routes /transcripts/:episode_id/* requests
+func (h *TranscriptHandler) handleEpisodeRoutes(w http.ResponseWriter, r *http.Request, path string) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 2 {
 		utils.NotFound(w, r)
+		return
+	}
+
+	episodeID, err := strconv.Atoi(parts[0])
+	if err != nil {
+		utils.NotFound(w, r)
+		return
+	}
+
+	switch parts[1] {
+	case "speakers":
+		h.handleSpeakers(w, r, episodeID, parts[2:])
+	case "revisions":
+		h.handleRevisions(w, r, episodeID, parts[2:])
+	case "transcribe":
+		// POST /transcripts/:episode_id/transcribe
+		if len(parts) != 2 {
+			utils.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodPost {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleTranscribe(w, r, episodeID)
+	case "low-confidence":
+		// GET /transcripts/:episode_id/low-confidence
+		if len(parts) != 2 {
+			utils.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleLowConfidence(w, r, episodeID)
+	case "export":
+		// GET /transcripts/:episode_id/export
+		if len(parts) != 2 {
+			utils.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleExport(w, r, episodeID)
+	case "analytics":
+		// GET /transcripts/:episode_id/analytics
+		if len(parts) != 2 {
+			utils.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleAnalytics(w, episodeID)
+	case "annotations":
+		h.handleAnnotations(w, r, episodeID, parts[2:])
+	case "redactions":
+		// GET /transcripts/:episode_id/redactions
+		if len(parts) != 2 {
+			utils.NotFound(w, r)
+			return
+		}
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleRedactions(w, r, episodeID)
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+// handleSpeakers routes /transcripts/:episode_id/speakers/* requests
+func (h *TranscriptHandler) handleSpeakers(w http.ResponseWriter, r *http.Request, episodeID int, parts []string) {
+	if len(parts) != 1 {
+		utils.NotFound(w, r)
+		return
+	}
+
+	if parts[0] == "merge" {
+		// POST /transcripts/:episode_id/speakers/merge
+		if r.Method != http.MethodPost {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleMergeSpeakers(w, r, episodeID)
+		return
+	}
+
+	speakerIndex, err := strconv.Atoi(parts[0])
+	if err != nil || speakerIndex < 0 {
+		utils.NotFound(w, r)
+		return
+	}
+
+	// PATCH /transcripts/:episode_id/speakers/:index
+	if r.Method != http.MethodPatch {
+		utils.NotAllowed(w)
+		return
+	}
+	h.handleRenameSpeaker(w, r, episodeID, speakerIndex)
+}
+
+func (h *TranscriptHandler) handleRenameSpeaker(w http.ResponseWriter, r *http.Request, episodeID, speakerIndex int) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	req, errResp := utils.DecodeBody[RenameSpeakerRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	speaker, errResp := h.service.RenameSpeaker(episodeID, speakerIndex, userID, req.Name)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, speaker)
+}
+
+func (h *TranscriptHandler) handleMergeSpeakers(w http.ResponseWriter, r *http.Request, episodeID int) {
+	req, errResp := utils.DecodeBody[MergeSpeakersRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	speakers, errResp := h.service.MergeSpeakers(episodeID, *req.FromIndex, *req.ToIndex)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, speakers)
+}
+
+func (h *TranscriptHandler) handleTranscribe(w http.ResponseWriter, r *http.Request, episodeID int) {
+	req, errResp := utils.DecodeBody[TranscribeRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	job, errResp := h.service.StartTranscriptionJob(episodeID, userID, req.Preferences)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusAccepted, job)
+}
+
+func (h *TranscriptHandler) handleLowConfidence(w http.ResponseWriter, r *http.Request, episodeID int) {
+	threshold := DefaultLowConfidenceThreshold
+	if value := r.URL.Query().Get("threshold"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed <= 0 || parsed > 1 {
+			utils.EncodeResponse(w, http.StatusBadRequest, &errors.ErrorResponse{
+				Message: errors.ValidationError,
+				Details: "threshold must be a number greater than 0 and at most 1",
+			})
+			return
+		}
+		threshold = parsed
+	}
+
+	report, errResp := h.service.GetLowConfidenceSpans(episodeID, threshold)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, report)
+}
+
+func (h *TranscriptHandler) handleAnalytics(w http.ResponseWriter, episodeID int) {
+	analytics, errResp := h.service.GetAnalytics(episodeID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, analytics)
+}
+
+// handleRedactions returns the original text of redacted chunks to admins
+func (h *TranscriptHandler) handleRedactions(w http.ResponseWriter, r *http.Request, episodeID int) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	chunks, errResp := h.service.GetRedactions(episodeID, userID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, chunks)
+}
+
+// handleExport returns the transcript, or with ?lang= its translation. Like summaries, a
+// translation responds 202 while it is generated.
+func (h *TranscriptHandler) handleExport(w http.ResponseWriter, r *http.Request, episodeID int) {
+	language := r.URL.Query().Get("lang")
+	if language == "" {
+		export, errResp := h.service.ExportTranscript(episodeID)
+		if errResp != nil {
+			h.encodeError(w, errResp)
+			return
+		}
+
+		utils.EncodeResponse(w, http.StatusOK, export)
+		return
+	}
+
+	export, errResp := h.service.ExportTranslatedTranscript(episodeID, language)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	status := http.StatusOK
+	if export.TranslationStatus == string(TranscriptStatusProcessing) {
+		status = http.StatusAccepted
+	}
+
+	utils.EncodeResponse(w, status, export)
+}
+
+// handleRevisions routes /transcripts/:episode_id/revisions/* requests
+func (h *TranscriptHandler) handleRevisions(w http.ResponseWriter, r *http.Request, episodeID int, parts []string) {
+	switch len(parts) {
+	case 0:
+		switch r.Method {
+		case http.MethodGet:
+			// GET /transcripts/:episode_id/revisions
+			h.handleGetRevisions(w, episodeID)
+		case http.MethodPost:
+			// POST /transcripts/:episode_id/revisions
+			h.handleCreateRevision(w, r, episodeID)
+		default:
+			utils.NotAllowed(w)
+		}
+	case 2:
+		revisionID, err := strconv.Atoi(parts[0])
+		if err != nil || parts[1] != "revert" {
+			utils.NotFound(w, r)
+			return
+		}
+
+		// POST /transcripts/:episode_id/revisions/:revision_id/revert
+		if r.Method != http.MethodPost {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleRevertRevision(w, r, episodeID, revisionID)
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+func (h *TranscriptHandler) handleGetRevisions(w http.ResponseWriter, episodeID int) {
+	revisions, errResp := h.service.GetRevisions(episodeID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, revisions)
+}
+
+func (h *TranscriptHandler) handleCreateRevision(w http.ResponseWriter, r *http.Request, episodeID int) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	req, errResp := utils.DecodeBody[CreateRevisionRequest](r)
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	if err := req.Validate(); err != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, err)
+		return
+	}
+
+	revision, errResp := h.service.CreateRevision(episodeID, userID, *req.StartPosition, *req.EndPosition, req.Text)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusCreated, revision)
+}
+
+func (h *TranscriptHandler) handleRevertRevision(w http.ResponseWriter, r *http.Request, episodeID, revisionID int) {
+	userID, _ := r.Context().Value(middlewares.UserIDContextKey).(int)
+
+	revision, errResp := h.service.RevertRevision(episodeID, revisionID, userID)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusCreated, revision)
+}
+
+// encodeError maps service errors to HTTP status codes
+func (h *TranscriptHandler) encodeError(w http.ResponseWriter, errResp *errors.ErrorResponse) {
+	switch errResp.Message {
+	case errors.DatabaseNotFound:
+		utils.EncodeResponse(w, http.StatusNotFound, errResp)
+	case errors.ValidationError:
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+	case errors.DatabaseConflict:
+		utils.EncodeResponse(w, http.StatusConflict, errResp)
+	case errors.Unauthorized:
+		utils.EncodeResponse(w, http.StatusForbidden, errResp)
+	default:
+		utils.EncodeResponse(w, http.StatusInternalServerError, errResp)
 	}
 }
 
@@ -69,6 +439,22 @@ func (h *TranscriptHandler) handleSSEStream(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	preferences, errResp := parseStreamPreferences(r.URL.Query())
+	if errResp != nil {
+		utils.EncodeResponse(w, http.StatusBadRequest, errResp)
+		return
+	}
+
+	// With lang the complete transcript is replayed translated instead
+	language := r.URL.Query().Get("lang")
+	if language != "" && !translationLanguagePattern.MatchString(language) {
+		utils.EncodeResponse(w, http.StatusBadRequest, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "lang must be a language code (e.g. pt, es, pt-BR)",
+		})
+		return
+	}
+
 	// Set SSE headers
 	w.Header().Set("Content-Type", "text/event-stream")
 	w.Header().Set("Cache-Control", "no-cache")
@@ -170,28 +556,30 @@ func (h *TranscriptHandler) handleSSEStream(w http.ResponseWriter, r *http.Reque
 
 	// Stream transcript: push events to the channel instead of writing
 	// directly to the response.
-	err = h.service.StreamTranscript(streamCtx, episodeID,
-		// Chunk callback
-		func(chunk *Chunk) error {
-			data, _ := json.Marshal(chunk)
-			payload := fmt.Sprintf("event: chunk\ndata: %s\n\n", data)
-			if err := enqueue(payload); err != nil {
-				// Context cancelled or channel closed - stop processing
-				return err
-			}
-			return nil
-		},
-		// Speaker callback
-		func(speaker *Speaker) error {
-			data, _ := json.Marshal(speaker)
-			payload := fmt.Sprintf("event: speaker\ndata: %s\n\n", data)
-			if err := enqueue(payload); err != nil {
-				// Context cancelled or channel closed - stop processing
-				return err
-			}
-			return nil
-		},
-	)
+	chunkCB := func(chunk *Chunk) error {
+		data, _ := json.Marshal(chunk)
+		payload := fmt.Sprintf("event: chunk\ndata: %s\n\n", data)
+		if err := enqueue(payload); err != nil {
+			// Context cancelled or channel closed - stop processing
+			return err
+		}
+		return nil
+	}
+	speakerCB := func(speaker *Speaker) error {
+		data, _ := json.Marshal(speaker)
+		payload := fmt.Sprintf("event: speaker\ndata: %s\n\n", data)
+		if err := enqueue(payload); err != nil {
+			// Context cancelled or channel closed - stop processing
+			return err
+		}
+		return nil
+	}
+
+	if language != "" {
+		err = h.service.StreamTranslation(streamCtx, episodeID, language, chunkCB, speakerCB)
+	} else {
+		err = h.service.StreamTranscript(streamCtx, episodeID, preferences, chunkCB, speakerCB)
+	}
 
 	h.log.Debug("StreamTranscript completed", map[string]any{
 		"episodeID": episodeID,
@@ -256,3 +644,34 @@ func (h *TranscriptHandler) handleSSEStream(w http.ResponseWriter, r *http.Reque
 	default:
 	}
 }
+
+// parseStreamPreferences reads the optional language, model, smart_format, keyterm and
+// mode query parameters used when the episode has not been transcribed yet
+func parseStreamPreferences(query url.Values) (transcription.Preferences, *errors.ErrorResponse) {
+	preferences := transcription.Preferences{
+		Language: query.Get("language"),
+		Model:    query.Get("model"),
+		Keyterms: query["keyterm"],
+		Mode:     query.Get("mode"),
+	}
+
+	if value := query.Get("smart_format"); value != "" {
+		smartFormat, err := strconv.ParseBool(value)
+		if err != nil {
+			return transcription.Preferences{}, &errors.ErrorResponse{
+				Message: errors.ValidationError,
+				Details: "smart_format must be true or false",
+			}
+		}
+		preferences.SmartFormat = &smartFormat
+	}
+
+	if err := preferences.Validate(); err != nil {
+		return transcription.Preferences{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: err.Error(),
+		}
+	}
+
+	return preferences, nil
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"strings"
 	"testing"
 
@@ -16,7 +17,7 @@ import (
 
 type MockTranscriptionClient struct{}
 
-func (m *MockTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, callback transcription.StreamCallback) error {
+func (m *MockTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, opts transcription.StreamOptions, callback transcription.StreamCallback) error {
 	// Simulate streaming a few chunks
 	response := &transcription.StreamResponse{
 		Type: "Results",
@@ -63,7 +64,7 @@ func (m *MockLLMClient) Chat(ctx context.Context, req llm.ChatRequest) (llm.Chat
 func setupMockedService() *Service {
 	transcriptionClient := &MockTranscriptionClient{}
 	llmClient := &MockLLMClient{}
-	service := NewService(transcriptionClient, llmClient)
+	service := NewService(NewTranscriptRepository(), transcriptionClient, llmClient)
 
 	// Mock episode exists
 	mockEpisode := Episode{
@@ -120,7 +121,7 @@ func setupMockedService() *Service {
 func TestTranscriptHandler_HandleRequest(t *testing.T) {
 	transcriptionClient := &MockTranscriptionClient{}
 	llmClient := &MockLLMClient{}
-	service := NewService(transcriptionClient, llmClient)
+	service := NewService(NewTranscriptRepository(), transcriptionClient, llmClient)
 	handler := NewTranscriptHandler(service)
 
 	t.Run("should handle SSE stream request with valid episode_id", func(t *testing.T) {
@@ -156,6 +157,19 @@ func TestTranscriptHandler_HandleRequest(t *testing.T) {
 		}
 	})
 
+	t.Run("should return bad request for invalid transcription options", func(t *testing.T) {
+		for _, query := range []string{"language=english", "model=nova%203", "smart_format=maybe", "lang=Portuguese"} {
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(http.MethodGet, "/transcripts/stream/sse?episode_id=1&"+query, nil)
+
+			handler.HandleRequest(w, r)
+
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("Expected status code %v for %s, got %v", http.StatusBadRequest, query, w.Code)
+			}
+		}
+	})
+
 	t.Run("should return not found for unknown path", func(t *testing.T) {
 		w := httptest.NewRecorder()
 		r := httptest.NewRequest(http.MethodGet, "/transcripts/unknown", nil)
@@ -289,7 +303,7 @@ func TestTranscriptHandler_StreamErrors(t *testing.T) {
 	t.Run("should handle database error gracefully", func(t *testing.T) {
 		transcriptionClient := &MockTranscriptionClient{}
 		llmClient := &MockLLMClient{}
-		service := NewService(transcriptionClient, llmClient)
+		service := NewService(NewTranscriptRepository(), transcriptionClient, llmClient)
 		handler := NewTranscriptHandler(service)
 
 		// Mock episode query returns error
@@ -339,3 +353,22 @@ func TestTranscriptHandler_SSEHeaders(t *testing.T) {
 		}
 	})
 }
+
+func TestParseStreamPreferences(t *testing.T) {
+	query := url.Values{
+		"language":     {"pt-BR"},
+		"model":        {"nova-3"},
+		"smart_format": {"false"},
+		"keyterm":      {"Cribe", "pgx"},
+	}
+
+	preferences, errResp := parseStreamPreferences(query)
+
+	if errResp != nil {
+		t.Fatalf("Expected no error, got %v", errResp)
+	}
+	if preferences.Language != "pt-BR" || preferences.Model != "nova-3" || len(preferences.Keyterms) != 2 ||
+		preferences.SmartFormat == nil || *preferences.SmartFormat {
+		t.Errorf("Unexpected preferences: %+v", preferences)
+	}
+}
@@ -0,0 +1,344 @@
+package transcripts
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+const (
+	// maxHighlights keeps the best quotes of the whole episode, whatever the number of windows
+	maxHighlights = 10
+	// highlightMinWords and highlightMaxWords drop fragments and passages too long to quote
+	highlightMinWords = 5
+	highlightMaxWords = 80
+)
+
+// highlightCandidate is a quote proposed by the LLM, before it is located in the chunks
+type highlightCandidate struct {
+	Quote  string
+	Start  float64
+	Score  int
+	Reason string
+}
+
+// llmHighlights is the LLM response for one transcript window
+type llmHighlights struct {
+	Highlights []struct {
+		Quote  string `json:"quote"`
+		Start  string `json:"start"`
+		Score  int    `json:"score"`
+		Reason string `json:"reason"`
+	} `json:"highlights"`
+}
+
+// GetHighlights returns the highlights of an episode with the current speaker names. When an
+// episode with a complete transcript has none yet, or their last generation was abandoned or
+// failed a while ago (see needsRegeneration), generation is started and a processing
+// placeholder is returned.
+func (s *Service) GetHighlights(episodeID int) (EpisodeHighlights, *errors.ErrorResponse) {
+	highlights, err := s.repo.GetHighlightsByEpisodeID(episodeID)
+	if err == nil && !needsRegeneration(highlights.Status, highlights.UpdatedAt) {
+		speakers, err := s.repo.GetSpeakersByTranscriptID(highlights.TranscriptID)
+		if err != nil {
+			return EpisodeHighlights{}, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to fetch speakers",
+			}
+		}
+		return withHighlightSpeakerNames(highlights, speakers), nil
+	}
+	if err != nil && err.Error() != "no rows in result set" {
+		return EpisodeHighlights{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch highlights",
+		}
+	}
+
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return EpisodeHighlights{}, errResp
+	}
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return EpisodeHighlights{}, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Highlights are available once the transcript is complete",
+		}
+	}
+
+	if !s.llmAvailable() {
+		return EpisodeHighlights{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "LLM client not configured",
+		}
+	}
+
+	s.scheduleHighlights(episodeID)
+
+	return EpisodeHighlights{
+		EpisodeID:    episodeID,
+		TranscriptID: transcript.ID,
+		Status:       string(TranscriptStatusProcessing),
+		Highlights:   []Highlight{},
+	}, nil
+}
+
+// scheduleHighlights (re)generates the highlights of an episode in the background
+func (s *Service) scheduleHighlights(episodeID int) {
+	if s.highlights == nil || !s.llmAvailable() {
+		return
+	}
+
+	s.highlights.schedule(episodeID, func() {
+		if err := s.generateHighlights(episodeID); err != nil {
+			s.log.Error("Failed to generate episode highlights", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+	})
+}
+
+// generateHighlights asks the LLM for the quotable passages of each transcript window and
+// keeps the best ones that can be found word for word in the chunks
+func (s *Service) generateHighlights(episodeID int) error {
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return fmt.Errorf("%s", errResp.Details)
+	}
+
+	if _, err := s.repo.StartHighlights(episodeID, transcript.ID); err != nil {
+		return fmt.Errorf("failed to start highlights: %w", err)
+	}
+
+	fail := func(err error) error {
+		_ = s.repo.FailHighlights(episodeID, err.Error())
+		return err
+	}
+
+	episode, chunks, windows, err := s.loadTranscriptWindows(episodeID, transcript.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	s.log.Info("Generating episode highlights", map[string]any{
+		"episodeID": episodeID,
+		"windows":   len(windows),
+	})
+
+	parts, err := mapWindows(windows, func(i int, window transcriptWindow) (llmHighlights, error) {
+		return chatJSON[llmHighlights](s.llmClient, llm.ChatRequest{
+			Messages: []llm.Message{
+				{Role: "system", Content: HighlightWindowSystemPrompt},
+				{Role: "user", Content: HighlightWindowUserPrompt(episode.Name, i+1, len(windows), window.Text)},
+			},
+			MaxTokens: 800,
+		})
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to pick highlights in %w", err))
+	}
+
+	candidates := []highlightCandidate{}
+	for i, part := range parts {
+		candidates = append(candidates, toHighlightCandidates(part, windows[i].Start)...)
+	}
+
+	highlights := locateHighlights(candidates, chunks)
+	if len(highlights) == 0 {
+		return fail(fmt.Errorf("LLM returned no highlights found in the transcript"))
+	}
+
+	if err := s.repo.CompleteHighlights(episodeID, highlights); err != nil {
+		return fmt.Errorf("failed to save highlights: %w", err)
+	}
+
+	s.log.Info("Episode highlights generated", map[string]any{
+		"episodeID":  episodeID,
+		"candidates": len(candidates),
+		"highlights": len(highlights),
+	})
+
+	return nil
+}
+
+// toHighlightCandidates converts the quotes of an LLM response, dropping empty ones. A start
+// that can't be read falls back to the start of the window; it only breaks ties between
+// repeated quotes.
+func toHighlightCandidates(response llmHighlights, windowStart float64) []highlightCandidate {
+	candidates := make([]highlightCandidate, 0, len(response.Highlights))
+	for _, highlight := range response.Highlights {
+		quote := strings.TrimSpace(highlight.Quote)
+		if quote == "" {
+			continue
+		}
+		start, ok := ParseTimestamp(highlight.Start)
+		if !ok {
+			start = windowStart
+		}
+		candidates = append(candidates, highlightCandidate{
+			Quote:  quote,
+			Start:  start,
+			Score:  highlight.Score,
+			Reason: strings.TrimSpace(highlight.Reason),
+		})
+	}
+	return candidates
+}
+
+// locateHighlights maps the best scored candidates to the chunks they quote. Quotes that
+// aren't found word for word, span several speakers, have an unquotable length or overlap
+// a better highlight are dropped. The highlights are returned in transcript order.
+func locateHighlights(candidates []highlightCandidate, chunks []TranscriptChunk) []Highlight {
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Score > candidates[j].Score })
+
+	words := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		words[i] = normalizeQuoteWord(chunk.Text)
+	}
+
+	highlights := []Highlight{}
+	for _, candidate := range candidates {
+		if len(highlights) == maxHighlights {
+			break
+		}
+
+		quoted := quoteWords(candidate.Quote)
+		if len(quoted) < highlightMinWords || len(quoted) > highlightMaxWords {
+			continue
+		}
+
+		from, to, ok := findQuote(quoted, words, chunks, candidate.Start)
+		if !ok || !singleSpeaker(chunks[from:to+1]) {
+			continue
+		}
+
+		overlaps := false
+		for _, highlight := range highlights {
+			if chunks[from].Position <= highlight.EndPosition && chunks[to].Position >= highlight.StartPosition {
+				overlaps = true
+				break
+			}
+		}
+		if overlaps {
+			continue
+		}
+
+		texts := make([]string, 0, to-from+1)
+		for _, chunk := range chunks[from : to+1] {
+			if chunk.Text != "" {
+				texts = append(texts, chunk.Text)
+			}
+		}
+
+		highlights = append(highlights, Highlight{
+			Text:          strings.Join(texts, " "),
+			SpeakerIndex:  chunks[from].SpeakerIndex,
+			Start:         chunks[from].StartTime,
+			End:           chunks[to].EndTime,
+			StartPosition: chunks[from].Position,
+			EndPosition:   chunks[to].Position,
+			Reason:        candidate.Reason,
+		})
+	}
+
+	sort.Slice(highlights, func(i, j int) bool { return highlights[i].StartPosition < highlights[j].StartPosition })
+	return highlights
+}
+
+// LocateQuote returns the indexes of the first and last chunk quoted word for word, ignoring
+// casing and punctuation. When the quote occurs more than once the occurrence closest to
+// near, in seconds, wins.
+func LocateQuote(quote string, chunks []TranscriptChunk, near float64) (int, int, bool) {
+	words := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		words[i] = normalizeQuoteWord(chunk.Text)
+	}
+	return findQuote(quoteWords(quote), words, chunks, near)
+}
+
+// quoteWords returns the normalized words of a quote
+func quoteWords(quote string) []string {
+	var quoted []string
+	for _, field := range strings.Fields(quote) {
+		if word := normalizeQuoteWord(field); word != "" {
+			quoted = append(quoted, word)
+		}
+	}
+	return quoted
+}
+
+// findQuote returns the indexes of the first and last chunk of the quoted words, comparing
+// them with the normalized chunk words and skipping chunks left without words, e.g. by
+// redaction. When the quote occurs more than once the occurrence closest to near wins.
+func findQuote(quoted []string, words []string, chunks []TranscriptChunk, near float64) (int, int, bool) {
+	if len(quoted) == 0 {
+		return 0, 0, false
+	}
+
+	// Indexes of the chunks with words, so a match can skip the empty ones
+	indexes := make([]int, 0, len(words))
+	for i, word := range words {
+		if word != "" {
+			indexes = append(indexes, i)
+		}
+	}
+
+	from, to, distance := 0, 0, math.Inf(1)
+	for k := 0; k+len(quoted) <= len(indexes); k++ {
+		match := true
+		for j, word := range quoted {
+			if words[indexes[k+j]] != word {
+				match = false
+				break
+			}
+		}
+		if !match {
+			continue
+		}
+		if d := math.Abs(chunks[indexes[k]].StartTime - near); d < distance {
+			from, to, distance = indexes[k], indexes[k+len(quoted)-1], d
+		}
+	}
+
+	return from, to, !math.IsInf(distance, 1)
+}
+
+func singleSpeaker(chunks []TranscriptChunk) bool {
+	for _, chunk := range chunks {
+		if chunk.SpeakerIndex != chunks[0].SpeakerIndex {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeQuoteWord lowercases a word and strips its punctuation, since the LLM rarely
+// copies punctuation and casing exactly
+func normalizeQuoteWord(word string) string {
+	var out strings.Builder
+	for _, r := range strings.ToLower(word) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			out.WriteRune(r)
+		}
+	}
+	return out.String()
+}
+
+// withHighlightSpeakerNames fills in the current speaker names, which aren't stored so
+// renames don't require a regeneration
+func withHighlightSpeakerNames(highlights EpisodeHighlights, speakers []TranscriptSpeaker) EpisodeHighlights {
+	names := speakerNames(speakers)
+	named := make([]Highlight, len(highlights.Highlights))
+	for i, highlight := range highlights.Highlights {
+		highlight.SpeakerName = speakerName(names, highlight.SpeakerIndex)
+		named[i] = highlight
+	}
+	highlights.Highlights = named
+	return highlights
+}
@@ -0,0 +1,256 @@
+package transcripts
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupHighlightService mocks a complete transcript with the given chunks and records the
+// highlights written by CompleteHighlights
+func setupHighlightService(client llm.LLMClient, chunks []TranscriptChunk) (*Service, chan []Highlight) {
+	service, _ := setupSummaryService(client, chunks)
+	service.highlights = newGenerationQueue[int]()
+	completed := make(chan []Highlight, 1)
+
+	service.repo.highlightRepo.Executor = utils.QueryExecutor[EpisodeHighlights]{
+		QueryItem: func(query string, args ...any) (EpisodeHighlights, error) {
+			if strings.Contains(query, "INSERT") {
+				return EpisodeHighlights{EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+			}
+			return EpisodeHighlights{}, fmt.Errorf("no rows in result set")
+		},
+		Exec: func(query string, args ...any) error {
+			if strings.Contains(query, "'complete'") {
+				completed <- args[1].([]Highlight)
+			}
+			return nil
+		},
+	}
+
+	return service, completed
+}
+
+// highlightChunks turns the text into one chunk per word, one second each, switching
+// speakers at every "|"
+func highlightChunks(text string) []TranscriptChunk {
+	chunks := []TranscriptChunk{}
+	speaker := 0
+	for _, word := range strings.Fields(text) {
+		if word == "|" {
+			speaker = 1 - speaker
+			continue
+		}
+		i := len(chunks)
+		chunks = append(chunks, TranscriptChunk{Position: i, SpeakerIndex: speaker, StartTime: float64(i), EndTime: float64(i) + 0.5, Text: word})
+	}
+	return chunks
+}
+
+func TestLocateQuote(t *testing.T) {
+	chunks := highlightChunks("So, the best way to learn is to teach it. | Really? The best way to learn is to teach it, you say?")
+
+	from, to, ok := LocateQuote("the best way to learn", chunks, 12)
+	if !ok || from != 11 || to != 15 {
+		t.Errorf("Expected the occurrence closest to 12s (11-15), got %d-%d (found %v)", from, to, ok)
+	}
+
+	if _, _, ok := LocateQuote("the worst way", chunks, 0); ok {
+		t.Error("Expected a quote that isn't in the transcript not to be found")
+	}
+	if _, _, ok := LocateQuote(" ... ", chunks, 0); ok {
+		t.Error("Expected a quote without words not to be found")
+	}
+}
+
+func TestLocateHighlights(t *testing.T) {
+	chunks := highlightChunks("So, the best way to learn is to teach it. | Really? The best way to learn is to teach it, you say? | Yes.")
+
+	t.Run("maps quotes to exact chunk ranges", func(t *testing.T) {
+		highlights := locateHighlights([]highlightCandidate{
+			{Quote: "The best way to learn is to teach it", Start: 12, Score: 9, Reason: "Memorable"},
+		}, chunks)
+
+		want := Highlight{
+			Text:          "The best way to learn is to teach it,",
+			SpeakerIndex:  1,
+			Start:         11,
+			End:           19.5,
+			StartPosition: 11,
+			EndPosition:   19,
+			Reason:        "Memorable",
+		}
+		if len(highlights) != 1 || highlights[0] != want {
+			t.Errorf("Expected the occurrence closest to the start %+v, got %+v", want, highlights)
+		}
+	})
+
+	t.Run("drops unfound, cross-speaker, short and overlapping quotes", func(t *testing.T) {
+		highlights := locateHighlights([]highlightCandidate{
+			{Quote: "learn is to teach it, you say", Score: 5},
+			{Quote: "the best way to learn is to teach it.", Start: 0, Score: 9},
+			{Quote: "the best way to study is to teach it", Score: 10},
+			{Quote: "teach it. Really? The best way", Score: 8},
+			{Quote: "Yes.", Score: 7},
+			{Quote: "best way to learn is to", Score: 6},
+		}, chunks)
+
+		if len(highlights) != 2 {
+			t.Fatalf("Expected 2 highlights, got %+v", highlights)
+		}
+		if highlights[0].StartPosition != 1 || highlights[0].EndPosition != 9 {
+			t.Errorf("Expected the best scored quote first in transcript order, got %+v", highlights[0])
+		}
+		if highlights[1].StartPosition != 15 || highlights[1].EndPosition != 21 {
+			t.Errorf("Expected the quote of the second speaker, got %+v", highlights[1])
+		}
+	})
+
+	t.Run("skips chunks emptied by redaction", func(t *testing.T) {
+		chunks := highlightChunks("call me at [phone] now or later please")
+		chunks[3].Text = ""
+
+		highlights := locateHighlights([]highlightCandidate{{Quote: "me at now or later", Score: 1}}, chunks)
+
+		if len(highlights) != 1 || highlights[0].Text != "me at now or later" || highlights[0].StartPosition != 1 || highlights[0].EndPosition != 6 {
+			t.Errorf("Expected the empty chunk to be skipped, got %+v", highlights)
+		}
+	})
+}
+
+func TestTranscriptService_GenerateHighlights(t *testing.T) {
+	chunks := highlightChunks("Welcome back. | Thanks. Every expert was once a beginner who refused to quit.")
+
+	t.Run("saves the quotes found in the transcript", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			HighlightWindowSystemPrompt: `{"highlights": [
+				{"quote": "every expert was once a beginner who refused to quit", "start": "0:03", "score": 9, "reason": "Motivating"},
+				{"quote": "a sentence nobody said in this episode", "start": "0:00", "score": 10}
+			]}`,
+		}}
+		service, completed := setupHighlightService(client, chunks)
+
+		if err := service.generateHighlights(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		highlights := <-completed
+		if len(highlights) != 1 || highlights[0].StartPosition != 3 || highlights[0].EndPosition != 12 || highlights[0].SpeakerIndex != 1 {
+			t.Errorf("Unexpected highlights: %+v", highlights)
+		}
+	})
+
+	t.Run("fails when no quote is found", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			HighlightWindowSystemPrompt: `{"highlights": [{"quote": "a sentence nobody said in this episode", "start": "0:00", "score": 10}]}`,
+		}}
+		service, _ := setupHighlightService(client, chunks)
+
+		if err := service.generateHighlights(1); err == nil || !strings.Contains(err.Error(), "no highlights") {
+			t.Errorf("Expected a no highlights error, got %v", err)
+		}
+	})
+}
+
+func TestTranscriptService_GetHighlights(t *testing.T) {
+	t.Run("starts generation for a complete transcript without highlights", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			HighlightWindowSystemPrompt: `{"highlights": [{"quote": "w0 w1 w2 w3 w4", "start": "0:00", "score": 5}]}`,
+		}}
+		chunks := summaryChunks(6)
+		for i := range chunks {
+			chunks[i].SpeakerIndex = 0
+		}
+		service, completed := setupHighlightService(client, chunks)
+
+		highlights, errResp := service.GetHighlights(1)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if highlights.Status != string(TranscriptStatusProcessing) || highlights.TranscriptID != 10 {
+			t.Errorf("Expected a processing placeholder, got %+v", highlights)
+		}
+		select {
+		case <-completed:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the highlights to be generated")
+		}
+	})
+
+	t.Run("fills in the current speaker names", func(t *testing.T) {
+		service, _ := setupHighlightService(&MockLLMClient{}, nil)
+		service.repo.highlightRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeHighlights, error) {
+			return EpisodeHighlights{TranscriptID: 10, Status: string(TranscriptStatusComplete), Highlights: []Highlight{
+				{Text: "Quote", SpeakerIndex: 1},
+				{Text: "Other", SpeakerIndex: 7},
+			}}, nil
+		}
+
+		highlights, errResp := service.GetHighlights(1)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if highlights.Highlights[0].SpeakerName != "Jane Doe" || highlights.Highlights[1].SpeakerName != "Speaker 7" {
+			t.Errorf("Expected speaker names, got %+v", highlights.Highlights)
+		}
+	})
+
+	t.Run("returns not found until the transcript is complete", func(t *testing.T) {
+		service, _ := setupHighlightService(&MockLLMClient{}, nil)
+		service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+			return Transcript{ID: 10, EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+		}
+
+		_, errResp := service.GetHighlights(1)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptHandler_Highlights(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		status     TranscriptStatus
+		wantStatus int
+		wantBody   string
+	}{
+		{"highlights", http.MethodGet, "/episodes/1/highlights", TranscriptStatusComplete, http.StatusOK, `"start_position":4`},
+		{"regenerating", http.MethodGet, "/episodes/1/highlights", TranscriptStatusProcessing, http.StatusAccepted, `"speaker_name":"Jane Doe"`},
+		{"wrong method", http.MethodPost, "/episodes/1/highlights", TranscriptStatusComplete, http.StatusMethodNotAllowed, ""},
+		{"sub-resource", http.MethodGet, "/episodes/1/highlights/2", TranscriptStatusComplete, http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupHighlightService(&MockLLMClient{}, nil)
+			service.repo.highlightRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeHighlights, error) {
+				return EpisodeHighlights{EpisodeID: 1, TranscriptID: 10, Status: string(tt.status), UpdatedAt: time.Now(), Highlights: []Highlight{
+					{Text: "Quote", SpeakerIndex: 1, StartPosition: 4, EndPosition: 9},
+				}}, nil
+			}
+
+			w := httptest.NewRecorder()
+			NewTranscriptHandler(service).HandleRequest(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package transcripts
+
+import (
+	"context"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// StartTranscriptionJob transcribes an episode in the background without a streaming
+// client, e.g. for backfills. Batch mode is used unless the request picks another mode.
+// Only admins may start jobs.
+func (s *Service) StartTranscriptionJob(episodeID, userID int, requested transcription.Preferences) (TranscriptionJob, *errors.ErrorResponse) {
+	isAdmin, err := s.repo.roles.IsAdmin(userID)
+	if err != nil {
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to check user role",
+		}
+	}
+	if !isAdmin {
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.Unauthorized,
+			Details: "Only admins can start transcription jobs",
+		}
+	}
+
+	if requested.Mode == "" {
+		requested.Mode = transcription.ModeBatch
+	}
+
+	s.log.Info("Starting transcription job", map[string]any{
+		"episodeID": episodeID,
+		"mode":      requested.Mode,
+	})
+
+	transcript, err := s.repo.GetTranscriptByEpisodeID(episodeID)
+	if err != nil && err.Error() != "no rows in result set" {
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript",
+		}
+	}
+
+	switch transcript.Status {
+	case string(TranscriptStatusComplete):
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Episode is already transcribed",
+		}
+	case string(TranscriptStatusProcessing):
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Episode transcription is already in progress",
+		}
+	}
+
+	episode, err := s.repo.GetEpisodeByID(episodeID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return TranscriptionJob{}, &errors.ErrorResponse{
+				Message: errors.DatabaseNotFound,
+				Details: "Episode not found",
+			}
+		}
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch episode",
+		}
+	}
+
+	if s.transcriptionClient == nil {
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.InternalServerError,
+			Details: "Transcription provider not configured",
+		}
+	}
+
+	// Claim the transcript before answering, so a concurrent request can't start a second job
+	opts := buildStreamOptions(episode, requested)
+	transcriptID, err := s.repo.CreateTranscript(episodeID, opts)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return TranscriptionJob{}, &errors.ErrorResponse{
+				Message: errors.DatabaseConflict,
+				Details: "Episode transcription is already in progress",
+			}
+		}
+		return TranscriptionJob{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to create transcript",
+		}
+	}
+
+	go func() {
+		err := s.transcribe(context.Background(), transcriptID, episodeID, episode.AudioURL, episode.Description, opts, episode.RedactionPolicy,
+			func(chunk *Chunk) error { return nil },
+			func(speaker *Speaker) error { return nil },
+		)
+		if err != nil {
+			s.log.Error("Transcription job failed", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+			return
+		}
+
+		s.log.Info("Transcription job finished", map[string]any{
+			"episodeID": episodeID,
+		})
+	}()
+
+	return TranscriptionJob{
+		EpisodeID: episodeID,
+		Status:    string(TranscriptStatusProcessing),
+		Mode:      requested.Mode,
+	}, nil
+}
@@ -0,0 +1,189 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/routes/users"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+type recordingTranscriptionClient struct {
+	opts chan transcription.StreamOptions
+}
+
+func (m *recordingTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, opts transcription.StreamOptions, callback transcription.StreamCallback) error {
+	m.opts <- opts
+	return nil
+}
+
+// setupJobService mocks an episode without a transcript and an admin user, and records the
+// options of each job
+func setupJobService() (*Service, chan transcription.StreamOptions) {
+	client := &recordingTranscriptionClient{opts: make(chan transcription.StreamOptions, 1)}
+	service := NewService(NewTranscriptRepository(), client, &MockLLMClient{})
+	setupMockRepos(service, false)
+	service.repo.roles.Executor = utils.QueryExecutor[users.Role]{
+		QueryItem: func(query string, args ...any) (users.Role, error) {
+			return users.Role{IsAdmin: true}, nil
+		},
+	}
+	return service, client.opts
+}
+
+func TestTranscriptService_StartTranscriptionJob(t *testing.T) {
+	t.Run("transcribes in the background using batch mode by default", func(t *testing.T) {
+		service, opts := setupJobService()
+
+		job, errResp := service.StartTranscriptionJob(1, 5, transcription.Preferences{Language: "pt"})
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if job.Mode != transcription.ModeBatch || job.Status != string(TranscriptStatusProcessing) {
+			t.Errorf("Unexpected job: %+v", job)
+		}
+		select {
+		case used := <-opts:
+			if used.Mode != transcription.ModeBatch || used.Language != "pt" {
+				t.Errorf("Unexpected options: %+v", used)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Expected transcription to start")
+		}
+	})
+
+	t.Run("keeps an explicit mode", func(t *testing.T) {
+		service, opts := setupJobService()
+
+		job, _ := service.StartTranscriptionJob(1, 5, transcription.Preferences{Mode: transcription.ModeStreaming})
+
+		if job.Mode != transcription.ModeStreaming {
+			t.Errorf("Expected streaming mode, got %s", job.Mode)
+		}
+		<-opts
+	})
+
+	tests := []struct {
+		name        string
+		status      TranscriptStatus
+		episodeErr  error
+		wantMessage string
+	}{
+		{"already transcribed", TranscriptStatusComplete, nil, cribeErrors.ValidationError},
+		{"already processing", TranscriptStatusProcessing, nil, cribeErrors.ValidationError},
+		{"missing episode", "", fmt.Errorf("no rows in result set"), cribeErrors.DatabaseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupJobService()
+			service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+				if tt.status == "" {
+					return Transcript{}, fmt.Errorf("no rows in result set")
+				}
+				return Transcript{ID: 1, Status: string(tt.status)}, nil
+			}
+			service.repo.episodeRepo.Executor = utils.QueryExecutor[Episode]{
+				QueryItem: func(query string, args ...any) (Episode, error) {
+					return Episode{ID: 1}, tt.episodeErr
+				},
+			}
+
+			_, errResp := service.StartTranscriptionJob(1, 5, transcription.Preferences{})
+
+			if errResp == nil || errResp.Message != tt.wantMessage {
+				t.Errorf("Expected %q error, got %v", tt.wantMessage, errResp)
+			}
+		})
+	}
+}
+
+func TestTranscriptService_StartTranscriptionJob_Forbidden(t *testing.T) {
+	service, opts := setupJobService()
+	service.repo.roles.Executor.QueryItem = func(query string, args ...any) (users.Role, error) {
+		return users.Role{IsAdmin: false}, nil
+	}
+
+	_, errResp := service.StartTranscriptionJob(1, 5, transcription.Preferences{})
+
+	if errResp == nil || errResp.Message != cribeErrors.Unauthorized {
+		t.Errorf("Expected unauthorized, got %v", errResp)
+	}
+	select {
+	case <-opts:
+		t.Error("Expected no transcription to start")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w := httptest.NewRecorder()
+	NewTranscriptHandler(service).HandleRequest(w, httptest.NewRequest(http.MethodPost, "/transcripts/1/transcribe", strings.NewReader(`{}`)))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusForbidden, w.Code, w.Body.String())
+	}
+}
+
+func TestTranscriptService_StartTranscriptionJob_ClaimLost(t *testing.T) {
+	service, opts := setupJobService()
+	service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+		// The status check sees no transcript, but a concurrent request claims it first
+		return Transcript{}, fmt.Errorf("no rows in result set")
+	}
+
+	_, errResp := service.StartTranscriptionJob(1, 5, transcription.Preferences{})
+
+	if errResp == nil || errResp.Message != cribeErrors.DatabaseConflict {
+		t.Errorf("Expected %q error, got %v", cribeErrors.DatabaseConflict, errResp)
+	}
+	select {
+	case <-opts:
+		t.Error("Expected no transcription to start")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	handler := NewTranscriptHandler(service)
+	w := httptest.NewRecorder()
+	handler.HandleRequest(w, httptest.NewRequest(http.MethodPost, "/transcripts/1/transcribe", strings.NewReader(`{}`)))
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected status %d, got %d: %s", http.StatusConflict, w.Code, w.Body.String())
+	}
+}
+
+func TestTranscriptHandler_Transcribe(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{"start job", http.MethodPost, "/transcripts/1/transcribe", `{}`, http.StatusAccepted},
+		{"invalid mode", http.MethodPost, "/transcripts/1/transcribe", `{"mode":"realtime"}`, http.StatusBadRequest},
+		{"invalid body", http.MethodPost, "/transcripts/1/transcribe", `{`, http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "/transcripts/1/transcribe", ``, http.StatusMethodNotAllowed},
+		{"unknown sub-path", http.MethodPost, "/transcripts/1/transcribe/now", `{}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupJobService()
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+
+			handler.HandleRequest(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
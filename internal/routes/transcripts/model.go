@@ -1,6 +1,14 @@
 package transcripts
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/redaction"
+	"cribeapp.com/cribe-server/internal/utils"
+)
 
 // TranscriptStatus represents the valid states of a transcript
 type TranscriptStatus string
@@ -11,6 +19,12 @@ const (
 	TranscriptStatusFailed     TranscriptStatus = "failed"
 )
 
+// DefaultLowConfidenceThreshold flags words the provider is less than 60% sure about
+const DefaultLowConfidenceThreshold = 0.6
+
+// MaxRevisionChunks limits how many chunk positions a single correction may span
+const MaxRevisionChunks = 500
+
 type Transcript struct {
 	ID           int        `json:"id"`
 	EpisodeID    int        `json:"episode_id"`
@@ -18,16 +32,20 @@ type Transcript struct {
 	ErrorMessage *string    `json:"error_message,omitempty"`
 	CreatedAt    time.Time  `json:"created_at"`
 	CompletedAt  *time.Time `json:"completed_at,omitempty"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	// Options used to produce the transcript, kept so results can be reproduced
+	Options *transcription.StreamOptions `json:"options,omitempty"`
 }
 
 type TranscriptChunk struct {
-	ID           int     `json:"id"`
-	TranscriptID int     `json:"transcript_id"`
-	Position     int     `json:"position"`
-	SpeakerIndex int     `json:"speaker_index"`
-	StartTime    float64 `json:"start_time"`
-	EndTime      float64 `json:"end_time"`
-	Text         string  `json:"text"`
+	ID           int      `json:"id"`
+	TranscriptID int      `json:"transcript_id"`
+	Position     int      `json:"position"`
+	SpeakerIndex int      `json:"speaker_index"`
+	StartTime    float64  `json:"start_time"`
+	EndTime      float64  `json:"end_time"`
+	Text         string   `json:"text"`
+	Confidence   *float64 `json:"confidence,omitempty"`
 }
 
 type TranscriptSpeaker struct {
@@ -35,24 +53,510 @@ type TranscriptSpeaker struct {
 	TranscriptID int       `json:"transcript_id"`
 	SpeakerIndex int       `json:"speaker_index"`
 	SpeakerName  string    `json:"speaker_name"`
+	IsHumanSet   bool      `json:"is_human_set"`
 	InferredAt   time.Time `json:"inferred_at"`
 }
 
 type Episode struct {
-	ID          int    `json:"id"`
-	AudioURL    string `json:"audio_url"`
-	Description string `json:"description"`
+	ID                   int                       `json:"id"`
+	AudioURL             string                    `json:"audio_url"`
+	Description          string                    `json:"description"`
+	Name                 string                    `json:"name"`
+	PodcastName          string                    `json:"podcast_name"`
+	TranscriptionOptions transcription.Preferences `json:"transcription_options"`
+	RedactionPolicy      redaction.Policy          `json:"redaction_policy"`
 }
 
 type Chunk struct {
+	Position     int      `json:"position"`
+	SpeakerIndex int      `json:"speaker_index"`
+	Start        float64  `json:"start"`
+	End          float64  `json:"end"`
+	Text         string   `json:"text"`
+	Confidence   *float64 `json:"confidence,omitempty"`
+	// OriginalText is the text before redaction, stored in a column only admins can read
+	OriginalText *string `json:"-"`
+}
+
+type Speaker struct {
+	Index      int    `json:"index"`
+	Name       string `json:"name"`
+	IsHumanSet bool   `json:"is_human_set,omitempty"`
+}
+
+// DTOs for API requests
+
+// RenameSpeakerRequest is the request to manually rename a speaker
+type RenameSpeakerRequest struct {
+	Name string `json:"name" validate:"required,min=1,max=255"`
+}
+
+func (dto RenameSpeakerRequest) Validate() *errors.ErrorResponse {
+	return utils.ValidateStruct(dto)
+}
+
+// MergeSpeakersRequest is the request to merge one diarization index into another
+type MergeSpeakersRequest struct {
+	FromIndex *int `json:"from_index" validate:"required,min=0"`
+	ToIndex   *int `json:"to_index" validate:"required,min=0"`
+}
+
+func (dto MergeSpeakersRequest) Validate() *errors.ErrorResponse {
+	if errResp := utils.ValidateStruct(dto); errResp != nil {
+		return errResp
+	}
+
+	if *dto.FromIndex == *dto.ToIndex {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "from_index and to_index must be different",
+		}
+	}
+
+	return nil
+}
+
+// TranscriptRevision records a correction of the text over a range of chunk positions.
+// The chunk texts are kept per position so a revision can be reverted exactly.
+type TranscriptRevision struct {
+	ID                int        `json:"id"`
+	TranscriptID      int        `json:"transcript_id"`
+	StartPosition     int        `json:"start_position"`
+	EndPosition       int        `json:"end_position"`
+	OldText           string     `json:"old_text"`
+	NewText           string     `json:"new_text"`
+	OldChunkTexts     []string   `json:"-"`
+	NewChunkTexts     []string   `json:"-"`
+	UserID            *int       `json:"user_id,omitempty"`
+	RevertsRevisionID *int       `json:"reverts_revision_id,omitempty"`
+	RevertedAt        *time.Time `json:"reverted_at,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// CreateRevisionRequest is the request to correct the text of a range of chunk positions
+type CreateRevisionRequest struct {
+	StartPosition *int   `json:"start_position" validate:"required,min=0"`
+	EndPosition   *int   `json:"end_position" validate:"required,min=0"`
+	Text          string `json:"text" validate:"required,min=1,max=10000"`
+}
+
+func (dto CreateRevisionRequest) Validate() *errors.ErrorResponse {
+	if errResp := utils.ValidateStruct(dto); errResp != nil {
+		return errResp
+	}
+
+	if *dto.StartPosition > *dto.EndPosition {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "start_position must not be greater than end_position",
+		}
+	}
+
+	if *dto.EndPosition-*dto.StartPosition+1 > MaxRevisionChunks {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: fmt.Sprintf("A revision can span at most %d chunks", MaxRevisionChunks),
+		}
+	}
+
+	return nil
+}
+
+// TranscribeRequest starts a background transcription job; mode defaults to batch
+type TranscribeRequest struct {
+	transcription.Preferences
+}
+
+func (dto TranscribeRequest) Validate() *errors.ErrorResponse {
+	if err := dto.Preferences.Validate(); err != nil {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: err.Error(),
+		}
+	}
+	return nil
+}
+
+// TranscriptionJob describes a transcription started in the background
+type TranscriptionJob struct {
+	EpisodeID int    `json:"episode_id"`
+	Status    string `json:"status"`
+	Mode      string `json:"mode"`
+}
+
+// LowConfidenceSpan groups adjacent low-confidence words of one speaker for review.
+// Its positions can be sent as-is to the revisions endpoint.
+type LowConfidenceSpan struct {
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	SpeakerIndex  int     `json:"speaker_index"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Text          string  `json:"text"`
+	MinConfidence float64 `json:"min_confidence"`
+	AvgConfidence float64 `json:"avg_confidence"`
+}
+
+// LowConfidenceReport lists the spans of a transcript below the confidence threshold
+type LowConfidenceReport struct {
+	EpisodeID int                 `json:"episode_id"`
+	Threshold float64             `json:"threshold"`
+	Spans     []LowConfidenceSpan `json:"spans"`
+}
+
+// TranscriptExport is the full transcript of an episode in a single document
+type TranscriptExport struct {
+	EpisodeID  int        `json:"episode_id"`
+	Transcript Transcript `json:"transcript"`
+	Speakers   []Speaker  `json:"speakers"`
+	Chunks     []Chunk    `json:"chunks"`
+	// Language and TranslationStatus are set when a translation was requested; each
+	// chunk is then a translated speaker turn
+	Language          string               `json:"language,omitempty"`
+	TranslationStatus string               `json:"translation_status,omitempty"`
+	Analytics         *TranscriptAnalytics `json:"analytics,omitempty"`
+}
+
+// SummarySection summarizes one part of an episode, starting and ending at audio times
+type SummarySection struct {
+	Title   string  `json:"title"`
+	Summary string  `json:"summary"`
+	Start   float64 `json:"start"`
+	End     float64 `json:"end"`
+}
+
+// EpisodeSummary is the multi-level LLM summary of an episode transcript.
+// Status follows the transcript states; while processing, the previous content is kept.
+type EpisodeSummary struct {
+	ID           int              `json:"id"`
+	EpisodeID    int              `json:"episode_id"`
+	TranscriptID int              `json:"transcript_id"`
+	Status       string           `json:"status"`
+	TLDR         string           `json:"tldr"`
+	KeyPoints    []string         `json:"key_points"`
+	Sections     []SummarySection `json:"sections"`
+	ErrorMessage *string          `json:"error_message,omitempty"`
+	CreatedAt    time.Time        `json:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at"`
+}
+
+// Chapter is a titled part of an episode. Start is the start time of the chunk at
+// StartPosition; End is the start of the next chapter, or the end of the audio.
+type Chapter struct {
+	Title         string  `json:"title"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+}
+
+// EpisodeChapters holds the LLM chapters of an episode transcript
+type EpisodeChapters struct {
+	ID           int       `json:"id"`
+	EpisodeID    int       `json:"episode_id"`
+	TranscriptID int       `json:"transcript_id"`
+	Status       string    `json:"status"`
+	Chapters     []Chapter `json:"chapters"`
+	ErrorMessage *string   `json:"error_message,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// PodcastChaptersVersion is the Podcasting 2.0 JSON chapters format version produced
+const PodcastChaptersVersion = "1.2.0"
+
+// PodcastChapters is the Podcasting 2.0 JSON chapters document (application/json+chapters)
+type PodcastChapters struct {
+	Version  string           `json:"version"`
+	Chapters []PodcastChapter `json:"chapters"`
+}
+
+type PodcastChapter struct {
+	StartTime float64 `json:"startTime"`
+	EndTime   float64 `json:"endTime,omitempty"`
+	Title     string  `json:"title"`
+}
+
+// PodcastChapters converts the chapters to the Podcasting 2.0 JSON chapters format
+func (c EpisodeChapters) PodcastChapters() PodcastChapters {
+	chapters := make([]PodcastChapter, len(c.Chapters))
+	for i, chapter := range c.Chapters {
+		chapters[i] = PodcastChapter{StartTime: chapter.Start, EndTime: chapter.End, Title: chapter.Title}
+	}
+	return PodcastChapters{Version: PodcastChaptersVersion, Chapters: chapters}
+}
+
+// Highlight is a quotable passage of an episode. Text is the transcript text of the chunks
+// from StartPosition to EndPosition, all spoken by one speaker.
+type Highlight struct {
+	Text          string  `json:"text"`
+	SpeakerIndex  int     `json:"speaker_index"`
+	SpeakerName   string  `json:"speaker_name,omitempty"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	Reason        string  `json:"reason,omitempty"`
+}
+
+// EpisodeHighlights holds the LLM highlights of an episode transcript, in transcript order
+type EpisodeHighlights struct {
+	ID           int         `json:"id"`
+	EpisodeID    int         `json:"episode_id"`
+	TranscriptID int         `json:"transcript_id"`
+	Status       string      `json:"status"`
+	Highlights   []Highlight `json:"highlights"`
+	ErrorMessage *string     `json:"error_message,omitempty"`
+	CreatedAt    time.Time   `json:"created_at"`
+	UpdatedAt    time.Time   `json:"updated_at"`
+}
+
+// AskEpisodeRequest asks a question about an episode, continuing a conversation when
+// conversation_id is set
+type AskEpisodeRequest struct {
+	ConversationID *int   `json:"conversation_id" validate:"omitempty,min=1"`
+	Message        string `json:"message" validate:"required,min=1,max=2000"`
+}
+
+func (dto AskEpisodeRequest) Validate() *errors.ErrorResponse {
+	return utils.ValidateStruct(dto)
+}
+
+// Citation points to the transcript passage an answer refers to with [Ref]
+type Citation struct {
+	Ref           int     `json:"ref"`
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Text          string  `json:"text"`
+}
+
+type Conversation struct {
+	ID        int                   `json:"id"`
+	EpisodeID int                   `json:"episode_id"`
+	UserID    int                   `json:"user_id"`
+	CreatedAt time.Time             `json:"created_at"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Messages  []ConversationMessage `json:"messages,omitempty"`
+}
+
+type ConversationMessage struct {
+	ID             int        `json:"id"`
+	ConversationID int        `json:"conversation_id"`
+	Role           string     `json:"role"`
+	Content        string     `json:"content"`
+	Citations      []Citation `json:"citations"`
+	CreatedAt      time.Time  `json:"created_at"`
+}
+
+// EpisodeAnswer is the assistant reply to a question about an episode
+type EpisodeAnswer struct {
+	ConversationID int                 `json:"conversation_id"`
+	Message        ConversationMessage `json:"message"`
+}
+
+// TranscriptEmbedding is the embedding of one overlapping passage of a transcript
+type TranscriptEmbedding struct {
+	ID            int       `json:"id"`
+	TranscriptID  int       `json:"transcript_id"`
+	EpisodeID     int       `json:"episode_id"`
+	StartPosition int       `json:"start_position"`
+	EndPosition   int       `json:"end_position"`
+	StartTime     float64   `json:"start_time"`
+	EndTime       float64   `json:"end_time"`
+	Text          string    `json:"text"`
+	Model         string    `json:"model"`
+	Embedding     []float32 `json:"-"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// SearchPassage is a transcript passage matching a semantic search
+type SearchPassage struct {
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Text          string  `json:"text"`
+	Score         float64 `json:"score"`
+}
+
+// EpisodeSearchResult is an episode matching a semantic search with its best passages.
+// The episode score is the score of its best passage.
+type EpisodeSearchResult struct {
+	EpisodeID   int             `json:"episode_id"`
+	EpisodeName string          `json:"episode_name"`
+	PodcastName string          `json:"podcast_name"`
+	Score       float64         `json:"score"`
+	Passages    []SearchPassage `json:"passages"`
+}
+
+// SemanticSearchResult lists the matching episodes, most similar first
+type SemanticSearchResult struct {
+	Query    string                `json:"query"`
+	Episodes []EpisodeSearchResult `json:"episodes"`
+}
+
+// TranslatedSegment is one translated speaker turn, keeping the positions and times of
+// the chunks it covers
+type TranslatedSegment struct {
+	SpeakerIndex  int     `json:"speaker_index"`
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Text          string  `json:"text"`
+}
+
+// Chunk returns the segment in the shape of a streamed transcript chunk
+func (s TranslatedSegment) Chunk() Chunk {
+	return Chunk{
+		Position:     s.StartPosition,
+		SpeakerIndex: s.SpeakerIndex,
+		Start:        s.Start,
+		End:          s.End,
+		Text:         s.Text,
+	}
+}
+
+// TranscriptTranslation is the LLM translation of a transcript into one language.
+// Like summaries, the previous segments are kept while it is regenerated.
+type TranscriptTranslation struct {
+	ID           int                 `json:"id"`
+	TranscriptID int                 `json:"transcript_id"`
+	Language     string              `json:"language"`
+	Status       string              `json:"status"`
+	Segments     []TranslatedSegment `json:"segments"`
+	ErrorMessage *string             `json:"error_message,omitempty"`
+	CreatedAt    time.Time           `json:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at"`
+}
+
+// Monologue is an uninterrupted turn of one speaker
+type Monologue struct {
+	StartPosition int     `json:"start_position"`
+	EndPosition   int     `json:"end_position"`
+	Start         float64 `json:"start"`
+	End           float64 `json:"end"`
+	Duration      float64 `json:"duration"`
+	Words         int     `json:"words"`
+}
+
+// SpeakerAnalytics are the speaking statistics of one speaker. Times are in seconds.
+type SpeakerAnalytics struct {
+	SpeakerIndex     int       `json:"speaker_index"`
+	Name             string    `json:"name"`
+	TalkTime         float64   `json:"talk_time"`
+	TalkShare        float64   `json:"talk_share"`
+	Words            int       `json:"words"`
+	Turns            int       `json:"turns"`
+	WordsPerMinute   float64   `json:"words_per_minute"`
+	LongestMonologue Monologue `json:"longest_monologue"`
+	// Interruptions counts the turns this speaker started before the previous speaker finished
+	Interruptions int `json:"interruptions"`
+}
+
+// TranscriptAnalytics are the speaking statistics of a transcript, computed from the
+// chunk timings
+type TranscriptAnalytics struct {
+	TranscriptID   int                `json:"transcript_id"`
+	Duration       float64            `json:"duration"`
+	Words          int                `json:"words"`
+	WordsPerMinute float64            `json:"words_per_minute"`
+	Turns          int                `json:"turns"`
+	Interruptions  int                `json:"interruptions"`
+	Speakers       []SpeakerAnalytics `json:"speakers"`
+	ComputedAt     time.Time          `json:"computed_at"`
+}
+
+// RedactedChunk is a chunk masked by redaction along with its text before masking
+type RedactedChunk struct {
 	Position     int     `json:"position"`
 	SpeakerIndex int     `json:"speaker_index"`
-	Start        float64 `json:"start"`
-	End          float64 `json:"end"`
+	StartTime    float64 `json:"start_time"`
+	EndTime      float64 `json:"end_time"`
 	Text         string  `json:"text"`
+	OriginalText string  `json:"original_text"`
 }
 
-type Speaker struct {
-	Index int    `json:"index"`
-	Name  string `json:"name"`
+// DefaultAnnotationColor is used when an annotation is created without a color
+const DefaultAnnotationColor = "yellow"
+
+// Annotation is a user's highlight, with an optional note, on a range of an episode
+// transcript. The positions and Text are resolved from the start and end times against
+// the current chunks, so annotations follow corrections and new transcriptions.
+type Annotation struct {
+	ID            int       `json:"id"`
+	UserID        int       `json:"user_id"`
+	EpisodeID     int       `json:"episode_id"`
+	EpisodeName   string    `json:"episode_name"`
+	StartPosition int       `json:"start_position"`
+	EndPosition   int       `json:"end_position"`
+	StartTime     float64   `json:"start_time"`
+	EndTime       float64   `json:"end_time"`
+	Color         string    `json:"color"`
+	Note          string    `json:"note"`
+	Text          string    `json:"text"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// CreateAnnotationRequest highlights a range of chunk positions; color defaults to yellow
+type CreateAnnotationRequest struct {
+	StartPosition *int   `json:"start_position" validate:"required,min=0"`
+	EndPosition   *int   `json:"end_position" validate:"required,min=0"`
+	Color         string `json:"color" validate:"omitempty,oneof=yellow green blue pink purple"`
+	Note          string `json:"note" validate:"max=5000"`
+}
+
+func (dto CreateAnnotationRequest) Validate() *errors.ErrorResponse {
+	if errResp := utils.ValidateStruct(dto); errResp != nil {
+		return errResp
+	}
+
+	if *dto.StartPosition > *dto.EndPosition {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "start_position must not be greater than end_position",
+		}
+	}
+
+	return nil
+}
+
+// UpdateAnnotationRequest changes the fields that are set; a range needs both positions
+type UpdateAnnotationRequest struct {
+	StartPosition *int    `json:"start_position" validate:"omitempty,min=0"`
+	EndPosition   *int    `json:"end_position" validate:"omitempty,min=0"`
+	Color         *string `json:"color" validate:"omitempty,oneof=yellow green blue pink purple"`
+	Note          *string `json:"note" validate:"omitempty,max=5000"`
+}
+
+func (dto UpdateAnnotationRequest) Validate() *errors.ErrorResponse {
+	if errResp := utils.ValidateStruct(dto); errResp != nil {
+		return errResp
+	}
+
+	if (dto.StartPosition == nil) != (dto.EndPosition == nil) {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "start_position and end_position must be set together",
+		}
+	}
+
+	if dto.StartPosition != nil && *dto.StartPosition > *dto.EndPosition {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "start_position must not be greater than end_position",
+		}
+	}
+
+	if dto.StartPosition == nil && dto.Color == nil && dto.Note == nil {
+		return &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Nothing to update",
+		}
+	}
+
+	return nil
 }
@@ -0,0 +1,45 @@
+package transcripts
+
+import (
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+)
+
+// MaxSeededKeyterms caps the vocabulary sent to providers, which limit prompt size
+const MaxSeededKeyterms = 25
+
+// buildStreamOptions layers the podcast preferences and then the request preferences
+// onto the defaults, and seeds keyterms with the podcast and episode names
+func buildStreamOptions(episode Episode, requested transcription.Preferences) transcription.StreamOptions {
+	opts := transcription.DefaultStreamOptions()
+	opts = episode.TranscriptionOptions.Apply(opts)
+	opts = requested.Apply(opts)
+
+	opts.Keyterms = seedKeyterms(append([]string{episode.PodcastName, episode.Name}, opts.Keyterms...))
+
+	return opts
+}
+
+// seedKeyterms trims, drops blank or oversized terms and removes case-insensitive
+// duplicates, keeping the first MaxSeededKeyterms terms in order
+func seedKeyterms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	keyterms := make([]string, 0, min(len(terms), MaxSeededKeyterms))
+
+	for _, term := range terms {
+		term = strings.Join(strings.Fields(term), " ")
+		key := strings.ToLower(term)
+		if term == "" || len(term) > transcription.MaxKeytermLength || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		keyterms = append(keyterms, term)
+		if len(keyterms) == MaxSeededKeyterms {
+			break
+		}
+	}
+
+	return keyterms
+}
@@ -0,0 +1,67 @@
+package transcripts
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+)
+
+func TestBuildStreamOptions(t *testing.T) {
+	smartFormat := false
+	episode := Episode{
+		Name:        "Episode 12: Scaling Go",
+		PodcastName: "Cribe Talks",
+		TranscriptionOptions: transcription.Preferences{
+			Language:    "pt",
+			Model:       "nova-2",
+			Keyterms:    []string{"goroutine", "cribe talks"},
+			SmartFormat: &smartFormat,
+		},
+	}
+
+	t.Run("applies podcast preferences and seeds keyterms", func(t *testing.T) {
+		opts := buildStreamOptions(episode, transcription.Preferences{})
+
+		if opts.Language != "pt" || opts.Model != "nova-2" || opts.SmartFormat || !opts.Diarize {
+			t.Errorf("Unexpected options: %+v", opts)
+		}
+		want := []string{"Cribe Talks", "Episode 12: Scaling Go", "goroutine"}
+		if !slices.Equal(opts.Keyterms, want) {
+			t.Errorf("Expected keyterms %v, got %v", want, opts.Keyterms)
+		}
+	})
+
+	t.Run("request preferences override the podcast", func(t *testing.T) {
+		opts := buildStreamOptions(episode, transcription.Preferences{Language: transcription.LanguageAuto, Keyterms: []string{"pgx"}})
+
+		if !opts.IsLanguageDetection() || opts.Model != "nova-2" {
+			t.Errorf("Unexpected options: %+v", opts)
+		}
+		if opts.Keyterms[len(opts.Keyterms)-1] != "pgx" {
+			t.Errorf("Expected request keyterm to be kept, got %v", opts.Keyterms)
+		}
+	})
+
+	t.Run("defaults without preferences", func(t *testing.T) {
+		opts := buildStreamOptions(Episode{}, transcription.Preferences{})
+
+		if opts.Language != "en" || opts.Model != "" || !opts.SmartFormat || len(opts.Keyterms) != 0 {
+			t.Errorf("Unexpected default options: %+v", opts)
+		}
+	})
+}
+
+func TestSeedKeyterms(t *testing.T) {
+	terms := []string{"  The   Show ", "", strings.Repeat("x", transcription.MaxKeytermLength+1), "the show"}
+	for i := range MaxSeededKeyterms + 5 {
+		terms = append(terms, strings.Repeat("k", i+1))
+	}
+
+	keyterms := seedKeyterms(terms)
+
+	if keyterms[0] != "The Show" || len(keyterms) != MaxSeededKeyterms {
+		t.Errorf("Unexpected keyterms (%d): %v", len(keyterms), keyterms)
+	}
+}
@@ -1,6 +1,9 @@
 package transcripts
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 // InferSpeakerNameSystemPrompt is the system prompt for speaker name inference
 var InferSpeakerNameSystemPrompt = "You are an expert at identifying speakers in podcast transcripts. Look for explicit name mentions in the text (e.g., 'this is John', 'I'm Sarah', 'talking with Mike'). Return ONLY the person's name."
@@ -22,3 +25,209 @@ Instructions:
 Who is speaker %d? Return only their full name (e.g., "John Smith"). If uncertain, return "Speaker %d".`,
 		episodeDescription, speakerIndex, chunksText, speakerIndex, speakerIndex)
 }
+
+// SummarizeSectionSystemPrompt is the system prompt for summarizing one window of a transcript
+var SummarizeSectionSystemPrompt = `You are an expert at summarizing podcast episodes. You receive one part of a transcript where every line starts with a [timestamp] and the speaker name. Split it into the topics discussed, in order.
+
+Rules:
+- Create 1 to 4 sections per part; a section covers one topic
+- "start" must be copied from the [timestamp] of the line where the topic begins
+- Summaries are 1-3 sentences, written in the language of the transcript
+- Key points are concrete facts, claims or advice, not topics
+- Use speaker names when they are known
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "sections": [
+    {"title": "Short topic title", "start": "12:34", "summary": "What was said about it."}
+  ],
+  "key_points": ["A concrete takeaway"]
+}`
+
+// SummarizeSectionUserPrompt generates the user prompt for summarizing one window of a transcript
+func SummarizeSectionUserPrompt(episodeName string, part, totalParts int, transcriptText string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Transcript part %d of %d:
+%s`, episodeName, part, totalParts, transcriptText)
+}
+
+// SummarizeEpisodeSystemPrompt is the system prompt for combining section summaries into an episode summary
+var SummarizeEpisodeSystemPrompt = `You are an expert at summarizing podcast episodes. You receive the episode description and the summaries of its sections in order. Combine them into an overall summary.
+
+Rules:
+- "tldr" is 2-3 sentences capturing what the episode is about and its main conclusion
+- "key_points" lists the 3 to 7 most important takeaways of the whole episode, most important first
+- Write in the language of the section summaries
+- Do not invent information that is not in the sections
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "tldr": "Overall summary.",
+  "key_points": ["Most important takeaway"]
+}`
+
+// SummarizeEpisodeUserPrompt generates the user prompt for combining section summaries
+func SummarizeEpisodeUserPrompt(episodeName, episodeDescription, sectionsText string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Episode description:
+%s
+
+Section summaries:
+%s`, episodeName, episodeDescription, sectionsText)
+}
+
+// ChapterizeWindowSystemPrompt is the system prompt for splitting one window of a transcript into chapters
+var ChapterizeWindowSystemPrompt = `You are an expert at structuring podcast episodes into chapters for listeners. You receive one part of a transcript where every line starts with a [timestamp] and the speaker name. Split it into chapters, in order.
+
+Rules:
+- Create 1 to 5 chapters per part; a chapter covers one topic or segment and usually lasts several minutes
+- "start" must be copied from the [timestamp] of the line where the chapter begins
+- Titles are short (2-6 words), specific to the content and written in the language of the transcript
+- Do not create a chapter for a single remark
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "chapters": [
+    {"title": "Short chapter title", "start": "12:34"}
+  ]
+}`
+
+// ChapterizeWindowUserPrompt generates the user prompt for splitting one window of a transcript into chapters
+func ChapterizeWindowUserPrompt(episodeName string, part, totalParts int, transcriptText string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Transcript part %d of %d:
+%s`, episodeName, part, totalParts, transcriptText)
+}
+
+// MergeChaptersSystemPrompt is the system prompt for merging the chapters of all windows into the episode chapters
+var MergeChaptersSystemPrompt = `You are an expert at structuring podcast episodes into chapters for listeners. You receive the candidate chapters of an episode in order, found separately in consecutive parts of the transcript, so a topic may have been split where the parts meet.
+
+Rules:
+- Merge adjacent candidates that continue the same topic, keeping the earliest start
+- Aim for one chapter every 5 to 15 minutes of audio
+- "start" must be copied from one of the candidate [timestamp]s
+- You may reword titles to be short (2-6 words) and specific; keep the language of the candidates
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "chapters": [
+    {"title": "Short chapter title", "start": "12:34"}
+  ]
+}`
+
+// MergeChaptersUserPrompt generates the user prompt for merging candidate chapters
+func MergeChaptersUserPrompt(episodeName, episodeDescription, candidatesText string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Episode description:
+%s
+
+Candidate chapters:
+%s`, episodeName, episodeDescription, candidatesText)
+}
+
+// HighlightWindowSystemPrompt is the system prompt for picking the quotable passages of one window of a transcript
+var HighlightWindowSystemPrompt = `You are an editor picking quotes from a podcast episode for social media posts and study notes. You receive one part of a transcript where every line starts with a [timestamp] and the speaker name. Pick the most quotable passages.
+
+Rules:
+- Pick 0 to 3 passages; only pick passages that are insightful, memorable or funny on their own, without the surrounding conversation
+- "quote" must be copied word for word from the transcript, without the [timestamp] or speaker name, and must be spoken by a single speaker
+- A quote is one to three sentences, between 8 and 60 words
+- "start" must be copied from the [timestamp] of the line where the quote begins
+- "score" rates how quotable the passage is, from 1 to 10
+- "reason" explains in one short sentence, in the language of the transcript, why the passage stands out
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "highlights": [
+    {"quote": "Exact words from the transcript", "start": "12:34", "score": 8, "reason": "Why it stands out"}
+  ]
+}`
+
+// HighlightWindowUserPrompt generates the user prompt for picking the quotable passages of one window of a transcript
+func HighlightWindowUserPrompt(episodeName string, part, totalParts int, transcriptText string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Transcript part %d of %d:
+%s`, episodeName, part, totalParts, transcriptText)
+}
+
+// AskEpisodeSystemPrompt is the system prompt for answering questions about an episode
+var AskEpisodeSystemPrompt = `You are a helpful study assistant for a podcast episode. You answer the learner's questions using only the numbered transcript excerpts provided with each question.
+
+Rules:
+- Base every statement on the excerpts; if they don't contain the answer, say so briefly instead of guessing
+- Cite the excerpts you use with their number in brackets right after the statement, e.g. "She moved to Lisbon in 2019 [2]." Cite several as [1][3]
+- Refer to speakers by name when known
+- Answer in the language of the question, in a few short paragraphs at most
+- Earlier answers in the conversation may refer to excerpts that are not repeated; do not cite numbers that are not in the current excerpts`
+
+// AskEpisodeUserPrompt generates the user prompt carrying the retrieved excerpts and the question
+func AskEpisodeUserPrompt(episodeName, episodeDescription, excerptsText, question string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Episode description:
+%s
+
+Transcript excerpts:
+%s
+
+Question: %s`, episodeName, episodeDescription, excerptsText, question)
+}
+
+// TranslateSegmentsSystemPrompt is the system prompt for translating a batch of transcript speaker turns
+var TranslateSegmentsSystemPrompt = `You are an expert translator of podcast transcripts. You receive consecutive speaker turns of a transcript as JSON, each with an id. Translate every turn into the requested language.
+
+Rules:
+- Translate each turn on its own, keeping its id; never merge, split, drop or reorder turns
+- Keep the spoken, conversational register; do not summarize or add explanations
+- Keep names of people, shows and brands as they are
+- The text comes from speech recognition and may contain filler words or small errors; translate the intended meaning
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "segments": [
+    {"id": 0, "text": "Translated turn"}
+  ]
+}`
+
+// TranslateSegmentsUserPrompt generates the user prompt for translating a batch of speaker turns
+func TranslateSegmentsUserPrompt(episodeName, language, segmentsJSON string) string {
+	return fmt.Sprintf(`Episode: %s
+
+Target language: %s
+
+Speaker turns:
+%s`, episodeName, language, segmentsJSON)
+}
+
+// RedactionSystemPrompt is the system prompt for finding what the redaction patterns missed
+var RedactionSystemPrompt = `You review podcast transcripts before they are shown to students. Find every phrase in the transcript that belongs to one of the requested categories.
+
+Categories:
+- "pii": personal contact details and identifiers of private people, such as phone numbers, email addresses, street addresses, and ID, account or card numbers, including ones spelled out in words
+- "profanity": profanity, slurs and sexually explicit words
+
+Rules:
+- Only report the requested categories
+- Copy each phrase exactly as it appears in the transcript, as short as possible
+- Do not report names of the hosts, guests, public figures, companies or places
+- Text already masked like [email], [phone] or s*** does not need to be reported
+
+Return ONLY a valid JSON object with this exact structure:
+{
+  "phrases": [
+    {"text": "exact phrase", "category": "pii"}
+  ]
+}`
+
+// RedactionUserPrompt generates the user prompt for finding phrases to redact in a transcript window
+func RedactionUserPrompt(categories []string, transcript string) string {
+	return fmt.Sprintf(`Requested categories: %s
+
+Transcript:
+%s`, strings.Join(categories, ", "), transcript)
+}
@@ -0,0 +1,103 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+const (
+	// Defaults are generous because long episodes are transcribed at playback speed
+	DefaultStaleTranscriptAfterMinutes = 360
+	DefaultReaperIntervalMinutes       = 15
+	// TranscriptHeartbeatInterval is how often a running transcription touches its transcript,
+	// well under the stale threshold so live transcriptions are never reaped
+	TranscriptHeartbeatInterval = time.Minute
+)
+
+// StaleTranscriptReaper periodically fails transcripts that were left in the
+// processing state (e.g. after a restart lost the background save goroutine)
+// and removes their partial chunks and speakers so the episode can be transcribed again
+type StaleTranscriptReaper struct {
+	repo       *TranscriptRepository
+	staleAfter time.Duration
+	interval   time.Duration
+	log        *logger.ContextualLogger
+}
+
+// NewStaleTranscriptReaper creates a reaper on the repository, configured from the environment
+func NewStaleTranscriptReaper(repo *TranscriptRepository) *StaleTranscriptReaper {
+	return &StaleTranscriptReaper{
+		repo:       repo,
+		staleAfter: time.Duration(utils.GetEnvInt("TRANSCRIPT_STALE_AFTER_MINUTES", DefaultStaleTranscriptAfterMinutes)) * time.Minute,
+		interval:   time.Duration(utils.GetEnvInt("TRANSCRIPT_REAPER_INTERVAL_MINUTES", DefaultReaperIntervalMinutes)) * time.Minute,
+		log:        logger.NewServiceLogger("StaleTranscriptReaper"),
+	}
+}
+
+// Start runs the reaper immediately and then on every interval until ctx is cancelled
+func (r *StaleTranscriptReaper) Start(ctx context.Context) {
+	r.log.Info("Starting stale transcript reaper", map[string]any{
+		"staleAfter": r.staleAfter.String(),
+		"interval":   r.interval.String(),
+	})
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	r.ReapStaleTranscripts()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.log.Info("Stopping stale transcript reaper", nil)
+			return
+		case <-ticker.C:
+			r.ReapStaleTranscripts()
+		}
+	}
+}
+
+// ReapStaleTranscripts fails stuck transcripts and cleans up their partial chunks and speakers,
+// returning how many transcripts were reaped
+func (r *StaleTranscriptReaper) ReapStaleTranscripts() int {
+	errorMessage := fmt.Sprintf("Transcription did not finish within %s", r.staleAfter)
+
+	reaped, err := r.repo.FailStaleTranscripts(r.staleAfter, errorMessage)
+	if err != nil {
+		r.log.Error("Failed to reap stale transcripts", map[string]any{
+			"error": err.Error(),
+		})
+		return 0
+	}
+
+	if len(reaped) == 0 {
+		r.log.Debug("No stale transcripts found", nil)
+		return 0
+	}
+
+	transcriptIDs := make([]int, 0, len(reaped))
+	cleanupFailures := 0
+	for _, transcript := range reaped {
+		transcriptIDs = append(transcriptIDs, transcript.ID)
+
+		if err := r.repo.DeletePartialTranscript(transcript.ID); err != nil {
+			cleanupFailures++
+			r.log.Error("Failed to delete partial content of stale transcript", map[string]any{
+				"transcriptID": transcript.ID,
+				"error":        err.Error(),
+			})
+		}
+	}
+
+	r.log.Warn("Reaped stale transcripts", map[string]any{
+		"count":           len(reaped),
+		"transcriptIDs":   transcriptIDs,
+		"cleanupFailures": cleanupFailures,
+	})
+
+	return len(reaped)
+}
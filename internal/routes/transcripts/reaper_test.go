@@ -0,0 +1,130 @@
+package transcripts
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+func setupReaper(stale []Transcript, failErr error, deleteErr error) (*StaleTranscriptReaper, *[]int, *[]int) {
+	deleted := []int{}
+	deletedSpeakers := []int{}
+
+	repo := NewTranscriptRepository()
+	repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+		QueryList: func(query string, args ...any) ([]Transcript, error) {
+			return stale, failErr
+		},
+	}
+	repo.chunkRepo.Executor = utils.QueryExecutor[TranscriptChunk]{
+		Exec: func(query string, args ...any) error {
+			if deleteErr != nil {
+				return deleteErr
+			}
+			deleted = append(deleted, args[0].(int))
+			return nil
+		},
+	}
+	repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
+		Exec: func(query string, args ...any) error {
+			deletedSpeakers = append(deletedSpeakers, args[0].(int))
+			return nil
+		},
+	}
+
+	return &StaleTranscriptReaper{
+		repo:       repo,
+		staleAfter: time.Hour,
+		interval:   time.Hour,
+		log:        logger.NewServiceLogger("TestStaleTranscriptReaper"),
+	}, &deleted, &deletedSpeakers
+}
+
+func TestStaleTranscriptReaper_ReapStaleTranscripts(t *testing.T) {
+	t.Run("fails stale transcripts and deletes their chunks and speakers", func(t *testing.T) {
+		reaper, deleted, deletedSpeakers := setupReaper([]Transcript{{ID: 3}, {ID: 7}}, nil, nil)
+
+		count := reaper.ReapStaleTranscripts()
+
+		if count != 2 {
+			t.Errorf("Expected 2 reaped transcripts, got %d", count)
+		}
+		if len(*deleted) != 2 || (*deleted)[0] != 3 || (*deleted)[1] != 7 {
+			t.Errorf("Expected chunks deleted for transcripts [3 7], got %v", *deleted)
+		}
+		if len(*deletedSpeakers) != 2 || (*deletedSpeakers)[0] != 3 || (*deletedSpeakers)[1] != 7 {
+			t.Errorf("Expected speakers deleted for transcripts [3 7], got %v", *deletedSpeakers)
+		}
+	})
+
+	t.Run("does nothing when there are no stale transcripts", func(t *testing.T) {
+		reaper, deleted, _ := setupReaper([]Transcript{}, nil, nil)
+
+		if count := reaper.ReapStaleTranscripts(); count != 0 {
+			t.Errorf("Expected 0 reaped transcripts, got %d", count)
+		}
+		if len(*deleted) != 0 {
+			t.Errorf("Expected no chunk deletions, got %v", *deleted)
+		}
+	})
+
+	t.Run("returns zero when the query fails", func(t *testing.T) {
+		reaper, _, _ := setupReaper(nil, errors.New("database error"), nil)
+
+		if count := reaper.ReapStaleTranscripts(); count != 0 {
+			t.Errorf("Expected 0 reaped transcripts, got %d", count)
+		}
+	})
+
+	t.Run("still counts transcripts whose chunk cleanup fails", func(t *testing.T) {
+		reaper, _, _ := setupReaper([]Transcript{{ID: 1}}, nil, errors.New("delete failed"))
+
+		if count := reaper.ReapStaleTranscripts(); count != 1 {
+			t.Errorf("Expected 1 reaped transcript, got %d", count)
+		}
+	})
+}
+
+func TestStaleTranscriptReaper_Start(t *testing.T) {
+	t.Run("runs once on start and stops when context is cancelled", func(t *testing.T) {
+		reaper, deleted, _ := setupReaper([]Transcript{{ID: 1}}, nil, nil)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			reaper.Start(ctx)
+			close(done)
+		}()
+
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("Reaper did not stop after context cancellation")
+		}
+
+		if len(*deleted) != 1 {
+			t.Errorf("Expected one reap on start, got %d chunk deletions", len(*deleted))
+		}
+	})
+}
+
+func TestNewStaleTranscriptReaper(t *testing.T) {
+	t.Setenv("TRANSCRIPT_STALE_AFTER_MINUTES", "90")
+	t.Setenv("TRANSCRIPT_REAPER_INTERVAL_MINUTES", "")
+
+	reaper := NewStaleTranscriptReaper(NewTranscriptRepository())
+
+	if reaper.staleAfter != 90*time.Minute {
+		t.Errorf("Expected staleAfter 90m, got %s", reaper.staleAfter)
+	}
+	if reaper.interval != DefaultReaperIntervalMinutes*time.Minute {
+		t.Errorf("Expected default interval, got %s", reaper.interval)
+	}
+}
@@ -0,0 +1,159 @@
+package transcripts
+
+import (
+	"fmt"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/redaction"
+)
+
+// redactionWindowWords keeps each LLM redaction request, and the phrases it returns, small
+const redactionWindowWords = 1500
+
+// llmRedaction is the LLM response listing the phrases to redact in a transcript window
+type llmRedaction struct {
+	Phrases []redaction.Phrase `json:"phrases"`
+}
+
+// GetRedactions returns the redacted chunks of an episode transcript with their original
+// text. Only admins may read it.
+func (s *Service) GetRedactions(episodeID, userID int) ([]RedactedChunk, *errors.ErrorResponse) {
+	isAdmin, err := s.repo.roles.IsAdmin(userID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to check user role",
+		}
+	}
+	if !isAdmin {
+		return nil, &errors.ErrorResponse{
+			Message: errors.Unauthorized,
+			Details: "Only admins can view redacted text",
+		}
+	}
+
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	chunks, err := s.repo.GetRedactedChunks(transcript.ID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch redacted chunks",
+		}
+	}
+	if chunks == nil {
+		chunks = []RedactedChunk{}
+	}
+
+	return chunks, nil
+}
+
+// redactTranscript redacts the finished transcript before it is saved. The patterns run
+// again over the whole transcript to catch matches split across streaming responses, and
+// the LLM pass runs when the policy asks for it. A failed LLM pass keeps the pattern
+// redactions, so the transcript is still saved.
+func (s *Service) redactTranscript(transcriptID int, chunks []Chunk, policy redaction.Policy) {
+	if !policy.Enabled() {
+		return
+	}
+
+	redactChunks(chunks, redaction.New(policy).Redact)
+
+	if !policy.LLM || !s.llmAvailable() {
+		return
+	}
+
+	phrases, err := s.findRedactionPhrases(chunks, policy)
+	if err != nil {
+		s.log.Error("LLM redaction failed, keeping pattern redactions", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return
+	}
+
+	redactChunks(chunks, func(words []string) []string {
+		return redaction.RedactPhrases(words, phrases)
+	})
+
+	s.log.Info("Transcript redacted", map[string]any{
+		"transcriptID": transcriptID,
+		"llmPhrases":   len(phrases),
+	})
+}
+
+// findRedactionPhrases asks the LLM for the phrases of the requested categories in each
+// transcript window
+func (s *Service) findRedactionPhrases(chunks []Chunk, policy redaction.Policy) ([]redaction.Phrase, error) {
+	var categories []string
+	if policy.PII {
+		categories = append(categories, redaction.CategoryPII)
+	}
+	if policy.Profanity {
+		categories = append(categories, redaction.CategoryProfanity)
+	}
+
+	var windows []string
+	for start := 0; start < len(chunks); start += redactionWindowWords {
+		texts := make([]string, 0, redactionWindowWords)
+		for _, chunk := range chunks[start:min(start+redactionWindowWords, len(chunks))] {
+			texts = append(texts, chunk.Text)
+		}
+		windows = append(windows, strings.Join(texts, " "))
+	}
+
+	found, err := mapWindows(windows, func(i int, window string) ([]redaction.Phrase, error) {
+		response, err := chatJSON[llmRedaction](s.llmClient, llm.ChatRequest{
+			Messages: []llm.Message{
+				{Role: "system", Content: RedactionSystemPrompt},
+				{Role: "user", Content: RedactionUserPrompt(categories, window)},
+			},
+			MaxTokens: 1000,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return response.Phrases, nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find phrases to redact in %w", err)
+	}
+
+	var phrases []redaction.Phrase
+	for _, window := range found {
+		for _, phrase := range window {
+			// Only act on the requested categories, whatever the LLM returned
+			if (phrase.Category == redaction.CategoryPII && policy.PII) ||
+				(phrase.Category == redaction.CategoryProfanity && policy.Profanity) {
+				phrases = append(phrases, phrase)
+			}
+		}
+	}
+
+	return phrases, nil
+}
+
+// redactChunks replaces the text of the chunks with its redaction, keeping the text from
+// before the first redaction in OriginalText
+func redactChunks(chunks []Chunk, redact func(words []string) []string) {
+	texts := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		texts[i] = chunk.Text
+	}
+
+	for i, text := range redact(texts) {
+		if text == chunks[i].Text {
+			continue
+		}
+		if chunks[i].OriginalText == nil {
+			original := chunks[i].Text
+			chunks[i].OriginalText = &original
+		}
+		chunks[i].Text = text
+	}
+}
@@ -0,0 +1,210 @@
+package transcripts
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/transcription"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/redaction"
+	"cribeapp.com/cribe-server/internal/routes/users"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// responsesTranscriptionClient streams each entry of responses as one provider response
+type responsesTranscriptionClient struct {
+	responses [][]string
+}
+
+func (m *responsesTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, opts transcription.StreamOptions, callback transcription.StreamCallback) error {
+	start := 0.0
+	for _, texts := range m.responses {
+		words := make([]transcription.Word, len(texts))
+		for i, text := range texts {
+			words[i] = transcription.Word{Word: text, PunctuatedWord: text, Start: start, End: start + 0.5}
+			start++
+		}
+		if err := callback(&transcription.StreamResponse{
+			Type:    "Results",
+			Channel: transcription.Channel{Alternatives: []transcription.Alternative{{Words: words}}},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// savedChunk is the text and original text of a chunk written by SaveChunksBatched
+type savedChunk struct {
+	text     string
+	original *string
+}
+
+// streamWithPolicy transcribes the responses with the policy and returns the streamed
+// chunk texts and the chunks saved in the background
+func streamWithPolicy(t *testing.T, responses [][]string, policy redaction.Policy) ([]string, []savedChunk) {
+	t.Helper()
+
+	service := NewService(NewTranscriptRepository(), &responsesTranscriptionClient{responses: responses}, &MockLLMClient{})
+	service.summaries, service.chapters, service.highlights, service.indexing, service.translations, service.analytics = nil, nil, nil, nil, nil, nil
+	setupMockRepos(service, false)
+
+	saved := make(chan []savedChunk, 1)
+	service.repo.chunkRepo.Executor.Exec = func(query string, args ...any) error {
+		if strings.Contains(query, "INSERT INTO transcript_chunks") {
+			var chunks []savedChunk
+			for i := 0; i < len(args); i += 8 {
+				chunks = append(chunks, savedChunk{text: args[i+5].(string), original: args[i+7].(*string)})
+			}
+			saved <- chunks
+		}
+		return nil
+	}
+
+	var streamed []string
+	err := service.streamFromTranscriptionAPI(context.Background(), 1, "test.mp3", "Test", transcription.DefaultStreamOptions(), policy,
+		func(chunk *Chunk) error { streamed = append(streamed, chunk.Text); return nil },
+		func(speaker *Speaker) error { return nil },
+	)
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	select {
+	case chunks := <-saved:
+		return streamed, chunks
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for the chunks to be saved")
+		return nil, nil
+	}
+}
+
+func TestTranscriptService_StreamRedaction(t *testing.T) {
+	t.Run("redacts chunks before streaming and keeps the originals", func(t *testing.T) {
+		streamed, saved := streamWithPolicy(t, [][]string{
+			{"Call", "555", "123", "4567,", "damn!"},
+			{"Mail", "jane@example.com"},
+		}, redaction.Policy{PII: true, Profanity: true})
+
+		want := []string{"Call", "[phone]", "", ",", "d***!", "Mail", "[email]"}
+		if strings.Join(streamed, "|") != strings.Join(want, "|") {
+			t.Errorf("Expected streamed %q, got %q", want, streamed)
+		}
+		if len(saved) != len(want) || saved[1].text != "[phone]" || saved[1].original == nil || *saved[1].original != "555" {
+			t.Errorf("Expected the original text to be saved, got %+v", saved)
+		}
+		if saved[0].original != nil {
+			t.Errorf("Expected no original text for an unredacted chunk, got %q", *saved[0].original)
+		}
+	})
+
+	t.Run("catches matches split across responses before saving", func(t *testing.T) {
+		streamed, saved := streamWithPolicy(t, [][]string{
+			{"Call", "555", "123"},
+			{"4567", "today"},
+		}, redaction.Policy{PII: true})
+
+		if streamed[1] != "555" {
+			t.Errorf("Expected the split number to stream unredacted, got %q", streamed)
+		}
+		if saved[1].text != "[phone]" || saved[3].text != "" || *saved[3].original != "4567" {
+			t.Errorf("Expected the number to be redacted before saving, got %+v", saved)
+		}
+	})
+
+	t.Run("leaves chunks untouched without a policy", func(t *testing.T) {
+		streamed, saved := streamWithPolicy(t, [][]string{{"damn", "jane@example.com"}}, redaction.Policy{})
+
+		if streamed[0] != "damn" || saved[1].text != "jane@example.com" || saved[1].original != nil {
+			t.Errorf("Expected no redaction, got %q and %+v", streamed, saved)
+		}
+	})
+}
+
+func TestTranscriptService_RedactTranscriptWithLLM(t *testing.T) {
+	newChunks := func() []Chunk {
+		chunks := []Chunk{}
+		for i, text := range strings.Fields("I live at 12 Oak Street, heck") {
+			chunks = append(chunks, Chunk{Position: i, Text: text})
+		}
+		return chunks
+	}
+
+	t.Run("masks the phrases of the requested categories", func(t *testing.T) {
+		service := setupService()
+		service.llmClient = &scriptedLLMClient{replies: map[string]string{
+			RedactionSystemPrompt: `{"phrases": [{"text": "12 Oak Street", "category": "pii"}, {"text": "heck", "category": "profanity"}]}`,
+		}}
+		chunks := newChunks()
+
+		service.redactTranscript(1, chunks, redaction.Policy{PII: true, LLM: true})
+
+		if chunks[3].Text != "[redacted]" || chunks[4].Text != "" || chunks[5].Text != "," {
+			t.Errorf("Expected the address to be redacted, got %+v", chunks)
+		}
+		if *chunks[5].OriginalText != "Street," {
+			t.Errorf("Expected the original text to be kept, got %+v", chunks[5])
+		}
+		if chunks[6].Text != "heck" {
+			t.Errorf("Expected profanity to be kept when the policy doesn't ask for it, got %q", chunks[6].Text)
+		}
+	})
+
+	t.Run("keeps pattern redactions when the LLM fails", func(t *testing.T) {
+		service := setupService()
+		service.llmClient = &scriptedLLMClient{}
+		chunks := append(newChunks(), Chunk{Position: 7, Text: "jane@example.com"})
+
+		service.redactTranscript(1, chunks, redaction.Policy{PII: true, LLM: true})
+
+		if chunks[3].Text != "12" || chunks[7].Text != "[email]" {
+			t.Errorf("Expected only the pattern redactions, got %+v", chunks)
+		}
+	})
+}
+
+func TestTranscriptService_GetRedactions(t *testing.T) {
+	setup := func(isAdmin bool) *Service {
+		service, _ := setupSpeakerService(TranscriptStatusComplete)
+		service.repo.roles.Executor = utils.QueryExecutor[users.Role]{
+			QueryItem: func(query string, args ...any) (users.Role, error) {
+				return users.Role{IsAdmin: isAdmin}, nil
+			},
+		}
+		service.repo.redactedRepo.Executor = utils.QueryExecutor[RedactedChunk]{
+			QueryList: func(query string, args ...any) ([]RedactedChunk, error) {
+				return []RedactedChunk{{Position: 3, Text: "[phone]", OriginalText: "555-123-4567"}}, nil
+			},
+		}
+		return service
+	}
+
+	t.Run("returns the original text to admins", func(t *testing.T) {
+		chunks, errResp := setup(true).GetRedactions(1, 5)
+
+		if errResp != nil || len(chunks) != 1 || chunks[0].OriginalText != "555-123-4567" {
+			t.Errorf("Expected the redacted chunks, got %+v, %v", chunks, errResp)
+		}
+	})
+
+	t.Run("forbids other users", func(t *testing.T) {
+		_, errResp := setup(false).GetRedactions(1, 5)
+
+		if errResp == nil || errResp.Message != cribeErrors.Unauthorized {
+			t.Errorf("Expected unauthorized, got %v", errResp)
+		}
+	})
+
+	t.Run("is only served to admins over HTTP", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		NewTranscriptHandler(setup(false)).HandleRequest(w, httptest.NewRequest(http.MethodGet, "/transcripts/1/redactions", nil))
+
+		if w.Code != http.StatusForbidden {
+			t.Errorf("Expected status 403, got %d: %s", w.Code, w.Body.String())
+		}
+	})
+}
@@ -5,7 +5,9 @@ import (
 	"strings"
 	"time"
 
+	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/routes/users"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
@@ -14,6 +16,18 @@ type TranscriptRepository struct {
 	chunkRepo      *utils.Repository[TranscriptChunk]
 	speakerRepo    *utils.Repository[TranscriptSpeaker]
 	episodeRepo    *utils.Repository[Episode]
+	revisionRepo   *utils.Repository[TranscriptRevision]
+	summaryRepo    *utils.Repository[EpisodeSummary]
+	chapterRepo    *utils.Repository[EpisodeChapters]
+	highlightRepo  *utils.Repository[EpisodeHighlights]
+	convRepo       *utils.Repository[Conversation]
+	messageRepo    *utils.Repository[ConversationMessage]
+	embeddingRepo  *utils.Repository[TranscriptEmbedding]
+	translateRepo  *utils.Repository[TranscriptTranslation]
+	analyticsRepo  *utils.Repository[TranscriptAnalytics]
+	redactedRepo   *utils.Repository[RedactedChunk]
+	roles          *users.RoleRepository
+	annotationRepo *utils.Repository[Annotation]
 	logger         *logger.ContextualLogger
 }
 
@@ -23,6 +37,18 @@ func NewTranscriptRepository() *TranscriptRepository {
 		chunkRepo:      utils.NewRepository[TranscriptChunk](),
 		speakerRepo:    utils.NewRepository[TranscriptSpeaker](),
 		episodeRepo:    utils.NewRepository[Episode](),
+		revisionRepo:   utils.NewRepository[TranscriptRevision](),
+		summaryRepo:    utils.NewRepository[EpisodeSummary](),
+		chapterRepo:    utils.NewRepository[EpisodeChapters](),
+		highlightRepo:  utils.NewRepository[EpisodeHighlights](),
+		convRepo:       utils.NewRepository[Conversation](),
+		messageRepo:    utils.NewRepository[ConversationMessage](),
+		embeddingRepo:  utils.NewRepository[TranscriptEmbedding](),
+		translateRepo:  utils.NewRepository[TranscriptTranslation](),
+		analyticsRepo:  utils.NewRepository[TranscriptAnalytics](),
+		redactedRepo:   utils.NewRepository[RedactedChunk](),
+		roles:          users.NewRoleRepository(),
+		annotationRepo: utils.NewRepository[Annotation](),
 		logger:         logger.NewRepositoryLogger("TranscriptRepository"),
 	}
 }
@@ -57,7 +83,12 @@ func (r *TranscriptRepository) GetEpisodeByID(episodeID int) (Episode, error) {
 		"episodeID": episodeID,
 	})
 
-	query := `SELECT id, audio_url, description FROM episodes WHERE id = $1`
+	query := `
+		SELECT e.id, e.audio_url, e.description, e.name, p.name AS podcast_name, p.transcription_options, p.redaction_policy
+		FROM episodes e
+		JOIN podcasts p ON p.id = e.podcast_id
+		WHERE e.id = $1
+	`
 	result, err := r.episodeRepo.Executor.QueryItem(query, episodeID)
 
 	if err != nil {
@@ -75,20 +106,24 @@ func (r *TranscriptRepository) GetEpisodeByID(episodeID int) (Episode, error) {
 	return result, nil
 }
 
-func (r *TranscriptRepository) CreateTranscript(episodeID int) (int, error) {
+// CreateTranscript claims the transcript of an episode for a new transcription, creating it or
+// restarting a failed one. It fails with no rows when the transcript is processing or complete,
+// so concurrent callers can't transcribe the same episode twice.
+func (r *TranscriptRepository) CreateTranscript(episodeID int, opts transcription.StreamOptions) (int, error) {
 	r.logger.Debug("Creating transcript record", map[string]any{
 		"episodeID": episodeID,
 	})
 
 	query := `
-		INSERT INTO transcripts (episode_id, status, created_at)
-		VALUES ($1, 'processing', NOW())
+		INSERT INTO transcripts (episode_id, status, options, created_at, updated_at)
+		VALUES ($1, 'processing', $2, NOW(), NOW())
 		ON CONFLICT (episode_id) DO UPDATE
-		SET status = 'processing'
+		SET status = 'processing', options = $2, error_message = NULL, updated_at = NOW()
+		WHERE transcripts.status = 'failed'
 		RETURNING id
 	`
 
-	rows, err := r.transcriptRepo.Executor.QueryItem(query, episodeID)
+	rows, err := r.transcriptRepo.Executor.QueryItem(query, episodeID, opts)
 	if err != nil {
 		r.logger.Error("Failed to create transcript", map[string]any{
 			"episodeID": episodeID,
@@ -116,17 +151,17 @@ func (r *TranscriptRepository) UpdateTranscriptStatus(transcriptID int, status T
 	switch {
 	case status == TranscriptStatusFailed && errorMessage != "":
 		err = r.transcriptRepo.Executor.Exec(
-			`UPDATE transcripts SET status = $1, error_message = $2 WHERE id = $3`,
+			`UPDATE transcripts SET status = $1, error_message = $2, updated_at = NOW() WHERE id = $3`,
 			status, errorMessage, transcriptID,
 		)
 	case status == TranscriptStatusComplete:
 		err = r.transcriptRepo.Executor.Exec(
-			`UPDATE transcripts SET status = $1, completed_at = NOW() WHERE id = $2`,
+			`UPDATE transcripts SET status = $1, completed_at = NOW(), updated_at = NOW() WHERE id = $2`,
 			string(TranscriptStatusComplete), transcriptID,
 		)
 	default:
 		err = r.transcriptRepo.Executor.Exec(
-			`UPDATE transcripts SET status = $1 WHERE id = $2`,
+			`UPDATE transcripts SET status = $1, updated_at = NOW() WHERE id = $2`,
 			status, transcriptID,
 		)
 	}
@@ -148,12 +183,130 @@ func (r *TranscriptRepository) UpdateTranscriptStatus(transcriptID int, status T
 	return nil
 }
 
+// TouchTranscript records that a transcription is still running, so the reaper doesn't take
+// it for stale. It fails with no rows once the transcript is no longer processing.
+func (r *TranscriptRepository) TouchTranscript(transcriptID int) error {
+	_, err := r.transcriptRepo.Executor.QueryItem(
+		`UPDATE transcripts SET updated_at = NOW() WHERE id = $1 AND status = 'processing' RETURNING id`,
+		transcriptID,
+	)
+	if err != nil {
+		r.logger.Warn("Failed to touch transcript", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// CompleteTranscript marks a processing transcript as complete. It fails with no rows when
+// the transcript is no longer processing, e.g. after the reaper failed it.
+func (r *TranscriptRepository) CompleteTranscript(transcriptID int) error {
+	r.logger.Debug("Completing transcript", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	_, err := r.transcriptRepo.Executor.QueryItem(`
+		UPDATE transcripts SET status = 'complete', completed_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND status = 'processing'
+		RETURNING id
+	`, transcriptID)
+	if err != nil {
+		r.logger.Error("Failed to complete transcript", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	r.logger.Info("Transcript status updated", map[string]any{
+		"transcriptID": transcriptID,
+		"status":       TranscriptStatusComplete,
+	})
+
+	return nil
+}
+
+// FailStaleTranscripts atomically marks transcripts that have been processing for longer
+// than staleAfter as failed and returns them, so concurrent reapers never claim the same row
+func (r *TranscriptRepository) FailStaleTranscripts(staleAfter time.Duration, errorMessage string) ([]Transcript, error) {
+	r.logger.Debug("Failing stale transcripts", map[string]any{
+		"staleAfterSeconds": staleAfter.Seconds(),
+	})
+
+	query := `
+		UPDATE transcripts
+		SET status = 'failed', error_message = $1, updated_at = NOW()
+		WHERE status = 'processing' AND updated_at < NOW() - make_interval(secs => $2)
+		RETURNING id, episode_id, status, error_message, created_at, completed_at, updated_at
+	`
+	result, err := r.transcriptRepo.Executor.QueryList(query, errorMessage, staleAfter.Seconds())
+
+	if err != nil {
+		r.logger.Error("Failed to fail stale transcripts", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	r.logger.Debug("Stale transcripts failed", map[string]any{
+		"count": len(result),
+	})
+
+	return result, nil
+}
+
+func (r *TranscriptRepository) DeleteChunksByTranscriptID(transcriptID int) error {
+	r.logger.Debug("Deleting chunks for transcript", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	err := r.chunkRepo.Executor.Exec(`DELETE FROM transcript_chunks WHERE transcript_id = $1`, transcriptID)
+	if err != nil {
+		r.logger.Error("Failed to delete chunks", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) DeleteSpeakersByTranscriptID(transcriptID int) error {
+	r.logger.Debug("Deleting speakers for transcript", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	err := r.speakerRepo.Executor.Exec(`DELETE FROM transcript_speakers WHERE transcript_id = $1`, transcriptID)
+	if err != nil {
+		r.logger.Error("Failed to delete speakers", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// DeletePartialTranscript removes the chunks and speakers saved for a transcript that did
+// not complete
+func (r *TranscriptRepository) DeletePartialTranscript(transcriptID int) error {
+	if err := r.DeleteChunksByTranscriptID(transcriptID); err != nil {
+		return err
+	}
+	return r.DeleteSpeakersByTranscriptID(transcriptID)
+}
+
 func (r *TranscriptRepository) GetSpeakersByTranscriptID(transcriptID int) ([]TranscriptSpeaker, error) {
 	r.logger.Debug("Fetching speakers for transcript", map[string]any{
 		"transcriptID": transcriptID,
 	})
 
-	query := `SELECT speaker_index, speaker_name FROM transcript_speakers WHERE transcript_id = $1 ORDER BY speaker_index`
+	query := `SELECT speaker_index, speaker_name, is_human_set FROM transcript_speakers WHERE transcript_id = $1 ORDER BY speaker_index`
 	speakers, err := r.speakerRepo.Executor.QueryList(query, transcriptID)
 
 	if err != nil {
@@ -172,15 +325,17 @@ func (r *TranscriptRepository) GetSpeakersByTranscriptID(transcriptID int) ([]Tr
 	return speakers, nil
 }
 
+// GetChunksByTranscriptID returns the latest text of every chunk, skipping chunks
+// whose words were removed by a correction
 func (r *TranscriptRepository) GetChunksByTranscriptID(transcriptID int) ([]TranscriptChunk, error) {
 	r.logger.Debug("Fetching chunks for transcript", map[string]any{
 		"transcriptID": transcriptID,
 	})
 
 	query := `
-		SELECT position, speaker_index, start_time, end_time, text
+		SELECT position, speaker_index, start_time, end_time, text, confidence
 		FROM transcript_chunks
-		WHERE transcript_id = $1
+		WHERE transcript_id = $1 AND text <> ''
 		ORDER BY position ASC
 	`
 	chunks, err := r.chunkRepo.Executor.QueryList(query, transcriptID)
@@ -219,16 +374,16 @@ func (r *TranscriptRepository) SaveChunksBatched(transcriptID int, chunks []Chun
 
 		// Build multi-row INSERT
 		var query strings.Builder
-		query.WriteString(`INSERT INTO transcript_chunks (transcript_id, position, speaker_index, start_time, end_time, text) VALUES `)
-		args := make([]any, 0, len(batch)*6)
+		query.WriteString(`INSERT INTO transcript_chunks (transcript_id, position, speaker_index, start_time, end_time, text, confidence, original_text) VALUES `)
+		args := make([]any, 0, len(batch)*8)
 
 		for j, chunk := range batch {
 			if j > 0 {
 				query.WriteString(", ")
 			}
-			offset := j * 6
-			query.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d)", offset+1, offset+2, offset+3, offset+4, offset+5, offset+6))
-			args = append(args, transcriptID, chunk.Position, chunk.SpeakerIndex, chunk.Start, chunk.End, chunk.Text)
+			offset := j * 8
+			query.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", offset+1, offset+2, offset+3, offset+4, offset+5, offset+6, offset+7, offset+8))
+			args = append(args, transcriptID, chunk.Position, chunk.SpeakerIndex, chunk.Start, chunk.End, chunk.Text, chunk.Confidence, chunk.OriginalText)
 		}
 		query.WriteString(" ON CONFLICT (transcript_id, position) DO NOTHING")
 
@@ -264,10 +419,10 @@ func (r *TranscriptRepository) SaveChunks(transcriptID int, chunks []Chunk) erro
 
 	for _, chunk := range chunks {
 		err := r.chunkRepo.Executor.Exec(
-			`INSERT INTO transcript_chunks (transcript_id, position, speaker_index, start_time, end_time, text)
-			 VALUES ($1, $2, $3, $4, $5, $6)
+			`INSERT INTO transcript_chunks (transcript_id, position, speaker_index, start_time, end_time, text, confidence, original_text)
+			 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 			 ON CONFLICT (transcript_id, position) DO NOTHING`,
-			transcriptID, chunk.Position, chunk.SpeakerIndex, chunk.Start, chunk.End, chunk.Text,
+			transcriptID, chunk.Position, chunk.SpeakerIndex, chunk.Start, chunk.End, chunk.Text, chunk.Confidence, chunk.OriginalText,
 		)
 		if err != nil {
 			r.logger.Error("Failed to save chunk", map[string]any{
@@ -298,7 +453,8 @@ func (r *TranscriptRepository) UpsertSpeaker(transcriptID int, speakerIndex int,
 		`INSERT INTO transcript_speakers (transcript_id, speaker_index, speaker_name, inferred_at)
 		 VALUES ($1, $2, $3, NOW())
 		 ON CONFLICT (transcript_id, speaker_index)
-		 DO UPDATE SET speaker_name = $3, inferred_at = NOW()`,
+		 DO UPDATE SET speaker_name = $3, inferred_at = NOW()
+		 WHERE transcript_speakers.is_human_set = FALSE`,
 		transcriptID, speakerIndex, speakerName,
 	)
 
@@ -345,3 +501,1129 @@ func (r *TranscriptRepository) UpsertSpeakerWithRetry(transcriptID int, speakerI
 
 	return fmt.Errorf("failed after %d attempts: %w", maxRetries, lastErr)
 }
+
+// RenameSpeaker stores a human-provided speaker name that later inference will not overwrite
+func (r *TranscriptRepository) RenameSpeaker(transcriptID int, speakerIndex int, speakerName string, userID int) error {
+	r.logger.Debug("Renaming speaker", map[string]any{
+		"transcriptID": transcriptID,
+		"speakerIndex": speakerIndex,
+		"speakerName":  speakerName,
+	})
+
+	err := r.speakerRepo.Executor.Exec(
+		`INSERT INTO transcript_speakers (transcript_id, speaker_index, speaker_name, is_human_set, renamed_by, renamed_at)
+		 VALUES ($1, $2, $3, TRUE, $4, NOW())
+		 ON CONFLICT (transcript_id, speaker_index)
+		 DO UPDATE SET speaker_name = $3, is_human_set = TRUE, renamed_by = $4, renamed_at = NOW()`,
+		transcriptID, speakerIndex, speakerName, userID,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to rename speaker", map[string]any{
+			"transcriptID": transcriptID,
+			"speakerIndex": speakerIndex,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	r.logger.Info("Speaker renamed successfully", map[string]any{
+		"transcriptID": transcriptID,
+		"speakerIndex": speakerIndex,
+		"speakerName":  speakerName,
+	})
+
+	return nil
+}
+
+// HasSpeakerChunks reports whether any chunk of the transcript is attributed to the speaker
+func (r *TranscriptRepository) HasSpeakerChunks(transcriptID int, speakerIndex int) (bool, error) {
+	r.logger.Debug("Checking speaker chunks", map[string]any{
+		"transcriptID": transcriptID,
+		"speakerIndex": speakerIndex,
+	})
+
+	query := `
+		SELECT position
+		FROM transcript_chunks
+		WHERE transcript_id = $1 AND speaker_index = $2
+		LIMIT 1
+	`
+	_, err := r.chunkRepo.Executor.QueryItem(query, transcriptID, speakerIndex)
+
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return false, nil
+		}
+		r.logger.Error("Failed to check speaker chunks", map[string]any{
+			"transcriptID": transcriptID,
+			"speakerIndex": speakerIndex,
+			"error":        err.Error(),
+		})
+		return false, err
+	}
+
+	return true, nil
+}
+
+// MergeSpeakers remaps every chunk from one speaker index to another and removes the
+// merged speaker. It runs as a single statement so the remap and cleanup commit atomically.
+func (r *TranscriptRepository) MergeSpeakers(transcriptID int, fromIndex int, toIndex int) error {
+	r.logger.Debug("Merging speakers", map[string]any{
+		"transcriptID": transcriptID,
+		"fromIndex":    fromIndex,
+		"toIndex":      toIndex,
+	})
+
+	err := r.speakerRepo.Executor.Exec(
+		`WITH moved_chunks AS (
+			UPDATE transcript_chunks
+			SET speaker_index = $3
+			WHERE transcript_id = $1 AND speaker_index = $2
+		), target_speaker AS (
+			INSERT INTO transcript_speakers (transcript_id, speaker_index, speaker_name, inferred_at)
+			VALUES ($1, $3, $4, NOW())
+			ON CONFLICT (transcript_id, speaker_index) DO NOTHING
+		)
+		DELETE FROM transcript_speakers
+		WHERE transcript_id = $1 AND speaker_index = $2`,
+		transcriptID, fromIndex, toIndex, fmt.Sprintf("Speaker %d", toIndex),
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to merge speakers", map[string]any{
+			"transcriptID": transcriptID,
+			"fromIndex":    fromIndex,
+			"toIndex":      toIndex,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	r.logger.Info("Speakers merged successfully", map[string]any{
+		"transcriptID": transcriptID,
+		"fromIndex":    fromIndex,
+		"toIndex":      toIndex,
+	})
+
+	return nil
+}
+
+// GetChunksInRange returns every chunk between two positions (inclusive), including
+// chunks emptied by earlier corrections
+func (r *TranscriptRepository) GetChunksInRange(transcriptID int, startPosition int, endPosition int) ([]TranscriptChunk, error) {
+	r.logger.Debug("Fetching chunks in range", map[string]any{
+		"transcriptID":  transcriptID,
+		"startPosition": startPosition,
+		"endPosition":   endPosition,
+	})
+
+	query := `
+		SELECT position, speaker_index, start_time, end_time, text
+		FROM transcript_chunks
+		WHERE transcript_id = $1 AND position BETWEEN $2 AND $3
+		ORDER BY position ASC
+	`
+	chunks, err := r.chunkRepo.Executor.QueryList(query, transcriptID, startPosition, endPosition)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch chunks in range", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// CreateRevision stores a revision and applies its new chunk texts. When the revision
+// reverts another one, that revision is marked as reverted. It runs as a single statement
+// so the history and the chunk text cannot drift apart, and it only applies when every
+// chunk still has the revision's old text, failing with no rows otherwise, so concurrent
+// revisions of the same range cannot overwrite each other.
+func (r *TranscriptRepository) CreateRevision(revision TranscriptRevision) (TranscriptRevision, error) {
+	r.logger.Debug("Creating transcript revision", map[string]any{
+		"transcriptID":  revision.TranscriptID,
+		"startPosition": revision.StartPosition,
+		"endPosition":   revision.EndPosition,
+	})
+
+	query := `
+		WITH expected_chunks AS (
+			SELECT c.id, v.text
+			FROM transcript_chunks AS c
+			JOIN unnest($6::text[], $7::text[]) WITH ORDINALITY AS v(old_text, text, offset_index)
+				ON c.position = $2::int + v.offset_index - 1
+			WHERE c.transcript_id = $1::int AND c.text = v.old_text
+			FOR UPDATE OF c
+		), unchanged AS (
+			SELECT COUNT(*) = cardinality($7::text[]) AS ok FROM expected_chunks
+		), updated_chunks AS (
+			UPDATE transcript_chunks AS c
+			SET text = e.text
+			FROM expected_chunks AS e, unchanged AS u
+			WHERE c.id = e.id AND u.ok
+		), reverted AS (
+			UPDATE transcript_revisions
+			SET reverted_at = NOW()
+			WHERE id = $9::int AND transcript_id = $1::int AND (SELECT ok FROM unchanged)
+		)
+		INSERT INTO transcript_revisions (
+			transcript_id, start_position, end_position, old_text, new_text,
+			old_chunk_texts, new_chunk_texts, user_id, reverts_revision_id
+		)
+		SELECT $1::int, $2::int, $3::int, $4::text, $5::text, $6::text[], $7::text[], $8::int, $9::int
+		FROM unchanged
+		WHERE ok
+		RETURNING id, transcript_id, start_position, end_position, old_text, new_text,
+			old_chunk_texts, new_chunk_texts, user_id, reverts_revision_id, reverted_at, created_at
+	`
+	result, err := r.revisionRepo.Executor.QueryItem(query,
+		revision.TranscriptID,
+		revision.StartPosition,
+		revision.EndPosition,
+		revision.OldText,
+		revision.NewText,
+		revision.OldChunkTexts,
+		revision.NewChunkTexts,
+		revision.UserID,
+		revision.RevertsRevisionID,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create transcript revision", map[string]any{
+			"transcriptID": revision.TranscriptID,
+			"error":        err.Error(),
+		})
+		return TranscriptRevision{}, err
+	}
+
+	r.logger.Info("Transcript revision created", map[string]any{
+		"transcriptID": result.TranscriptID,
+		"revisionID":   result.ID,
+	})
+
+	return result, nil
+}
+
+// GetRevisionsByTranscriptID returns the revision history of a transcript, newest first
+func (r *TranscriptRepository) GetRevisionsByTranscriptID(transcriptID int) ([]TranscriptRevision, error) {
+	r.logger.Debug("Fetching transcript revisions", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	query := `
+		SELECT id, transcript_id, start_position, end_position, old_text, new_text,
+			old_chunk_texts, new_chunk_texts, user_id, reverts_revision_id, reverted_at, created_at
+		FROM transcript_revisions
+		WHERE transcript_id = $1
+		ORDER BY id DESC
+	`
+	revisions, err := r.revisionRepo.Executor.QueryList(query, transcriptID)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch transcript revisions", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (r *TranscriptRepository) GetRevisionByID(transcriptID int, revisionID int) (TranscriptRevision, error) {
+	r.logger.Debug("Fetching transcript revision", map[string]any{
+		"transcriptID": transcriptID,
+		"revisionID":   revisionID,
+	})
+
+	query := `
+		SELECT id, transcript_id, start_position, end_position, old_text, new_text,
+			old_chunk_texts, new_chunk_texts, user_id, reverts_revision_id, reverted_at, created_at
+		FROM transcript_revisions
+		WHERE transcript_id = $1 AND id = $2
+	`
+	revision, err := r.revisionRepo.Executor.QueryItem(query, transcriptID, revisionID)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch transcript revision", map[string]any{
+			"transcriptID": transcriptID,
+			"revisionID":   revisionID,
+			"error":        err.Error(),
+		})
+		return TranscriptRevision{}, err
+	}
+
+	return revision, nil
+}
+
+const summaryColumns = `id, episode_id, transcript_id, status, tldr, key_points, sections, error_message, created_at, updated_at`
+
+func (r *TranscriptRepository) GetSummaryByEpisodeID(episodeID int) (EpisodeSummary, error) {
+	r.logger.Debug("Fetching episode summary", map[string]any{
+		"episodeID": episodeID,
+	})
+
+	query := `SELECT ` + summaryColumns + ` FROM episode_summaries WHERE episode_id = $1`
+	summary, err := r.summaryRepo.Executor.QueryItem(query, episodeID)
+
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch episode summary", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+		return EpisodeSummary{}, err
+	}
+
+	return summary, nil
+}
+
+// StartSummary marks the summary of an episode as processing, creating it if needed.
+// Existing content is kept so readers see the previous summary while it is regenerated.
+func (r *TranscriptRepository) StartSummary(episodeID, transcriptID int) (EpisodeSummary, error) {
+	r.logger.Debug("Starting episode summary", map[string]any{
+		"episodeID":    episodeID,
+		"transcriptID": transcriptID,
+	})
+
+	query := `
+		INSERT INTO episode_summaries (episode_id, transcript_id, status)
+		VALUES ($1, $2, 'processing')
+		ON CONFLICT (episode_id) DO UPDATE
+		SET transcript_id = EXCLUDED.transcript_id, status = 'processing', error_message = NULL, updated_at = NOW()
+		RETURNING ` + summaryColumns
+	summary, err := r.summaryRepo.Executor.QueryItem(query, episodeID, transcriptID)
+
+	if err != nil {
+		r.logger.Error("Failed to start episode summary", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return EpisodeSummary{}, err
+	}
+
+	return summary, nil
+}
+
+func (r *TranscriptRepository) CompleteSummary(episodeID int, tldr string, keyPoints []string, sections []SummarySection) error {
+	r.logger.Debug("Completing episode summary", map[string]any{
+		"episodeID": episodeID,
+		"sections":  len(sections),
+	})
+
+	err := r.summaryRepo.Executor.Exec(
+		`UPDATE episode_summaries
+		 SET status = 'complete', tldr = $2, key_points = $3, sections = $4, error_message = NULL, updated_at = NOW()
+		 WHERE episode_id = $1`,
+		episodeID, tldr, keyPoints, sections,
+	)
+	if err != nil {
+		r.logger.Error("Failed to complete episode summary", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) FailSummary(episodeID int, errorMessage string) error {
+	err := r.summaryRepo.Executor.Exec(
+		`UPDATE episode_summaries SET status = 'failed', error_message = $2, updated_at = NOW() WHERE episode_id = $1`,
+		episodeID, errorMessage,
+	)
+	if err != nil {
+		r.logger.Error("Failed to mark episode summary as failed", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+const chapterColumns = `id, episode_id, transcript_id, status, chapters, error_message, created_at, updated_at`
+
+func (r *TranscriptRepository) GetChaptersByEpisodeID(episodeID int) (EpisodeChapters, error) {
+	r.logger.Debug("Fetching episode chapters", map[string]any{
+		"episodeID": episodeID,
+	})
+
+	query := `SELECT ` + chapterColumns + ` FROM episode_chapters WHERE episode_id = $1`
+	chapters, err := r.chapterRepo.Executor.QueryItem(query, episodeID)
+
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch episode chapters", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+		return EpisodeChapters{}, err
+	}
+
+	return chapters, nil
+}
+
+// StartChapters marks the chapters of an episode as processing, creating the row if needed
+func (r *TranscriptRepository) StartChapters(episodeID, transcriptID int) (EpisodeChapters, error) {
+	r.logger.Debug("Starting episode chapters", map[string]any{
+		"episodeID":    episodeID,
+		"transcriptID": transcriptID,
+	})
+
+	query := `
+		INSERT INTO episode_chapters (episode_id, transcript_id, status)
+		VALUES ($1, $2, 'processing')
+		ON CONFLICT (episode_id) DO UPDATE
+		SET transcript_id = EXCLUDED.transcript_id, status = 'processing', error_message = NULL, updated_at = NOW()
+		RETURNING ` + chapterColumns
+	chapters, err := r.chapterRepo.Executor.QueryItem(query, episodeID, transcriptID)
+
+	if err != nil {
+		r.logger.Error("Failed to start episode chapters", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return EpisodeChapters{}, err
+	}
+
+	return chapters, nil
+}
+
+func (r *TranscriptRepository) CompleteChapters(episodeID int, chapters []Chapter) error {
+	r.logger.Debug("Completing episode chapters", map[string]any{
+		"episodeID": episodeID,
+		"chapters":  len(chapters),
+	})
+
+	err := r.chapterRepo.Executor.Exec(
+		`UPDATE episode_chapters
+		 SET status = 'complete', chapters = $2, error_message = NULL, updated_at = NOW()
+		 WHERE episode_id = $1`,
+		episodeID, chapters,
+	)
+	if err != nil {
+		r.logger.Error("Failed to complete episode chapters", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) FailChapters(episodeID int, errorMessage string) error {
+	err := r.chapterRepo.Executor.Exec(
+		`UPDATE episode_chapters SET status = 'failed', error_message = $2, updated_at = NOW() WHERE episode_id = $1`,
+		episodeID, errorMessage,
+	)
+	if err != nil {
+		r.logger.Error("Failed to mark episode chapters as failed", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+const highlightColumns = `id, episode_id, transcript_id, status, highlights, error_message, created_at, updated_at`
+
+func (r *TranscriptRepository) GetHighlightsByEpisodeID(episodeID int) (EpisodeHighlights, error) {
+	r.logger.Debug("Fetching episode highlights", map[string]any{
+		"episodeID": episodeID,
+	})
+
+	query := `SELECT ` + highlightColumns + ` FROM episode_highlights WHERE episode_id = $1`
+	highlights, err := r.highlightRepo.Executor.QueryItem(query, episodeID)
+
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch episode highlights", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+		return EpisodeHighlights{}, err
+	}
+
+	return highlights, nil
+}
+
+// StartHighlights marks the highlights of an episode as processing, creating the row if needed
+func (r *TranscriptRepository) StartHighlights(episodeID, transcriptID int) (EpisodeHighlights, error) {
+	r.logger.Debug("Starting episode highlights", map[string]any{
+		"episodeID":    episodeID,
+		"transcriptID": transcriptID,
+	})
+
+	query := `
+		INSERT INTO episode_highlights (episode_id, transcript_id, status)
+		VALUES ($1, $2, 'processing')
+		ON CONFLICT (episode_id) DO UPDATE
+		SET transcript_id = EXCLUDED.transcript_id, status = 'processing', error_message = NULL, updated_at = NOW()
+		RETURNING ` + highlightColumns
+	highlights, err := r.highlightRepo.Executor.QueryItem(query, episodeID, transcriptID)
+
+	if err != nil {
+		r.logger.Error("Failed to start episode highlights", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return EpisodeHighlights{}, err
+	}
+
+	return highlights, nil
+}
+
+func (r *TranscriptRepository) CompleteHighlights(episodeID int, highlights []Highlight) error {
+	r.logger.Debug("Completing episode highlights", map[string]any{
+		"episodeID":  episodeID,
+		"highlights": len(highlights),
+	})
+
+	err := r.highlightRepo.Executor.Exec(
+		`UPDATE episode_highlights
+		 SET status = 'complete', highlights = $2, error_message = NULL, updated_at = NOW()
+		 WHERE episode_id = $1`,
+		episodeID, highlights,
+	)
+	if err != nil {
+		r.logger.Error("Failed to complete episode highlights", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) FailHighlights(episodeID int, errorMessage string) error {
+	err := r.highlightRepo.Executor.Exec(
+		`UPDATE episode_highlights SET status = 'failed', error_message = $2, updated_at = NOW() WHERE episode_id = $1`,
+		episodeID, errorMessage,
+	)
+	if err != nil {
+		r.logger.Error("Failed to mark episode highlights as failed", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) CreateConversation(episodeID, userID int) (Conversation, error) {
+	r.logger.Debug("Creating conversation", map[string]any{
+		"episodeID": episodeID,
+		"userID":    userID,
+	})
+
+	conversation, err := r.convRepo.Executor.QueryItem(
+		`INSERT INTO episode_conversations (episode_id, user_id)
+		 VALUES ($1, $2)
+		 RETURNING id, episode_id, user_id, created_at, updated_at`,
+		episodeID, userID,
+	)
+	if err != nil {
+		r.logger.Error("Failed to create conversation", map[string]any{
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return Conversation{}, err
+	}
+
+	return conversation, nil
+}
+
+func (r *TranscriptRepository) GetConversationByID(conversationID int) (Conversation, error) {
+	conversation, err := r.convRepo.Executor.QueryItem(
+		`SELECT id, episode_id, user_id, created_at, updated_at FROM episode_conversations WHERE id = $1`,
+		conversationID,
+	)
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch conversation", map[string]any{
+				"conversationID": conversationID,
+				"error":          err.Error(),
+			})
+		}
+		return Conversation{}, err
+	}
+
+	return conversation, nil
+}
+
+// GetConversationMessages returns the messages of a conversation, oldest first
+func (r *TranscriptRepository) GetConversationMessages(conversationID int) ([]ConversationMessage, error) {
+	messages, err := r.messageRepo.Executor.QueryList(
+		`SELECT id, conversation_id, role, content, citations, created_at
+		 FROM conversation_messages
+		 WHERE conversation_id = $1
+		 ORDER BY id`,
+		conversationID,
+	)
+	if err != nil {
+		r.logger.Error("Failed to fetch conversation messages", map[string]any{
+			"conversationID": conversationID,
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+// SaveConversationExchange stores a question and its answer together, so the history
+// never holds a question without a reply
+func (r *TranscriptRepository) SaveConversationExchange(conversationID int, question, answer string, citations []Citation) ([]ConversationMessage, error) {
+	r.logger.Debug("Saving conversation exchange", map[string]any{
+		"conversationID": conversationID,
+		"citations":      len(citations),
+	})
+
+	messages, err := r.messageRepo.Executor.QueryList(
+		`WITH touched AS (
+			UPDATE episode_conversations SET updated_at = NOW() WHERE id = $1
+		)
+		INSERT INTO conversation_messages (conversation_id, role, content, citations)
+		VALUES ($1, 'user', $2, '[]'), ($1, 'assistant', $3, $4)
+		RETURNING id, conversation_id, role, content, citations, created_at`,
+		conversationID, question, answer, citations,
+	)
+	if err != nil {
+		r.logger.Error("Failed to save conversation exchange", map[string]any{
+			"conversationID": conversationID,
+			"error":          err.Error(),
+		})
+		return nil, err
+	}
+
+	return messages, nil
+}
+
+const embeddingColumns = `id, transcript_id, episode_id, start_position, end_position, start_time, end_time, text, model, embedding, updated_at`
+
+func (r *TranscriptRepository) GetEmbeddingsByTranscriptID(transcriptID int) ([]TranscriptEmbedding, error) {
+	r.logger.Debug("Fetching transcript embeddings", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	query := `SELECT ` + embeddingColumns + ` FROM transcript_embeddings WHERE transcript_id = $1 ORDER BY start_position`
+	embeddings, err := r.embeddingRepo.Executor.QueryList(query, transcriptID)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch transcript embeddings", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return nil, err
+	}
+
+	return embeddings, nil
+}
+
+// GetAllEmbeddings returns the embeddings of every complete transcript, used to build
+// the in-memory search index
+func (r *TranscriptRepository) GetAllEmbeddings() ([]TranscriptEmbedding, error) {
+	query := `
+		SELECT ` + embeddingColumns + `
+		FROM transcript_embeddings
+		WHERE transcript_id IN (SELECT id FROM transcripts WHERE status = 'complete')
+		ORDER BY transcript_id, start_position
+	`
+	embeddings, err := r.embeddingRepo.Executor.QueryList(query)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch embeddings", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	r.logger.Debug("Embeddings fetched", map[string]any{
+		"count": len(embeddings),
+	})
+
+	return embeddings, nil
+}
+
+// GetUnindexedTranscripts returns the complete transcripts that have no embeddings yet,
+// such as transcripts created before semantic search existed
+func (r *TranscriptRepository) GetUnindexedTranscripts() ([]Transcript, error) {
+	query := `
+		SELECT t.id, t.episode_id, t.status, t.error_message, t.created_at, t.completed_at
+		FROM transcripts t
+		WHERE t.status = 'complete'
+		AND NOT EXISTS (SELECT 1 FROM transcript_embeddings e WHERE e.transcript_id = t.id)
+		ORDER BY t.id
+	`
+	transcripts, err := r.transcriptRepo.Executor.QueryList(query)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch unindexed transcripts", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return transcripts, nil
+}
+
+// SaveEmbeddings inserts the embeddings, replacing the stored passages that start at the
+// same positions
+func (r *TranscriptRepository) SaveEmbeddings(embeddings []TranscriptEmbedding) error {
+	if len(embeddings) == 0 {
+		return nil
+	}
+
+	r.logger.Debug("Saving transcript embeddings", map[string]any{
+		"transcriptID": embeddings[0].TranscriptID,
+		"count":        len(embeddings),
+	})
+
+	const batchSize = 100
+	for i := 0; i < len(embeddings); i += batchSize {
+		end := min(i+batchSize, len(embeddings))
+		batch := embeddings[i:end]
+
+		var query strings.Builder
+		query.WriteString(`INSERT INTO transcript_embeddings (transcript_id, episode_id, start_position, end_position, start_time, end_time, text, model, embedding) VALUES `)
+		args := make([]any, 0, len(batch)*9)
+
+		for j, embedding := range batch {
+			if j > 0 {
+				query.WriteString(", ")
+			}
+			offset := j * 9
+			query.WriteString(fmt.Sprintf("($%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d, $%d)", offset+1, offset+2, offset+3, offset+4, offset+5, offset+6, offset+7, offset+8, offset+9))
+			args = append(args, embedding.TranscriptID, embedding.EpisodeID, embedding.StartPosition, embedding.EndPosition,
+				embedding.StartTime, embedding.EndTime, embedding.Text, embedding.Model, embedding.Embedding)
+		}
+		query.WriteString(` ON CONFLICT (transcript_id, start_position) DO UPDATE
+			SET end_position = EXCLUDED.end_position, start_time = EXCLUDED.start_time, end_time = EXCLUDED.end_time,
+			text = EXCLUDED.text, model = EXCLUDED.model, embedding = EXCLUDED.embedding, updated_at = NOW()`)
+
+		err := r.embeddingRepo.Executor.Exec(query.String(), args...)
+		if err != nil {
+			r.logger.Error("Failed to save embedding batch", map[string]any{
+				"transcriptID": embeddings[0].TranscriptID,
+				"batchStart":   i,
+				"batchEnd":     end,
+				"error":        err.Error(),
+			})
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DeleteEmbeddings removes the passages of a transcript starting at the given positions
+func (r *TranscriptRepository) DeleteEmbeddings(transcriptID int, startPositions []int) error {
+	if len(startPositions) == 0 {
+		return nil
+	}
+
+	err := r.embeddingRepo.Executor.Exec(
+		`DELETE FROM transcript_embeddings WHERE transcript_id = $1 AND start_position = ANY($2)`,
+		transcriptID, startPositions,
+	)
+	if err != nil {
+		r.logger.Error("Failed to delete transcript embeddings", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+const translationColumns = `id, transcript_id, language, status, segments, error_message, created_at, updated_at`
+
+func (r *TranscriptRepository) GetTranslation(transcriptID int, language string) (TranscriptTranslation, error) {
+	r.logger.Debug("Fetching transcript translation", map[string]any{
+		"transcriptID": transcriptID,
+		"language":     language,
+	})
+
+	query := `SELECT ` + translationColumns + ` FROM transcript_translations WHERE transcript_id = $1 AND language = $2`
+	translation, err := r.translateRepo.Executor.QueryItem(query, transcriptID, language)
+
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch transcript translation", map[string]any{
+				"transcriptID": transcriptID,
+				"language":     language,
+				"error":        err.Error(),
+			})
+		}
+		return TranscriptTranslation{}, err
+	}
+
+	return translation, nil
+}
+
+// GetTranslationLanguages returns the languages a transcript has been translated into
+func (r *TranscriptRepository) GetTranslationLanguages(transcriptID int) ([]string, error) {
+	translations, err := r.translateRepo.Executor.QueryList(
+		`SELECT language FROM transcript_translations WHERE transcript_id = $1 ORDER BY language`,
+		transcriptID,
+	)
+	if err != nil {
+		r.logger.Error("Failed to fetch translation languages", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return nil, err
+	}
+
+	languages := make([]string, len(translations))
+	for i, translation := range translations {
+		languages[i] = translation.Language
+	}
+
+	return languages, nil
+}
+
+// StartTranslation marks a translation as processing, creating the row if needed
+func (r *TranscriptRepository) StartTranslation(transcriptID int, language string) (TranscriptTranslation, error) {
+	r.logger.Debug("Starting transcript translation", map[string]any{
+		"transcriptID": transcriptID,
+		"language":     language,
+	})
+
+	query := `
+		INSERT INTO transcript_translations (transcript_id, language, status)
+		VALUES ($1, $2, 'processing')
+		ON CONFLICT (transcript_id, language) DO UPDATE
+		SET status = 'processing', error_message = NULL, updated_at = NOW()
+		RETURNING ` + translationColumns
+	translation, err := r.translateRepo.Executor.QueryItem(query, transcriptID, language)
+
+	if err != nil {
+		r.logger.Error("Failed to start transcript translation", map[string]any{
+			"transcriptID": transcriptID,
+			"language":     language,
+			"error":        err.Error(),
+		})
+		return TranscriptTranslation{}, err
+	}
+
+	return translation, nil
+}
+
+func (r *TranscriptRepository) CompleteTranslation(transcriptID int, language string, segments []TranslatedSegment) error {
+	r.logger.Debug("Completing transcript translation", map[string]any{
+		"transcriptID": transcriptID,
+		"language":     language,
+		"segments":     len(segments),
+	})
+
+	err := r.translateRepo.Executor.Exec(
+		`UPDATE transcript_translations
+		 SET status = 'complete', segments = $3, error_message = NULL, updated_at = NOW()
+		 WHERE transcript_id = $1 AND language = $2`,
+		transcriptID, language, segments,
+	)
+	if err != nil {
+		r.logger.Error("Failed to complete transcript translation", map[string]any{
+			"transcriptID": transcriptID,
+			"language":     language,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) FailTranslation(transcriptID int, language string, errorMessage string) error {
+	err := r.translateRepo.Executor.Exec(
+		`UPDATE transcript_translations SET status = 'failed', error_message = $3, updated_at = NOW() WHERE transcript_id = $1 AND language = $2`,
+		transcriptID, language, errorMessage,
+	)
+	if err != nil {
+		r.logger.Error("Failed to mark transcript translation as failed", map[string]any{
+			"transcriptID": transcriptID,
+			"language":     language,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+func (r *TranscriptRepository) GetAnalytics(transcriptID int) (TranscriptAnalytics, error) {
+	r.logger.Debug("Fetching transcript analytics", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	analytics, err := r.analyticsRepo.Executor.QueryItem(
+		`SELECT transcript_id, duration, words, words_per_minute, turns, interruptions, speakers, computed_at
+		 FROM transcript_analytics
+		 WHERE transcript_id = $1`,
+		transcriptID,
+	)
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch transcript analytics", map[string]any{
+				"transcriptID": transcriptID,
+				"error":        err.Error(),
+			})
+		}
+		return TranscriptAnalytics{}, err
+	}
+
+	return analytics, nil
+}
+
+func (r *TranscriptRepository) SaveAnalytics(analytics TranscriptAnalytics) error {
+	r.logger.Debug("Saving transcript analytics", map[string]any{
+		"transcriptID": analytics.TranscriptID,
+	})
+
+	err := r.analyticsRepo.Executor.Exec(
+		`INSERT INTO transcript_analytics (transcript_id, duration, words, words_per_minute, turns, interruptions, speakers, computed_at)
+		 VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		 ON CONFLICT (transcript_id) DO UPDATE
+		 SET duration = EXCLUDED.duration, words = EXCLUDED.words, words_per_minute = EXCLUDED.words_per_minute, turns = EXCLUDED.turns,
+		 interruptions = EXCLUDED.interruptions, speakers = EXCLUDED.speakers, computed_at = EXCLUDED.computed_at`,
+		analytics.TranscriptID, analytics.Duration, analytics.Words, analytics.WordsPerMinute, analytics.Turns,
+		analytics.Interruptions, analytics.Speakers, analytics.ComputedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to save transcript analytics", map[string]any{
+			"transcriptID": analytics.TranscriptID,
+			"error":        err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// GetRedactedChunks returns the chunks masked by redaction with their original text
+func (r *TranscriptRepository) GetRedactedChunks(transcriptID int) ([]RedactedChunk, error) {
+	r.logger.Debug("Fetching redacted chunks", map[string]any{
+		"transcriptID": transcriptID,
+	})
+
+	chunks, err := r.redactedRepo.Executor.QueryList(
+		`SELECT position, speaker_index, start_time, end_time, text, original_text
+		 FROM transcript_chunks
+		 WHERE transcript_id = $1 AND original_text IS NOT NULL
+		 ORDER BY position`,
+		transcriptID,
+	)
+	if err != nil {
+		r.logger.Error("Failed to fetch redacted chunks", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return nil, err
+	}
+
+	return chunks, nil
+}
+
+// annotationSelect reads annotations from the relation named by %s. The range is resolved
+// against the current chunks of the episode from the anchoring start and end times, falling
+// back to the stored positions when no chunk lies within them.
+const annotationSelect = `
+	SELECT a.id, a.user_id, a.episode_id, e.name AS episode_name,
+		COALESCE(anchor.start_position, a.start_position) AS start_position,
+		COALESCE(anchor.end_position, a.end_position) AS end_position,
+		a.start_time, a.end_time, a.color, a.note, COALESCE(anchor.text, '') AS text,
+		a.created_at, a.updated_at
+	FROM %s a
+	JOIN episodes e ON e.id = a.episode_id
+	LEFT JOIN transcripts t ON t.episode_id = a.episode_id
+	LEFT JOIN LATERAL (
+		SELECT MIN(c.position) AS start_position, MAX(c.position) AS end_position,
+			string_agg(NULLIF(c.text, ''), ' ' ORDER BY c.position) AS text
+		FROM transcript_chunks c
+		WHERE c.transcript_id = t.id AND c.start_time >= a.start_time AND c.end_time <= a.end_time
+	) anchor ON TRUE`
+
+func (r *TranscriptRepository) CreateAnnotation(annotation Annotation) (Annotation, error) {
+	r.logger.Debug("Creating annotation", map[string]any{
+		"userID":    annotation.UserID,
+		"episodeID": annotation.EpisodeID,
+	})
+
+	query := `
+		WITH saved AS (
+			INSERT INTO user_annotations (user_id, episode_id, start_position, end_position, start_time, end_time, color, note)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+			RETURNING *
+		)` + fmt.Sprintf(annotationSelect, "saved")
+	result, err := r.annotationRepo.Executor.QueryItem(query,
+		annotation.UserID,
+		annotation.EpisodeID,
+		annotation.StartPosition,
+		annotation.EndPosition,
+		annotation.StartTime,
+		annotation.EndTime,
+		annotation.Color,
+		annotation.Note,
+	)
+
+	if err != nil {
+		r.logger.Error("Failed to create annotation", map[string]any{
+			"userID":    annotation.UserID,
+			"episodeID": annotation.EpisodeID,
+			"error":     err.Error(),
+		})
+		return Annotation{}, err
+	}
+
+	return result, nil
+}
+
+func (r *TranscriptRepository) GetAnnotation(annotationID, userID, episodeID int) (Annotation, error) {
+	query := fmt.Sprintf(annotationSelect, "user_annotations") + `
+		WHERE a.id = $1 AND a.user_id = $2 AND a.episode_id = $3`
+	annotation, err := r.annotationRepo.Executor.QueryItem(query, annotationID, userID, episodeID)
+
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to fetch annotation", map[string]any{
+				"annotationID": annotationID,
+				"error":        err.Error(),
+			})
+		}
+		return Annotation{}, err
+	}
+
+	return annotation, nil
+}
+
+// GetAnnotationsByEpisodeID returns the annotations of a user on an episode in transcript order
+func (r *TranscriptRepository) GetAnnotationsByEpisodeID(userID, episodeID int) ([]Annotation, error) {
+	r.logger.Debug("Fetching episode annotations", map[string]any{
+		"userID":    userID,
+		"episodeID": episodeID,
+	})
+
+	query := fmt.Sprintf(annotationSelect, "user_annotations") + `
+		WHERE a.user_id = $1 AND a.episode_id = $2
+		ORDER BY a.start_time ASC, a.id ASC`
+	annotations, err := r.annotationRepo.Executor.QueryList(query, userID, episodeID)
+
+	if err != nil {
+		r.logger.Error("Failed to fetch episode annotations", map[string]any{
+			"userID":    userID,
+			"episodeID": episodeID,
+			"error":     err.Error(),
+		})
+		return nil, err
+	}
+
+	return annotations, nil
+}
+
+// SearchAnnotations returns the annotations of a user across episodes, most recently updated
+// first. A non-empty search matches the note, the annotated text or the episode name.
+func (r *TranscriptRepository) SearchAnnotations(userID int, search string, limit, offset int) ([]Annotation, error) {
+	r.logger.Debug("Searching annotations", map[string]any{
+		"userID": userID,
+		"search": search,
+	})
+
+	query := fmt.Sprintf(annotationSelect, "user_annotations") + `
+		WHERE a.user_id = $1 AND ($2 = '' OR a.note ILIKE $2 OR anchor.text ILIKE $2 OR e.name ILIKE $2)
+		ORDER BY a.updated_at DESC, a.id DESC
+		LIMIT $3 OFFSET $4`
+	annotations, err := r.annotationRepo.Executor.QueryList(query, userID, likePattern(search), limit, offset)
+
+	if err != nil {
+		r.logger.Error("Failed to search annotations", map[string]any{
+			"userID": userID,
+			"error":  err.Error(),
+		})
+		return nil, err
+	}
+
+	return annotations, nil
+}
+
+func (r *TranscriptRepository) UpdateAnnotation(annotation Annotation) (Annotation, error) {
+	r.logger.Debug("Updating annotation", map[string]any{
+		"annotationID": annotation.ID,
+	})
+
+	query := `
+		WITH saved AS (
+			UPDATE user_annotations
+			SET start_position = $4, end_position = $5, start_time = $6, end_time = $7, color = $8, note = $9, updated_at = NOW()
+			WHERE id = $1 AND user_id = $2 AND episode_id = $3
+			RETURNING *
+		)` + fmt.Sprintf(annotationSelect, "saved")
+	result, err := r.annotationRepo.Executor.QueryItem(query,
+		annotation.ID,
+		annotation.UserID,
+		annotation.EpisodeID,
+		annotation.StartPosition,
+		annotation.EndPosition,
+		annotation.StartTime,
+		annotation.EndTime,
+		annotation.Color,
+		annotation.Note,
+	)
+
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to update annotation", map[string]any{
+				"annotationID": annotation.ID,
+				"error":        err.Error(),
+			})
+		}
+		return Annotation{}, err
+	}
+
+	return result, nil
+}
+
+func (r *TranscriptRepository) DeleteAnnotation(annotationID, userID, episodeID int) error {
+	r.logger.Debug("Deleting annotation", map[string]any{
+		"annotationID": annotationID,
+	})
+
+	_, err := r.annotationRepo.Executor.QueryItem(
+		`DELETE FROM user_annotations WHERE id = $1 AND user_id = $2 AND episode_id = $3 RETURNING id`,
+		annotationID, userID, episodeID,
+	)
+	if err != nil {
+		if err.Error() != "no rows in result set" {
+			r.logger.Error("Failed to delete annotation", map[string]any{
+				"annotationID": annotationID,
+				"error":        err.Error(),
+			})
+		}
+		return err
+	}
+
+	return nil
+}
+
+// likePattern turns a search into an ILIKE pattern matching it anywhere, or "" for no search
+func likePattern(search string) string {
+	if search == "" {
+		return ""
+	}
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(search) + "%"
+}
@@ -2,9 +2,11 @@ package transcripts
 
 import (
 	"errors"
+	"strings"
 	"testing"
 	"time"
 
+	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
@@ -149,10 +151,45 @@ func TestTranscriptRepository_UpdateTranscriptStatus(t *testing.T) {
 	})
 }
 
+func TestTranscriptRepository_CompleteTranscript(t *testing.T) {
+	t.Run("only completes processing transcripts", func(t *testing.T) {
+		var gotQuery string
+		repo := NewTranscriptRepository()
+		repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				gotQuery = query
+				return Transcript{ID: 1}, nil
+			},
+		}
+
+		if err := repo.CompleteTranscript(1); err != nil {
+			t.Errorf("Expected no error, got %v", err)
+		}
+		if !strings.Contains(gotQuery, "status = 'processing'") {
+			t.Errorf("Expected a conditional update, got %s", gotQuery)
+		}
+	})
+
+	t.Run("fails when the transcript is no longer processing", func(t *testing.T) {
+		repo := NewTranscriptRepository()
+		repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				return Transcript{}, errors.New("no rows in result set")
+			},
+		}
+
+		if err := repo.CompleteTranscript(1); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
 func TestTranscriptRepository_CreateTranscript(t *testing.T) {
 	t.Run("should create transcript successfully", func(t *testing.T) {
+		var gotArgs []any
 		mockExecutor := utils.QueryExecutor[Transcript]{
 			QueryItem: func(query string, args ...any) (Transcript, error) {
+				gotArgs = args
 				return Transcript{ID: 1, EpisodeID: 1}, nil
 			},
 		}
@@ -160,7 +197,8 @@ func TestTranscriptRepository_CreateTranscript(t *testing.T) {
 		repo := NewTranscriptRepository()
 		repo.transcriptRepo.Executor = mockExecutor
 
-		id, err := repo.CreateTranscript(1)
+		opts := transcription.DefaultStreamOptions()
+		id, err := repo.CreateTranscript(1, opts)
 
 		if err != nil {
 			t.Errorf("Expected no error, got %v", err)
@@ -169,6 +207,28 @@ func TestTranscriptRepository_CreateTranscript(t *testing.T) {
 		if id != 1 {
 			t.Errorf("Expected transcript ID 1, got %v", id)
 		}
+
+		if stored, ok := gotArgs[1].(transcription.StreamOptions); !ok || stored.Language != opts.Language {
+			t.Errorf("Expected stream options to be stored, got %v", gotArgs)
+		}
+	})
+
+	t.Run("should only restart failed transcripts", func(t *testing.T) {
+		var gotQuery string
+		repo := NewTranscriptRepository()
+		repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				gotQuery = query
+				return Transcript{}, errors.New("no rows in result set")
+			},
+		}
+
+		if _, err := repo.CreateTranscript(1, transcription.DefaultStreamOptions()); err == nil {
+			t.Error("Expected error when the transcript is already claimed, got nil")
+		}
+		if !strings.Contains(gotQuery, "WHERE transcripts.status = 'failed'") {
+			t.Errorf("Expected a conditional upsert, got %s", gotQuery)
+		}
 	})
 }
 
@@ -298,3 +358,213 @@ func TestTranscriptRepository_UpsertSpeaker(t *testing.T) {
 		}
 	})
 }
+
+func TestTranscriptRepository_FailStaleTranscripts(t *testing.T) {
+	t.Run("should pass error message and age in seconds", func(t *testing.T) {
+		var gotArgs []any
+		repo := NewTranscriptRepository()
+		repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryList: func(query string, args ...any) ([]Transcript, error) {
+				gotArgs = args
+				return []Transcript{{ID: 1, Status: string(TranscriptStatusFailed)}}, nil
+			},
+		}
+
+		result, err := repo.FailStaleTranscripts(2*time.Hour, "timed out")
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(result) != 1 {
+			t.Errorf("Expected 1 transcript, got %d", len(result))
+		}
+		if gotArgs[0] != "timed out" || gotArgs[1] != float64(7200) {
+			t.Errorf("Unexpected args: %v", gotArgs)
+		}
+	})
+
+	t.Run("should return error when query fails", func(t *testing.T) {
+		repo := NewTranscriptRepository()
+		repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryList: func(query string, args ...any) ([]Transcript, error) {
+				return nil, errors.New("database error")
+			},
+		}
+
+		if _, err := repo.FailStaleTranscripts(time.Hour, "timed out"); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestTranscriptRepository_DeleteChunksByTranscriptID(t *testing.T) {
+	t.Run("should return error when delete fails", func(t *testing.T) {
+		repo := NewTranscriptRepository()
+		repo.chunkRepo.Executor = utils.QueryExecutor[TranscriptChunk]{
+			Exec: func(query string, args ...any) error {
+				return errors.New("database error")
+			},
+		}
+
+		if err := repo.DeleteChunksByTranscriptID(1); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestTranscriptRepository_RenameSpeaker(t *testing.T) {
+	t.Run("should pass the renaming user", func(t *testing.T) {
+		var gotArgs []any
+		repo := NewTranscriptRepository()
+		repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
+			Exec: func(query string, args ...any) error {
+				gotArgs = args
+				return nil
+			},
+		}
+
+		if err := repo.RenameSpeaker(1, 2, "Jane Doe", 7); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if gotArgs[2] != "Jane Doe" || gotArgs[3] != 7 {
+			t.Errorf("Unexpected args: %v", gotArgs)
+		}
+	})
+}
+
+func TestTranscriptRepository_HasSpeakerChunks(t *testing.T) {
+	tests := []struct {
+		name       string
+		queryErr   error
+		wantExists bool
+		wantErr    bool
+	}{
+		{"speaker has chunks", nil, true, false},
+		{"speaker has no chunks", errors.New("no rows in result set"), false, false},
+		{"query fails", errors.New("database error"), false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := NewTranscriptRepository()
+			repo.chunkRepo.Executor = utils.QueryExecutor[TranscriptChunk]{
+				QueryItem: func(query string, args ...any) (TranscriptChunk, error) {
+					return TranscriptChunk{}, tt.queryErr
+				},
+			}
+
+			exists, err := repo.HasSpeakerChunks(1, 0)
+
+			if exists != tt.wantExists || (err != nil) != tt.wantErr {
+				t.Errorf("Expected exists=%v err=%v, got exists=%v err=%v", tt.wantExists, tt.wantErr, exists, err)
+			}
+		})
+	}
+}
+
+func TestTranscriptRepository_MergeSpeakers(t *testing.T) {
+	t.Run("should name a missing target speaker after its index", func(t *testing.T) {
+		var gotArgs []any
+		repo := NewTranscriptRepository()
+		repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
+			Exec: func(query string, args ...any) error {
+				gotArgs = args
+				return nil
+			},
+		}
+
+		if err := repo.MergeSpeakers(1, 2, 0); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if gotArgs[1] != 2 || gotArgs[2] != 0 || gotArgs[3] != "Speaker 0" {
+			t.Errorf("Unexpected args: %v", gotArgs)
+		}
+	})
+
+	t.Run("should return error when merge fails", func(t *testing.T) {
+		repo := NewTranscriptRepository()
+		repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
+			Exec: func(query string, args ...any) error {
+				return errors.New("database error")
+			},
+		}
+
+		if err := repo.MergeSpeakers(1, 2, 0); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestTranscriptRepository_CreateRevision(t *testing.T) {
+	t.Run("should store revision and apply new chunk texts in one statement", func(t *testing.T) {
+		var gotQuery string
+		var gotArgs []any
+		revertedID := 3
+		repo := NewTranscriptRepository()
+		repo.revisionRepo.Executor = utils.QueryExecutor[TranscriptRevision]{
+			QueryItem: func(query string, args ...any) (TranscriptRevision, error) {
+				gotQuery = query
+				gotArgs = args
+				return TranscriptRevision{ID: 4, TranscriptID: 1}, nil
+			},
+		}
+
+		revision, err := repo.CreateRevision(TranscriptRevision{
+			TranscriptID:      1,
+			StartPosition:     2,
+			EndPosition:       3,
+			OldChunkTexts:     []string{"New", "York"},
+			NewChunkTexts:     []string{"NYC", ""},
+			RevertsRevisionID: &revertedID,
+		})
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if revision.ID != 4 {
+			t.Errorf("Expected revision ID 4, got %d", revision.ID)
+		}
+		if !strings.Contains(gotQuery, "UPDATE transcript_chunks") || !strings.Contains(gotQuery, "SET reverted_at = NOW()") {
+			t.Errorf("Expected chunk update and revert marking in query, got %s", gotQuery)
+		}
+		if !strings.Contains(gotQuery, "c.text = v.old_text") || !strings.Contains(gotQuery, "WHERE ok") {
+			t.Errorf("Expected the update to compare the old chunk texts, got %s", gotQuery)
+		}
+		if len(gotArgs) != 9 || gotArgs[8] != &revertedID {
+			t.Errorf("Unexpected args: %v", gotArgs)
+		}
+	})
+
+	t.Run("should return error when insert fails", func(t *testing.T) {
+		repo := NewTranscriptRepository()
+		repo.revisionRepo.Executor = utils.QueryExecutor[TranscriptRevision]{
+			QueryItem: func(query string, args ...any) (TranscriptRevision, error) {
+				return TranscriptRevision{}, errors.New("database error")
+			},
+		}
+
+		if _, err := repo.CreateRevision(TranscriptRevision{TranscriptID: 1}); err == nil {
+			t.Error("Expected error, got nil")
+		}
+	})
+}
+
+func TestTranscriptRepository_GetRevisionsByTranscriptID(t *testing.T) {
+	t.Run("should list revisions", func(t *testing.T) {
+		repo := NewTranscriptRepository()
+		repo.revisionRepo.Executor = utils.QueryExecutor[TranscriptRevision]{
+			QueryList: func(query string, args ...any) ([]TranscriptRevision, error) {
+				return []TranscriptRevision{{ID: 2}, {ID: 1}}, nil
+			},
+		}
+
+		revisions, err := repo.GetRevisionsByTranscriptID(1)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(revisions) != 2 {
+			t.Errorf("Expected 2 revisions, got %d", len(revisions))
+		}
+	})
+}
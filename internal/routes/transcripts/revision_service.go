@@ -0,0 +1,240 @@
+package transcripts
+
+import (
+	"slices"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// CreateRevision replaces the text of a range of chunk positions and records the change
+func (s *Service) CreateRevision(episodeID, userID, startPosition, endPosition int, text string) (TranscriptRevision, *errors.ErrorResponse) {
+	s.log.Info("Creating transcript revision", map[string]any{
+		"episodeID":     episodeID,
+		"startPosition": startPosition,
+		"endPosition":   endPosition,
+	})
+
+	words := strings.Fields(text)
+	if len(words) == 0 {
+		return TranscriptRevision{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Revision text cannot be blank",
+		}
+	}
+
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return TranscriptRevision{}, errResp
+	}
+
+	oldChunkTexts, errResp := s.getChunkTextsInRange(transcript.ID, startPosition, endPosition)
+	if errResp != nil {
+		return TranscriptRevision{}, errResp
+	}
+
+	newChunkTexts := distributeWords(words, len(oldChunkTexts))
+	if slices.Equal(oldChunkTexts, newChunkTexts) {
+		return TranscriptRevision{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Revision does not change the transcript text",
+		}
+	}
+
+	revision, errResp := s.saveRevision(TranscriptRevision{
+		TranscriptID:  transcript.ID,
+		StartPosition: startPosition,
+		EndPosition:   endPosition,
+		OldText:       joinChunkTexts(oldChunkTexts),
+		NewText:       joinChunkTexts(newChunkTexts),
+		OldChunkTexts: oldChunkTexts,
+		NewChunkTexts: newChunkTexts,
+		UserID:        optionalUserID(userID),
+	})
+	if errResp != nil {
+		return TranscriptRevision{}, errResp
+	}
+
+	s.scheduleSummary(episodeID)
+	s.scheduleChapters(episodeID)
+	s.scheduleHighlights(episodeID)
+	s.scheduleIndexing(episodeID)
+	s.scheduleTranslations(episodeID)
+	s.scheduleAnalytics(episodeID)
+	return revision, nil
+}
+
+// GetRevisions returns the revision history of an episode transcript, newest first
+func (s *Service) GetRevisions(episodeID int) ([]TranscriptRevision, *errors.ErrorResponse) {
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	revisions, err := s.repo.GetRevisionsByTranscriptID(transcript.ID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch revisions",
+		}
+	}
+
+	if revisions == nil {
+		revisions = []TranscriptRevision{}
+	}
+
+	return revisions, nil
+}
+
+// RevertRevision restores the text a revision replaced by recording a new revision.
+// It is rejected when a later revision changed the same range, so history is never lost.
+func (s *Service) RevertRevision(episodeID, revisionID, userID int) (TranscriptRevision, *errors.ErrorResponse) {
+	s.log.Info("Reverting transcript revision", map[string]any{
+		"episodeID":  episodeID,
+		"revisionID": revisionID,
+	})
+
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return TranscriptRevision{}, errResp
+	}
+
+	revision, err := s.repo.GetRevisionByID(transcript.ID, revisionID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return TranscriptRevision{}, &errors.ErrorResponse{
+				Message: errors.DatabaseNotFound,
+				Details: "Revision not found for this transcript",
+			}
+		}
+		return TranscriptRevision{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch revision",
+		}
+	}
+
+	if revision.RevertedAt != nil {
+		return TranscriptRevision{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Revision has already been reverted",
+		}
+	}
+
+	currentChunkTexts, errResp := s.getChunkTextsInRange(transcript.ID, revision.StartPosition, revision.EndPosition)
+	if errResp != nil {
+		return TranscriptRevision{}, errResp
+	}
+
+	if !slices.Equal(currentChunkTexts, revision.NewChunkTexts) {
+		return TranscriptRevision{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "A later revision changed this range; revert it first",
+		}
+	}
+
+	revert, errResp := s.saveRevision(TranscriptRevision{
+		TranscriptID:      transcript.ID,
+		StartPosition:     revision.StartPosition,
+		EndPosition:       revision.EndPosition,
+		OldText:           revision.NewText,
+		NewText:           revision.OldText,
+		OldChunkTexts:     revision.NewChunkTexts,
+		NewChunkTexts:     revision.OldChunkTexts,
+		UserID:            optionalUserID(userID),
+		RevertsRevisionID: &revision.ID,
+	})
+	if errResp != nil {
+		return TranscriptRevision{}, errResp
+	}
+
+	s.scheduleSummary(episodeID)
+	s.scheduleChapters(episodeID)
+	s.scheduleHighlights(episodeID)
+	s.scheduleIndexing(episodeID)
+	s.scheduleTranslations(episodeID)
+	s.scheduleAnalytics(episodeID)
+	return revert, nil
+}
+
+// getChunkTextsInRange returns the current text of every position in the range,
+// failing when the range is not fully covered by the transcript
+func (s *Service) getChunkTextsInRange(transcriptID, startPosition, endPosition int) ([]string, *errors.ErrorResponse) {
+	chunks, err := s.repo.GetChunksInRange(transcriptID, startPosition, endPosition)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript chunks",
+		}
+	}
+
+	if len(chunks) != endPosition-startPosition+1 {
+		return nil, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Range is outside the transcript",
+		}
+	}
+
+	texts := make([]string, 0, len(chunks))
+	for _, chunk := range chunks {
+		texts = append(texts, chunk.Text)
+	}
+
+	return texts, nil
+}
+
+// saveRevision stores a revision, failing with a conflict when another revision changed
+// the range since its chunk texts were read
+func (s *Service) saveRevision(revision TranscriptRevision) (TranscriptRevision, *errors.ErrorResponse) {
+	saved, err := s.repo.CreateRevision(revision)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			s.log.Warn("Transcript range changed concurrently", map[string]any{
+				"transcriptID":  revision.TranscriptID,
+				"startPosition": revision.StartPosition,
+				"endPosition":   revision.EndPosition,
+			})
+			return TranscriptRevision{}, &errors.ErrorResponse{
+				Message: errors.DatabaseConflict,
+				Details: "Another revision changed this range; reload the transcript and try again",
+			}
+		}
+		return TranscriptRevision{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to save revision",
+		}
+	}
+
+	return saved, nil
+}
+
+// distributeWords spreads the corrected words over the chunks of the range so every
+// chunk keeps its timing. Extra words share chunks; missing words leave chunks empty.
+func distributeWords(words []string, chunkCount int) []string {
+	texts := make([]string, chunkCount)
+	if len(words) <= chunkCount {
+		copy(texts, words)
+		return texts
+	}
+
+	for i := range texts {
+		start := i * len(words) / chunkCount
+		end := (i + 1) * len(words) / chunkCount
+		texts[i] = strings.Join(words[start:end], " ")
+	}
+
+	return texts
+}
+
+// joinChunkTexts joins chunk texts into readable text, skipping emptied chunks
+func joinChunkTexts(texts []string) string {
+	return strings.Join(slices.DeleteFunc(slices.Clone(texts), func(text string) bool {
+		return text == ""
+	}), " ")
+}
+
+func optionalUserID(userID int) *int {
+	if userID <= 0 {
+		return nil
+	}
+	return &userID
+}
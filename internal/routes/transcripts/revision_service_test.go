@@ -0,0 +1,260 @@
+package transcripts
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupRevisionService mocks a complete transcript reading "the new york times" at positions 0-3
+// and a single revision (ID 7) that corrected "new york" at positions 1-2 into "NYC"
+func setupRevisionService(status TranscriptStatus) (*Service, *[]TranscriptRevision) {
+	service, _ := setupSpeakerService(status)
+	chunkTexts := []string{"the", "NYC", "", "times"}
+	var saved []TranscriptRevision
+
+	service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+		start, end := args[1].(int), args[2].(int)
+		var chunks []TranscriptChunk
+		for position := max(start, 0); position <= end && position < len(chunkTexts); position++ {
+			chunks = append(chunks, TranscriptChunk{Position: position, Text: chunkTexts[position]})
+		}
+		return chunks, nil
+	}
+	service.repo.revisionRepo.Executor = utils.QueryExecutor[TranscriptRevision]{
+		QueryItem: func(query string, args ...any) (TranscriptRevision, error) {
+			if strings.Contains(query, "INSERT INTO transcript_revisions") {
+				// Apply the revision only when the range still has its old texts
+				start, oldTexts, newTexts := args[1].(int), args[5].([]string), args[6].([]string)
+				if !slices.Equal(chunkTexts[start:start+len(oldTexts)], oldTexts) {
+					return TranscriptRevision{}, fmt.Errorf("no rows in result set")
+				}
+				copy(chunkTexts[start:], newTexts)
+				revision := TranscriptRevision{
+					ID:            len(saved) + 8,
+					TranscriptID:  args[0].(int),
+					StartPosition: args[1].(int),
+					EndPosition:   args[2].(int),
+					OldText:       args[3].(string),
+					NewText:       args[4].(string),
+					OldChunkTexts: args[5].([]string),
+					NewChunkTexts: args[6].([]string),
+				}
+				saved = append(saved, revision)
+				return revision, nil
+			}
+			if args[1].(int) != 7 {
+				return TranscriptRevision{}, fmt.Errorf("no rows in result set")
+			}
+			return TranscriptRevision{
+				ID:            7,
+				TranscriptID:  10,
+				StartPosition: 1,
+				EndPosition:   2,
+				OldText:       "new york",
+				NewText:       "NYC",
+				OldChunkTexts: []string{"new", "york"},
+				NewChunkTexts: []string{"NYC", ""},
+			}, nil
+		},
+		QueryList: func(query string, args ...any) ([]TranscriptRevision, error) {
+			return nil, nil
+		},
+	}
+
+	return service, &saved
+}
+
+func TestDistributeWords(t *testing.T) {
+	tests := []struct {
+		name       string
+		words      []string
+		chunkCount int
+		want       []string
+	}{
+		{"one word per chunk", []string{"a", "b"}, 2, []string{"a", "b"}},
+		{"fewer words empty trailing chunks", []string{"NYC"}, 3, []string{"NYC", "", ""}},
+		{"more words share chunks", []string{"a", "b", "c", "d", "e"}, 2, []string{"a b", "c d e"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := distributeWords(tt.words, tt.chunkCount); !slices.Equal(got, tt.want) {
+				t.Errorf("Expected %q, got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTranscriptService_CreateRevision(t *testing.T) {
+	t.Run("stores old and new text of the range", func(t *testing.T) {
+		service, saved := setupRevisionService(TranscriptStatusComplete)
+
+		revision, errResp := service.CreateRevision(1, 5, 0, 3, "The New York Times")
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if revision.OldText != "the NYC times" || revision.NewText != "The New York Times" {
+			t.Errorf("Unexpected revision texts: %+v", revision)
+		}
+		if len(*saved) != 1 || !slices.Equal((*saved)[0].NewChunkTexts, []string{"The", "New", "York", "Times"}) {
+			t.Errorf("Unexpected saved revisions: %+v", *saved)
+		}
+	})
+
+	tests := []struct {
+		name        string
+		episodeID   int
+		start, end  int
+		text        string
+		status      TranscriptStatus
+		wantMessage string
+	}{
+		{"blank text", 1, 0, 1, "   ", TranscriptStatusComplete, cribeErrors.ValidationError},
+		{"missing transcript", 2, 0, 1, "fix", TranscriptStatusComplete, cribeErrors.DatabaseNotFound},
+		{"processing transcript", 1, 0, 1, "fix", TranscriptStatusProcessing, cribeErrors.ValidationError},
+		{"range outside transcript", 1, 2, 9, "fix", TranscriptStatusComplete, cribeErrors.ValidationError},
+		{"unchanged text", 1, 0, 0, "the", TranscriptStatusComplete, cribeErrors.ValidationError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, saved := setupRevisionService(tt.status)
+
+			_, errResp := service.CreateRevision(tt.episodeID, 5, tt.start, tt.end, tt.text)
+
+			if errResp == nil || errResp.Message != tt.wantMessage {
+				t.Errorf("Expected %q error, got %v", tt.wantMessage, errResp)
+			}
+			if len(*saved) != 0 {
+				t.Errorf("Expected no writes, got %v", *saved)
+			}
+		})
+	}
+}
+
+func TestTranscriptService_CreateRevision_Interleaved(t *testing.T) {
+	service, saved := setupRevisionService(TranscriptStatusComplete)
+	readChunks := service.repo.chunkRepo.Executor.QueryList
+	interleaved := false
+	service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+		chunks, err := readChunks(query, args...)
+		if !interleaved {
+			// Another revision of the range is saved after this one read the chunks
+			interleaved = true
+			if _, errResp := service.CreateRevision(1, 6, 0, 3, "The New York Times"); errResp != nil {
+				t.Fatalf("Expected the first revision to be saved, got %v", errResp)
+			}
+		}
+		return chunks, err
+	}
+
+	_, errResp := service.CreateRevision(1, 5, 0, 3, "the NY times")
+
+	if errResp == nil || errResp.Message != cribeErrors.DatabaseConflict {
+		t.Errorf("Expected %q error, got %v", cribeErrors.DatabaseConflict, errResp)
+	}
+	if len(*saved) != 1 || (*saved)[0].NewText != "The New York Times" {
+		t.Errorf("Expected only the first revision saved, got %+v", *saved)
+	}
+}
+
+func TestTranscriptService_RevertRevision(t *testing.T) {
+	t.Run("restores the replaced chunk texts", func(t *testing.T) {
+		service, saved := setupRevisionService(TranscriptStatusComplete)
+
+		revision, errResp := service.RevertRevision(1, 7, 5)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if revision.NewText != "new york" || !slices.Equal((*saved)[0].NewChunkTexts, []string{"new", "york"}) {
+			t.Errorf("Unexpected revert revision: %+v", revision)
+		}
+	})
+
+	t.Run("rejects revisions already reverted", func(t *testing.T) {
+		service, saved := setupRevisionService(TranscriptStatusComplete)
+		getRevision := service.repo.revisionRepo.Executor.QueryItem
+		service.repo.revisionRepo.Executor.QueryItem = func(query string, args ...any) (TranscriptRevision, error) {
+			revision, err := getRevision(query, args...)
+			revertedAt := time.Now()
+			revision.RevertedAt = &revertedAt
+			return revision, err
+		}
+
+		_, errResp := service.RevertRevision(1, 7, 5)
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError || len(*saved) != 0 {
+			t.Errorf("Expected validation error without writes, got %v", errResp)
+		}
+	})
+
+	t.Run("rejects when a later revision changed the range", func(t *testing.T) {
+		service, saved := setupRevisionService(TranscriptStatusComplete)
+		service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+			return []TranscriptChunk{{Position: 1, Text: "N.Y.C."}, {Position: 2, Text: ""}}, nil
+		}
+
+		_, errResp := service.RevertRevision(1, 7, 5)
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError || len(*saved) != 0 {
+			t.Errorf("Expected validation error without writes, got %v", errResp)
+		}
+	})
+
+	t.Run("returns not found for unknown revision", func(t *testing.T) {
+		service, _ := setupRevisionService(TranscriptStatusComplete)
+
+		_, errResp := service.RevertRevision(1, 99, 5)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found error, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptHandler_Revisions(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{"list revisions", http.MethodGet, "/transcripts/1/revisions", ``, http.StatusOK},
+		{"list revisions for missing transcript", http.MethodGet, "/transcripts/2/revisions", ``, http.StatusNotFound},
+		{"create revision", http.MethodPost, "/transcripts/1/revisions", `{"start_position":1,"end_position":2,"text":"New York"}`, http.StatusCreated},
+		{"create with inverted range", http.MethodPost, "/transcripts/1/revisions", `{"start_position":2,"end_position":1,"text":"x"}`, http.StatusBadRequest},
+		{"create with missing text", http.MethodPost, "/transcripts/1/revisions", `{"start_position":1,"end_position":2}`, http.StatusBadRequest},
+		{"create with wrong method", http.MethodPut, "/transcripts/1/revisions", `{}`, http.StatusMethodNotAllowed},
+		{"revert revision", http.MethodPost, "/transcripts/1/revisions/7/revert", ``, http.StatusCreated},
+		{"revert unknown revision", http.MethodPost, "/transcripts/1/revisions/99/revert", ``, http.StatusNotFound},
+		{"revert with wrong method", http.MethodGet, "/transcripts/1/revisions/7/revert", ``, http.StatusMethodNotAllowed},
+		{"unknown revisions sub-path", http.MethodPost, "/transcripts/1/revisions/7/undo", ``, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupRevisionService(TranscriptStatusComplete)
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+
+			handler.HandleRequest(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
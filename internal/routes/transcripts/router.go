@@ -7,11 +7,11 @@ import (
 	"cribeapp.com/cribe-server/internal/clients/transcription"
 )
 
-func HandleHTTPRequests() func(http.ResponseWriter, *http.Request) {
-	transcriptionClient := transcription.NewClient()
+func HandleHTTPRequests(repo *TranscriptRepository) func(http.ResponseWriter, *http.Request) {
+	transcriptionClient := transcription.NewProvider()
 	llmClient := llm.NewClient()
 
-	service := NewService(transcriptionClient, llmClient)
+	service := NewService(repo, transcriptionClient, llmClient)
 	handler := NewTranscriptHandler(service)
 
 	return handler.HandleRequest
@@ -0,0 +1,49 @@
+package transcripts
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// handleSearchRoutes routes /search/* requests
+func (h *TranscriptHandler) handleSearchRoutes(w http.ResponseWriter, r *http.Request, path string) {
+	switch strings.Trim(path, "/") {
+	case "semantic":
+		// GET /search/semantic?q=...&limit=...
+		if r.Method != http.MethodGet {
+			utils.NotAllowed(w)
+			return
+		}
+		h.handleSemanticSearch(w, r)
+	default:
+		utils.NotFound(w, r)
+	}
+}
+
+func (h *TranscriptHandler) handleSemanticSearch(w http.ResponseWriter, r *http.Request) {
+	limit := DefaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > MaxSearchLimit {
+			utils.EncodeResponse(w, http.StatusBadRequest, &errors.ErrorResponse{
+				Message: errors.ValidationError,
+				Details: fmt.Sprintf("limit must be a number between 1 and %d", MaxSearchLimit),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	result, errResp := h.service.SemanticSearch(r.Context(), r.URL.Query().Get("q"), limit)
+	if errResp != nil {
+		h.encodeError(w, errResp)
+		return
+	}
+
+	utils.EncodeResponse(w, http.StatusOK, result)
+}
@@ -0,0 +1,470 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+const (
+	// searchPassageWords and searchPassageOverlap size the embedded passages. Passages
+	// overlap so a sentence cut at a boundary is still whole in the next passage.
+	searchPassageWords   = 120
+	searchPassageOverlap = 30
+	// embeddingBatchSize is the number of passages embedded per request
+	embeddingBatchSize = 64
+	// searchIndexRefresh reloads the index so embeddings written by other instances are found
+	searchIndexRefresh = 10 * time.Minute
+	// searchMinScore drops passages that are barely related to the query
+	searchMinScore = 0.2
+	// searchPassagesPerEpisode is the number of passages returned for each episode
+	searchPassagesPerEpisode = 3
+	searchTimeout            = 30 * time.Second
+	maxSearchQueryLength     = 500
+
+	DefaultSearchLimit = 10
+	MaxSearchLimit     = 50
+)
+
+// searchIndex holds the passage embeddings of every complete transcript in memory.
+// Vectors are normalized when added, so cosine similarity is a dot product.
+type searchIndex struct {
+	mu       sync.RWMutex
+	loadedAt time.Time
+	passages map[int][]TranscriptEmbedding // by transcript ID
+
+	loadMu      sync.Mutex
+	backfilling atomic.Bool
+}
+
+func newSearchIndex() *searchIndex {
+	return &searchIndex{passages: make(map[int][]TranscriptEmbedding)}
+}
+
+// stale reports whether the index was never loaded or is due for a refresh
+func (idx *searchIndex) stale() bool {
+	idx.mu.RLock()
+	defer idx.mu.RUnlock()
+	return time.Since(idx.loadedAt) > searchIndexRefresh
+}
+
+// load replaces the whole index
+func (idx *searchIndex) load(embeddings []TranscriptEmbedding) {
+	passages := make(map[int][]TranscriptEmbedding)
+	for _, embedding := range embeddings {
+		embedding.Embedding = normalize(embedding.Embedding)
+		passages[embedding.TranscriptID] = append(passages[embedding.TranscriptID], embedding)
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	idx.passages = passages
+	idx.loadedAt = time.Now()
+}
+
+// replace swaps the passages of one transcript, dropping those of earlier transcripts
+// of the same episode
+func (idx *searchIndex) replace(transcriptID, episodeID int, embeddings []TranscriptEmbedding) {
+	passages := make([]TranscriptEmbedding, len(embeddings))
+	for i, embedding := range embeddings {
+		embedding.Embedding = normalize(embedding.Embedding)
+		passages[i] = embedding
+	}
+
+	idx.mu.Lock()
+	defer idx.mu.Unlock()
+	for id, indexed := range idx.passages {
+		if id != transcriptID && len(indexed) > 0 && indexed[0].EpisodeID == episodeID {
+			delete(idx.passages, id)
+		}
+	}
+	idx.passages[transcriptID] = passages
+}
+
+// search returns the best passages of the episodes most similar to the query vector.
+// Overlapping passages of the same episode are skipped in favour of the better one.
+func (idx *searchIndex) search(query []float32, limit int) []EpisodeSearchResult {
+	query = normalize(query)
+
+	type scoredPassage struct {
+		TranscriptEmbedding
+		score float64
+	}
+
+	idx.mu.RLock()
+	byEpisode := make(map[int][]scoredPassage)
+	for _, passages := range idx.passages {
+		for _, passage := range passages {
+			// Vectors from another embedding model can't be compared
+			if len(passage.Embedding) != len(query) {
+				continue
+			}
+			score := dot(query, passage.Embedding)
+			if score < searchMinScore {
+				continue
+			}
+			byEpisode[passage.EpisodeID] = append(byEpisode[passage.EpisodeID], scoredPassage{passage, score})
+		}
+	}
+	idx.mu.RUnlock()
+
+	results := make([]EpisodeSearchResult, 0, len(byEpisode))
+	for episodeID, scored := range byEpisode {
+		sort.Slice(scored, func(i, j int) bool { return scored[i].score > scored[j].score })
+
+		result := EpisodeSearchResult{EpisodeID: episodeID, Score: scored[0].score, Passages: []SearchPassage{}}
+		for _, passage := range scored {
+			if len(result.Passages) == searchPassagesPerEpisode {
+				break
+			}
+			overlaps := false
+			for _, kept := range result.Passages {
+				if passage.StartPosition <= kept.EndPosition && kept.StartPosition <= passage.EndPosition {
+					overlaps = true
+					break
+				}
+			}
+			if overlaps {
+				continue
+			}
+			result.Passages = append(result.Passages, SearchPassage{
+				StartPosition: passage.StartPosition,
+				EndPosition:   passage.EndPosition,
+				Start:         passage.StartTime,
+				End:           passage.EndTime,
+				Text:          passage.Text,
+				Score:         passage.score,
+			})
+		}
+		results = append(results, result)
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		return results[i].EpisodeID < results[j].EpisodeID
+	})
+
+	return results[:min(limit, len(results))]
+}
+
+// SemanticSearch finds the episodes whose transcripts are closest in meaning to the query,
+// with their best timestamped passages
+func (s *Service) SemanticSearch(ctx context.Context, query string, limit int) (SemanticSearchResult, *errors.ErrorResponse) {
+	query = strings.TrimSpace(query)
+	if query == "" || len(query) > maxSearchQueryLength {
+		return SemanticSearchResult{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: fmt.Sprintf("q is required and must be at most %d characters", maxSearchQueryLength),
+		}
+	}
+
+	embedder, ok := s.embeddingClient()
+	if !ok || s.search == nil {
+		return SemanticSearchResult{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "Embedding client not configured",
+		}
+	}
+
+	if err := s.refreshSearchIndex(); err != nil {
+		return SemanticSearchResult{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to load the search index",
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, searchTimeout)
+	defer cancel()
+
+	response, err := embedder.Embed(ctx, llm.EmbeddingRequest{Input: []string{query}})
+	if err != nil {
+		s.log.Error("Failed to embed search query", map[string]any{
+			"error": err.Error(),
+		})
+		return SemanticSearchResult{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "Failed to embed the search query",
+		}
+	}
+
+	results := s.search.search(response.Data[0].Embedding, limit)
+
+	episodes := make([]EpisodeSearchResult, 0, len(results))
+	for _, result := range results {
+		episode, err := s.repo.GetEpisodeByID(result.EpisodeID)
+		if err != nil {
+			// The episode was deleted since the index was loaded
+			if err.Error() == "no rows in result set" {
+				continue
+			}
+			return SemanticSearchResult{}, &errors.ErrorResponse{
+				Message: errors.DatabaseError,
+				Details: "Failed to fetch episode",
+			}
+		}
+		result.EpisodeName, result.PodcastName = episode.Name, episode.PodcastName
+		episodes = append(episodes, result)
+	}
+
+	return SemanticSearchResult{Query: query, Episodes: episodes}, nil
+}
+
+// refreshSearchIndex reloads the index when it is stale and then indexes, in the
+// background, the complete transcripts that have no embeddings yet
+func (s *Service) refreshSearchIndex() error {
+	if !s.search.stale() {
+		return nil
+	}
+
+	s.search.loadMu.Lock()
+	defer s.search.loadMu.Unlock()
+
+	// Another request may have reloaded it while this one waited
+	if !s.search.stale() {
+		return nil
+	}
+
+	embeddings, err := s.repo.GetAllEmbeddings()
+	if err != nil {
+		return err
+	}
+	s.search.load(embeddings)
+
+	s.log.Info("Search index loaded", map[string]any{
+		"passages": len(embeddings),
+	})
+
+	if s.indexing != nil && s.search.backfilling.CompareAndSwap(false, true) {
+		go func() {
+			defer s.search.backfilling.Store(false)
+			s.backfillSearchIndex()
+		}()
+	}
+
+	return nil
+}
+
+// backfillSearchIndex indexes the unindexed transcripts one at a time, so a large
+// backlog doesn't flood the embedding API
+func (s *Service) backfillSearchIndex() {
+	transcripts, err := s.repo.GetUnindexedTranscripts()
+	if err != nil {
+		return
+	}
+
+	for _, transcript := range transcripts {
+		if err := s.indexTranscript(transcript.EpisodeID); err != nil {
+			s.log.Error("Failed to index transcript", map[string]any{
+				"episodeID": transcript.EpisodeID,
+				"error":     err.Error(),
+			})
+		}
+	}
+}
+
+// scheduleIndexing (re)indexes the transcript of an episode for semantic search in the background
+func (s *Service) scheduleIndexing(episodeID int) {
+	if _, ok := s.embeddingClient(); s.indexing == nil || !ok {
+		return
+	}
+
+	s.indexing.schedule(episodeID, func() {
+		if err := s.indexTranscript(episodeID); err != nil {
+			s.log.Error("Failed to index transcript", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+	})
+}
+
+// indexTranscript embeds the passages of a transcript that are new or whose text changed
+// since the last indexing, and removes the passages that no longer exist
+func (s *Service) indexTranscript(episodeID int) error {
+	embedder, ok := s.embeddingClient()
+	if !ok {
+		return fmt.Errorf("embedding client not configured")
+	}
+
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return fmt.Errorf("%s", errResp.Details)
+	}
+
+	chunks, err := s.repo.GetChunksByTranscriptID(transcript.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch chunks: %w", err)
+	}
+
+	stored, err := s.repo.GetEmbeddingsByTranscriptID(transcript.ID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch embeddings: %w", err)
+	}
+
+	existing := make(map[int]TranscriptEmbedding, len(stored))
+	for _, embedding := range stored {
+		existing[embedding.StartPosition] = embedding
+	}
+
+	passages := buildSearchPassages(transcript.ID, episodeID, chunks)
+	changed := []int{}
+	for i, passage := range passages {
+		previous, ok := existing[passage.StartPosition]
+		delete(existing, passage.StartPosition)
+		if ok && previous.EndPosition == passage.EndPosition && previous.Text == passage.Text {
+			passages[i] = previous
+			continue
+		}
+		changed = append(changed, i)
+	}
+
+	removed := make([]int, 0, len(existing))
+	for startPosition := range existing {
+		removed = append(removed, startPosition)
+	}
+	sort.Ints(removed)
+
+	if len(changed) == 0 && len(removed) == 0 {
+		return nil
+	}
+
+	s.log.Info("Indexing transcript", map[string]any{
+		"episodeID": episodeID,
+		"passages":  len(passages),
+		"changed":   len(changed),
+		"removed":   len(removed),
+	})
+
+	embedded := make([]TranscriptEmbedding, 0, len(changed))
+	for start := 0; start < len(changed); start += embeddingBatchSize {
+		batch := changed[start:min(start+embeddingBatchSize, len(changed))]
+
+		inputs := make([]string, len(batch))
+		for i, passage := range batch {
+			inputs[i] = passages[passage].Text
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), searchTimeout)
+		response, err := embedder.Embed(ctx, llm.EmbeddingRequest{Input: inputs})
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to embed passages: %w", err)
+		}
+
+		for i, passage := range batch {
+			passages[passage].Model = response.Model
+			passages[passage].Embedding = response.Data[i].Embedding
+			embedded = append(embedded, passages[passage])
+		}
+	}
+
+	if err := s.repo.SaveEmbeddings(embedded); err != nil {
+		return fmt.Errorf("failed to save embeddings: %w", err)
+	}
+	if err := s.repo.DeleteEmbeddings(transcript.ID, removed); err != nil {
+		return fmt.Errorf("failed to delete embeddings: %w", err)
+	}
+
+	if s.search != nil {
+		s.search.replace(transcript.ID, episodeID, passages)
+	}
+
+	return nil
+}
+
+// embeddingClient returns the LLM client when it can embed text
+func (s *Service) embeddingClient() (llm.EmbeddingClient, bool) {
+	if !s.llmAvailable() {
+		return nil, false
+	}
+	embedder, ok := s.llmClient.(llm.EmbeddingClient)
+	return embedder, ok
+}
+
+// buildSearchPassages splits the transcript into passages of searchPassageWords positions
+// that overlap by searchPassageOverlap. Passages follow positions rather than chunk
+// indexes, so removing a word only changes the passages around it.
+func buildSearchPassages(transcriptID, episodeID int, chunks []TranscriptChunk) []TranscriptEmbedding {
+	passages := []TranscriptEmbedding{}
+	if len(chunks) == 0 {
+		return passages
+	}
+
+	const stride = searchPassageWords - searchPassageOverlap
+	lastPosition := chunks[len(chunks)-1].Position
+
+	first := 0
+	for from := 0; ; from += stride {
+		to := from + searchPassageWords
+
+		for first < len(chunks) && chunks[first].Position < from {
+			first++
+		}
+		last := first
+		words := []string{}
+		for last < len(chunks) && chunks[last].Position < to {
+			words = append(words, chunks[last].Text)
+			last++
+		}
+
+		// After a long removed stretch two passages can start on the same word; the
+		// later one covers more of the transcript
+		if len(words) > 0 && len(passages) > 0 && passages[len(passages)-1].StartPosition == chunks[first].Position {
+			passages = passages[:len(passages)-1]
+		}
+		if len(words) > 0 {
+			passages = append(passages, TranscriptEmbedding{
+				TranscriptID:  transcriptID,
+				EpisodeID:     episodeID,
+				StartPosition: chunks[first].Position,
+				EndPosition:   chunks[last-1].Position,
+				StartTime:     chunks[first].StartTime,
+				EndTime:       chunks[last-1].EndTime,
+				Text:          strings.Join(words, " "),
+			})
+		}
+
+		// The tail is already covered by this passage
+		if to > lastPosition {
+			break
+		}
+	}
+
+	return passages
+}
+
+// normalize returns the vector scaled to unit length
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+
+	norm := math.Sqrt(sum)
+	normalized := make([]float32, len(vector))
+	for i, v := range vector {
+		normalized[i] = float32(float64(v) / norm)
+	}
+	return normalized
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
@@ -0,0 +1,315 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// keywordEmbeddingClient embeds text as counts of cooking and travel words, so tests
+// can reason about similarity
+type keywordEmbeddingClient struct {
+	MockLLMClient
+	inputs []string
+}
+
+func (m *keywordEmbeddingClient) Embed(ctx context.Context, req llm.EmbeddingRequest) (llm.EmbeddingResponse, error) {
+	m.inputs = append(m.inputs, req.Input...)
+
+	response := llm.EmbeddingResponse{Model: "test-embedding"}
+	for i, input := range req.Input {
+		vector := []float32{0, 0, 0.1}
+		for _, word := range strings.Fields(strings.ToLower(input)) {
+			switch word {
+			case "pasta", "flour", "cooking":
+				vector[0]++
+			case "lisbon", "flight", "travel":
+				vector[1]++
+			}
+		}
+		response.Data = append(response.Data, llm.Embedding{Index: i, Embedding: vector})
+	}
+	return response, nil
+}
+
+// setupSearchService mocks a complete transcript with the given chunks and stored
+// embeddings, and records the embeddings saved and the passages deleted
+func setupSearchService(chunks []TranscriptChunk, stored []TranscriptEmbedding) (*Service, *keywordEmbeddingClient, *[]TranscriptEmbedding, *[]int) {
+	client := &keywordEmbeddingClient{}
+	service, _ := setupSummaryService(client, chunks)
+	service.summaries = nil
+	var saved []TranscriptEmbedding
+	var deleted []int
+
+	service.repo.embeddingRepo.Executor = utils.QueryExecutor[TranscriptEmbedding]{
+		QueryList: func(query string, args ...any) ([]TranscriptEmbedding, error) {
+			return stored, nil
+		},
+		Exec: func(query string, args ...any) error {
+			if strings.Contains(query, "DELETE") {
+				deleted = append(deleted, args[1].([]int)...)
+				return nil
+			}
+			for i := 0; i < len(args); i += 9 {
+				saved = append(saved, TranscriptEmbedding{
+					StartPosition: args[i+2].(int),
+					Text:          args[i+6].(string),
+					Model:         args[i+7].(string),
+					Embedding:     args[i+8].([]float32),
+				})
+			}
+			return nil
+		},
+	}
+
+	return service, client, &saved, &deleted
+}
+
+func wordChunks(positions []int, word func(position int) string) []TranscriptChunk {
+	chunks := make([]TranscriptChunk, len(positions))
+	for i, position := range positions {
+		chunks[i] = TranscriptChunk{Position: position, StartTime: float64(position), EndTime: float64(position) + 0.5, Text: word(position)}
+	}
+	return chunks
+}
+
+func positionRange(from, to int) []int {
+	positions := []int{}
+	for position := from; position < to; position++ {
+		positions = append(positions, position)
+	}
+	return positions
+}
+
+func TestBuildSearchPassages(t *testing.T) {
+	t.Run("overlapping passages cover the transcript", func(t *testing.T) {
+		chunks := wordChunks(positionRange(0, 250), func(int) string { return "word" })
+
+		passages := buildSearchPassages(10, 1, chunks)
+
+		if len(passages) != 3 {
+			t.Fatalf("Expected 3 passages, got %d", len(passages))
+		}
+		bounds := [][2]int{{0, 119}, {90, 209}, {180, 249}}
+		for i, passage := range passages {
+			if passage.StartPosition != bounds[i][0] || passage.EndPosition != bounds[i][1] {
+				t.Errorf("Expected passage %d to cover %v, got %d-%d", i, bounds[i], passage.StartPosition, passage.EndPosition)
+			}
+		}
+		if passages[1].StartTime != 90 || passages[1].EndTime != 209.5 || passages[1].TranscriptID != 10 || passages[1].EpisodeID != 1 {
+			t.Errorf("Unexpected passage: %+v", passages[1])
+		}
+	})
+
+	t.Run("passages starting on the same word after a removed stretch are merged", func(t *testing.T) {
+		chunks := wordChunks(append(positionRange(0, 10), positionRange(200, 210)...), func(int) string { return "word" })
+
+		passages := buildSearchPassages(10, 1, chunks)
+
+		if len(passages) != 2 || passages[1].StartPosition != 200 || passages[1].EndPosition != 209 {
+			t.Errorf("Unexpected passages: %+v", passages)
+		}
+	})
+
+	t.Run("empty transcript", func(t *testing.T) {
+		if passages := buildSearchPassages(10, 1, nil); len(passages) != 0 {
+			t.Errorf("Expected no passages, got %+v", passages)
+		}
+	})
+}
+
+func TestSearchIndex_Search(t *testing.T) {
+	index := newSearchIndex()
+	index.load([]TranscriptEmbedding{
+		{TranscriptID: 10, EpisodeID: 1, StartPosition: 0, EndPosition: 119, Text: "pasta", Embedding: []float32{3, 0, 0}},
+		{TranscriptID: 10, EpisodeID: 1, StartPosition: 90, EndPosition: 209, Text: "overlapping pasta", Embedding: []float32{2, 1, 0}},
+		{TranscriptID: 10, EpisodeID: 1, StartPosition: 180, EndPosition: 299, Text: "more pasta", Embedding: []float32{1, 1, 0}},
+		{TranscriptID: 20, EpisodeID: 2, StartPosition: 0, EndPosition: 119, Text: "pasta in lisbon", Embedding: []float32{1, 2, 0}},
+		{TranscriptID: 30, EpisodeID: 3, StartPosition: 0, EndPosition: 119, Text: "lisbon", Embedding: []float32{0, 1, 0}},
+		{TranscriptID: 40, EpisodeID: 4, StartPosition: 0, EndPosition: 119, Text: "other model", Embedding: []float32{1, 0}},
+	})
+
+	results := index.search([]float32{1, 0, 0}, 10)
+
+	if len(results) != 2 || results[0].EpisodeID != 1 || results[1].EpisodeID != 2 {
+		t.Fatalf("Expected episodes 1 and 2, got %+v", results)
+	}
+	passages := results[0].Passages
+	if len(passages) != 2 || passages[0].Text != "pasta" || passages[1].Text != "more pasta" {
+		t.Errorf("Expected the overlapping passage to be skipped, got %+v", passages)
+	}
+	if results[0].Score < 0.99 || results[0].Score != passages[0].Score {
+		t.Errorf("Expected the episode score to be its best passage score, got %v", results[0].Score)
+	}
+
+	if results := index.search([]float32{1, 0, 0}, 1); len(results) != 1 {
+		t.Errorf("Expected the limit to apply, got %d results", len(results))
+	}
+
+	index.replace(11, 1, []TranscriptEmbedding{{TranscriptID: 11, EpisodeID: 1, Text: "lisbon", Embedding: []float32{0, 1, 0}}})
+	if results := index.search([]float32{1, 0, 0}, 10); len(results) != 1 || results[0].EpisodeID != 2 {
+		t.Errorf("Expected the earlier transcript of episode 1 to be dropped, got %+v", results)
+	}
+}
+
+func TestTranscriptService_IndexTranscript(t *testing.T) {
+	chunks := wordChunks(positionRange(0, 250), func(position int) string {
+		if position < 90 {
+			return "pasta"
+		}
+		return "lisbon"
+	})
+	current := buildSearchPassages(10, 1, chunks)
+
+	t.Run("embeds every passage of a new transcript", func(t *testing.T) {
+		service, client, saved, deleted := setupSearchService(chunks, nil)
+
+		if err := service.indexTranscript(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(*saved) != 3 || len(client.inputs) != 3 || len(*deleted) != 0 {
+			t.Errorf("Expected 3 passages to be embedded and saved, got %d saved and %d embedded", len(*saved), len(client.inputs))
+		}
+		if (*saved)[0].Model != "test-embedding" || len((*saved)[0].Embedding) != 3 {
+			t.Errorf("Unexpected saved embedding: %+v", (*saved)[0])
+		}
+	})
+
+	t.Run("only embeds changed passages and deletes removed ones", func(t *testing.T) {
+		stored := []TranscriptEmbedding{
+			{TranscriptID: 10, EpisodeID: 1, StartPosition: 0, EndPosition: 119, Text: "outdated text", Embedding: []float32{0, 0, 1}},
+			{TranscriptID: 10, EpisodeID: 1, StartPosition: 90, EndPosition: 209, Text: current[1].Text, Embedding: []float32{0, 1, 0}},
+			{TranscriptID: 10, EpisodeID: 1, StartPosition: 180, EndPosition: 249, Text: current[2].Text, Embedding: []float32{0, 1, 0}},
+			{TranscriptID: 10, EpisodeID: 1, StartPosition: 270, EndPosition: 389, Text: "gone", Embedding: []float32{0, 1, 0}},
+		}
+		service, client, saved, deleted := setupSearchService(chunks, stored)
+
+		if err := service.indexTranscript(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(client.inputs) != 1 || len(*saved) != 1 || (*saved)[0].StartPosition != 0 {
+			t.Errorf("Expected only the first passage to be embedded, got %+v", *saved)
+		}
+		if len(*deleted) != 1 || (*deleted)[0] != 270 {
+			t.Errorf("Expected the passage at 270 to be deleted, got %v", *deleted)
+		}
+
+		results := service.search.search([]float32{1, 0, 0}, 10)
+		if len(results) != 1 || results[0].Passages[0].StartPosition != 0 {
+			t.Errorf("Expected the index to hold the new embedding, got %+v", results)
+		}
+	})
+
+	t.Run("does nothing when the transcript did not change", func(t *testing.T) {
+		service, client, saved, _ := setupSearchService(chunks, current)
+
+		if err := service.indexTranscript(1); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		if len(client.inputs) != 0 || len(*saved) != 0 {
+			t.Errorf("Expected no embedding requests, got %d", len(client.inputs))
+		}
+	})
+}
+
+func TestTranscriptService_SemanticSearch(t *testing.T) {
+	stored := []TranscriptEmbedding{
+		{TranscriptID: 10, EpisodeID: 1, StartPosition: 0, EndPosition: 119, StartTime: 12, EndTime: 60, Text: "we made fresh pasta", Embedding: []float32{1, 0, 0}},
+		{TranscriptID: 20, EpisodeID: 2, StartPosition: 0, EndPosition: 119, Text: "a weekend in lisbon", Embedding: []float32{0, 1, 0}},
+	}
+
+	t.Run("returns matching episodes with timestamped passages", func(t *testing.T) {
+		service, _, _, _ := setupSearchService(nil, stored)
+
+		result, errResp := service.SemanticSearch(context.Background(), " cooking ", DefaultSearchLimit)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if result.Query != "cooking" || len(result.Episodes) != 1 {
+			t.Fatalf("Expected one episode, got %+v", result)
+		}
+		episode := result.Episodes[0]
+		if episode.EpisodeID != 1 || episode.EpisodeName != "Pilot" || episode.Passages[0].Start != 12 || episode.Passages[0].End != 60 {
+			t.Errorf("Unexpected episode: %+v", episode)
+		}
+	})
+
+	t.Run("rejects an empty query", func(t *testing.T) {
+		service, _, _, _ := setupSearchService(nil, stored)
+
+		_, errResp := service.SemanticSearch(context.Background(), "  ", DefaultSearchLimit)
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError {
+			t.Errorf("Expected a validation error, got %v", errResp)
+		}
+	})
+
+	t.Run("requires an embedding client", func(t *testing.T) {
+		service := setupService()
+
+		_, errResp := service.SemanticSearch(context.Background(), "cooking", DefaultSearchLimit)
+
+		if errResp == nil || errResp.Message != cribeErrors.ExternalAPIError {
+			t.Errorf("Expected an external API error, got %v", errResp)
+		}
+	})
+
+	t.Run("fails when the index can't be loaded", func(t *testing.T) {
+		service, _, _, _ := setupSearchService(nil, stored)
+		service.repo.embeddingRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptEmbedding, error) {
+			return nil, fmt.Errorf("connection refused")
+		}
+
+		_, errResp := service.SemanticSearch(context.Background(), "cooking", DefaultSearchLimit)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseError {
+			t.Errorf("Expected a database error, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptHandler_SemanticSearch(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"search", http.MethodGet, "/search/semantic?q=pasta", http.StatusOK, `"episode_name":"Pilot"`},
+		{"missing query", http.MethodGet, "/search/semantic", http.StatusBadRequest, ""},
+		{"invalid limit", http.MethodGet, "/search/semantic?q=pasta&limit=0", http.StatusBadRequest, "limit"},
+		{"wrong method", http.MethodPost, "/search/semantic?q=pasta", http.StatusMethodNotAllowed, ""},
+		{"unknown search", http.MethodGet, "/search/keyword?q=pasta", http.StatusNotFound, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _, _, _ := setupSearchService(nil, []TranscriptEmbedding{
+				{TranscriptID: 10, EpisodeID: 1, Text: "pasta", Embedding: []float32{1, 0, 0}},
+			})
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			handler.HandleRequest(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
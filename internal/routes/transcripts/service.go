@@ -3,7 +3,6 @@ package transcripts
 import (
 	"context"
 	"fmt"
-	"reflect"
 	"strings"
 	"sync"
 	"time"
@@ -11,11 +10,12 @@ import (
 	"cribeapp.com/cribe-server/internal/clients/llm"
 	"cribeapp.com/cribe-server/internal/clients/transcription"
 	"cribeapp.com/cribe-server/internal/core/logger"
+	"cribeapp.com/cribe-server/internal/redaction"
 )
 
 // TranscriptionClientInterface defines the contract for transcription clients
 type TranscriptionClientInterface interface {
-	StreamAudioURL(ctx context.Context, audioURL string, callback transcription.StreamCallback) error
+	StreamAudioURL(ctx context.Context, audioURL string, opts transcription.StreamOptions, callback transcription.StreamCallback) error
 }
 
 // Service handles transcript business logic
@@ -23,23 +23,40 @@ type Service struct {
 	repo                *TranscriptRepository
 	transcriptionClient TranscriptionClientInterface
 	llmClient           llm.LLMClient
-	log                 *logger.ContextualLogger
+	// summaries, chapters, highlights, indexing, translations and analytics are nil when
+	// background generation is disabled
+	summaries    *generationQueue[int]
+	chapters     *generationQueue[int]
+	highlights   *generationQueue[int]
+	indexing     *generationQueue[int]
+	translations *generationQueue[translationKey]
+	analytics    *generationQueue[int]
+	search       *searchIndex
+	log          *logger.ContextualLogger
 }
 
-// NewService creates a new transcript service
-func NewService(transcriptionClient TranscriptionClientInterface, llmClient llm.LLMClient) *Service {
+// NewService creates a new transcript service on the repository, which the process shares
+// with the reaper and the quizzes
+func NewService(repo *TranscriptRepository, transcriptionClient TranscriptionClientInterface, llmClient llm.LLMClient) *Service {
 	return &Service{
-		repo:                NewTranscriptRepository(),
+		repo:                repo,
 		transcriptionClient: transcriptionClient,
 		llmClient:           llmClient,
+		summaries:           newGenerationQueue[int](),
+		chapters:            newGenerationQueue[int](),
+		highlights:          newGenerationQueue[int](),
+		indexing:            newGenerationQueue[int](),
+		translations:        newGenerationQueue[translationKey](),
+		analytics:           newGenerationQueue[int](),
+		search:              newSearchIndex(),
 		log:                 logger.NewServiceLogger("TranscriptService"),
 	}
 }
 
 // inferSpeakerName uses LLM to infer speaker name with proper timeout handling
 func (s *Service) inferSpeakerName(episodeDescription string, speakerIndex int, transcriptChunks []string) (string, error) {
-	// If LLM client is not available (nil interface or nil pointer), return default speaker name
-	if s.llmClient == nil || reflect.ValueOf(s.llmClient).IsNil() {
+	// If LLM client is not available, return default speaker name
+	if !s.llmAvailable() {
 		return fmt.Sprintf("Speaker %d", speakerIndex), nil
 	}
 
@@ -87,8 +104,9 @@ type ChunkCallback func(chunk *Chunk) error
 // SpeakerCallback is called when a speaker is identified
 type SpeakerCallback func(speaker *Speaker) error
 
-// StreamTranscript streams a transcript for an episode
-func (s *Service) StreamTranscript(ctx context.Context, episodeID int, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
+// StreamTranscript streams a transcript for an episode. The requested preferences only
+// apply when the episode is transcribed; cached transcripts are replayed as stored.
+func (s *Service) StreamTranscript(ctx context.Context, episodeID int, requested transcription.Preferences, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
 	s.log.Info("Starting transcript stream", map[string]any{
 		"episodeID": episodeID,
 	})
@@ -124,12 +142,17 @@ func (s *Service) StreamTranscript(ctx context.Context, episodeID int, chunkCB C
 		return fmt.Errorf("failed to get episode: %w", err)
 	}
 
+	opts := buildStreamOptions(episode, requested)
+
 	s.log.Info("Streaming from transcription API", map[string]any{
 		"audioURL": episode.AudioURL,
+		"model":    opts.Model,
+		"language": opts.Language,
+		"keyterms": len(opts.Keyterms),
 	})
 
 	// Stream from transcription API and save to DB
-	return s.streamFromTranscriptionAPI(ctx, episodeID, episode.AudioURL, episode.Description, chunkCB, speakerCB)
+	return s.streamFromTranscriptionAPI(ctx, episodeID, episode.AudioURL, episode.Description, opts, episode.RedactionPolicy, chunkCB, speakerCB)
 }
 
 // getExistingTranscript checks if a transcript exists for the episode
@@ -147,14 +170,18 @@ func (s *Service) getExistingTranscript(episodeID int) (int, bool, error) {
 	return transcript.ID, transcript.Status == string(TranscriptStatusComplete), nil
 }
 
-// createTranscript creates a new transcript record
-func (s *Service) createTranscript(episodeID int) (int, error) {
-	return s.repo.CreateTranscript(episodeID)
+// createTranscript claims the transcript record of an episode with the options used to produce it,
+// failing when another caller is already transcribing the episode
+func (s *Service) createTranscript(episodeID int, opts transcription.StreamOptions) (int, error) {
+	transcriptID, err := s.repo.CreateTranscript(episodeID, opts)
+	if err != nil && err.Error() == "no rows in result set" {
+		return 0, fmt.Errorf("episode transcription is already in progress")
+	}
+	return transcriptID, err
 }
 
-// streamFromDB streams a cached transcript from the database
-func (s *Service) streamFromDB(transcriptID int, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
-	// First, send all speakers
+// streamSpeakersFromDB sends the stored speakers of a transcript
+func (s *Service) streamSpeakersFromDB(transcriptID int, speakerCB SpeakerCallback) error {
 	speakers, err := s.repo.GetSpeakersByTranscriptID(transcriptID)
 	if err != nil {
 		return fmt.Errorf("failed to get speakers: %w", err)
@@ -167,8 +194,9 @@ func (s *Service) streamFromDB(transcriptID int, chunkCB ChunkCallback, speakerC
 
 	for _, speaker := range speakers {
 		if err := speakerCB(&Speaker{
-			Index: speaker.SpeakerIndex,
-			Name:  speaker.SpeakerName,
+			Index:      speaker.SpeakerIndex,
+			Name:       speaker.SpeakerName,
+			IsHumanSet: speaker.IsHumanSet,
 		}); err != nil {
 			s.log.Error("Failed to send speaker callback", map[string]any{
 				"error":        err.Error(),
@@ -178,6 +206,16 @@ func (s *Service) streamFromDB(transcriptID int, chunkCB ChunkCallback, speakerC
 		}
 	}
 
+	return nil
+}
+
+// streamFromDB streams a cached transcript from the database
+func (s *Service) streamFromDB(transcriptID int, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
+	// First, send all speakers
+	if err := s.streamSpeakersFromDB(transcriptID, speakerCB); err != nil {
+		return err
+	}
+
 	// Then stream all chunks
 	chunks, err := s.repo.GetChunksByTranscriptID(transcriptID)
 	if err != nil {
@@ -202,6 +240,7 @@ func (s *Service) streamFromDB(transcriptID int, chunkCB ChunkCallback, speakerC
 				Start:        chunk.StartTime,
 				End:          chunk.EndTime,
 				Text:         chunk.Text,
+				Confidence:   chunk.Confidence,
 			}); err != nil {
 				s.log.Error("Failed to send chunk callback", map[string]any{
 					"error":    err.Error(),
@@ -218,16 +257,35 @@ func (s *Service) streamFromDB(transcriptID int, chunkCB ChunkCallback, speakerC
 	}
 
 	s.log.Info("Completed streaming from DB", map[string]any{
-		"transcriptID":  transcriptID,
-		"totalChunks":   len(chunks),
-		"totalSpeakers": len(speakers),
+		"transcriptID": transcriptID,
+		"totalChunks":  len(chunks),
 	})
 
 	return nil
 }
 
-// streamFromTranscriptionAPI streams from transcription API and saves to DB
-func (s *Service) streamFromTranscriptionAPI(ctx context.Context, episodeId int, audioURL, episodeDesc string, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
+// streamFromTranscriptionAPI claims the transcript of an episode, then streams it from the
+// transcription API and saves it to DB
+func (s *Service) streamFromTranscriptionAPI(ctx context.Context, episodeId int, audioURL, episodeDesc string, opts transcription.StreamOptions, policy redaction.Policy, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
+	if s.transcriptionClient == nil {
+		return fmt.Errorf("transcription provider not configured")
+	}
+
+	transcriptID, err := s.createTranscript(episodeId, opts)
+
+	if err != nil {
+		s.log.Error("Failed to create transcript record", map[string]any{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to create transcript record: %w", err)
+	}
+
+	return s.transcribe(ctx, transcriptID, episodeId, audioURL, episodeDesc, opts, policy, chunkCB, speakerCB)
+}
+
+// transcribe streams a claimed transcript from the transcription API and saves it to DB. Chunks
+// are redacted per the podcast policy before they are streamed or saved.
+func (s *Service) transcribe(ctx context.Context, transcriptID, episodeId int, audioURL, episodeDesc string, opts transcription.StreamOptions, policy redaction.Policy, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
 	const (
 		minSamplesForInference = 50 // Min words before inferring speaker name
 	)
@@ -239,19 +297,16 @@ func (s *Service) streamFromTranscriptionAPI(ctx context.Context, episodeId int,
 		position        = 0
 		mu              sync.Mutex
 		speakerChunks   = make(map[int][]string)
+		redactor        = redaction.New(policy)
 	)
 
-	transcriptID, err := s.createTranscript(episodeId)
-
-	if err != nil {
-		s.log.Error("Failed to create transcript record", map[string]any{
-			"error": err.Error(),
-		})
-		return fmt.Errorf("failed to create transcript record: %w", err)
-	}
-
 	// Stream from transcription API
-	err = s.transcriptionClient.StreamAudioURL(ctx, audioURL, func(response *transcription.StreamResponse) error {
+	lastHeartbeat := time.Now()
+	err := s.transcriptionClient.StreamAudioURL(ctx, audioURL, opts, func(response *transcription.StreamResponse) error {
+		if err := s.keepTranscriptAlive(transcriptID, &lastHeartbeat); err != nil {
+			return err
+		}
+
 		if len(response.Channel.Alternatives) == 0 {
 			return nil
 		}
@@ -261,20 +316,28 @@ func (s *Service) streamFromTranscriptionAPI(ctx context.Context, episodeId int,
 			return nil
 		}
 
-		for _, word := range words {
-			chunk := Chunk{
-				Position:     position,
+		// Redact the whole response at once so matches spanning several words are caught
+		batch := make([]Chunk, len(words))
+		for i, word := range words {
+			batch[i] = Chunk{
 				SpeakerIndex: word.Speaker,
 				Start:        word.Start,
 				End:          word.End,
 				Text:         word.PunctuatedWord,
+				Confidence:   wordConfidence(word),
 			}
+		}
+		redactChunks(batch, redactor.Redact)
+
+		for i, word := range words {
+			chunk := batch[i]
+			chunk.Position = position
 
 			mu.Lock()
 			chunks = append(chunks, chunk)
 			position++
 
-			speakerChunks[word.Speaker] = append(speakerChunks[word.Speaker], word.PunctuatedWord)
+			speakerChunks[word.Speaker] = append(speakerChunks[word.Speaker], chunk.Text)
 
 			if !speakersSeen[word.Speaker] {
 				speakersSeen[word.Speaker] = true
@@ -402,18 +465,47 @@ func (s *Service) streamFromTranscriptionAPI(ctx context.Context, episodeId int,
 
 	// Streaming completed successfully, save to DB in background
 	// Pass speakerInferred map to skip already-inferred speakers
-	go s.saveTranscriptInBackground(transcriptID, chunks, speakerChunks, episodeDesc, speakerInferred)
+	go s.saveTranscriptInBackground(episodeId, transcriptID, chunks, speakerChunks, episodeDesc, speakerInferred, policy)
+
+	return nil
+}
+
+// keepTranscriptAlive touches the transcript once per heartbeat interval while it streams.
+// It fails when the transcript is no longer processing, which stops the transcription;
+// other errors are only logged so a database hiccup doesn't end a long transcription.
+func (s *Service) keepTranscriptAlive(transcriptID int, lastHeartbeat *time.Time) error {
+	if time.Since(*lastHeartbeat) < TranscriptHeartbeatInterval {
+		return nil
+	}
+
+	err := s.repo.TouchTranscript(transcriptID)
+	if err != nil && err.Error() == "no rows in result set" {
+		return fmt.Errorf("transcript %d is no longer processing", transcriptID)
+	}
+	*lastHeartbeat = time.Now()
 
 	return nil
 }
 
 // saveTranscriptInBackground saves chunks and infers speaker names in the background
-func (s *Service) saveTranscriptInBackground(transcriptID int, chunks []Chunk, speakerChunks map[int][]string, episodeDesc string, speakerInferred map[int]bool) {
+func (s *Service) saveTranscriptInBackground(episodeID, transcriptID int, chunks []Chunk, speakerChunks map[int][]string, episodeDesc string, speakerInferred map[int]bool, policy redaction.Policy) {
 	s.log.Info("Saving transcript to DB in background", map[string]any{
 		"transcriptID": transcriptID,
 		"totalChunks":  len(chunks),
 	})
 
+	// The reaper may have failed the transcript while it was streaming; its chunks would
+	// then belong to a failed transcript
+	if err := s.repo.TouchTranscript(transcriptID); err != nil {
+		s.log.Warn("Transcript is no longer processing, discarding its chunks", map[string]any{
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
+		})
+		return
+	}
+
+	s.redactTranscript(transcriptID, chunks, policy)
+
 	// Save chunks to DB using batched inserts to reduce connection time
 	if err := s.repo.SaveChunksBatched(transcriptID, chunks); err != nil {
 		s.log.Error("Failed to save chunks", map[string]any{
@@ -475,17 +567,28 @@ func (s *Service) saveTranscriptInBackground(transcriptID int, chunks []Chunk, s
 	}
 	wg.Wait()
 
-	// Update transcript status
-	if err := s.repo.UpdateTranscriptStatus(transcriptID, TranscriptStatusComplete, ""); err != nil {
+	// Complete the transcript unless it was failed meanwhile, in which case its saved
+	// content is removed so the episode can be transcribed again
+	if err := s.repo.CompleteTranscript(transcriptID); err != nil {
 		s.log.Error("Failed to update transcript status", map[string]any{
-			"error": err.Error(),
+			"transcriptID": transcriptID,
+			"error":        err.Error(),
 		})
+		if err.Error() == "no rows in result set" {
+			_ = s.repo.DeletePartialTranscript(transcriptID)
+		}
 		return
 	}
 
 	s.log.Info("Transcript saved successfully", map[string]any{
 		"transcriptID": transcriptID,
 	})
+
+	s.scheduleSummary(episodeID)
+	s.scheduleChapters(episodeID)
+	s.scheduleHighlights(episodeID)
+	s.scheduleIndexing(episodeID)
+	s.scheduleAnalytics(episodeID)
 }
 
 // buildSpeakerContexts creates context-aware samples for each speaker by including
@@ -9,12 +9,17 @@ import (
 
 	"cribeapp.com/cribe-server/internal/clients/llm"
 	"cribeapp.com/cribe-server/internal/clients/transcription"
+	"cribeapp.com/cribe-server/internal/redaction"
 	"cribeapp.com/cribe-server/internal/utils"
 )
 
 // Test helpers
+// setupService creates a service with background summaries and chapters disabled, so
+// tests that complete or correct a transcript don't need to mock their queries
 func setupService() *Service {
-	return NewService(&MockTranscriptionClient{}, &MockLLMClient{})
+	service := NewService(NewTranscriptRepository(), &MockTranscriptionClient{}, &MockLLMClient{})
+	service.summaries, service.chapters, service.highlights, service.indexing, service.translations, service.analytics = nil, nil, nil, nil, nil, nil
+	return service
 }
 
 func setupMockRepos(service *Service, transcriptExists bool) {
@@ -63,7 +68,7 @@ func TestTranscriptService_StreamTranscript(t *testing.T) {
 			setupMockRepos(service, tt.transcriptExists)
 
 			chunkCount := 0
-			err := service.StreamTranscript(context.Background(), 1,
+			err := service.StreamTranscript(context.Background(), 1, transcription.Preferences{},
 				func(chunk *Chunk) error {
 					chunkCount++
 					return nil
@@ -83,12 +88,12 @@ func TestTranscriptService_StreamTranscript(t *testing.T) {
 
 type mockFailingTranscriptionClient struct{}
 
-func (m *mockFailingTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, callback transcription.StreamCallback) error {
+func (m *mockFailingTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, opts transcription.StreamOptions, callback transcription.StreamCallback) error {
 	return fmt.Errorf("transcription API error")
 }
 
 func TestTranscriptService_ErrorHandling(t *testing.T) {
-	service := NewService(&mockFailingTranscriptionClient{}, &MockLLMClient{})
+	service := NewService(NewTranscriptRepository(), &mockFailingTranscriptionClient{}, &MockLLMClient{})
 	statusUpdated := false
 
 	service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
@@ -106,7 +111,7 @@ func TestTranscriptService_ErrorHandling(t *testing.T) {
 		},
 	}
 
-	err := service.streamFromTranscriptionAPI(context.Background(), 1, "test.mp3", "Test",
+	err := service.streamFromTranscriptionAPI(context.Background(), 1, "test.mp3", "Test", transcription.DefaultStreamOptions(), redaction.Policy{},
 		func(chunk *Chunk) error { return nil },
 		func(speaker *Speaker) error { return nil },
 	)
@@ -140,12 +145,16 @@ func TestTranscriptService_BuildEarlySpeakerContext(t *testing.T) {
 }
 
 func TestTranscriptService_EarlyInference(t *testing.T) {
-	service := NewService(&customMockTranscriptionClient{wordCount: 60}, &MockLLMClient{})
+	service := NewService(NewTranscriptRepository(), &customMockTranscriptionClient{wordCount: 60}, &MockLLMClient{})
 	setupMockRepos(service, false)
 
 	var speakerNames []string
-	err := service.streamFromTranscriptionAPI(context.Background(), 1, "test.mp3", "Test episode",
-		func(chunk *Chunk) error { return nil },
+	var chunks []Chunk
+	err := service.streamFromTranscriptionAPI(context.Background(), 1, "test.mp3", "Test episode", transcription.DefaultStreamOptions(), redaction.Policy{},
+		func(chunk *Chunk) error {
+			chunks = append(chunks, *chunk)
+			return nil
+		},
 		func(speaker *Speaker) error {
 			speakerNames = append(speakerNames, speaker.Name)
 			return nil
@@ -164,13 +173,16 @@ func TestTranscriptService_EarlyInference(t *testing.T) {
 	if len(speakerNames) >= 1 && speakerNames[0] != "Speaker 0" {
 		t.Errorf("got first speaker '%s', want 'Speaker 0'", speakerNames[0])
 	}
+	if len(chunks) == 0 || chunks[0].Confidence == nil || *chunks[0].Confidence != 0.91 {
+		t.Errorf("want word confidence on the first chunk, got %+v", chunks)
+	}
 }
 
 type customMockTranscriptionClient struct {
 	wordCount int
 }
 
-func (m *customMockTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, callback transcription.StreamCallback) error {
+func (m *customMockTranscriptionClient) StreamAudioURL(ctx context.Context, audioURL string, opts transcription.StreamOptions, callback transcription.StreamCallback) error {
 	for i := 0; i < m.wordCount; i++ {
 		if err := callback(&transcription.StreamResponse{
 			Type: "Results",
@@ -181,6 +193,7 @@ func (m *customMockTranscriptionClient) StreamAudioURL(ctx context.Context, audi
 						PunctuatedWord: fmt.Sprintf("word%d", i),
 						Start:          float64(i),
 						End:            float64(i) + 0.5,
+						Confidence:     0.91,
 						Speaker:        0,
 					}},
 				}},
@@ -197,6 +210,9 @@ func TestTranscriptService_SaveChunksBatchedError(t *testing.T) {
 	var statusUpdateError string
 
 	service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+		QueryItem: func(query string, args ...any) (Transcript, error) {
+			return Transcript{ID: 1}, nil
+		},
 		Exec: func(query string, args ...any) error {
 			if len(args) >= 3 {
 				// Check for failed status update (3 args: status, errorMessage, transcriptID)
@@ -215,9 +231,9 @@ func TestTranscriptService_SaveChunksBatchedError(t *testing.T) {
 		},
 	}
 
-	service.saveTranscriptInBackground(1,
+	service.saveTranscriptInBackground(1, 1,
 		[]Chunk{{Position: 0, Text: "Test", SpeakerIndex: 0, Start: 0.0, End: 1.0}},
-		make(map[int][]string), "test", make(map[int]bool),
+		make(map[int][]string), "test", make(map[int]bool), redaction.Policy{},
 	)
 
 	if statusUpdateError != "database connection error" {
@@ -225,11 +241,101 @@ func TestTranscriptService_SaveChunksBatchedError(t *testing.T) {
 	}
 }
 
+func TestTranscriptService_keepTranscriptAlive(t *testing.T) {
+	tests := []struct {
+		name          string
+		sinceLast     time.Duration
+		touchErr      error
+		wantTouched   bool
+		wantErr       bool
+		wantHeartbeat bool
+	}{
+		{"not due", time.Second, nil, false, false, false},
+		{"due and processing", TranscriptHeartbeatInterval, nil, true, false, true},
+		{"due and reaped", TranscriptHeartbeatInterval, fmt.Errorf("no rows in result set"), true, true, false},
+		{"due with a database error", TranscriptHeartbeatInterval, fmt.Errorf("connection reset"), true, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := setupService()
+			touched := false
+			service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+				QueryItem: func(query string, args ...any) (Transcript, error) {
+					touched = strings.Contains(query, "status = 'processing'")
+					return Transcript{ID: 1}, tt.touchErr
+				},
+			}
+			last := time.Now().Add(-tt.sinceLast)
+			previous := last
+
+			err := service.keepTranscriptAlive(1, &last)
+
+			if (err != nil) != tt.wantErr || touched != tt.wantTouched {
+				t.Errorf("got err=%v touched=%v, want err=%v touched=%v", err, touched, tt.wantErr, tt.wantTouched)
+			}
+			if last.After(previous) != tt.wantHeartbeat {
+				t.Errorf("got heartbeat moved=%v, want %v", last.After(previous), tt.wantHeartbeat)
+			}
+		})
+	}
+}
+
+func TestTranscriptService_SaveTranscriptAfterReap(t *testing.T) {
+	tests := []struct {
+		name         string
+		reapedBefore bool
+		wantSaved    bool
+		wantDeleted  bool
+	}{
+		{"reaped while streaming", true, false, false},
+		{"reaped while saving", false, true, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := setupService()
+			var saved, deleted bool
+			service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+				QueryItem: func(query string, args ...any) (Transcript, error) {
+					if strings.Contains(query, "status = 'complete'") || tt.reapedBefore {
+						return Transcript{}, fmt.Errorf("no rows in result set")
+					}
+					return Transcript{ID: 1}, nil
+				},
+			}
+			service.repo.chunkRepo.Executor = utils.QueryExecutor[TranscriptChunk]{
+				Exec: func(query string, args ...any) error {
+					if strings.HasPrefix(query, "DELETE") {
+						deleted = true
+					} else {
+						saved = true
+					}
+					return nil
+				},
+			}
+			service.repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
+				Exec: func(query string, args ...any) error { return nil },
+			}
+
+			service.saveTranscriptInBackground(1, 1,
+				[]Chunk{{Position: 0, Text: "Test", SpeakerIndex: 0, Start: 0.0, End: 1.0}},
+				make(map[int][]string), "test", map[int]bool{0: true}, redaction.Policy{},
+			)
+
+			if saved != tt.wantSaved || deleted != tt.wantDeleted {
+				t.Errorf("got saved=%v deleted=%v, want saved=%v deleted=%v", saved, deleted, tt.wantSaved, tt.wantDeleted)
+			}
+		})
+	}
+}
+
 func TestTranscriptService_SpeakerInferenceError(t *testing.T) {
 	t.Run("continues after speaker inference failure", func(t *testing.T) {
 		// Create a mock LLM client that fails
 		failingLLM := &mockFailingLLMClient{shouldFail: true}
-		service := NewService(&MockTranscriptionClient{}, failingLLM)
+		service := NewService(NewTranscriptRepository(), &MockTranscriptionClient{}, failingLLM)
+		service.summaries, service.chapters, service.highlights, service.indexing, service.translations, service.analytics = nil, nil, nil, nil, nil, nil
 
 		var upsertCalled bool
 		service.repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
@@ -239,6 +345,9 @@ func TestTranscriptService_SpeakerInferenceError(t *testing.T) {
 			},
 		}
 		service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				return Transcript{ID: 1}, nil
+			},
 			Exec: func(query string, args ...any) error {
 				return nil
 			},
@@ -254,7 +363,7 @@ func TestTranscriptService_SpeakerInferenceError(t *testing.T) {
 			{Position: 0, Text: "Test", SpeakerIndex: 0, Start: 0.0, End: 1.0},
 			{Position: 1, Text: "More", SpeakerIndex: 0, Start: 1.0, End: 2.0},
 		}
-		service.saveTranscriptInBackground(1, chunks, make(map[int][]string), "test", make(map[int]bool))
+		service.saveTranscriptInBackground(1, 1, chunks, make(map[int][]string), "test", make(map[int]bool), redaction.Policy{})
 
 		// Wait for goroutine to complete
 		time.Sleep(100 * time.Millisecond)
@@ -278,6 +387,9 @@ func TestTranscriptService_UpsertSpeakerError(t *testing.T) {
 			},
 		}
 		service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				return Transcript{ID: 1}, nil
+			},
 			Exec: func(query string, args ...any) error {
 				return nil
 			},
@@ -293,7 +405,7 @@ func TestTranscriptService_UpsertSpeakerError(t *testing.T) {
 			{Position: 0, Text: "Test word1", SpeakerIndex: 0, Start: 0.0, End: 1.0},
 			{Position: 1, Text: "Test word2", SpeakerIndex: 0, Start: 1.0, End: 2.0},
 		}
-		service.saveTranscriptInBackground(1, chunks, make(map[int][]string), "test", make(map[int]bool))
+		service.saveTranscriptInBackground(1, 1, chunks, make(map[int][]string), "test", make(map[int]bool), redaction.Policy{})
 
 		// Wait for goroutine to complete
 		time.Sleep(100 * time.Millisecond)
@@ -321,6 +433,9 @@ func TestTranscriptService_SpeakerInferenceWithRetry(t *testing.T) {
 			},
 		}
 		service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				return Transcript{ID: 1}, nil
+			},
 			Exec: func(query string, args ...any) error {
 				return nil
 			},
@@ -336,7 +451,7 @@ func TestTranscriptService_SpeakerInferenceWithRetry(t *testing.T) {
 			{Position: 0, Text: "Hello from speaker", SpeakerIndex: 0, Start: 0.0, End: 1.0},
 			{Position: 1, Text: "More from speaker", SpeakerIndex: 0, Start: 1.0, End: 2.0},
 		}
-		service.saveTranscriptInBackground(1, chunks, make(map[int][]string), "test", make(map[int]bool))
+		service.saveTranscriptInBackground(1, 1, chunks, make(map[int][]string), "test", make(map[int]bool), redaction.Policy{})
 
 		// Wait for goroutine to complete
 		time.Sleep(100 * time.Millisecond)
@@ -361,6 +476,9 @@ func TestTranscriptService_SpeakerInferenceWithRetry(t *testing.T) {
 			},
 		}
 		service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+			QueryItem: func(query string, args ...any) (Transcript, error) {
+				return Transcript{ID: 1}, nil
+			},
 			Exec: func(query string, args ...any) error {
 				return nil
 			},
@@ -378,7 +496,7 @@ func TestTranscriptService_SpeakerInferenceWithRetry(t *testing.T) {
 		}
 		speakerInferred := map[int]bool{0: true} // Speaker 0 already inferred
 
-		service.saveTranscriptInBackground(1, chunks, make(map[int][]string), "test", speakerInferred)
+		service.saveTranscriptInBackground(1, 1, chunks, make(map[int][]string), "test", speakerInferred, redaction.Policy{})
 
 		// Wait for goroutines to complete
 		time.Sleep(100 * time.Millisecond)
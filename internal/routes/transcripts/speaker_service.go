@@ -0,0 +1,159 @@
+package transcripts
+
+import (
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// getTranscript fetches the transcript of an episode, mapping a missing row to not found
+func (s *Service) getTranscript(episodeID int) (Transcript, *errors.ErrorResponse) {
+	transcript, err := s.repo.GetTranscriptByEpisodeID(episodeID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return Transcript{}, &errors.ErrorResponse{
+				Message: errors.DatabaseNotFound,
+				Details: "Transcript not found for this episode",
+			}
+		}
+		return Transcript{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch transcript",
+		}
+	}
+
+	return transcript, nil
+}
+
+// getEditableTranscript fetches the transcript of an episode and ensures it is complete,
+// since edits to a processing transcript would be overwritten by the background save
+func (s *Service) getEditableTranscript(episodeID int) (Transcript, *errors.ErrorResponse) {
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return Transcript{}, errResp
+	}
+
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return Transcript{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Transcript must be complete before it can be edited",
+		}
+	}
+
+	return transcript, nil
+}
+
+// RenameSpeaker sets a human-provided name for a speaker of an episode transcript
+func (s *Service) RenameSpeaker(episodeID, speakerIndex, userID int, name string) (Speaker, *errors.ErrorResponse) {
+	s.log.Info("Renaming speaker", map[string]any{
+		"episodeID":    episodeID,
+		"speakerIndex": speakerIndex,
+	})
+
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return Speaker{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "Speaker name cannot be blank",
+		}
+	}
+
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return Speaker{}, errResp
+	}
+
+	if errResp := s.ensureSpeakerExists(transcript.ID, speakerIndex); errResp != nil {
+		return Speaker{}, errResp
+	}
+
+	if err := s.repo.RenameSpeaker(transcript.ID, speakerIndex, name, userID); err != nil {
+		return Speaker{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to rename speaker",
+		}
+	}
+
+	s.scheduleSummary(episodeID)
+
+	return Speaker{
+		Index:      speakerIndex,
+		Name:       name,
+		IsHumanSet: true,
+	}, nil
+}
+
+// MergeSpeakers reassigns all chunks of one speaker to another and returns the remaining speakers
+func (s *Service) MergeSpeakers(episodeID, fromIndex, toIndex int) ([]Speaker, *errors.ErrorResponse) {
+	s.log.Info("Merging speakers", map[string]any{
+		"episodeID": episodeID,
+		"fromIndex": fromIndex,
+		"toIndex":   toIndex,
+	})
+
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return nil, errResp
+	}
+
+	for _, speakerIndex := range []int{fromIndex, toIndex} {
+		if errResp := s.ensureSpeakerExists(transcript.ID, speakerIndex); errResp != nil {
+			return nil, errResp
+		}
+	}
+
+	if err := s.repo.MergeSpeakers(transcript.ID, fromIndex, toIndex); err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to merge speakers",
+		}
+	}
+
+	s.scheduleSummary(episodeID)
+	// Chapter boundaries follow speaker turns, which merging joins
+	s.scheduleChapters(episodeID)
+	// Highlights store the speaker index of their chunks
+	s.scheduleHighlights(episodeID)
+	// Merging joins speaker turns, which are the units of translation
+	s.scheduleTranslations(episodeID)
+	s.scheduleAnalytics(episodeID)
+
+	speakers, err := s.repo.GetSpeakersByTranscriptID(transcript.ID)
+	if err != nil {
+		return nil, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch speakers",
+		}
+	}
+
+	result := make([]Speaker, 0, len(speakers))
+	for _, speaker := range speakers {
+		result = append(result, Speaker{
+			Index:      speaker.SpeakerIndex,
+			Name:       speaker.SpeakerName,
+			IsHumanSet: speaker.IsHumanSet,
+		})
+	}
+
+	return result, nil
+}
+
+// ensureSpeakerExists checks that the speaker index has at least one chunk in the transcript
+func (s *Service) ensureSpeakerExists(transcriptID, speakerIndex int) *errors.ErrorResponse {
+	exists, err := s.repo.HasSpeakerChunks(transcriptID, speakerIndex)
+	if err != nil {
+		return &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to check speaker",
+		}
+	}
+
+	if !exists {
+		return &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Speaker not found in this transcript",
+		}
+	}
+
+	return nil
+}
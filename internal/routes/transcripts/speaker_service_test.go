@@ -0,0 +1,177 @@
+package transcripts
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupSpeakerService mocks a complete transcript whose chunks belong to speakers 0 and 1
+func setupSpeakerService(status TranscriptStatus) (*Service, *[]string) {
+	service := setupService()
+	var execQueries []string
+
+	service.repo.transcriptRepo.Executor = utils.QueryExecutor[Transcript]{
+		QueryItem: func(query string, args ...any) (Transcript, error) {
+			if args[0].(int) != 1 {
+				return Transcript{}, fmt.Errorf("no rows in result set")
+			}
+			return Transcript{ID: 10, EpisodeID: 1, Status: string(status)}, nil
+		},
+	}
+	service.repo.chunkRepo.Executor = utils.QueryExecutor[TranscriptChunk]{
+		QueryItem: func(query string, args ...any) (TranscriptChunk, error) {
+			if speakerIndex := args[1].(int); speakerIndex > 1 {
+				return TranscriptChunk{}, fmt.Errorf("no rows in result set")
+			}
+			return TranscriptChunk{Position: 0}, nil
+		},
+	}
+	service.repo.speakerRepo.Executor = utils.QueryExecutor[TranscriptSpeaker]{
+		Exec: func(query string, args ...any) error {
+			execQueries = append(execQueries, query)
+			return nil
+		},
+		QueryList: func(query string, args ...any) ([]TranscriptSpeaker, error) {
+			return []TranscriptSpeaker{{SpeakerIndex: 1, SpeakerName: "Jane Doe", IsHumanSet: true}}, nil
+		},
+	}
+
+	return service, &execQueries
+}
+
+func TestTranscriptService_RenameSpeaker(t *testing.T) {
+	t.Run("renames speaker and marks it as human-set", func(t *testing.T) {
+		service, execQueries := setupSpeakerService(TranscriptStatusComplete)
+
+		speaker, errResp := service.RenameSpeaker(1, 0, 5, "  Jane Doe ")
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if speaker.Name != "Jane Doe" || !speaker.IsHumanSet || speaker.Index != 0 {
+			t.Errorf("Unexpected speaker: %+v", speaker)
+		}
+		if len(*execQueries) != 1 || !strings.Contains((*execQueries)[0], "is_human_set = TRUE") {
+			t.Errorf("Expected human-set rename query, got %v", *execQueries)
+		}
+	})
+
+	tests := []struct {
+		name         string
+		episodeID    int
+		speakerIndex int
+		speakerName  string
+		status       TranscriptStatus
+		wantMessage  string
+	}{
+		{"blank name", 1, 0, "   ", TranscriptStatusComplete, cribeErrors.ValidationError},
+		{"missing transcript", 2, 0, "Jane", TranscriptStatusComplete, cribeErrors.DatabaseNotFound},
+		{"processing transcript", 1, 0, "Jane", TranscriptStatusProcessing, cribeErrors.ValidationError},
+		{"unknown speaker", 1, 4, "Jane", TranscriptStatusComplete, cribeErrors.DatabaseNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, execQueries := setupSpeakerService(tt.status)
+
+			_, errResp := service.RenameSpeaker(tt.episodeID, tt.speakerIndex, 5, tt.speakerName)
+
+			if errResp == nil || errResp.Message != tt.wantMessage {
+				t.Errorf("Expected %q error, got %v", tt.wantMessage, errResp)
+			}
+			if len(*execQueries) != 0 {
+				t.Errorf("Expected no writes, got %v", *execQueries)
+			}
+		})
+	}
+}
+
+func TestTranscriptService_MergeSpeakers(t *testing.T) {
+	t.Run("merges speakers and returns remaining speakers", func(t *testing.T) {
+		service, execQueries := setupSpeakerService(TranscriptStatusComplete)
+
+		speakers, errResp := service.MergeSpeakers(1, 0, 1)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if len(speakers) != 1 || speakers[0].Index != 1 || speakers[0].Name != "Jane Doe" {
+			t.Errorf("Unexpected speakers: %+v", speakers)
+		}
+		if len(*execQueries) != 1 || !strings.Contains((*execQueries)[0], "UPDATE transcript_chunks") {
+			t.Errorf("Expected merge query, got %v", *execQueries)
+		}
+	})
+
+	t.Run("rejects unknown target speaker", func(t *testing.T) {
+		service, execQueries := setupSpeakerService(TranscriptStatusComplete)
+
+		_, errResp := service.MergeSpeakers(1, 0, 3)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found error, got %v", errResp)
+		}
+		if len(*execQueries) != 0 {
+			t.Errorf("Expected no writes, got %v", *execQueries)
+		}
+	})
+
+	t.Run("returns database error when merge fails", func(t *testing.T) {
+		service, _ := setupSpeakerService(TranscriptStatusComplete)
+		service.repo.speakerRepo.Executor.Exec = func(query string, args ...any) error {
+			return errors.New("database error")
+		}
+
+		_, errResp := service.MergeSpeakers(1, 0, 1)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseError {
+			t.Errorf("Expected database error, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptHandler_Speakers(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		body       string
+		wantStatus int
+	}{
+		{"rename speaker", http.MethodPatch, "/transcripts/1/speakers/0", `{"name":"Jane Doe"}`, http.StatusOK},
+		{"rename with empty name", http.MethodPatch, "/transcripts/1/speakers/0", `{"name":""}`, http.StatusBadRequest},
+		{"rename with invalid body", http.MethodPatch, "/transcripts/1/speakers/0", `{`, http.StatusBadRequest},
+		{"rename unknown speaker", http.MethodPatch, "/transcripts/1/speakers/9", `{"name":"Jane"}`, http.StatusNotFound},
+		{"rename with invalid index", http.MethodPatch, "/transcripts/1/speakers/abc", `{"name":"Jane"}`, http.StatusNotFound},
+		{"rename with wrong method", http.MethodGet, "/transcripts/1/speakers/0", ``, http.StatusMethodNotAllowed},
+		{"merge speakers", http.MethodPost, "/transcripts/1/speakers/merge", `{"from_index":0,"to_index":1}`, http.StatusOK},
+		{"merge same speaker", http.MethodPost, "/transcripts/1/speakers/merge", `{"from_index":1,"to_index":1}`, http.StatusBadRequest},
+		{"merge missing index", http.MethodPost, "/transcripts/1/speakers/merge", `{"to_index":1}`, http.StatusBadRequest},
+		{"merge with wrong method", http.MethodPatch, "/transcripts/1/speakers/merge", `{}`, http.StatusMethodNotAllowed},
+		{"merge on missing transcript", http.MethodPost, "/transcripts/2/speakers/merge", `{"from_index":0,"to_index":1}`, http.StatusNotFound},
+		{"unknown speakers sub-path", http.MethodPost, "/transcripts/1/speakers/0/extra", `{}`, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupSpeakerService(TranscriptStatusComplete)
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			r := httptest.NewRequest(tt.method, tt.url, strings.NewReader(tt.body))
+
+			handler.HandleRequest(w, r)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package transcripts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+// llmSummaryPart is the LLM response for one transcript window
+type llmSummaryPart struct {
+	Sections []struct {
+		Title   string `json:"title"`
+		Start   string `json:"start"`
+		Summary string `json:"summary"`
+	} `json:"sections"`
+	KeyPoints []string `json:"key_points"`
+}
+
+// llmEpisodeSummary is the LLM response combining the section summaries
+type llmEpisodeSummary struct {
+	TLDR      string   `json:"tldr"`
+	KeyPoints []string `json:"key_points"`
+}
+
+// GetSummary returns the summary of an episode. When an episode with a complete transcript
+// has none yet, or its last generation was abandoned or failed more than
+// retryFailedGenerationAfter ago, generation is started and a processing placeholder is returned.
+func (s *Service) GetSummary(episodeID int) (EpisodeSummary, *errors.ErrorResponse) {
+	summary, err := s.repo.GetSummaryByEpisodeID(episodeID)
+	if err == nil && !needsRegeneration(summary.Status, summary.UpdatedAt) {
+		return summary, nil
+	}
+	if err != nil && err.Error() != "no rows in result set" {
+		return EpisodeSummary{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch summary",
+		}
+	}
+
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return EpisodeSummary{}, errResp
+	}
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return EpisodeSummary{}, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Summary is available once the transcript is complete",
+		}
+	}
+
+	if !s.llmAvailable() {
+		return EpisodeSummary{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "LLM client not configured",
+		}
+	}
+
+	s.scheduleSummary(episodeID)
+
+	return EpisodeSummary{
+		EpisodeID:    episodeID,
+		TranscriptID: transcript.ID,
+		Status:       string(TranscriptStatusProcessing),
+		KeyPoints:    []string{},
+		Sections:     []SummarySection{},
+	}, nil
+}
+
+// scheduleSummary (re)generates the summary of an episode in the background
+func (s *Service) scheduleSummary(episodeID int) {
+	if s.summaries == nil || !s.llmAvailable() {
+		return
+	}
+
+	s.summaries.schedule(episodeID, func() {
+		if err := s.generateSummary(episodeID); err != nil {
+			s.log.Error("Failed to generate episode summary", map[string]any{
+				"episodeID": episodeID,
+				"error":     err.Error(),
+			})
+		}
+	})
+}
+
+// generateSummary summarizes each transcript window into sections (map), then combines
+// the sections into a TL;DR and key points (reduce)
+func (s *Service) generateSummary(episodeID int) error {
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return fmt.Errorf("%s", errResp.Details)
+	}
+
+	if _, err := s.repo.StartSummary(episodeID, transcript.ID); err != nil {
+		return fmt.Errorf("failed to start summary: %w", err)
+	}
+
+	fail := func(err error) error {
+		_ = s.repo.FailSummary(episodeID, err.Error())
+		return err
+	}
+
+	episode, _, windows, err := s.loadTranscriptWindows(episodeID, transcript.ID)
+	if err != nil {
+		return fail(err)
+	}
+
+	s.log.Info("Generating episode summary", map[string]any{
+		"episodeID": episodeID,
+		"windows":   len(windows),
+	})
+
+	sections, keyPoints, err := s.summarizeWindows(episode.Name, windows)
+	if err != nil {
+		return fail(err)
+	}
+
+	combined, err := s.combineSummary(episode, sections, keyPoints)
+	if err != nil {
+		return fail(err)
+	}
+
+	if err := s.repo.CompleteSummary(episodeID, combined.TLDR, combined.KeyPoints, sections); err != nil {
+		return fmt.Errorf("failed to save summary: %w", err)
+	}
+
+	s.log.Info("Episode summary generated", map[string]any{
+		"episodeID": episodeID,
+		"sections":  len(sections),
+	})
+
+	return nil
+}
+
+// summarizeWindows summarizes the windows concurrently and returns their sections in order
+// with the key points of every window
+func (s *Service) summarizeWindows(episodeName string, windows []transcriptWindow) ([]SummarySection, []string, error) {
+	parts, err := mapWindows(windows, func(i int, window transcriptWindow) (llmSummaryPart, error) {
+		return chatJSON[llmSummaryPart](s.llmClient, llm.ChatRequest{
+			Messages: []llm.Message{
+				{Role: "system", Content: SummarizeSectionSystemPrompt},
+				{Role: "user", Content: SummarizeSectionUserPrompt(episodeName, i+1, len(windows), window.Text)},
+			},
+			MaxTokens: 1000,
+		})
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to summarize %w", err)
+	}
+
+	sections, keyPoints := []SummarySection{}, []string{}
+	for i, part := range parts {
+		sections = append(sections, toSummarySections(part, windows[i])...)
+		keyPoints = append(keyPoints, part.KeyPoints...)
+	}
+
+	return sections, keyPoints, nil
+}
+
+// combineSummary reduces the section summaries into the TL;DR and episode key points
+func (s *Service) combineSummary(episode Episode, sections []SummarySection, keyPoints []string) (llmEpisodeSummary, error) {
+	var sectionsText strings.Builder
+	for _, section := range sections {
+		fmt.Fprintf(&sectionsText, "[%s] %s: %s\n", FormatTimestamp(section.Start), section.Title, section.Summary)
+	}
+	if len(keyPoints) > 0 {
+		sectionsText.WriteString("\nKey points noted in the sections:\n")
+		for _, point := range keyPoints {
+			fmt.Fprintf(&sectionsText, "- %s\n", point)
+		}
+	}
+
+	combined, err := chatJSON[llmEpisodeSummary](s.llmClient, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: SummarizeEpisodeSystemPrompt},
+			{Role: "user", Content: SummarizeEpisodeUserPrompt(episode.Name, episode.Description, sectionsText.String())},
+		},
+		MaxTokens: 800,
+	})
+	if err != nil {
+		return llmEpisodeSummary{}, fmt.Errorf("failed to combine summary: %w", err)
+	}
+
+	if strings.TrimSpace(combined.TLDR) == "" {
+		return llmEpisodeSummary{}, fmt.Errorf("LLM returned an empty summary")
+	}
+	if combined.KeyPoints == nil {
+		combined.KeyPoints = []string{}
+	}
+
+	return combined, nil
+}
+
+// toSummarySections converts the sections of one window, placing each between its own
+// start and the start of the next one. Unreadable timestamps fall back to the window start.
+func toSummarySections(part llmSummaryPart, window transcriptWindow) []SummarySection {
+	sections := make([]SummarySection, 0, len(part.Sections))
+	for _, section := range part.Sections {
+		if strings.TrimSpace(section.Summary) == "" {
+			continue
+		}
+		start, ok := ParseTimestamp(section.Start)
+		if !ok || start < window.Start || start > window.End {
+			start = window.Start
+		}
+		sections = append(sections, SummarySection{
+			Title:   strings.TrimSpace(section.Title),
+			Summary: strings.TrimSpace(section.Summary),
+			Start:   start,
+		})
+	}
+
+	sort.SliceStable(sections, func(i, j int) bool { return sections[i].Start < sections[j].Start })
+	for i := range sections {
+		if i+1 < len(sections) {
+			sections[i].End = sections[i+1].Start
+		} else {
+			sections[i].End = window.End
+		}
+	}
+
+	return sections
+}
@@ -0,0 +1,314 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// scriptedLLMClient answers each system prompt with a fixed reply
+type scriptedLLMClient struct {
+	replies map[string]string
+	calls   atomic.Int32
+}
+
+func (m *scriptedLLMClient) Chat(ctx context.Context, req llm.ChatRequest) (llm.ChatCompletionResponse, error) {
+	m.calls.Add(1)
+	return llm.ChatCompletionResponse{
+		Choices: []llm.Choice{{Message: llm.Message{Content: m.replies[req.Messages[0].Content]}}},
+	}, nil
+}
+
+type completedSummary struct {
+	tldr      string
+	keyPoints []string
+	sections  []SummarySection
+}
+
+// setupSummaryService mocks a complete transcript with the given chunks and records the
+// summary written by CompleteSummary
+func setupSummaryService(client llm.LLMClient, chunks []TranscriptChunk) (*Service, chan completedSummary) {
+	service, _ := setupSpeakerService(TranscriptStatusComplete)
+	service.llmClient = client
+	service.summaries = newGenerationQueue[int]()
+	completed := make(chan completedSummary, 1)
+
+	service.repo.episodeRepo.Executor = utils.QueryExecutor[Episode]{
+		QueryItem: func(query string, args ...any) (Episode, error) {
+			return Episode{ID: 1, Name: "Pilot", Description: "The first one"}, nil
+		},
+	}
+	service.repo.chunkRepo.Executor.QueryList = func(query string, args ...any) ([]TranscriptChunk, error) {
+		return chunks, nil
+	}
+	service.repo.summaryRepo.Executor = utils.QueryExecutor[EpisodeSummary]{
+		QueryItem: func(query string, args ...any) (EpisodeSummary, error) {
+			if strings.Contains(query, "INSERT") {
+				return EpisodeSummary{EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+			}
+			return EpisodeSummary{}, fmt.Errorf("no rows in result set")
+		},
+		Exec: func(query string, args ...any) error {
+			if strings.Contains(query, "'complete'") {
+				completed <- completedSummary{args[1].(string), args[2].([]string), args[3].([]SummarySection)}
+			}
+			return nil
+		},
+	}
+
+	return service, completed
+}
+
+func summaryChunks(count int) []TranscriptChunk {
+	chunks := make([]TranscriptChunk, count)
+	for i := range chunks {
+		chunks[i] = TranscriptChunk{Position: i, SpeakerIndex: i / 3 % 2, StartTime: float64(i * 30), EndTime: float64(i*30 + 1), Text: fmt.Sprintf("w%d", i)}
+	}
+	return chunks
+}
+
+func TestToSummarySections(t *testing.T) {
+	part, err := utils.DecodeResponse[llmSummaryPart](`{"sections": [
+		{"title": "Outro", "start": "9:00", "summary": "Wrap up"},
+		{"title": "Intro", "start": "nonsense", "summary": "Hello"},
+		{"title": "Empty", "start": "6:00", "summary": " "},
+		{"title": "Late", "start": "20:00", "summary": "Out of range"}
+	]}`)
+	if err != nil {
+		t.Fatalf("Failed to decode part: %v", err)
+	}
+
+	sections := toSummarySections(part, transcriptWindow{Start: 300, End: 600})
+
+	if len(sections) != 3 {
+		t.Fatalf("Expected 3 sections, got %+v", sections)
+	}
+	want := []SummarySection{
+		{Title: "Intro", Summary: "Hello", Start: 300, End: 300},
+		{Title: "Late", Summary: "Out of range", Start: 300, End: 540},
+		{Title: "Outro", Summary: "Wrap up", Start: 540, End: 600},
+	}
+	for i := range want {
+		if sections[i] != want[i] {
+			t.Errorf("Expected section %d to be %+v, got %+v", i, want[i], sections[i])
+		}
+	}
+}
+
+func TestTranscriptService_GenerateSummary(t *testing.T) {
+	client := &scriptedLLMClient{
+		replies: map[string]string{
+			SummarizeSectionSystemPrompt: "```json\n" + `{"sections": [{"title": "Opening", "start": "0:00", "summary": "They meet."}], "key_points": ["A point"]}` + "\n```",
+			SummarizeEpisodeSystemPrompt: `{"tldr": "A short pilot.", "key_points": ["Main point"]}`,
+		},
+	}
+	chunks := make([]TranscriptChunk, transcriptWindowWords+1)
+	for i := range chunks {
+		chunks[i] = TranscriptChunk{Position: i, StartTime: float64(i), EndTime: float64(i) + 0.5, Text: "word"}
+	}
+	service, completed := setupSummaryService(client, chunks)
+
+	if err := service.generateSummary(1); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	summary := <-completed
+	if summary.tldr != "A short pilot." || len(summary.keyPoints) != 1 || summary.keyPoints[0] != "Main point" {
+		t.Errorf("Unexpected summary: %+v", summary)
+	}
+	if len(summary.sections) != 2 {
+		t.Fatalf("Expected one section per window, got %+v", summary.sections)
+	}
+	if summary.sections[1].Start != float64(transcriptWindowWords) || summary.sections[1].End != float64(transcriptWindowWords)+0.5 {
+		t.Errorf("Expected the second section to cover the second window, got %+v", summary.sections[1])
+	}
+	if calls := client.calls.Load(); calls != 3 {
+		t.Errorf("Expected 2 section requests and 1 combine request, got %d", calls)
+	}
+
+	t.Run("marks the summary as failed when the LLM reply is not JSON", func(t *testing.T) {
+		service, _ := setupSummaryService(&MockLLMClient{}, summaryChunks(3))
+		var failed []any
+		service.repo.summaryRepo.Executor.Exec = func(query string, args ...any) error {
+			failed = args
+			return nil
+		}
+
+		if err := service.generateSummary(1); err == nil {
+			t.Fatal("Expected an error")
+		}
+		if !strings.Contains(fmt.Sprint(failed...), "failed to parse LLM response") {
+			t.Errorf("Expected the failure to be stored, got %v", failed)
+		}
+	})
+}
+
+func TestTranscriptService_GetSummary(t *testing.T) {
+	t.Run("returns the stored summary", func(t *testing.T) {
+		service, _ := setupSummaryService(&MockLLMClient{}, nil)
+		service.repo.summaryRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeSummary, error) {
+			return EpisodeSummary{EpisodeID: 1, Status: string(TranscriptStatusComplete), TLDR: "Stored"}, nil
+		}
+
+		summary, errResp := service.GetSummary(1)
+
+		if errResp != nil || summary.TLDR != "Stored" {
+			t.Errorf("Expected the stored summary, got %+v, %v", summary, errResp)
+		}
+	})
+
+	t.Run("starts generation for a complete transcript without summary", func(t *testing.T) {
+		client := &scriptedLLMClient{
+			replies: map[string]string{
+				SummarizeSectionSystemPrompt: `{"sections": [{"title": "Opening", "start": "0:00", "summary": "They meet."}]}`,
+				SummarizeEpisodeSystemPrompt: `{"tldr": "A short pilot.", "key_points": []}`,
+			},
+		}
+		service, completed := setupSummaryService(client, summaryChunks(3))
+
+		summary, errResp := service.GetSummary(1)
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if summary.Status != string(TranscriptStatusProcessing) || summary.TranscriptID != 10 {
+			t.Errorf("Expected a processing placeholder, got %+v", summary)
+		}
+		select {
+		case generated := <-completed:
+			if generated.tldr != "A short pilot." {
+				t.Errorf("Unexpected summary: %+v", generated)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the summary to be generated")
+		}
+	})
+
+	t.Run("returns not found until the transcript is complete", func(t *testing.T) {
+		service, _ := setupSummaryService(&MockLLMClient{}, nil)
+		service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+			return Transcript{ID: 10, EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+		}
+
+		_, errResp := service.GetSummary(1)
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptService_GetSummary_Regenerates(t *testing.T) {
+	tests := []struct {
+		name           string
+		status         TranscriptStatus
+		updatedAgo     time.Duration
+		wantStatus     TranscriptStatus
+		wantRegenerate bool
+	}{
+		{"failed summary", TranscriptStatusFailed, retryFailedGenerationAfter + time.Minute, TranscriptStatusProcessing, true},
+		{"recently failed summary", TranscriptStatusFailed, time.Minute, TranscriptStatusFailed, false},
+		{"abandoned summary", TranscriptStatusProcessing, staleGenerationAfter + time.Minute, TranscriptStatusProcessing, true},
+		{"summary being generated", TranscriptStatusProcessing, time.Minute, TranscriptStatusProcessing, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &scriptedLLMClient{
+				replies: map[string]string{
+					SummarizeSectionSystemPrompt: `{"sections": [{"title": "Opening", "start": "0:00", "summary": "They meet."}]}`,
+					SummarizeEpisodeSystemPrompt: `{"tldr": "A short pilot.", "key_points": []}`,
+				},
+			}
+			service, completed := setupSummaryService(client, summaryChunks(3))
+			querySummary := service.repo.summaryRepo.Executor.QueryItem
+			service.repo.summaryRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeSummary, error) {
+				if strings.HasPrefix(strings.TrimSpace(query), "SELECT") {
+					return EpisodeSummary{EpisodeID: 1, TranscriptID: 10, Status: string(tt.status), UpdatedAt: time.Now().Add(-tt.updatedAgo)}, nil
+				}
+				return querySummary(query, args...)
+			}
+
+			summary, errResp := service.GetSummary(1)
+
+			if errResp != nil || summary.Status != string(tt.wantStatus) {
+				t.Fatalf("Expected a %s summary, got %+v, %v", tt.wantStatus, summary, errResp)
+			}
+			select {
+			case <-completed:
+				if !tt.wantRegenerate {
+					t.Error("Expected the summary not to be regenerated")
+				}
+			case <-time.After(200 * time.Millisecond):
+				if tt.wantRegenerate {
+					t.Error("Expected the summary to be regenerated")
+				}
+			}
+		})
+	}
+}
+
+func TestTranscriptService_CorrectionsRegenerateSummary(t *testing.T) {
+	service, completed := setupSummaryService(&scriptedLLMClient{
+		replies: map[string]string{
+			SummarizeSectionSystemPrompt: `{"sections": [{"title": "Opening", "start": "0:00", "summary": "They meet."}]}`,
+			SummarizeEpisodeSystemPrompt: `{"tldr": "Renamed.", "key_points": []}`,
+		},
+	}, summaryChunks(3))
+
+	if _, errResp := service.RenameSpeaker(1, 0, 5, "John"); errResp != nil {
+		t.Fatalf("Expected no error, got %v", errResp)
+	}
+
+	select {
+	case <-completed:
+	case <-time.After(time.Second):
+		t.Fatal("Expected renaming a speaker to regenerate the summary")
+	}
+}
+
+func TestTranscriptHandler_Summary(t *testing.T) {
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		status     string
+		wantStatus int
+	}{
+		{"complete summary", http.MethodGet, "/episodes/1/summary", string(TranscriptStatusComplete), http.StatusOK},
+		{"summary being generated", http.MethodGet, "/episodes/1/summary", string(TranscriptStatusProcessing), http.StatusAccepted},
+		{"unknown episode", http.MethodGet, "/episodes/2/summary", "", http.StatusNotFound},
+		{"wrong method", http.MethodPost, "/episodes/1/summary", "", http.StatusMethodNotAllowed},
+		{"unknown route", http.MethodGet, "/episodes/1/unknown", "", http.StatusNotFound},
+		{"invalid episode id", http.MethodGet, "/episodes/abc/summary", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupSummaryService(&MockLLMClient{}, nil)
+			service.repo.summaryRepo.Executor.QueryItem = func(query string, args ...any) (EpisodeSummary, error) {
+				if args[0].(int) != 1 || tt.status == "" {
+					return EpisodeSummary{}, fmt.Errorf("no rows in result set")
+				}
+				return EpisodeSummary{EpisodeID: 1, Status: tt.status}, nil
+			}
+			handler := NewTranscriptHandler(service)
+
+			w := httptest.NewRecorder()
+			handler.HandleRequest(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,396 @@
+package transcripts
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	"cribeapp.com/cribe-server/internal/errors"
+)
+
+const (
+	// translationTurnWords splits long monologues so translated segments keep
+	// fine-grained timing
+	translationTurnWords = 120
+	// translationBatchWords keeps each batch and its translation well within the output limit
+	translationBatchWords = 600
+	// translationPollInterval is how often a stream waiting for a translation checks on it
+	translationPollInterval = 2 * time.Second
+	// translationWaitTimeout stops a stream waiting on a translation that never finishes,
+	// e.g. after a restart interrupted it
+	translationWaitTimeout = 10 * time.Minute
+)
+
+var translationLanguagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[A-Za-z0-9]{2,8})?$`)
+
+// languageNames spells out common language codes for the prompt; other codes are sent as is
+var languageNames = map[string]string{
+	"de":    "German",
+	"en":    "English",
+	"es":    "Spanish",
+	"fr":    "French",
+	"it":    "Italian",
+	"pt":    "Portuguese",
+	"pt-BR": "Brazilian Portuguese",
+	"pt-PT": "European Portuguese",
+}
+
+// translationKey identifies one translation in the generation queue
+type translationKey struct {
+	episodeID int
+	language  string
+}
+
+// llmTranslation is the LLM response for one batch of speaker turns
+type llmTranslation struct {
+	Segments []struct {
+		ID   int    `json:"id"`
+		Text string `json:"text"`
+	} `json:"segments"`
+}
+
+// GetTranslation returns the translation of an episode transcript. When none exists yet, or
+// the last attempt was abandoned or failed a while ago (see needsRegeneration), translation is
+// started and a processing placeholder is returned.
+func (s *Service) GetTranslation(episodeID int, language string) (TranscriptTranslation, *errors.ErrorResponse) {
+	if !translationLanguagePattern.MatchString(language) {
+		return TranscriptTranslation{}, &errors.ErrorResponse{
+			Message: errors.ValidationError,
+			Details: "lang must be a language code (e.g. pt, es, pt-BR)",
+		}
+	}
+
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return TranscriptTranslation{}, errResp
+	}
+	if transcript.Status != string(TranscriptStatusComplete) {
+		return TranscriptTranslation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseNotFound,
+			Details: "Translations are available once the transcript is complete",
+		}
+	}
+
+	translation, err := s.repo.GetTranslation(transcript.ID, language)
+	if err != nil && err.Error() != "no rows in result set" {
+		return TranscriptTranslation{}, &errors.ErrorResponse{
+			Message: errors.DatabaseError,
+			Details: "Failed to fetch translation",
+		}
+	}
+	if err == nil && !needsRegeneration(translation.Status, translation.UpdatedAt) {
+		return translation, nil
+	}
+
+	if !s.llmAvailable() {
+		return TranscriptTranslation{}, &errors.ErrorResponse{
+			Message: errors.ExternalAPIError,
+			Details: "LLM client not configured",
+		}
+	}
+
+	s.scheduleTranslation(episodeID, language)
+
+	segments := translation.Segments
+	if segments == nil {
+		segments = []TranslatedSegment{}
+	}
+
+	return TranscriptTranslation{
+		ID:           translation.ID,
+		TranscriptID: transcript.ID,
+		Language:     language,
+		Status:       string(TranscriptStatusProcessing),
+		Segments:     segments,
+	}, nil
+}
+
+// StreamTranslation replays a translated transcript like a cached one, with each chunk
+// holding a translated speaker turn. When the translation doesn't exist yet it waits for it.
+func (s *Service) StreamTranslation(ctx context.Context, episodeID int, language string, chunkCB ChunkCallback, speakerCB SpeakerCallback) error {
+	translation, errResp := s.GetTranslation(episodeID, language)
+	if errResp != nil {
+		return fmt.Errorf("%s", errResp.Details)
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, translationWaitTimeout)
+	defer cancel()
+
+	// A translation being regenerated after a correction still has its previous segments
+	for translation.Status == string(TranscriptStatusProcessing) && len(translation.Segments) == 0 {
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("timed out waiting for the translation")
+		case <-time.After(translationPollInterval):
+		}
+
+		current, err := s.repo.GetTranslation(translation.TranscriptID, language)
+		if err != nil {
+			// The background translation may not have created its row yet
+			if err.Error() == "no rows in result set" {
+				continue
+			}
+			return fmt.Errorf("failed to fetch translation: %w", err)
+		}
+		translation = current
+	}
+
+	if translation.Status == string(TranscriptStatusFailed) {
+		message := "translation failed"
+		if translation.ErrorMessage != nil {
+			message = *translation.ErrorMessage
+		}
+		return fmt.Errorf("%s", message)
+	}
+
+	if err := s.streamSpeakersFromDB(translation.TranscriptID, speakerCB); err != nil {
+		return err
+	}
+
+	for _, segment := range translation.Segments {
+		chunk := segment.Chunk()
+		if err := chunkCB(&chunk); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// scheduleTranslation (re)translates the transcript of an episode in the background
+func (s *Service) scheduleTranslation(episodeID int, language string) {
+	if s.translations == nil || !s.llmAvailable() {
+		return
+	}
+
+	s.translations.schedule(translationKey{episodeID, language}, func() {
+		if err := s.generateTranslation(episodeID, language); err != nil {
+			s.log.Error("Failed to translate transcript", map[string]any{
+				"episodeID": episodeID,
+				"language":  language,
+				"error":     err.Error(),
+			})
+		}
+	})
+}
+
+// scheduleTranslations retranslates every language an episode was translated into,
+// after a correction changed the transcript
+func (s *Service) scheduleTranslations(episodeID int) {
+	if s.translations == nil || !s.llmAvailable() {
+		return
+	}
+
+	transcript, errResp := s.getTranscript(episodeID)
+	if errResp != nil {
+		return
+	}
+
+	languages, err := s.repo.GetTranslationLanguages(transcript.ID)
+	if err != nil {
+		return
+	}
+
+	for _, language := range languages {
+		s.scheduleTranslation(episodeID, language)
+	}
+}
+
+// generateTranslation translates the speaker turns of a transcript in batches and stores
+// them with the timing of their chunks
+func (s *Service) generateTranslation(episodeID int, language string) error {
+	transcript, errResp := s.getEditableTranscript(episodeID)
+	if errResp != nil {
+		return fmt.Errorf("%s", errResp.Details)
+	}
+
+	if _, err := s.repo.StartTranslation(transcript.ID, language); err != nil {
+		return fmt.Errorf("failed to start translation: %w", err)
+	}
+
+	fail := func(err error) error {
+		_ = s.repo.FailTranslation(transcript.ID, language, err.Error())
+		return err
+	}
+
+	episode, err := s.repo.GetEpisodeByID(episodeID)
+	if err != nil {
+		return fail(fmt.Errorf("failed to fetch episode: %w", err))
+	}
+
+	chunks, err := s.repo.GetChunksByTranscriptID(transcript.ID)
+	if err != nil {
+		return fail(fmt.Errorf("failed to fetch chunks: %w", err))
+	}
+
+	batches := batchTranslationSegments(buildTranslationSegments(chunks), translationBatchWords)
+
+	s.log.Info("Translating transcript", map[string]any{
+		"episodeID": episodeID,
+		"language":  language,
+		"batches":   len(batches),
+	})
+
+	translated, err := mapWindows(batches, func(i int, batch []TranslatedSegment) ([]TranslatedSegment, error) {
+		return s.translateBatch(episode.Name, language, batch)
+	})
+	if err != nil {
+		return fail(fmt.Errorf("failed to translate %w", err))
+	}
+
+	segments := []TranslatedSegment{}
+	for _, batch := range translated {
+		segments = append(segments, batch...)
+	}
+
+	if err := s.repo.CompleteTranslation(transcript.ID, language, segments); err != nil {
+		return fmt.Errorf("failed to save translation: %w", err)
+	}
+
+	s.log.Info("Transcript translated", map[string]any{
+		"episodeID": episodeID,
+		"language":  language,
+		"segments":  len(segments),
+	})
+
+	return nil
+}
+
+// translateBatch translates a batch of speaker turns, failing when a turn is missing from
+// the reply so no part of the transcript silently goes untranslated
+func (s *Service) translateBatch(episodeName, language string, batch []TranslatedSegment) ([]TranslatedSegment, error) {
+	type sourceSegment struct {
+		ID   int    `json:"id"`
+		Text string `json:"text"`
+	}
+	source := struct {
+		Segments []sourceSegment `json:"segments"`
+	}{Segments: make([]sourceSegment, len(batch))}
+	words := 0
+	for i, segment := range batch {
+		source.Segments[i] = sourceSegment{ID: i, Text: segment.Text}
+		words += len(strings.Fields(segment.Text))
+	}
+
+	sourceJSON, err := json.Marshal(source)
+	if err != nil {
+		return nil, err
+	}
+
+	languageName := language
+	if name, ok := languageNames[language]; ok {
+		languageName = name
+	}
+
+	response, err := chatJSON[llmTranslation](s.llmClient, llm.ChatRequest{
+		Messages: []llm.Message{
+			{Role: "system", Content: TranslateSegmentsSystemPrompt},
+			{Role: "user", Content: TranslateSegmentsUserPrompt(episodeName, languageName, string(sourceJSON))},
+		},
+		// Translations can take more tokens than the source, especially for accented languages
+		MaxTokens: words*3 + 200,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	texts := make(map[int]string, len(response.Segments))
+	for _, segment := range response.Segments {
+		texts[segment.ID] = strings.TrimSpace(segment.Text)
+	}
+
+	translated := make([]TranslatedSegment, len(batch))
+	for i, segment := range batch {
+		text := texts[i]
+		if text == "" {
+			return nil, fmt.Errorf("LLM did not translate turn %d of %d", i+1, len(batch))
+		}
+		segment.Text = text
+		translated[i] = segment
+	}
+
+	return translated, nil
+}
+
+// buildTranslationSegments groups the chunks into speaker turns, splitting turns longer
+// than translationTurnWords. Segments hold the source text until translated.
+func buildTranslationSegments(chunks []TranscriptChunk) []TranslatedSegment {
+	segments := []TranslatedSegment{}
+	var words []string
+
+	for i, chunk := range chunks {
+		if len(words) == 0 {
+			segments = append(segments, TranslatedSegment{
+				SpeakerIndex:  chunk.SpeakerIndex,
+				StartPosition: chunk.Position,
+				Start:         chunk.StartTime,
+			})
+		}
+
+		words = append(words, chunk.Text)
+		current := &segments[len(segments)-1]
+		current.EndPosition, current.End = chunk.Position, chunk.EndTime
+
+		turnEnds := i+1 == len(chunks) || chunks[i+1].SpeakerIndex != chunk.SpeakerIndex
+		if turnEnds || len(words) >= translationTurnWords {
+			current.Text = strings.Join(words, " ")
+			words = nil
+		}
+	}
+
+	return segments
+}
+
+// batchTranslationSegments packs consecutive segments into batches of about maxWords words
+func batchTranslationSegments(segments []TranslatedSegment, maxWords int) [][]TranslatedSegment {
+	var (
+		batches [][]TranslatedSegment
+		batch   []TranslatedSegment
+		words   int
+	)
+
+	for _, segment := range segments {
+		segmentWords := len(strings.Fields(segment.Text))
+		if len(batch) > 0 && words+segmentWords > maxWords {
+			batches = append(batches, batch)
+			batch, words = nil, 0
+		}
+		batch = append(batch, segment)
+		words += segmentWords
+	}
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+// ExportTranslatedTranscript returns the export of an episode with each chunk replaced by
+// a translated speaker turn. While the first translation runs the chunks are empty.
+func (s *Service) ExportTranslatedTranscript(episodeID int, language string) (TranscriptExport, *errors.ErrorResponse) {
+	translation, errResp := s.GetTranslation(episodeID, language)
+	if errResp != nil {
+		return TranscriptExport{}, errResp
+	}
+
+	export, errResp := s.ExportTranscript(episodeID)
+	if errResp != nil {
+		return TranscriptExport{}, errResp
+	}
+
+	export.Language = language
+	export.TranslationStatus = translation.Status
+	export.Chunks = make([]Chunk, len(translation.Segments))
+	for i, segment := range translation.Segments {
+		export.Chunks[i] = segment.Chunk()
+	}
+
+	return export, nil
+}
@@ -0,0 +1,255 @@
+package transcripts
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"cribeapp.com/cribe-server/internal/clients/llm"
+	cribeErrors "cribeapp.com/cribe-server/internal/errors"
+	"cribeapp.com/cribe-server/internal/utils"
+)
+
+// setupTranslationService mocks a complete transcript with the given chunks and a stored
+// translation (nil for none), and records the segments written by CompleteTranslation
+func setupTranslationService(client llm.LLMClient, chunks []TranscriptChunk, stored *TranscriptTranslation) (*Service, chan []TranslatedSegment) {
+	service, _ := setupSummaryService(client, chunks)
+	service.summaries = nil
+	service.translations = newGenerationQueue[translationKey]()
+	completed := make(chan []TranslatedSegment, 1)
+
+	service.repo.translateRepo.Executor = utils.QueryExecutor[TranscriptTranslation]{
+		QueryItem: func(query string, args ...any) (TranscriptTranslation, error) {
+			if strings.Contains(query, "INSERT") {
+				return TranscriptTranslation{TranscriptID: 10, Language: args[1].(string), Status: string(TranscriptStatusProcessing)}, nil
+			}
+			if stored == nil {
+				return TranscriptTranslation{}, fmt.Errorf("no rows in result set")
+			}
+			return *stored, nil
+		},
+		QueryList: func(query string, args ...any) ([]TranscriptTranslation, error) {
+			if stored == nil {
+				return nil, nil
+			}
+			return []TranscriptTranslation{*stored}, nil
+		},
+		Exec: func(query string, args ...any) error {
+			if strings.Contains(query, "'complete'") {
+				completed <- args[2].([]TranslatedSegment)
+			}
+			return nil
+		},
+	}
+
+	return service, completed
+}
+
+func TestBuildTranslationSegments(t *testing.T) {
+	chunks := summaryChunks(7)
+	for i := range translationTurnWords + 1 {
+		chunks = append(chunks, TranscriptChunk{Position: 7 + i, SpeakerIndex: 0, StartTime: float64(300 + i), EndTime: float64(300+i) + 0.5, Text: "long"})
+	}
+
+	segments := buildTranslationSegments(chunks)
+
+	// Speaker 0 (w0-w2), speaker 1 (w3-w5), then speaker 0 continues into a long monologue
+	if len(segments) != 4 {
+		t.Fatalf("Expected 4 segments, got %+v", segments)
+	}
+	first := TranslatedSegment{SpeakerIndex: 0, StartPosition: 0, EndPosition: 2, Start: 0, End: 61, Text: "w0 w1 w2"}
+	if segments[0] != first {
+		t.Errorf("Expected %+v, got %+v", first, segments[0])
+	}
+	if segments[1].SpeakerIndex != 1 || segments[1].Text != "w3 w4 w5" {
+		t.Errorf("Unexpected second segment: %+v", segments[1])
+	}
+	if segments[2].StartPosition != 6 || len(strings.Fields(segments[2].Text)) != translationTurnWords {
+		t.Errorf("Expected the monologue to be split after %d words, got %+v", translationTurnWords, segments[2])
+	}
+	if segments[3].StartPosition != 6+translationTurnWords || segments[3].Text != "long long" {
+		t.Errorf("Unexpected last segment: %+v", segments[3])
+	}
+
+	if segments := buildTranslationSegments(nil); len(segments) != 0 {
+		t.Errorf("Expected no segments for an empty transcript, got %+v", segments)
+	}
+}
+
+func TestBatchTranslationSegments(t *testing.T) {
+	segments := []TranslatedSegment{{Text: "a b c"}, {Text: "d e"}, {Text: "f"}, {Text: "g h i j k"}}
+
+	batches := batchTranslationSegments(segments, 5)
+
+	if len(batches) != 3 || len(batches[0]) != 2 || len(batches[1]) != 1 || len(batches[2]) != 1 {
+		t.Errorf("Unexpected batches: %+v", batches)
+	}
+}
+
+func TestTranscriptService_GenerateTranslation(t *testing.T) {
+	t.Run("translates turns keeping their timing", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			TranslateSegmentsSystemPrompt: `{"segments": [{"id": 1, "text": "p3 p4 p5"}, {"id": 0, "text": " p0 p1 p2 "}]}`,
+		}}
+		service, completed := setupTranslationService(client, summaryChunks(6), nil)
+
+		if err := service.generateTranslation(1, "pt"); err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+
+		segments := <-completed
+		if len(segments) != 2 || segments[0].Text != "p0 p1 p2" || segments[1].Text != "p3 p4 p5" {
+			t.Fatalf("Unexpected segments: %+v", segments)
+		}
+		if segments[1].SpeakerIndex != 1 || segments[1].StartPosition != 3 || segments[1].Start != 90 || segments[1].End != 151 {
+			t.Errorf("Expected the timing of the chunks to be kept, got %+v", segments[1])
+		}
+	})
+
+	t.Run("fails when a turn is not translated", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			TranslateSegmentsSystemPrompt: `{"segments": [{"id": 0, "text": "p0 p1 p2"}]}`,
+		}}
+		service, _ := setupTranslationService(client, summaryChunks(6), nil)
+
+		if err := service.generateTranslation(1, "pt"); err == nil || !strings.Contains(err.Error(), "turn 2 of 2") {
+			t.Errorf("Expected a missing turn error, got %v", err)
+		}
+	})
+}
+
+func TestTranscriptService_GetTranslation(t *testing.T) {
+	t.Run("starts translation when none exists", func(t *testing.T) {
+		client := &scriptedLLMClient{replies: map[string]string{
+			TranslateSegmentsSystemPrompt: `{"segments": [{"id": 0, "text": "p0 p1 p2"}]}`,
+		}}
+		service, completed := setupTranslationService(client, summaryChunks(3), nil)
+
+		translation, errResp := service.GetTranslation(1, "pt")
+
+		if errResp != nil {
+			t.Fatalf("Expected no error, got %v", errResp)
+		}
+		if translation.Status != string(TranscriptStatusProcessing) || translation.TranscriptID != 10 || translation.Segments == nil {
+			t.Errorf("Expected a processing placeholder, got %+v", translation)
+		}
+		select {
+		case <-completed:
+		case <-time.After(time.Second):
+			t.Fatal("Timed out waiting for the translation")
+		}
+	})
+
+	t.Run("returns a stored translation", func(t *testing.T) {
+		stored := &TranscriptTranslation{TranscriptID: 10, Language: "es", Status: string(TranscriptStatusComplete), Segments: []TranslatedSegment{{Text: "hola"}}}
+		service, _ := setupTranslationService(&MockLLMClient{}, nil, stored)
+
+		translation, errResp := service.GetTranslation(1, "es")
+
+		if errResp != nil || translation.Status != string(TranscriptStatusComplete) || translation.Segments[0].Text != "hola" {
+			t.Errorf("Expected the stored translation, got %+v, %v", translation, errResp)
+		}
+	})
+
+	t.Run("rejects an invalid language", func(t *testing.T) {
+		service, _ := setupTranslationService(&MockLLMClient{}, nil, nil)
+
+		_, errResp := service.GetTranslation(1, "Portuguese")
+
+		if errResp == nil || errResp.Message != cribeErrors.ValidationError {
+			t.Errorf("Expected a validation error, got %v", errResp)
+		}
+	})
+
+	t.Run("returns not found until the transcript is complete", func(t *testing.T) {
+		service, _ := setupTranslationService(&MockLLMClient{}, nil, nil)
+		service.repo.transcriptRepo.Executor.QueryItem = func(query string, args ...any) (Transcript, error) {
+			return Transcript{ID: 10, EpisodeID: 1, Status: string(TranscriptStatusProcessing)}, nil
+		}
+
+		_, errResp := service.GetTranslation(1, "pt")
+
+		if errResp == nil || errResp.Message != cribeErrors.DatabaseNotFound {
+			t.Errorf("Expected not found, got %v", errResp)
+		}
+	})
+}
+
+func TestTranscriptService_StreamTranslation(t *testing.T) {
+	t.Run("replays speakers and translated turns", func(t *testing.T) {
+		stored := &TranscriptTranslation{TranscriptID: 10, Language: "pt", Status: string(TranscriptStatusComplete), Segments: []TranslatedSegment{
+			{SpeakerIndex: 1, StartPosition: 3, EndPosition: 5, Start: 90, End: 151, Text: "Olá a todos"},
+		}}
+		service, _ := setupTranslationService(&MockLLMClient{}, nil, stored)
+
+		var chunks []*Chunk
+		var speakers []*Speaker
+		err := service.StreamTranslation(context.Background(), 1, "pt",
+			func(chunk *Chunk) error { chunks = append(chunks, chunk); return nil },
+			func(speaker *Speaker) error { speakers = append(speakers, speaker); return nil },
+		)
+
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if len(speakers) != 1 || speakers[0].Name != "Jane Doe" {
+			t.Errorf("Expected the stored speakers, got %+v", speakers)
+		}
+		if len(chunks) != 1 || chunks[0].Text != "Olá a todos" || chunks[0].Position != 3 || chunks[0].Start != 90 {
+			t.Errorf("Unexpected chunks: %+v", chunks)
+		}
+	})
+
+	t.Run("stops waiting when the client disconnects", func(t *testing.T) {
+		service, _ := setupTranslationService(&MockLLMClient{}, nil, nil)
+		service.translations = nil
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := service.StreamTranslation(ctx, 1, "pt", func(*Chunk) error { return nil }, func(*Speaker) error { return nil })
+
+		if err != context.Canceled {
+			t.Errorf("Expected the context error, got %v", err)
+		}
+	})
+}
+
+func TestTranscriptHandler_Translation(t *testing.T) {
+	stored := &TranscriptTranslation{TranscriptID: 10, Language: "pt", Status: string(TranscriptStatusComplete), Segments: []TranslatedSegment{
+		{SpeakerIndex: 0, StartPosition: 0, EndPosition: 2, Start: 0, End: 61, Text: "Olá"},
+	}}
+
+	tests := []struct {
+		name       string
+		path       string
+		stored     *TranscriptTranslation
+		wantStatus int
+		wantBody   string
+	}{
+		{"translated export", "/transcripts/1/export?lang=pt", stored, http.StatusOK, `"text":"Olá"`},
+		{"translation in progress", "/transcripts/1/export?lang=pt", &TranscriptTranslation{TranscriptID: 10, Status: string(TranscriptStatusProcessing)}, http.StatusAccepted, `"translation_status":"processing"`},
+		{"invalid language", "/transcripts/1/export?lang=xx_YY", nil, http.StatusBadRequest, ""},
+		{"translated stream", "/transcripts/stream/sse?episode_id=1&lang=pt", stored, http.StatusOK, "event: chunk\ndata: {\"position\":0,\"speaker_index\":0,\"start\":0,\"end\":61,\"text\":\"Olá\"}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, _ := setupTranslationService(&MockLLMClient{}, summaryChunks(3), tt.stored)
+			service.translations = nil
+
+			w := httptest.NewRecorder()
+			NewTranscriptHandler(service).HandleRequest(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("Expected status %d, got %d: %s", tt.wantStatus, w.Code, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("Expected body to contain %s, got %s", tt.wantBody, w.Body.String())
+			}
+		})
+	}
+}
@@ -12,6 +12,7 @@ type User struct {
 	FirstName string    `json:"first_name"`
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
+	IsAdmin   bool      `json:"is_admin"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -22,6 +23,7 @@ type UserWithPassword struct {
 	LastName  string    `json:"last_name"`
 	Email     string    `json:"email"`
 	Password  string    `json:"password"`
+	IsAdmin   bool      `json:"is_admin"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
@@ -37,3 +39,8 @@ type UserDTO struct {
 func (dto UserDTO) Validate() *errors.ErrorResponse {
 	return utils.ValidateStruct(dto)
 }
+
+// Role is the part of a user needed for authorization checks
+type Role struct {
+	IsAdmin bool
+}
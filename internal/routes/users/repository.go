@@ -98,3 +98,33 @@ func (r *UserRepository) GetUsers() ([]UserWithPassword, error) {
 
 	return r.Executor.QueryList(query)
 }
+
+// RoleRepository answers authorization checks about users for the other route packages
+type RoleRepository struct {
+	*utils.Repository[Role]
+	logger *logger.ContextualLogger
+}
+
+func NewRoleRepository(options ...utils.Option[Role]) *RoleRepository {
+	return &RoleRepository{
+		Repository: utils.NewRepository(options...),
+		logger:     logger.NewRepositoryLogger("RoleRepository"),
+	}
+}
+
+// IsAdmin reports whether the user is an admin; unknown users are not
+func (r *RoleRepository) IsAdmin(userID int) (bool, error) {
+	role, err := r.Executor.QueryItem(`SELECT is_admin FROM users WHERE id = $1`, userID)
+	if err != nil {
+		if err.Error() == "no rows in result set" {
+			return false, nil
+		}
+		r.logger.Error("Failed to fetch user role", map[string]any{
+			"userID": userID,
+			"error":  err.Error(),
+		})
+		return false, err
+	}
+
+	return role.IsAdmin, nil
+}
@@ -1,7 +1,11 @@
 package users
 
 import (
+	"context"
 	"testing"
+
+	"cribeapp.com/cribe-server/internal/utils"
+	"github.com/pashagolub/pgxmock/v4"
 )
 
 const userTestEmail = "john.doe.user.repository@example.com"
@@ -151,3 +155,32 @@ func TestUserRepository_GetUsers(t *testing.T) {
 		}
 	})
 }
+
+func TestRoleRepository_IsAdmin(t *testing.T) {
+	conn, _ := pgxmock.NewConn()
+	defer func() { _ = conn.Close(context.Background()) }()
+
+	roleDB := utils.NewDatabase[Role](conn)
+	roles := NewRoleRepository(utils.WithQueryExecutor(utils.QueryExecutor[Role]{
+		QueryItem: roleDB.QueryItem,
+		QueryList: roleDB.QueryList,
+		Exec:      roleDB.Exec,
+	}))
+
+	conn.ExpectQuery("SELECT is_admin FROM users WHERE id = \\$1").
+		WithArgs(1).
+		WillReturnRows(pgxmock.NewRows([]string{"is_admin"}).AddRow(true))
+	conn.ExpectQuery("SELECT is_admin FROM users WHERE id = \\$1").
+		WithArgs(2).
+		WillReturnRows(pgxmock.NewRows([]string{"is_admin"}))
+
+	if isAdmin, err := roles.IsAdmin(1); err != nil || !isAdmin {
+		t.Errorf("Expected an admin, got %v, %v", isAdmin, err)
+	}
+	if isAdmin, err := roles.IsAdmin(2); err != nil || isAdmin {
+		t.Errorf("Expected an unknown user not to be an admin, got %v, %v", isAdmin, err)
+	}
+	if err := conn.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
@@ -83,6 +83,7 @@ func (service *UserService) sanitizeUser(user UserWithPassword) User {
 		FirstName: user.FirstName,
 		LastName:  user.LastName,
 		Email:     user.Email,
+		IsAdmin:   user.IsAdmin,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
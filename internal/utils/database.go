@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 // PgxConn interface to allow mocking
@@ -16,9 +18,23 @@ type PgxConn interface {
 	Close(ctx context.Context) error
 }
 
+// Transaction is a database transaction whose queries run on a single connection
+type Transaction interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Commit(ctx context.Context) error
+	Rollback(ctx context.Context) error
+}
+
+// querier runs queries either on a connection or inside a transaction
+type querier interface {
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+}
+
 // Database holds the connection
 type Database[T any] struct {
-	conn PgxConn
+	conn querier
 }
 
 // DatabaseInterface allows dependency injection for tests
@@ -29,12 +45,41 @@ type DatabaseInterface interface {
 }
 
 // NewDatabase returns a Database with an optional connection
-// If conn is nil, it will create a new connection internally
+// If conn is nil, it uses the connection pool shared by the process
 func NewDatabase[T any](conn PgxConn) *Database[T] {
-	if conn == nil {
-		conn = NewConnection()
+	if conn != nil {
+		return &Database[T]{conn: conn}
+	}
+	if pool := SharedPool(); pool != nil {
+		return &Database[T]{conn: pool}
 	}
-	return &Database[T]{conn: conn}
+	return &Database[T]{}
+}
+
+// NewTxDatabase returns a Database whose queries run inside the given transaction
+func NewTxDatabase[T any](tx Transaction) *Database[T] {
+	return &Database[T]{conn: tx}
+}
+
+var (
+	sharedPool     *pgxpool.Pool
+	sharedPoolOnce sync.Once
+)
+
+// SharedPool returns the connection pool shared by the repositories of the process, created
+// on first use. Connections are opened lazily, so it's nil only for an invalid DATABASE_URL.
+func SharedPool() *pgxpool.Pool {
+	sharedPoolOnce.Do(func() {
+		pool, err := pgxpool.New(context.Background(), os.Getenv("DATABASE_URL"))
+		if err != nil {
+			log.Error("Unable to create connection pool", map[string]any{
+				"error": err.Error(),
+			})
+			return
+		}
+		sharedPool = pool
+	})
+	return sharedPool
 }
 
 // NewConnection creates a new pgx connection
@@ -134,3 +179,24 @@ func (db *Database[T]) Exec(query string, params ...any) error {
 
 	return nil
 }
+
+// Begin starts a transaction on the database connection. On the shared pool each transaction
+// gets a connection of its own, so concurrent transactions don't see each other's statements.
+func (db *Database[T]) Begin() (Transaction, error) {
+	conn, ok := db.conn.(interface {
+		Begin(ctx context.Context) (pgx.Tx, error)
+	})
+	if !ok {
+		return nil, fmt.Errorf("failed to connect to database")
+	}
+
+	tx, err := conn.Begin(context.Background())
+	if err != nil {
+		log.Error("Unable to begin transaction", map[string]any{
+			"error": err.Error(),
+		})
+		return nil, err
+	}
+
+	return tx, nil
+}
@@ -4,8 +4,12 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"sync"
 	"testing"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
@@ -275,3 +279,186 @@ func TestNewConnection_InvalidURL(t *testing.T) {
 		}
 	}
 }
+
+func TestRunInTransaction_Commit(t *testing.T) {
+	conn, _ := pgxmock.NewConn()
+	defer func() { _ = conn.Close(context.Background()) }()
+	repo := NewRepository[TestItem](WithQueryExecutor(QueryExecutor[TestItem]{
+		Begin: NewDatabase[TestItem](conn).Begin,
+	}))
+
+	conn.ExpectBegin()
+	conn.ExpectExec("INSERT INTO test_items").WithArgs("Alice").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	conn.ExpectExec("INSERT INTO test_items").WithArgs("Bob").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	conn.ExpectCommit()
+
+	err := RunInTransaction(repo.Executor.Begin, func(tx Transaction) error {
+		txRepo := repo.WithTx(tx)
+		if err := txRepo.Executor.Exec("INSERT INTO test_items (name) VALUES ($1)", "Alice"); err != nil {
+			return err
+		}
+		return txRepo.Executor.Exec("INSERT INTO test_items (name) VALUES ($1)", "Bob")
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+
+	if err := conn.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestRunInTransaction_Rollback(t *testing.T) {
+	conn, _ := pgxmock.NewConn()
+	defer func() { _ = conn.Close(context.Background()) }()
+	db := NewDatabase[TestItem](conn)
+
+	conn.ExpectBegin()
+	conn.ExpectExec("INSERT INTO test_items").WithArgs("Alice").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	conn.ExpectExec("INSERT INTO test_items").WithArgs("Bob").WillReturnError(fmt.Errorf("constraint violation"))
+	conn.ExpectRollback()
+
+	err := RunInTransaction(db.Begin, func(tx Transaction) error {
+		txDB := NewTxDatabase[TestItem](tx)
+		if err := txDB.Exec("INSERT INTO test_items (name) VALUES ($1)", "Alice"); err != nil {
+			return err
+		}
+		return txDB.Exec("INSERT INTO test_items (name) VALUES ($1)", "Bob")
+	})
+	if err == nil || err.Error() != "constraint violation" {
+		t.Errorf("Expected constraint violation error, got %v", err)
+	}
+
+	if err := conn.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestRunInTransaction_BeginError(t *testing.T) {
+	conn, _ := pgxmock.NewConn()
+	defer func() { _ = conn.Close(context.Background()) }()
+	db := NewDatabase[TestItem](conn)
+
+	conn.ExpectBegin().WillReturnError(fmt.Errorf("connection lost"))
+
+	called := false
+	err := RunInTransaction(db.Begin, func(tx Transaction) error {
+		called = true
+		return nil
+	})
+	if err == nil {
+		t.Error("Expected begin error, got nil")
+	}
+	if called {
+		t.Error("Expected fn not to run when begin fails")
+	}
+
+	if err := conn.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations: %v", err)
+	}
+}
+
+func TestRunInTransaction_WithoutBegin(t *testing.T) {
+	repo := &Repository[TestItem]{}
+
+	err := RunInTransaction(repo.Executor.Begin, func(tx Transaction) error {
+		if tx != nil {
+			t.Error("Expected nil transaction without begin")
+		}
+		if repo.WithTx(tx) != repo {
+			t.Error("Expected WithTx(nil) to return the same repository")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("Unexpected error: %v", err)
+	}
+}
+
+func TestNewDatabase_SharedPool(t *testing.T) {
+	db := NewDatabase[TestItem](nil)
+	if _, ok := db.conn.(*pgxpool.Pool); !ok {
+		t.Fatalf("Expected the shared pool, got %T", db.conn)
+	}
+	if db.conn != NewDatabase[TestItem](nil).conn {
+		t.Error("Expected databases to share one pool")
+	}
+}
+
+// mockPool hands each transaction the next of its connections, like pgxpool
+type mockPool struct {
+	mu    sync.Mutex
+	conns []pgxmock.PgxConnIface
+}
+
+func (p *mockPool) Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error) {
+	return nil, fmt.Errorf("unexpected query outside a transaction")
+}
+
+func (p *mockPool) Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error) {
+	return pgconn.CommandTag{}, fmt.Errorf("unexpected exec outside a transaction")
+}
+
+func (p *mockPool) Begin(ctx context.Context) (pgx.Tx, error) {
+	p.mu.Lock()
+	conn := p.conns[0]
+	p.conns = p.conns[1:]
+	p.mu.Unlock()
+	return conn.Begin(ctx)
+}
+
+func TestRunInTransaction_Concurrent(t *testing.T) {
+	failing, _ := pgxmock.NewConn()
+	defer func() { _ = failing.Close(context.Background()) }()
+	committing, _ := pgxmock.NewConn()
+	defer func() { _ = committing.Close(context.Background()) }()
+
+	failing.ExpectBegin()
+	failing.ExpectExec("INSERT INTO test_items").WithArgs("Alice").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	failing.ExpectRollback()
+	committing.ExpectBegin()
+	committing.ExpectExec("INSERT INTO test_items").WithArgs("Bob").WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	committing.ExpectCommit()
+
+	db := &Database[TestItem]{conn: &mockPool{conns: []pgxmock.PgxConnIface{failing, committing}}}
+
+	// The first transaction stays open until the second one has committed
+	started := make(chan struct{})
+	committed := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(2)
+	var failErr, commitErr error
+	go func() {
+		defer wg.Done()
+		failErr = RunInTransaction(db.Begin, func(tx Transaction) error {
+			close(started)
+			if err := NewTxDatabase[TestItem](tx).Exec("INSERT INTO test_items (name) VALUES ($1)", "Alice"); err != nil {
+				return err
+			}
+			<-committed
+			return fmt.Errorf("constraint violation")
+		})
+	}()
+	go func() {
+		defer wg.Done()
+		<-started
+		commitErr = RunInTransaction(db.Begin, func(tx Transaction) error {
+			return NewTxDatabase[TestItem](tx).Exec("INSERT INTO test_items (name) VALUES ($1)", "Bob")
+		})
+		close(committed)
+	}()
+	wg.Wait()
+
+	if failErr == nil || failErr.Error() != "constraint violation" {
+		t.Errorf("Expected constraint violation error, got %v", failErr)
+	}
+	if commitErr != nil {
+		t.Errorf("Unexpected error: %v", commitErr)
+	}
+	if err := failing.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations on the rolled back transaction: %v", err)
+	}
+	if err := committing.ExpectationsWereMet(); err != nil {
+		t.Errorf("Unmet expectations on the committed transaction: %v", err)
+	}
+}
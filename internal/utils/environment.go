@@ -2,6 +2,8 @@ package utils
 
 import (
 	"os"
+	"strconv"
+	"strings"
 )
 
 func GetPort() string {
@@ -13,3 +15,24 @@ func GetPort() string {
 
 	return port
 }
+
+// GetEnvInt returns the integer value of an environment variable,
+// falling back to the default when it is unset or not a positive integer
+func GetEnvInt(key string, fallback int) int {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return fallback
+	}
+
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed <= 0 {
+		log.Warn("Invalid integer environment variable, using default", map[string]any{
+			"key":      key,
+			"value":    value,
+			"fallback": fallback,
+		})
+		return fallback
+	}
+
+	return parsed
+}
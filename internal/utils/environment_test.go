@@ -143,3 +143,37 @@ func TestGetPort_EnvironmentIsolation(t *testing.T) {
 		t.Errorf("Third call: expected 8080, got %s", result3)
 	}
 }
+
+func TestGetEnvInt(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    string
+		fallback int
+		expected int
+	}{
+		{"unset returns fallback", "", 15, 15},
+		{"valid value is parsed", "30", 15, 30},
+		{"surrounding spaces are ignored", " 45 ", 15, 45},
+		{"non-numeric returns fallback", "abc", 15, 15},
+		{"zero returns fallback", "0", 15, 15},
+		{"negative returns fallback", "-5", 15, 15},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			const key = "TEST_GET_ENV_INT"
+			if test.value == "" {
+				_ = os.Unsetenv(key)
+			} else {
+				_ = os.Setenv(key, test.value)
+			}
+			defer func() { _ = os.Unsetenv(key) }()
+
+			result := GetEnvInt(key, test.fallback)
+
+			if result != test.expected {
+				t.Errorf("Expected %d, got %d", test.expected, result)
+			}
+		})
+	}
+}
@@ -1,10 +1,19 @@
 package utils
 
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
 // QueryExecutor is a generic interface for executing database queries
 type QueryExecutor[T any] struct {
 	QueryItem func(query string, args ...any) (T, error)
 	QueryList func(query string, args ...any) ([]T, error)
 	Exec      func(query string, args ...any) error
+	// Begin starts a transaction; executors without it run transactional work directly
+	Begin func() (Transaction, error)
 }
 
 // Repository is a generic interface for repository operations
@@ -32,6 +41,7 @@ func NewRepository[T any](options ...Option[T]) *Repository[T] {
 			QueryItem: db.QueryItem,
 			QueryList: db.QueryList,
 			Exec:      db.Exec,
+			Begin:     db.Begin,
 		},
 	}
 	for _, option := range options {
@@ -39,3 +49,82 @@ func NewRepository[T any](options ...Option[T]) *Repository[T] {
 	}
 	return repo
 }
+
+// WithConnection runs fn with a repository on a pooled connection of its own, released when fn
+// returns. Use it for work that needs all its statements on one session, like a held lock.
+func WithConnection[T any](fn func(repo *Repository[T]) error) error {
+	return withPoolConnection(SharedPool(), fn)
+}
+
+func withPoolConnection[T any](pool *pgxpool.Pool, fn func(repo *Repository[T]) error) error {
+	if pool == nil {
+		return fmt.Errorf("failed to connect to database")
+	}
+	conn, err := pool.Acquire(context.Background())
+	if err != nil {
+		log.Error("Unable to acquire connection", map[string]any{
+			"error": err.Error(),
+		})
+		return fmt.Errorf("failed to connect to database")
+	}
+	defer conn.Release()
+
+	db := &Database[T]{conn: conn}
+	return fn(&Repository[T]{
+		Executor: QueryExecutor[T]{
+			QueryItem: db.QueryItem,
+			QueryList: db.QueryList,
+			Exec:      db.Exec,
+			Begin:     db.Begin,
+		},
+	})
+}
+
+// WithTx returns a copy of the repository whose queries run inside the transaction
+// A nil transaction returns the repository unchanged
+func (r *Repository[T]) WithTx(tx Transaction) *Repository[T] {
+	if tx == nil {
+		return r
+	}
+
+	db := NewTxDatabase[T](tx)
+	return &Repository[T]{
+		Executor: QueryExecutor[T]{
+			QueryItem: db.QueryItem,
+			QueryList: db.QueryList,
+			Exec:      db.Exec,
+		},
+	}
+}
+
+// RunInTransaction begins a transaction with begin and runs fn inside it
+// The transaction is committed when fn succeeds and rolled back otherwise
+// A nil begin runs fn with a nil transaction, so repositories keep their own executors
+func RunInTransaction(begin func() (Transaction, error), fn func(tx Transaction) error) error {
+	if begin == nil {
+		return fn(nil)
+	}
+
+	tx, err := begin()
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		if rollbackErr := tx.Rollback(context.Background()); rollbackErr != nil {
+			log.Error("Unable to roll back transaction", map[string]any{
+				"error": rollbackErr.Error(),
+			})
+		}
+		return err
+	}
+
+	if err := tx.Commit(context.Background()); err != nil {
+		log.Error("Unable to commit transaction", map[string]any{
+			"error": err.Error(),
+		})
+		return err
+	}
+
+	return nil
+}
@@ -321,16 +321,28 @@ func CreateTestTranscriptChunks(transcriptID int) error {
 	return nil
 }
 
-// CreateTestQuestions creates fake quiz questions with options for testing
+// CreateTestQuestions creates fake quiz questions with options for testing, in the question
+// bank of the default quiz configuration
 func CreateTestQuestions(episodeID int) error {
 	db := NewDatabase[struct{ ID int }](nil)
 
+	bank, err := db.QueryItem(
+		`INSERT INTO question_banks (episode_id, multiple_choice, true_false, open_ended, difficulty)
+		 VALUES ($1, 1, 1, 1, 'medium')
+		 RETURNING id`,
+		episodeID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create question bank: %w", err)
+	}
+
 	// Create first question
 	question1, err := db.QueryItem(
-		`INSERT INTO questions (episode_id, question_text, type, position, created_at)
-		 VALUES ($1, $2, $3, $4, NOW())
+		`INSERT INTO questions (episode_id, bank_id, question_text, type, position, created_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())
 		 RETURNING id`,
 		episodeID,
+		bank.ID,
 		"What is the topic of this episode?",
 		"multiple_choice",
 		0,
@@ -362,10 +374,11 @@ func CreateTestQuestions(episodeID int) error {
 
 	// Create second question
 	question2, err := db.QueryItem(
-		`INSERT INTO questions (episode_id, question_text, type, position, created_at)
-		 VALUES ($1, $2, $3, $4, NOW())
+		`INSERT INTO questions (episode_id, bank_id, question_text, type, position, created_at)
+		 VALUES ($1, $2, $3, $4, $5, NOW())
 		 RETURNING id`,
 		episodeID,
+		bank.ID,
 		"How many speakers are in this episode?",
 		"multiple_choice",
 		1,